@@ -12,12 +12,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var app *application.Avalanche
+var (
+	app                  *application.Avalanche
+	localNetworkInstance string
+)
 
 // backendCmd is the command to run the backend gRPC process
 func NewCmd(injectedApp *application.Avalanche) *cobra.Command {
 	app = injectedApp
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:    constants.BackendCmd,
 		Short:  "Run the backend server",
 		Long:   "This tool requires a backend process to run; this command starts it",
@@ -25,9 +28,14 @@ func NewCmd(injectedApp *application.Avalanche) *cobra.Command {
 		Args:   cobra.ExactArgs(0),
 		Hidden: true,
 	}
+	cmd.Flags().StringVar(&localNetworkInstance, constants.LocalNetworkInstanceFlag, "", "local network instance to serve")
+	return cmd
 }
 
 func startBackend(_ *cobra.Command, _ []string) error {
+	if err := app.SetLocalNetworkInstance(localNetworkInstance); err != nil {
+		return err
+	}
 	s, err := binutils.NewGRPCServer(app.GetSnapshotsDir())
 	if err != nil {
 		return err
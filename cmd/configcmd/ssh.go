@@ -0,0 +1,86 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package configcmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sshMaxParallel int
+	sshRetries     int
+	sshKeepAlive   string
+	sshReuse       bool
+)
+
+// avalanche config ssh command
+func newSSHCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ssh",
+		Short: "set the ssh connection settings of node commands",
+		Long: `set the default ssh connection settings used by node commands to reach the cluster hosts.
+
+--max-parallel bounds the ssh connection attempts and remote operations running at
+the same time, --retries and the exponential backoff between them apply to transient
+connection errors, --keepalive is the interval of keepalive requests on open connections
+and --reuse keeps connections open across the steps of a command.
+
+Each node command can override these settings with its --ssh-* flags.
+Without flags, the command shows the current settings.`,
+		RunE:         handleSSHSettings,
+		Args:         cobra.ExactArgs(0),
+		SilenceUsage: true,
+	}
+	cmd.Flags().IntVar(&sshMaxParallel, "max-parallel", 0, "maximum ssh connection attempts and remote operations running at the same time")
+	cmd.Flags().IntVar(&sshRetries, "retries", 0, "ssh connection attempts on transient errors")
+	cmd.Flags().StringVar(&sshKeepAlive, "keepalive", "", "interval between keepalive requests on open ssh connections (0 disables them)")
+	cmd.Flags().BoolVar(&sshReuse, "reuse", true, "reuse ssh connections across the steps of a command")
+	return cmd
+}
+
+func handleSSHSettings(cmd *cobra.Command, _ []string) error {
+	if cmd.Flags().Changed("max-parallel") {
+		if sshMaxParallel < 1 {
+			return fmt.Errorf("--max-parallel must be at least 1")
+		}
+		if err := app.Conf.SetConfigValue(constants.ConfigSSHMaxParallelKey, sshMaxParallel); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("retries") {
+		if sshRetries < 1 {
+			return fmt.Errorf("--retries must be at least 1")
+		}
+		if err := app.Conf.SetConfigValue(constants.ConfigSSHRetriesKey, sshRetries); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("keepalive") {
+		if _, err := time.ParseDuration(sshKeepAlive); err != nil {
+			return fmt.Errorf("invalid --keepalive value: %w", err)
+		}
+		if err := app.Conf.SetConfigValue(constants.ConfigSSHKeepAliveKey, sshKeepAlive); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("reuse") {
+		if err := app.Conf.SetConfigValue(constants.ConfigSSHConnectionReuseKey, sshReuse); err != nil {
+			return err
+		}
+	}
+	settings, err := app.Conf.GetSSHSettings()
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("SSH settings:")
+	ux.Logger.PrintToUser("  max parallel: %d", settings.MaxParallel)
+	ux.Logger.PrintToUser("  retries: %d", settings.Retries)
+	ux.Logger.PrintToUser("  keepalive: %s", settings.KeepAlive)
+	ux.Logger.PrintToUser("  connection reuse: %t", settings.Reuse)
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package configcmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+)
+
+var validationExpiryWarning string
+
+// avalanche config validation command
+func newValidationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validation",
+		Short: "set when node commands warn about validations ending",
+		Long: `set how long before the end of a validation node status and node validate status
+warn about it.
+
+Each of these commands can override the setting with its --expiry-warning flag.
+It is also the threshold of the ValidationEndingSoon alert of clusters with monitoring,
+applied the next time their alert rules are regenerated, for example when
+avalanche node monitoring alerts updates the alerting of the cluster.
+Without flags, the command shows the current setting.`,
+		RunE:         handleValidationSettings,
+		Args:         cobra.ExactArgs(0),
+		SilenceUsage: true,
+	}
+	cmd.Flags().StringVar(&validationExpiryWarning, "expiry-warning", "", "warn about validations ending within the given duration (eg 168h)")
+	return cmd
+}
+
+func handleValidationSettings(cmd *cobra.Command, _ []string) error {
+	if cmd.Flags().Changed("expiry-warning") {
+		threshold, err := time.ParseDuration(validationExpiryWarning)
+		if err != nil {
+			return fmt.Errorf("invalid --expiry-warning value: %w", err)
+		}
+		if threshold < 0 {
+			return fmt.Errorf("--expiry-warning can't be negative")
+		}
+		if err := app.Conf.SetConfigValue(constants.ConfigValidationExpiryWarnKey, validationExpiryWarning); err != nil {
+			return err
+		}
+	}
+	threshold, err := app.Conf.GetValidationExpiryWarning()
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Validation settings:")
+	ux.Logger.PrintToUser("  expiry warning: %s", threshold)
+	return nil
+}
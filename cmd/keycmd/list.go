@@ -61,6 +61,7 @@ keys or for the ledger addresses associated to certain indices.`,
 		SilenceUsage: true,
 	}
 	networkoptions.AddNetworkFlagsToCmd(cmd, &globalNetworkFlags, false, listSupportedNetworkOptions)
+	networkoptions.AddLocalInstanceFlagToCmd(cmd, &globalNetworkFlags)
 	cmd.Flags().BoolVarP(
 		&all,
 		allFlag,
@@ -181,6 +182,9 @@ func listKeys(*cobra.Command, []string) error {
 	var addrInfos []addressInfo
 	networks := []models.Network{}
 	if globalNetworkFlags.UseLocal || all {
+		if err := app.SetLocalNetworkInstance(globalNetworkFlags.LocalInstance); err != nil {
+			return err
+		}
 		networks = append(networks, models.NewLocalNetwork())
 	}
 	if globalNetworkFlags.UseTahoe || all {
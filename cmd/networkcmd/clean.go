@@ -13,6 +13,7 @@ import (
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/shirou/gopsutil/process"
 	"github.com/spf13/cobra"
@@ -27,7 +28,9 @@ func newCleanCmd() *cobra.Command {
 		Short: "Stop the running local network and delete state",
 		Long: `The network clean command shuts down your local, multi-node network. All deployed Subnets
 shutdown and delete their state. You can restart the network by deploying a new Subnet
-configuration.`,
+configuration.
+
+When used on a named instance (--instance), the instance itself is also removed.`,
 		RunE:         clean,
 		Args:         cobra.ExactArgs(0),
 		SilenceUsage: true,
@@ -44,6 +47,9 @@ configuration.`,
 }
 
 func clean(*cobra.Command, []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
 	app.Log.Info("killing gRPC server process...")
 
 	configSingleNodeEnabled := app.Conf.GetConfigBoolValue(constants.ConfigSingleNodeEnabledKey)
@@ -57,6 +63,10 @@ func clean(*cobra.Command, []string) error {
 		return err
 	}
 
+	if err := teleporter.StopRelayer(app.GetAWMRelayerRunPath()); err != nil {
+		app.Log.Warn("failed stopping relayer", zap.Error(err))
+	}
+
 	if err := binutils.KillgRPCServerProcess(app); err != nil {
 		app.Log.Warn("failed killing server process", zap.Error(err))
 	} else {
@@ -88,6 +98,16 @@ func clean(*cobra.Command, []string) error {
 	if err := removeLocalElasticSubnetInfoFromSidecars(); err != nil {
 		return err
 	}
+	if instance := models.CurrentLocalNetworkInstance(); !instance.IsDefault() {
+		// named instances are fully released, so their port range can be reused
+		if err := os.RemoveAll(app.GetLocalNetworkInstanceDir()); err != nil {
+			return err
+		}
+		if err := app.RemoveLocalNetworkInstance(instance.Name); err != nil {
+			return err
+		}
+		ux.Logger.PrintToUser("Local network instance %s removed.", instance.Name)
+	}
 	return nil
 }
 
@@ -104,7 +124,7 @@ func removeLocalDeployInfoFromSidecars() error {
 			return err
 		}
 
-		delete(sc.Networks, models.Local.String())
+		delete(sc.Networks, models.NewLocalNetwork().Name())
 		if err = app.UpdateSidecar(&sc); err != nil {
 			return err
 		}
@@ -125,7 +145,7 @@ func removeLocalElasticSubnetInfoFromSidecars() error {
 			return err
 		}
 
-		delete(sc.ElasticSubnet, models.Local.String())
+		delete(sc.ElasticSubnet, models.NewLocalNetwork().Name())
 		if err = app.UpdateSidecar(&sc); err != nil {
 			return err
 		}
@@ -0,0 +1,61 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package networkcmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ixAnkit/cryft/pkg/binutils"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Lists local network instances",
+		Long: `The network list command prints all local network instances known to this
+machine, together with their port ranges and whether their backend controller is running.`,
+		RunE:         listInstances,
+		Args:         cobra.ExactArgs(0),
+		SilenceUsage: true,
+	}
+}
+
+func listInstances(*cobra.Command, []string) error {
+	instances, err := app.GetLocalNetworkInstances()
+	if err != nil {
+		return err
+	}
+	selectedInstance := models.CurrentLocalNetworkInstance()
+	defer models.SetCurrentLocalNetworkInstance(selectedInstance)
+	procChecker := binutils.NewProcessChecker()
+	header := []string{"Instance", "gRPC Port", "Node Ports", "Endpoint", "Backend Running"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	for _, instance := range instances {
+		models.SetCurrentLocalNetworkInstance(instance)
+		isRunning, err := procChecker.IsServerProcessRunning(app)
+		if err != nil {
+			return err
+		}
+		running := constants.NoLabel
+		if isRunning {
+			running = constants.YesLabel
+		}
+		firstPort, lastPort := instance.NodePortRange()
+		table.Append([]string{
+			instance.Name,
+			fmt.Sprintf("%d", instance.GRPCServerPort()),
+			fmt.Sprintf("%d-%d", firstPort, lastPort),
+			instance.Endpoint(),
+			running,
+		})
+	}
+	table.Render()
+	return nil
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 
 	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/spf13/cobra"
 )
 
-var app *application.Avalanche
+var (
+	app                  *application.Avalanche
+	localNetworkInstance string
+)
 
 func NewCmd(injectedApp *application.Avalanche) *cobra.Command {
 	app = injectedApp
@@ -23,7 +27,11 @@ When you deploy a Subnet locally, it runs on a local, multi-node Avalanche netwo
 subnet deploy command starts this network in the background. This command suite allows you
 to shutdown, restart, and clear that network.
 
-This network currently supports multiple, concurrently deployed Subnets.`,
+This network currently supports multiple, concurrently deployed Subnets.
+
+Several independent local networks can run side by side by selecting a named
+instance with --instance. Each instance has its own port range, backend controller,
+run dir and snapshots.`,
 		Run: func(cmd *cobra.Command, _ []string) {
 			err := cmd.Help()
 			if err != nil {
@@ -32,6 +40,12 @@ This network currently supports multiple, concurrently deployed Subnets.`,
 		},
 		Args: cobra.ExactArgs(0),
 	}
+	cmd.PersistentFlags().StringVar(
+		&localNetworkInstance,
+		constants.LocalNetworkInstanceFlag,
+		constants.DefaultLocalNetworkInstance,
+		"local network instance to operate on",
+	)
 	// network start
 	cmd.AddCommand(newStartCmd())
 	// network stop
@@ -40,5 +54,13 @@ This network currently supports multiple, concurrently deployed Subnets.`,
 	cmd.AddCommand(newCleanCmd())
 	// network status
 	cmd.AddCommand(newStatusCmd())
+	// network list
+	cmd.AddCommand(newListCmd())
 	return cmd
 }
+
+// selectLocalNetworkInstance must be called by every network subcommand
+// before accessing the local network
+func selectLocalNetworkInstance() error {
+	return app.SetLocalNetworkInstance(localNetworkInstance)
+}
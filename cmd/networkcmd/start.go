@@ -54,6 +54,9 @@ already running.`,
 }
 
 func StartNetwork(*cobra.Command, []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
 	var (
 		err          error
 		avagoVersion string
@@ -202,15 +205,15 @@ func determineAvagoVersion(userProvidedAvagoVersion string) (string, error) {
 
 		// if you have a custom vm, you must provide the version explicitly
 		// if you upgrade from subnet-evm to a custom vm, the RPC version will be 0
-		if sc.VM == models.CustomVM || sc.Networks[models.Local.String()].RPCVersion == 0 {
+		if sc.VM == models.CustomVM || sc.Networks[models.NewLocalNetwork().Name()].RPCVersion == 0 {
 			continue
 		}
 
 		if currentRPCVersion == -1 {
-			currentRPCVersion = sc.Networks[models.Local.String()].RPCVersion
+			currentRPCVersion = sc.Networks[models.NewLocalNetwork().Name()].RPCVersion
 		}
 
-		if sc.Networks[models.Local.String()].RPCVersion != currentRPCVersion {
+		if sc.Networks[models.NewLocalNetwork().Name()].RPCVersion != currentRPCVersion {
 			return "", fmt.Errorf(
 				"RPC version mismatch. Expected %d, got %d for Subnet %s. Upgrade all subnets to the same RPC version to launch the network",
 				currentRPCVersion,
@@ -5,6 +5,7 @@ package networkcmd
 import (
 	"github.com/ixAnkit/cryft/pkg/binutils"
 	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/utils"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/MetalBlockchain/metal-network-runner/server"
@@ -25,7 +26,15 @@ network is running and some basic stats about the network.`,
 }
 
 func networkStatus(*cobra.Command, []string) error {
-	ux.Logger.PrintToUser("Requesting network status...")
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
+	instance := models.CurrentLocalNetworkInstance()
+	if instance.IsDefault() {
+		ux.Logger.PrintToUser("Requesting network status...")
+	} else {
+		ux.Logger.PrintToUser("Requesting network status for local network instance %s...", instance.Name)
+	}
 
 	cli, err := binutils.NewGRPCClient(
 		binutils.WithDialTimeout(constants.FastGRPCDialTimeout),
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ixAnkit/cryft/pkg/binutils"
 	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
 	"github.com/ixAnkit/cryft/pkg/utils"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/MetalBlockchain/metal-network-runner/server"
@@ -38,11 +39,18 @@ default snapshot with network start.`,
 }
 
 func StopNetwork(*cobra.Command, []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
 	if err := saveNetwork(); errors.Is(err, binutils.ErrGRPCTimeout) {
 		// no server to kill
 		return nil
 	}
 
+	if err := teleporter.StopRelayer(app.GetAWMRelayerRunPath()); err != nil {
+		app.Log.Warn("failed stopping relayer", zap.Error(err))
+	}
+
 	relayerConfigPath := app.GetAWMRelayerConfigPath()
 	if utils.FileExists(relayerConfigPath) {
 		relayerStoredConfigPath := filepath.Join(app.GetAWMRelayerSnapshotConfsDir(), snapshotName+jsonExt)
@@ -0,0 +1,138 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/networkoptions"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+)
+
+func newAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add [clusterName]",
+		Short: "(ALPHA Warning) Add nodes to an existing cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node add command creates new nodes and adds them to an existing cluster.
+The new nodes are created in the cloud of the cluster, reusing its key pair and
+security group, and run the same avalanchego version as the current cluster
+nodes. Regions default to the region of the first cluster node.
+
+On Devnet clusters the new nodes join the existing devnet, bootstrapping from
+the current validators, and --num-apis nodes without stake can be added too.
+If the cluster has monitoring set up, the new nodes are added to its targets.
+
+Clusters of existing hosts are extended with the hosts listed in --existing-hosts.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         addNodes,
+	}
+	cmd.Flags().StringSliceVar(&cmdLineRegion, "region", []string{}, "create node(s) in given region(s). Use comma to separate multiple regions. defaults to the cluster region")
+	cmd.Flags().IntSliceVar(&numValidatorsNodes, "num-validators", []int{}, "number of nodes to create per region(s). Use comma to separate multiple numbers for each region in the same order as --region flag")
+	cmd.Flags().IntSliceVar(&numAPINodes, "num-apis", []int{}, "number of API nodes(nodes without stake) to create per region(s) [devnet only]")
+	cmd.Flags().StringVar(&nodeType, "node-type", "", "cloud instance type. Use 'default' to use recommended default instance type")
+	cmd.Flags().StringVar(&existingHostsFile, "existing-hosts", "", "add the servers listed in the given hosts yaml file [existing hosts clusters only]")
+	cmd.Flags().BoolVar(&authorizeAccess, "authorize-access", false, "authorize CLI to create cloud resources")
+	cmd.Flags().StringVar(&awsProfile, "aws-profile", constants.AWSDefaultCredential, "aws profile to use")
+	cmd.Flags().BoolVar(&useSSHAgent, "use-ssh-agent", false, "use ssh agent(ex: Yubikey) for ssh auth")
+	cmd.Flags().StringVar(&sshIdentity, "ssh-agent-identity", "", "use given ssh identity(only for ssh agent). If not set, default will be used")
+	// set from the cluster setup, so node create doesn't prompt for it
+	cmd.Flags().BoolVar(&addMonitoring, enableMonitoringFlag, false, "")
+	_ = cmd.Flags().MarkHidden(enableMonitoringFlag)
+	return cmd
+}
+
+func addNodes(cmd *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	if len(clusterConf.Nodes) == 0 {
+		return fmt.Errorf("cluster %s has no nodes to extend, use avalanche node create instead", clusterName)
+	}
+	refNodeConfig, err := app.LoadClusterNodeConfig(clusterConf.Nodes[0])
+	if err != nil {
+		return err
+	}
+	switch clusterConf.Network.Kind {
+	case models.Tahoe:
+		globalNetworkFlags = networkoptions.NetworkFlags{UseTahoe: true}
+		if len(numAPINodes) > 0 {
+			return fmt.Errorf("API nodes can only be added to Devnet clusters")
+		}
+	case models.Devnet:
+		globalNetworkFlags = networkoptions.NetworkFlags{UseDevnet: true}
+	default:
+		return fmt.Errorf("adding nodes to %s clusters is not supported", clusterConf.Network.Kind)
+	}
+	switch refNodeConfig.CloudService {
+	case constants.AWSCloudService:
+		useAWS = true
+	case constants.GCPCloudService:
+		useGCP = true
+	case constants.ExistingHostsCloudService:
+		if existingHostsFile == "" {
+			return fmt.Errorf("cluster %s is made of existing hosts, give the hosts to add with --existing-hosts", clusterName)
+		}
+	default:
+		return fmt.Errorf("adding nodes to %s clusters is not supported", refNodeConfig.CloudService)
+	}
+	if refNodeConfig.CloudService != constants.ExistingHostsCloudService {
+		if existingHostsFile != "" {
+			return fmt.Errorf("--existing-hosts can only be used on clusters of existing hosts")
+		}
+		if len(numValidatorsNodes) == 0 {
+			return fmt.Errorf("--num-validators must be given")
+		}
+		if len(cmdLineRegion) == 0 {
+			cmdLineRegion = []string{refNodeConfig.Region}
+		}
+		if clusterConf.Network.Kind == models.Devnet && len(numAPINodes) == 0 {
+			numAPINodes = make([]int, len(numValidatorsNodes))
+		}
+		useStaticIP = refNodeConfig.UseStaticIP
+	}
+	// new nodes run the same avalanchego version as the cluster
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	useCustomAvalanchegoVersion, err = getClusterAvalancheGoVersion(clusterName, clusterConf.GetValidatorHosts(hosts))
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Adding nodes running avalanchego %s to cluster %s", useCustomAvalanchegoVersion, clusterName)
+	// monitoring of the new nodes follows the cluster setup
+	addMonitoring = clusterConf.MonitoringInstance != ""
+	if err := cmd.Flags().Set(enableMonitoringFlag, fmt.Sprint(addMonitoring)); err != nil {
+		return err
+	}
+	return createNodes(cmd, []string{clusterName})
+}
+
+// getClusterAvalancheGoVersion returns the avalanchego version run by the first
+// responding host of the cluster
+func getClusterAvalancheGoVersion(clusterName string, hosts []*models.Host) (string, error) {
+	for _, host := range hosts {
+		resp, err := ssh.RunSSHCheckAvalancheGoVersion(host)
+		if err != nil {
+			continue
+		}
+		if version, _, err := parseAvalancheGoOutput(resp); err == nil {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("unable to get avalanchego version of cluster %s, no node is responding", clusterName)
+}
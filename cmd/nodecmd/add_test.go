@@ -0,0 +1,100 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddNodesChecks(t *testing.T) {
+	tests := []struct {
+		name              string
+		network           models.Network
+		nodes             []string
+		cloudService      string
+		numValidators     []int
+		numAPIs           []int
+		existingHostsFile string
+		expectedError     string
+	}{
+		{
+			name:          "unknown cluster",
+			expectedError: `cluster "other" does not exist`,
+		},
+		{
+			name:          "api nodes on tahoe",
+			network:       models.NewTahoeNetwork(),
+			nodes:         []string{"i-1"},
+			cloudService:  constants.AWSCloudService,
+			numValidators: []int{1},
+			numAPIs:       []int{1},
+			expectedError: "API nodes can only be added to Devnet clusters",
+		},
+		{
+			name:          "mainnet cluster",
+			network:       models.NewMainnetNetwork(),
+			nodes:         []string{"i-1"},
+			cloudService:  constants.AWSCloudService,
+			numValidators: []int{1},
+			expectedError: "adding nodes to Mainnet clusters is not supported",
+		},
+		{
+			name:          "existing hosts cluster without hosts",
+			network:       models.NewTahoeNetwork(),
+			nodes:         []string{"i-1"},
+			cloudService:  constants.ExistingHostsCloudService,
+			expectedError: "cluster cluster is made of existing hosts, give the hosts to add with --existing-hosts",
+		},
+		{
+			name:              "existing hosts on a cloud cluster",
+			network:           models.NewTahoeNetwork(),
+			nodes:             []string{"i-1"},
+			cloudService:      constants.AWSCloudService,
+			existingHostsFile: "hosts.yaml",
+			expectedError:     "--existing-hosts can only be used on clusters of existing hosts",
+		},
+		{
+			name:          "no validators",
+			network:       models.NewTahoeNetwork(),
+			nodes:         []string{"i-1"},
+			cloudService:  constants.GCPCloudService,
+			expectedError: "--num-validators must be given",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			app = application.New()
+			app.Setup(t.TempDir(), logging.NoLog{}, nil, nil, nil)
+			defaultNumValidators, defaultNumAPIs, defaultExistingHostsFile := numValidatorsNodes, numAPINodes, existingHostsFile
+			defaultUseAWS, defaultUseGCP, defaultNetworkFlags := useAWS, useGCP, globalNetworkFlags
+			t.Cleanup(func() {
+				numValidatorsNodes, numAPINodes, existingHostsFile = defaultNumValidators, defaultNumAPIs, defaultExistingHostsFile
+				useAWS, useGCP, globalNetworkFlags = defaultUseAWS, defaultUseGCP, defaultNetworkFlags
+			})
+			// flags are bound to their defaults when the command is created
+			cmd := newAddCmd()
+			numValidatorsNodes, numAPINodes, existingHostsFile = tt.numValidators, tt.numAPIs, tt.existingHostsFile
+			clusterName := "cluster"
+			if tt.nodes == nil {
+				clusterName = "other"
+			}
+			require.NoError(app.WriteClustersConfigFile(&models.ClustersConfig{
+				Clusters: map[string]models.ClusterConfig{"cluster": {Network: tt.network, Nodes: tt.nodes}},
+			}))
+			for _, cloudID := range tt.nodes {
+				require.NoError(app.CreateNodeCloudConfigFile(cloudID, &models.NodeConfig{
+					NodeID:       cloudID,
+					CloudService: tt.cloudService,
+				}))
+			}
+			require.EqualError(addNodes(cmd, []string{clusterName}), tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/snapshot"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/spf13/cobra"
+)
+
+const snapshotTimeout = 12 * time.Hour
+
+var (
+	backupNodeList         []string
+	backupOutput           string
+	backupStopNode         bool
+	backupNoStaking        bool
+	snapshotS3Options      snapshot.S3Options
+	snapshotPassphraseFile string
+)
+
+func newBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup [clusterName]",
+		Short: "(ALPHA Warning) Snapshot the database and staking files of the nodes in a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node backup command streams a snapshot of the avalanchego database and staking files
+of the cluster nodes over SSH into a local directory or an S3-compatible bucket
+(s3://bucket/prefix, use --s3-endpoint for other providers such as MinIO).
+
+Each snapshot is a tar.gz archive of the database together with a JSON manifest listing
+the node, avalanchego version, P-Chain height and SHA256 checksum of the archive. Snapshots
+taken while avalanchego is running may be inconsistent, so use --stop to stop the node while
+the snapshot is taken. Use avalanche node restore to seed a node from a snapshot.
+
+The staking files hold the node private keys, so they are stored in a separate archive
+encrypted with a passphrase read from --staking-passphrase-file or the
+` + constants.SnapshotPassphraseEnvVarName + ` environment variable. Use --no-staking to
+leave them out of the snapshot.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         backupCluster,
+	}
+	cmd.Flags().StringSliceVar(&backupNodeList, "node", []string{}, "comma separated list of nodes (cloud ID, IP or NodeID) to back up. defaults to all cluster nodes")
+	cmd.Flags().StringVar(&backupOutput, "output", "", "local directory or s3://bucket/prefix to store the snapshots at. defaults to the cluster backups dir")
+	cmd.Flags().BoolVar(&backupStopNode, "stop", false, "stop avalanchego while taking the snapshot, and restart it afterwards")
+	cmd.Flags().BoolVar(&backupNoStaking, "no-staking", false, "do not include the staking files in the snapshot")
+	addSnapshotStoreFlags(cmd)
+	addSnapshotPassphraseFlag(cmd)
+	return cmd
+}
+
+func addSnapshotStoreFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&snapshotS3Options.Endpoint, "s3-endpoint", "", "S3-compatible endpoint URL. defaults to AWS S3")
+	cmd.Flags().StringVar(&snapshotS3Options.Region, "s3-region", "", "S3 region. defaults to us-east-1")
+	cmd.Flags().StringVar(&snapshotS3Options.Profile, "aws-profile", constants.AWSDefaultCredential, "aws profile to use for S3 credentials")
+}
+
+func addSnapshotPassphraseFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&snapshotPassphraseFile, "staking-passphrase-file", "", "file holding the passphrase of the staking files archive. defaults to the "+constants.SnapshotPassphraseEnvVarName+" environment variable")
+}
+
+// getSnapshotPassphrase returns the passphrase of the staking files archives, read from
+// --staking-passphrase-file or the environment
+func getSnapshotPassphrase() ([]byte, error) {
+	passphrase := os.Getenv(constants.SnapshotPassphraseEnvVarName)
+	if snapshotPassphraseFile != "" {
+		content, err := os.ReadFile(utils.ExpandHome(snapshotPassphraseFile))
+		if err != nil {
+			return nil, err
+		}
+		passphrase = strings.TrimRight(string(content), "\r\n")
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("staking files are encrypted with a passphrase: set %s or use --staking-passphrase-file", constants.SnapshotPassphraseEnvVarName)
+	}
+	return []byte(passphrase), nil
+}
+
+func backupCluster(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	var passphrase []byte
+	if !backupNoStaking {
+		var err error
+		if passphrase, err = getSnapshotPassphrase(); err != nil {
+			return fmt.Errorf("%w, or use --no-staking to leave them out of the snapshot", err)
+		}
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	if backupOutput == "" {
+		backupOutput = app.GetNodeBackupsDir(clusterName)
+	}
+	store, err := snapshot.NewStore(backupOutput, snapshotS3Options)
+	if err != nil {
+		return err
+	}
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return err
+	}
+	if len(backupNodeList) > 0 {
+		hosts, err = filterHosts(hosts, backupNodeList)
+		if err != nil {
+			return err
+		}
+	}
+	defer disconnectHosts(hosts)
+	if !backupStopNode {
+		ux.Logger.PrintToUser(logging.Yellow.Wrap("Taking snapshots while avalanchego is running, the database may be inconsistent. Use --stop for consistent snapshots"))
+	}
+	// snapshots are taken one node at a time so the cluster doesn't lose several nodes at once
+	for _, host := range hosts {
+		manifest, err := backupNode(clusterName, clusterConf.Network.Name(), host, store, passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to back up node %s: %w", host.GetCloudID(), err)
+		}
+		ux.Logger.GreenCheckmarkToUser("Snapshot %s stored at %s (%d bytes)", manifest.Name, store.Location(), manifest.Size)
+	}
+	return nil
+}
+
+// backupNode streams a snapshot of the host into the store and saves its manifest. The
+// staking files are encrypted with [passphrase], and left out if it is empty
+func backupNode(clusterName string, network string, host *models.Host, store snapshot.Store, passphrase []byte) (*snapshot.Manifest, error) {
+	cloudID := host.GetCloudID()
+	manifest := &snapshot.Manifest{
+		Name:        snapshot.Name(clusterName, cloudID, time.Now()),
+		ClusterName: clusterName,
+		Network:     network,
+		CloudID:     cloudID,
+		CreatedAt:   time.Now().UTC(),
+		Stopped:     backupStopNode,
+		Contents:    []string{constants.CloudNodeDBDir},
+	}
+	manifest.Archive = manifest.Name + snapshot.ArchiveExtension
+	if len(passphrase) > 0 {
+		manifest.StakingArchive = manifest.Name + snapshot.StakingArchiveExtension
+	}
+	if nodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID)); err == nil {
+		manifest.NodeID = nodeID.String()
+	}
+	resp, err := ssh.RunSSHCheckAvalancheGoVersion(host)
+	if err != nil {
+		return nil, fmt.Errorf("avalanchego is not responding: %w", err)
+	}
+	if manifest.AvalancheGoVersion, _, err = parseAvalancheGoOutput(resp); err != nil {
+		return nil, err
+	}
+	if resp, err := ssh.RunSSHGetPChainHeight(host); err != nil {
+		ux.Logger.PrintToUser("Unable to get P-Chain height of node %s: %s", cloudID, err)
+	} else if manifest.PChainHeight, err = parsePChainHeightOutput(resp); err != nil {
+		ux.Logger.PrintToUser("Unable to get P-Chain height of node %s: %s", cloudID, err)
+	}
+
+	spinSession := ux.NewUserSpinner()
+	defer spinSession.Stop()
+	if backupStopNode {
+		spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Stopping avalanchego"))
+		if err := ssh.RunSSHStopNode(host); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return nil, err
+		}
+		ux.SpinComplete(spinner)
+		defer func() {
+			spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Starting avalanchego"))
+			if err := ssh.RunSSHStartNode(host); err != nil {
+				ux.SpinFailWithError(spinner, "", err)
+				return
+			}
+			ux.SpinComplete(spinner)
+		}()
+	}
+	spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Streaming snapshot %s", manifest.Archive))
+	if err := streamSnapshot(host, store, manifest); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return nil, err
+	}
+	if manifest.StakingArchive != "" {
+		if err := storeStakingArchive(host, store, manifest.StakingArchive, passphrase); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return nil, err
+		}
+	}
+	manifestBytes, err := manifest.Bytes()
+	if err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return nil, err
+	}
+	if err := store.Put(snapshot.ManifestName(manifest.Name), bytes.NewReader(manifestBytes), int64(len(manifestBytes))); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return nil, err
+	}
+	ux.SpinComplete(spinner)
+	return manifest, nil
+}
+
+// streamSnapshot streams the archive of the host into the store, filling in the
+// archive size and checksum of the manifest
+func streamSnapshot(host *models.Host, store snapshot.Store, manifest *snapshot.Manifest) error {
+	// tar exits with 1 when files change while being read, which is expected on a running node
+	tarCmd := fmt.Sprintf("tar -czf - -C %s %s; rc=$?; [ $rc -le 1 ]", constants.CloudNodeConfigBasePath, constants.CloudNodeDBDir)
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	if localStore, ok := store.(*snapshot.LocalStore); ok {
+		archive, err := localStore.Create(manifest.Archive)
+		if err != nil {
+			return err
+		}
+		if err := host.PipeSSHCommand(tarCmd, nil, io.MultiWriter(archive, hasher, counter), snapshotTimeout); err != nil {
+			_ = archive.Close()
+			return err
+		}
+		if err := archive.Close(); err != nil {
+			return err
+		}
+	} else {
+		// the archive size is unknown, so remote stores upload it in parts as it is streamed
+		pr, pw := io.Pipe()
+		putErrCh := make(chan error, 1)
+		go func() {
+			err := store.Put(manifest.Archive, pr, -1)
+			// unblocks the ssh stream if the upload fails before reading all of it
+			pr.CloseWithError(err)
+			putErrCh <- err
+		}()
+		err := host.PipeSSHCommand(tarCmd, nil, io.MultiWriter(pw, hasher, counter), snapshotTimeout)
+		// the upload is aborted if the stream failed
+		pw.CloseWithError(err)
+		putErr := <-putErrCh
+		if err != nil {
+			return err
+		}
+		if putErr != nil {
+			return putErr
+		}
+	}
+	manifest.Size = counter.n
+	manifest.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+	return nil
+}
+
+// storeStakingArchive stores the staking files of the host into the store as [name],
+// encrypted with [passphrase]
+func storeStakingArchive(host *models.Host, store snapshot.Store, name string, passphrase []byte) error {
+	tarCmd := fmt.Sprintf("tar -czf - -C %s %s", constants.CloudNodeConfigBasePath, constants.CloudNodeStakingDir)
+	var archive bytes.Buffer
+	if err := host.PipeSSHCommand(tarCmd, nil, &archive, constants.SSHScriptTimeout); err != nil {
+		return fmt.Errorf("failed to archive staking files: %w", err)
+	}
+	encrypted, err := snapshot.Encrypt(archive.Bytes(), passphrase)
+	if err != nil {
+		return err
+	}
+	return store.Put(name, bytes.NewReader(encrypted), int64(len(encrypted)))
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+func parsePChainHeightOutput(byteValue []byte) (uint64, error) {
+	reply := struct {
+		Result struct {
+			Height string `json:"height"`
+		} `json:"result"`
+	}{}
+	if err := json.Unmarshal(byteValue, &reply); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(reply.Result.Height, 10, 64)
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
+	dockerAPI "github.com/ixAnkit/cryft/pkg/cloud/docker"
+	existingAPI "github.com/ixAnkit/cryft/pkg/cloud/existing"
+	gcpAPI "github.com/ixAnkit/cryft/pkg/cloud/gcp"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"golang.org/x/net/context"
+)
+
+// separateHostPorts are the cluster host ports opened to separate monitoring and load test hosts
+var separateHostPorts = []int{constants.AvalanchegoMachineMetricsPort, constants.AvalanchegoAPIPort}
+
+// getCloudProvider returns the cloud provider managing hosts of the given cloud service
+func getCloudProvider(cloudService string) (cloud.Provider, error) {
+	switch cloudService {
+	case "", constants.AWSCloudService:
+		return awsAPI.NewProvider(awsProfile), nil
+	case constants.GCPCloudService:
+		gcpClient, projectName, _, err := getGCPCloudCredentials()
+		if err != nil {
+			return nil, err
+		}
+		gcpCloud, err := gcpAPI.NewGcpCloud(gcpClient, projectName, context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return gcpAPI.NewProvider(gcpCloud), nil
+	case constants.DockerCloudService:
+		return getDockerProvider(), nil
+	case constants.ExistingHostsCloudService:
+		return existingAPI.NewProvider(), nil
+	default:
+		return nil, fmt.Errorf("cloud service %s is not supported", cloudService)
+	}
+}
+
+// getDockerProvider returns the provider for local docker hosts. All docker
+// hosts share a single compose file
+func getDockerProvider() *dockerAPI.Provider {
+	composeFile := filepath.Join(app.GetNodesDir(), constants.DockerComposeFileName)
+	if utils.IsE2E() {
+		composeFile = constants.E2EDockerComposeFile
+	}
+	return dockerAPI.NewProvider(composeFile)
+}
+
+// checkCloudAccess asks the user to authorize access to the cloud account, if
+// not already authorized. Local docker hosts and existing hosts need no authorization
+func checkCloudAccess(cloudService string) error {
+	if cloudService == constants.DockerCloudService || cloudService == constants.ExistingHostsCloudService {
+		return nil
+	}
+	if !(authorizeAccess || authorizedAccessFromSettings()) && (requestCloudAuth(cloudService) != nil) {
+		return fmt.Errorf("cloud access is required")
+	}
+	return nil
+}
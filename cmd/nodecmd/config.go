@@ -0,0 +1,216 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+var (
+	configNodes    []string
+	configRole     string
+	configOnlyDiff bool
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "(ALPHA Warning) Manage the avalanchego config of the nodes of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node config command suite provides a collection of commands to read the avalanchego
+node config (node.json) of all nodes in a cluster, and to change avalanchego flags on
+selected nodes or roles.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := cmd.Help()
+			if err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+	// node config get clusterName [key...]
+	cmd.AddCommand(newConfigGetCmd())
+	// node config set clusterName key=value...
+	cmd.AddCommand(newConfigSetCmd())
+	// node config apply clusterName patchFile
+	cmd.AddCommand(newConfigApplyCmd())
+	return cmd
+}
+
+func newConfigGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get [clusterName] [key...]",
+		Short: "(ALPHA Warning) Show the avalanchego config of the nodes in a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node config get command reads the avalanchego node config from all nodes in the cluster
+and shows each setting with the nodes that use each value, so differences between nodes
+are easy to spot. If keys are given, only those settings are shown.`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE:         getNodesConfig,
+	}
+	addConfigSelectionFlags(cmd)
+	cmd.Flags().BoolVar(&configOnlyDiff, "diff", false, "only show settings that differ between nodes")
+	return cmd
+}
+
+func addConfigSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&configNodes, "nodes", []string{}, "comma separated list of nodes (cloud ID, IP or NodeID). defaults to all cluster nodes")
+	cmd.Flags().StringVar(&configRole, "role", "", "only use nodes with the given role (validator or api)")
+	addSelectorFlag(cmd)
+}
+
+func getNodesConfig(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	keys := args[1:]
+	hosts, err := getConfigHosts(clusterName)
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	nodeConfigs, err := downloadNodesConfig(hosts)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		for _, nodeConfig := range nodeConfigs {
+			keys = append(keys, maps.Keys(nodeConfig)...)
+		}
+		keys = utils.Unique(keys)
+		sort.Strings(keys)
+	}
+	header := []string{"Key", "Value", "Nodes"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	for _, key := range keys {
+		valueHosts := map[string][]string{}
+		for cloudID, nodeConfig := range nodeConfigs {
+			value := "<unset>"
+			if v, ok := nodeConfig[key]; ok {
+				valueBytes, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				value = string(valueBytes)
+			}
+			valueHosts[value] = append(valueHosts[value], cloudID)
+		}
+		if configOnlyDiff && len(valueHosts) == 1 {
+			continue
+		}
+		values := maps.Keys(valueHosts)
+		sort.Strings(values)
+		for _, value := range values {
+			cloudIDs := valueHosts[value]
+			sort.Strings(cloudIDs)
+			nodesStr := strings.Join(cloudIDs, "\n")
+			if len(valueHosts) == 1 {
+				nodesStr = "all"
+			} else {
+				value = logging.Yellow.Wrap(value)
+			}
+			table.Append([]string{key, value, nodesStr})
+		}
+	}
+	table.Render()
+	return nil
+}
+
+// getConfigHosts returns the cluster avalanchego hosts selected by --nodes, --role and
+// --selector
+func getConfigHosts(clusterName string) ([]*models.Host, error) {
+	if err := checkCluster(clusterName); err != nil {
+		return nil, err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case configRole == "":
+	case strings.EqualFold(configRole, constants.ValidatorRole):
+		hosts = clusterConf.GetValidatorHosts(hosts)
+	case strings.EqualFold(configRole, constants.APIRole):
+		hosts = clusterConf.GetAPIHosts(hosts)
+	default:
+		return nil, fmt.Errorf("invalid role %q, expected %s or %s", configRole, constants.ValidatorRole, constants.APIRole)
+	}
+	if len(configNodes) > 0 {
+		hosts, err = filterHosts(hosts, configNodes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	hosts, err = filterHostsBySelector(hosts)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no nodes selected in cluster %s", clusterName)
+	}
+	return hosts, nil
+}
+
+// downloadNodesConfig downloads the avalanchego config of the hosts into their
+// node instance dirs and returns them by cloud ID
+func downloadNodesConfig(hosts []*models.Host) (map[string]map[string]interface{}, error) {
+	wg := sync.WaitGroup{}
+	wgResults := models.NodeResults{}
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, host *models.Host) {
+			defer wg.Done()
+			nodeInstanceDirPath := app.GetNodeInstanceDirPath(host.GetCloudID())
+			if err := ssh.RunSSHDownloadNodeMonitoringConfig(host, nodeInstanceDirPath); err != nil {
+				nodeResults.AddResult(host.GetCloudID(), nil, err)
+				return
+			}
+			nodeConfig, err := loadNodeConfigFile(nodeInstanceDirPath)
+			nodeResults.AddResult(host.GetCloudID(), nodeConfig, err)
+		}(&wgResults, host)
+	}
+	wg.Wait()
+	if wgResults.HasErrors() {
+		return nil, fmt.Errorf("failed to get node config for node(s) %s", wgResults.GetErrorHostMap())
+	}
+	nodeConfigs := map[string]map[string]interface{}{}
+	for cloudID, nodeConfig := range wgResults.GetResultMap() {
+		nodeConfigs[cloudID] = nodeConfig.(map[string]interface{})
+	}
+	return nodeConfigs, nil
+}
+
+func loadNodeConfigFile(nodeInstanceDirPath string) (map[string]interface{}, error) {
+	nodeConfigBytes, err := os.ReadFile(filepath.Join(nodeInstanceDirPath, constants.NodeFileName))
+	if err != nil {
+		return nil, err
+	}
+	nodeConfig := map[string]interface{}{}
+	if err := json.Unmarshal(nodeConfigBytes, &nodeConfig); err != nil {
+		return nil, fmt.Errorf("invalid node config: %w", err)
+	}
+	return nodeConfig, nil
+}
@@ -0,0 +1,197 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/node"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+)
+
+var configNoRestart bool
+
+func newConfigSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set [clusterName] key=value...",
+		Short: "(ALPHA Warning) Set avalanchego flags on the nodes of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node config set command sets the given avalanchego flags in the node config of the
+selected nodes and restarts avalanchego. Values are parsed as JSON when possible, so
+api-admin-enabled=true sets a boolean, and a null value removes the flag from the config.
+
+Flags are validated against the known avalanchego flags before any node is changed.
+With --rolling, nodes are restarted in batches of at most --max-unavailable nodes,
+waiting for each batch to be healthy, bootstrapped and synced before continuing.`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(2),
+		RunE:         setNodesConfig,
+	}
+	addConfigChangeFlags(cmd)
+	return cmd
+}
+
+func newConfigApplyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply [clusterName] [patchFile]",
+		Short: "(ALPHA Warning) Apply a JSON merge patch to the avalanchego config of the nodes of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node config apply command applies the JSON merge patch at [patchFile] to the node
+config of the selected nodes and restarts avalanchego. Keys set to null are removed from
+the config.
+
+Flags are validated against the known avalanchego flags before any node is changed.
+With --rolling, nodes are restarted in batches of at most --max-unavailable nodes,
+waiting for each batch to be healthy, bootstrapped and synced before continuing.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE:         applyNodesConfig,
+	}
+	addConfigChangeFlags(cmd)
+	return cmd
+}
+
+func addConfigChangeFlags(cmd *cobra.Command) {
+	addConfigSelectionFlags(cmd)
+	cmd.Flags().BoolVar(&configNoRestart, "no-restart", false, "do not restart avalanchego after changing the config")
+	cmd.Flags().BoolVar(&rollingUpgrade, "rolling", false, "restart nodes in batches, waiting for each batch to recover before continuing")
+	cmd.Flags().IntVar(&maxUnavailable, "max-unavailable", 1, "maximum number of nodes restarted at the same time on a rolling restart")
+	cmd.Flags().DurationVar(&batchRecoveryTimeout, "batch-timeout", 10*time.Minute, "time to wait for a batch to be healthy, bootstrapped and synced on a rolling restart")
+}
+
+func setNodesConfig(_ *cobra.Command, args []string) error {
+	patch, err := node.ParseConfigPatch(args[1:])
+	if err != nil {
+		return err
+	}
+	return patchNodesConfig(args[0], patch)
+}
+
+func applyNodesConfig(_ *cobra.Command, args []string) error {
+	patchBytes, err := os.ReadFile(args[1])
+	if err != nil {
+		return err
+	}
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(patchBytes, &patch); err != nil {
+		return fmt.Errorf("invalid JSON merge patch %s: %w", args[1], err)
+	}
+	return patchNodesConfig(args[0], patch)
+}
+
+// patchNodesConfig applies [patch] to the node config of the selected hosts of the cluster
+// and restarts them as requested
+func patchNodesConfig(clusterName string, patch map[string]interface{}) error {
+	if err := node.ValidateConfigPatch(patch); err != nil {
+		return err
+	}
+	if rollingUpgrade && configNoRestart {
+		return fmt.Errorf("--rolling and --no-restart can't be used together")
+	}
+	if maxUnavailable < 1 {
+		return fmt.Errorf("--max-unavailable must be at least 1")
+	}
+	hosts, err := getConfigHosts(clusterName)
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	nodeConfigs, err := downloadNodesConfig(hosts)
+	if err != nil {
+		return err
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].GetCloudID() < hosts[j].GetCloudID() })
+	wg := sync.WaitGroup{}
+	wgResults := models.NodeResults{}
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, host *models.Host) {
+			defer wg.Done()
+			nodeInstanceDirPath := app.GetNodeInstanceDirPath(host.GetCloudID())
+			nodeConfigBytes, err := json.MarshalIndent(node.MergeConfigPatch(nodeConfigs[host.GetCloudID()], patch), "", "  ")
+			if err != nil {
+				nodeResults.AddResult(host.GetCloudID(), nil, err)
+				return
+			}
+			if err := os.WriteFile(filepath.Join(nodeInstanceDirPath, constants.NodeFileName), nodeConfigBytes, constants.WriteReadReadPerms); err != nil {
+				nodeResults.AddResult(host.GetCloudID(), nil, err)
+				return
+			}
+			nodeResults.AddResult(host.GetCloudID(), nil, ssh.RunSSHUploadNodeMonitoringConfig(host, nodeInstanceDirPath))
+		}(&wgResults, host)
+	}
+	wg.Wait()
+	if wgResults.HasErrors() {
+		return fmt.Errorf("failed to update node config for node(s) %s", wgResults.GetErrorHostMap())
+	}
+	ux.Logger.GreenCheckmarkToUser("Node config updated on %d node(s)", len(hosts))
+	if configNoRestart {
+		ux.Logger.PrintToUser("Changes will take effect after avalanchego is restarted")
+		return nil
+	}
+	if !rollingUpgrade {
+		return restartNodes(hosts)
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	blockchainIDs, err := getClusterBlockchainIDs(clusterConf)
+	if err != nil {
+		return err
+	}
+	numBatches := (len(hosts) + maxUnavailable - 1) / maxUnavailable
+	for i := 0; i < numBatches; i++ {
+		batch := hosts[i*maxUnavailable : min((i+1)*maxUnavailable, len(hosts))]
+		ux.Logger.PrintToUser("")
+		ux.Logger.PrintToUser("Restarting batch %d / %d: %s", i+1, numBatches, utils.Map(batch, func(h *models.Host) string { return h.GetCloudID() }))
+		if err := restartNodes(batch); err != nil {
+			return err
+		}
+		if err := waitForBatchRecovery(batch, blockchainIDs, batchRecoveryTimeout, healthCheckPoolTime); err != nil {
+			ux.Logger.RedXToUser("Halting rolling restart: %s", err)
+			return err
+		}
+	}
+	ux.Logger.GreenCheckmarkToUser("Rolling restart of cluster %s completed", clusterName)
+	return nil
+}
+
+func restartNodes(hosts []*models.Host) error {
+	spinSession := ux.NewUserSpinner()
+	defer spinSession.Stop()
+	wg := sync.WaitGroup{}
+	wgResults := models.NodeResults{}
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, host *models.Host) {
+			defer wg.Done()
+			spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Restarting avalanchego"))
+			if err := ssh.RunSSHRestartNode(host); err != nil {
+				nodeResults.AddResult(host.GetCloudID(), nil, err)
+				ux.SpinFailWithError(spinner, "", err)
+				return
+			}
+			nodeResults.AddResult(host.GetCloudID(), nil, nil)
+			ux.SpinComplete(spinner)
+		}(&wgResults, host)
+	}
+	wg.Wait()
+	if wgResults.HasErrors() {
+		return fmt.Errorf("failed to restart node(s) %s", wgResults.GetErrorHostMap())
+	}
+	return nil
+}
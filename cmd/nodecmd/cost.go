@@ -0,0 +1,295 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/cost"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var estimateCost bool
+
+func newCostCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cost [clusterName]",
+		Short: "(ALPHA Warning) Estimate the cloud cost of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node cost command estimates the hourly and monthly cloud cost of a cluster,
+per region. It covers the node instances, their volumes and static IPs, and
+the monitoring and load test instances of the cluster.
+
+Prices come from the offline price table shipped with the CLI, in on-demand
+prices of each region. To use up to date prices, place an updated copy of the
+table at ~/.metal-cli/prices.json. Docker nodes and existing hosts are not
+priced.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         clusterCost,
+	}
+	return cmd
+}
+
+func clusterCost(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	_, resources, err := getClusterCostResources(clusterName)
+	if err != nil {
+		return err
+	}
+	if len(resources) == 0 {
+		return fmt.Errorf("cluster %s has no cloud instances to price", clusterName)
+	}
+	priceTable, err := cost.LoadPriceTable(app.GetPriceTablePath())
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Estimated cost of cluster %s:", clusterName)
+	printCostEstimate(priceTable, priceTable.Estimate(resources))
+	return nil
+}
+
+// getClusterCostResources returns the cloud resources used by the cluster nodes,
+// including its monitoring and load test instances, together with their cloud IDs
+func getClusterCostResources(clusterName string) ([]string, []cost.Resource, error) {
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+	// cloud IDs include the monitoring instance, that may also be an avalanchego host
+	cloudIDs := utils.Unique(append(clusterConf.GetCloudIDs(), maps.Values(clusterConf.LoadTestInstance)...))
+	pricedCloudIDs := []string{}
+	resources := []cost.Resource{}
+	for _, cloudID := range cloudIDs {
+		nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if nodeConfig.CloudService != constants.AWSCloudService && nodeConfig.CloudService != constants.GCPCloudService {
+			ux.Logger.PrintToUser(logging.Yellow.Wrap(fmt.Sprintf("node %s is not a cloud instance, it is not priced", cloudID)))
+			continue
+		}
+		pricedCloudIDs = append(pricedCloudIDs, cloudID)
+		resources = append(resources, getNodeCostResource(clusterConf, nodeConfig))
+	}
+	return pricedCloudIDs, resources, nil
+}
+
+// getNodeCostResource returns the cloud resource used by a node. Nodes created
+// before instance and volume details were saved are assumed to use the defaults
+func getNodeCostResource(clusterConf models.ClusterConfig, nodeConfig models.NodeConfig) cost.Resource {
+	roles := clusterConf.GetHostRoles(nodeConfig)
+	if nodeConfig.IsLoadTest {
+		roles = append(roles, constants.LoadTestRole)
+	}
+	isExternalHost := nodeConfig.IsMonitor || nodeConfig.IsLoadTest
+	instanceType := nodeConfig.InstanceType
+	if instanceType == "" {
+		instanceType = constants.AWSDefaultInstanceType
+		if nodeConfig.CloudService == constants.GCPCloudService {
+			instanceType = constants.GCPDefaultInstanceType
+		}
+		ux.Logger.PrintToUser(logging.Yellow.Wrap(fmt.Sprintf("instance type of node %s is unknown, assuming %s", nodeConfig.NodeID, instanceType)))
+	}
+	volumeSize := nodeConfig.VolumeSize
+	if volumeSize == 0 {
+		volumeSize = constants.CloudServerStorageSize
+		if isExternalHost {
+			volumeSize = constants.MonitoringCloudServerStorageSize
+		}
+	}
+	return cost.Resource{
+		Cloud:        nodeConfig.CloudService,
+		Region:       nodeConfig.Region,
+		Role:         strings.Join(roles, ","),
+		Count:        1,
+		InstanceType: instanceType,
+		Spot:         nodeConfig.IsSpot,
+		VolumeType:   nodeConfig.VolumeType,
+		VolumeSize:   volumeSize,
+		IOPS:         nodeConfig.VolumeIOPS,
+		Throughput:   nodeConfig.VolumeThroughput,
+		StaticIP:     nodeConfig.UseStaticIP,
+	}
+}
+
+// setNodeVolume saves the volume settings of a newly created cloud node
+func setNodeVolume(nodeConfig *models.NodeConfig, forMonitoring bool) {
+	nodeConfig.VolumeSize = constants.CloudServerStorageSize
+	if forMonitoring {
+		nodeConfig.VolumeSize = constants.MonitoringCloudServerStorageSize
+	}
+	switch nodeConfig.CloudService {
+	case constants.AWSCloudService:
+		nodeConfig.VolumeType = volumeType
+		nodeConfig.VolumeIOPS = iops
+		nodeConfig.VolumeThroughput = throughput
+		if !forMonitoring {
+			nodeConfig.VolumeSize = volumeSize
+		}
+	case constants.GCPCloudService:
+		nodeConfig.VolumeType = constants.GCPVolumeTypeStandard
+	default:
+		nodeConfig.VolumeSize = 0
+	}
+}
+
+// estimateCreateCost prints the cost of the nodes node create would create, without
+// creating them
+func estimateCreateCost(cloudService string) error {
+	if cloudService != constants.AWSCloudService && cloudService != constants.GCPCloudService {
+		return fmt.Errorf("cost estimates are only available for AWS and GCP nodes")
+	}
+	if len(cmdLineRegion) == 0 || len(numValidatorsNodes) == 0 {
+		return fmt.Errorf("--region and --num-validators must be given to estimate the cost")
+	}
+	if len(cmdLineRegion) != len(numValidatorsNodes) {
+		return fmt.Errorf("number of regions and number of nodes must be equal")
+	}
+	if len(numAPINodes) > 0 && len(numAPINodes) != len(cmdLineRegion) {
+		return fmt.Errorf("number of regions and number of api nodes must be equal")
+	}
+	node := models.NodeConfig{CloudService: cloudService}
+	setNodeVolume(&node, false)
+	monitoringNode := models.NodeConfig{CloudService: cloudService}
+	setNodeVolume(&monitoringNode, true)
+	resources := []cost.Resource{}
+	for i, region := range cmdLineRegion {
+		numNodes := map[string]int{constants.ValidatorRole: numValidatorsNodes[i]}
+		if len(numAPINodes) > 0 {
+			numNodes[constants.APIRole] = numAPINodes[i]
+		}
+		for _, role := range []string{constants.ValidatorRole, constants.APIRole} {
+			if numNodes[role] == 0 {
+				continue
+			}
+			resources = append(resources, cost.Resource{
+				Cloud:        cloudService,
+				Region:       region,
+				Role:         role,
+				Count:        numNodes[role],
+				InstanceType: nodeType,
+				Spot:         useSpot,
+				VolumeType:   node.VolumeType,
+				VolumeSize:   node.VolumeSize,
+				IOPS:         node.VolumeIOPS,
+				Throughput:   node.VolumeThroughput,
+				StaticIP:     useStaticIP,
+			})
+		}
+	}
+	if addMonitoring {
+		resources = append(resources, cost.Resource{
+			Cloud:        cloudService,
+			Region:       cmdLineRegion[0],
+			Role:         constants.MonitorRole,
+			Count:        1,
+			InstanceType: nodeType,
+			VolumeType:   monitoringNode.VolumeType,
+			VolumeSize:   monitoringNode.VolumeSize,
+			IOPS:         monitoringNode.VolumeIOPS,
+			Throughput:   monitoringNode.VolumeThroughput,
+			StaticIP:     useStaticIP,
+		})
+	}
+	priceTable, err := cost.LoadPriceTable(app.GetPriceTablePath())
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Estimated cost of the nodes to create:")
+	printCostEstimate(priceTable, priceTable.Estimate(resources))
+	return nil
+}
+
+// estimateResizeCost prints the cost of the cluster after resizing [nodesToResize]
+// to the given node type and disk size, compared to its current cost
+func estimateResizeCost(clusterName string, nodesToResize []string, newDiskSize int) error {
+	cloudIDs, resources, err := getClusterCostResources(clusterName)
+	if err != nil {
+		return err
+	}
+	priceTable, err := cost.LoadPriceTable(app.GetPriceTablePath())
+	if err != nil {
+		return err
+	}
+	resizedResources := []cost.Resource{}
+	for i, resource := range resources {
+		if slices.Contains(nodesToResize, cloudIDs[i]) {
+			if nodeType != "" {
+				resource.InstanceType = nodeType
+			}
+			if newDiskSize != 0 {
+				resource.VolumeSize = newDiskSize
+			}
+		}
+		resizedResources = append(resizedResources, resource)
+	}
+	currentMonthly := priceTable.Estimate(resources).Monthly()
+	resizedEstimate := priceTable.Estimate(resizedResources)
+	ux.Logger.PrintToUser("Estimated cost of cluster %s after resizing:", clusterName)
+	printCostEstimate(priceTable, resizedEstimate)
+	ux.Logger.PrintToUser("Monthly cost change: %+.2f %s (currently %.2f %s)",
+		resizedEstimate.Monthly()-currentMonthly,
+		priceTable.Currency,
+		currentMonthly,
+		priceTable.Currency,
+	)
+	return nil
+}
+
+func printCostEstimate(priceTable *cost.PriceTable, estimate *cost.Estimate) {
+	header := []string{"Region", "Role", "Count", "Instance", "Volume", "Static IP", "Hourly", "Monthly"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	for _, region := range estimate.Regions() {
+		for _, item := range estimate.Items {
+			if item.Region != region {
+				continue
+			}
+			instance := item.InstanceType
+			if item.Spot {
+				instance += " (spot)"
+			}
+			volume := fmt.Sprintf("%dGB", item.VolumeSize)
+			if item.VolumeType != "" {
+				volume += " " + item.VolumeType
+			}
+			table.Append([]string{
+				item.Region,
+				item.Role,
+				fmt.Sprint(item.Count),
+				instance,
+				volume,
+				fmt.Sprint(item.StaticIP),
+				fmt.Sprintf("%.3f", item.Hourly()),
+				fmt.Sprintf("%.2f", item.Monthly()),
+			})
+		}
+	}
+	table.Render()
+	ux.Logger.PrintToUser("")
+	for _, region := range estimate.Regions() {
+		ux.Logger.PrintToUser("  %s: %.3f %s/hour, %.2f %s/month", region, estimate.RegionHourly(region), estimate.Currency, estimate.RegionHourly(region)*cost.HoursPerMonth, estimate.Currency)
+	}
+	ux.Logger.PrintToUser("Total: %.3f %s/hour, %.2f %s/month", estimate.Hourly(), estimate.Currency, estimate.Monthly(), estimate.Currency)
+	for _, warning := range estimate.Warnings {
+		ux.Logger.PrintToUser(logging.Yellow.Wrap(warning))
+	}
+	ux.Logger.PrintToUser("Prices from the price table of %s. Actual costs depend on usage, network traffic and discounts", priceTable.Updated)
+}
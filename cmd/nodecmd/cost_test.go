@@ -0,0 +1,83 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"io"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClusterCostResources(t *testing.T) {
+	tests := []struct {
+		name               string
+		nodes              []string
+		monitoringInstance string
+		loadTestInstance   map[string]string
+		expectedCloudIDs   []string
+		expectedRoles      []string
+	}{
+		{
+			name:             "validators only",
+			nodes:            []string{"i-1", "i-2"},
+			expectedCloudIDs: []string{"i-1", "i-2"},
+			expectedRoles:    []string{constants.ValidatorRole, constants.ValidatorRole},
+		},
+		{
+			name:               "separate monitoring host",
+			nodes:              []string{"i-1", "i-2"},
+			monitoringInstance: "i-mon",
+			expectedCloudIDs:   []string{"i-1", "i-2", "i-mon"},
+			expectedRoles:      []string{constants.ValidatorRole, constants.ValidatorRole, constants.MonitorRole},
+		},
+		{
+			name:               "separate monitoring and load test hosts",
+			nodes:              []string{"i-1"},
+			monitoringInstance: "i-mon",
+			loadTestInstance:   map[string]string{"lt": "i-lt"},
+			expectedCloudIDs:   []string{"i-1", "i-mon", "i-lt"},
+			expectedRoles:      []string{constants.ValidatorRole, constants.MonitorRole, constants.LoadTestRole},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ux.NewUserLog(logging.NoLog{}, io.Discard)
+			app = application.New()
+			app.Setup(t.TempDir(), logging.NoLog{}, nil, nil, nil)
+			require.NoError(app.WriteClustersConfigFile(&models.ClustersConfig{
+				Clusters: map[string]models.ClusterConfig{
+					"cluster": {
+						Nodes:              tt.nodes,
+						MonitoringInstance: tt.monitoringInstance,
+						LoadTestInstance:   tt.loadTestInstance,
+					},
+				},
+			}))
+			for _, cloudID := range tt.expectedCloudIDs {
+				require.NoError(app.CreateNodeCloudConfigFile(cloudID, &models.NodeConfig{
+					NodeID:       cloudID,
+					Region:       "us-east-1",
+					CloudService: constants.AWSCloudService,
+					InstanceType: "t3.xlarge",
+					IsMonitor:    cloudID == tt.monitoringInstance,
+					IsLoadTest:   cloudID == tt.loadTestInstance["lt"],
+				}))
+			}
+			cloudIDs, resources, err := getClusterCostResources("cluster")
+			require.NoError(err)
+			require.Equal(tt.expectedCloudIDs, cloudIDs)
+			require.Len(resources, len(tt.expectedCloudIDs))
+			for i, resource := range resources {
+				require.Equal(tt.expectedRoles[i], resource.Role)
+				require.Equal(1, resource.Count)
+			}
+		})
+	}
+}
@@ -14,10 +14,13 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
+	"github.com/ixAnkit/cryft/pkg/cloud"
 	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
+	dockerAPI "github.com/ixAnkit/cryft/pkg/cloud/docker"
 
 	"github.com/ixAnkit/cryft/cmd/flags"
 	"github.com/ixAnkit/cryft/cmd/subnetcmd"
@@ -35,6 +38,7 @@ import (
 
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/node"
 
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/spf13/cobra"
@@ -50,6 +54,8 @@ var (
 	globalNetworkFlags                    networkoptions.NetworkFlags
 	useAWS                                bool
 	useGCP                                bool
+	useDocker                             bool
+	existingHostsFile                     string
 	cmdLineRegion                         []string
 	authorizeAccess                       bool
 	numValidatorsNodes                    []int
@@ -75,7 +81,10 @@ var (
 	versionComments                       = map[string]string{
 		"v1.11.0-fuji": " (recommended for fuji durango)",
 	}
-	grafanaPkg string
+	grafanaPkg   string
+	useSpot      bool
+	spotMaxPrice string
+	expireAfter  time.Duration
 )
 
 func newCreateCmd() *cobra.Command {
@@ -95,7 +104,29 @@ status by running avalanche node status
 
 The created node will be part of group of validators called <clusterName> 
 and users can call node commands with <clusterName> so that the command
-will apply to all nodes in the cluster`,
+will apply to all nodes in the cluster
+
+Instead of creating cloud servers, already running servers can be adopted
+into the cluster with --existing-hosts, a yaml file listing each host ip,
+ssh user, ssh-key or ssh-agent-identity and region label:
+
+  hosts:
+    - ip: 10.0.0.5
+      user: ubuntu
+      ssh-key: ~/.ssh/id_ed25519
+      region: dc1
+
+On AWS, --aws-spot creates spot instances for the nodes, which are much cheaper
+for throwaway devnets and load tests but may be interrupted. Interrupted nodes
+are reported by node status, and node status --recreate-spot re-creates them with
+the same staking keys. --expire-after tags the instances with an expiry time, after which
+node destroy --expired cleans the cluster up.
+
+--estimate prints the hourly and monthly cost of the nodes to create, given by
+--region and --num-validators, and exits without creating them.
+
+If provisioning fails on some hosts, node provision <clusterName> --resume retries
+only the failed hosts and steps.`,
 		SilenceUsage: true,
 		Args:         cobra.ExactArgs(1),
 		RunE:         createNodes,
@@ -104,6 +135,8 @@ will apply to all nodes in the cluster`,
 	cmd.Flags().BoolVar(&useStaticIP, "use-static-ip", true, "attach static Public IP on cloud servers")
 	cmd.Flags().BoolVar(&useAWS, "aws", false, "create node/s in AWS cloud")
 	cmd.Flags().BoolVar(&useGCP, "gcp", false, "create node/s in GCP cloud")
+	cmd.Flags().BoolVar(&useDocker, "docker", false, "create node/s as local docker containers")
+	cmd.Flags().StringVar(&existingHostsFile, "existing-hosts", "", "adopt the servers listed in the given hosts yaml file instead of creating cloud instances")
 	cmd.Flags().StringSliceVar(&cmdLineRegion, "region", []string{}, "create node(s) in given region(s). Use comma to separate multiple regions")
 	cmd.Flags().BoolVar(&authorizeAccess, "authorize-access", false, "authorize CLI to create cloud resources")
 	cmd.Flags().IntSliceVar(&numValidatorsNodes, "num-validators", []int{}, "number of nodes to create per region(s). Use comma to separate multiple numbers for each region in the same order as --region flag")
@@ -127,6 +160,10 @@ will apply to all nodes in the cluster`,
 	cmd.Flags().IntVar(&throughput, "aws-throughput", constants.AWSGP3DefaultThroughput, "AWS throughput in MiB/s (for gp3 volume type only)")
 	cmd.Flags().StringVar(&volumeType, "aws-volume-type", "gp3", "AWS volume type")
 	cmd.Flags().IntVar(&volumeSize, "aws-volume-size", constants.CloudServerStorageSize, "AWS volume size in GB")
+	cmd.Flags().BoolVar(&useSpot, "aws-spot", false, "create AWS spot instances, which are cheaper but may be interrupted")
+	cmd.Flags().StringVar(&spotMaxPrice, "max-price", "", "max hourly price in USD for AWS spot instances. defaults to the on-demand price")
+	cmd.Flags().DurationVar(&expireAfter, "expire-after", 0, "tag AWS instances to expire after the given duration, so node destroy --expired cleans them up")
+	cmd.Flags().BoolVar(&estimateCost, "estimate", false, "print the estimated cost of the nodes to create, without creating them")
 	return cmd
 }
 
@@ -134,8 +171,22 @@ func preCreateChecks() error {
 	if !flags.EnsureMutuallyExclusive([]bool{useLatestAvalanchegoReleaseVersion, useLatestAvalanchegoPreReleaseVersion, useAvalanchegoVersionFromSubnet != "", useCustomAvalanchegoVersion != ""}) {
 		return fmt.Errorf("latest avalanchego released version, latest avalanchego pre-released version, custom avalanchego version and avalanchego version based on given subnet, are mutually exclusive options")
 	}
-	if useAWS && useGCP {
-		return fmt.Errorf("could not use both AWS and GCP cloud options")
+	if !flags.EnsureMutuallyExclusive([]bool{useAWS, useGCP, useDocker}) {
+		return fmt.Errorf("could not use more than one of AWS, GCP and docker cloud options")
+	}
+	if existingHostsFile != "" {
+		if useAWS || useGCP || useDocker {
+			return fmt.Errorf("existing hosts can't be used together with a cloud option")
+		}
+		if len(cmdLineRegion) > 0 || len(numValidatorsNodes) > 0 || len(numAPINodes) > 0 || nodeType != "" {
+			return fmt.Errorf("regions, number of nodes and node type are taken from the existing hosts file")
+		}
+		if !utils.FileExists(utils.ExpandHome(existingHostsFile)) {
+			return fmt.Errorf("existing hosts file %s does not exist", existingHostsFile)
+		}
+	}
+	if useDocker && !dockerAPI.Available() {
+		return fmt.Errorf("docker cloud option requires docker and docker-compose to be installed")
 	}
 	if !useAWS && awsProfile != constants.AWSDefaultCredential {
 		return fmt.Errorf("could not use AWS profile for non AWS cloud option")
@@ -193,6 +244,17 @@ func preCreateChecks() error {
 	if grafanaPkg != "" && (!strings.HasSuffix(grafanaPkg, ".deb") || !utils.IsValidURL(grafanaPkg)) {
 		return fmt.Errorf("grafana package must be URL to a .deb file")
 	}
+	if spotMaxPrice != "" {
+		if !useSpot {
+			return fmt.Errorf("max price can only be used with AWS spot instances")
+		}
+		if price, err := strconv.ParseFloat(spotMaxPrice, 64); err != nil || price <= 0 {
+			return fmt.Errorf("invalid max price %q", spotMaxPrice)
+		}
+	}
+	if expireAfter < 0 {
+		return fmt.Errorf("expire after must be a positive duration")
+	}
 	if grafanaPkg != "" && !addMonitoring {
 		return fmt.Errorf("grafana package can only be used with monitoring setup")
 	}
@@ -235,12 +297,23 @@ func createNodes(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	network = models.NewNetworkFromCluster(network, clusterName)
-
-	globalNetworkFlags.UseDevnet = network.Kind == models.Devnet // set globalNetworkFlags.UseDevnet to true if network is devnet for further use
-	avalancheGoVersion, err := getAvalancheGoVersion()
-	if err != nil {
+	// nodes added to an existing cluster join its network
+	extendingCluster := false
+	if clusterExists, err := app.ClusterExists(clusterName); err != nil {
 		return err
+	} else if clusterExists {
+		clusterConf, err := app.GetClusterConfig(clusterName)
+		if err != nil {
+			return err
+		}
+		if clusterConf.Network.Kind != network.Kind {
+			return fmt.Errorf("cluster %s is on %s, can't add %s nodes to it", clusterName, clusterConf.Network.Kind, network.Kind)
+		}
+		network = clusterConf.Network
+		extendingCluster = len(clusterConf.Nodes) > 0
 	}
+
+	globalNetworkFlags.UseDevnet = network.Kind == models.Devnet // set globalNetworkFlags.UseDevnet to true if network is devnet for further use
 	cloudService, err := setCloudService()
 	if err != nil {
 		return err
@@ -250,6 +323,16 @@ func createNodes(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if cloudService != constants.AWSCloudService && (useSpot || expireAfter != 0) {
+		return fmt.Errorf("spot instances and instance expiry are only supported on AWS")
+	}
+	if estimateCost {
+		return estimateCreateCost(cloudService)
+	}
+	avalancheGoVersion, err := getAvalancheGoVersion()
+	if err != nil {
+		return err
+	}
 	if cloudService != constants.GCPCloudService && cmdLineGCPCredentialsPath != "" {
 		return fmt.Errorf("set to use GCP credentials but cloud option is not GCP")
 	}
@@ -271,59 +354,81 @@ func createNodes(cmd *cobra.Command, args []string) error {
 	}
 	monitoringHostRegion := ""
 	monitoringNodeConfig := models.RegionConfig{}
+	existingHosts := []models.ExistingHost{}
 	existingMonitoringInstance, err = getExistingMonitoringInstance(clusterName)
 	if err != nil {
 		return err
 	}
-	if existingMonitoringInstance == "" && !cmd.Flags().Changed(enableMonitoringFlag) {
+	if existingMonitoringInstance == "" && !cmd.Flags().Changed(enableMonitoringFlag) && cloudService != constants.ExistingHostsCloudService {
 		if addMonitoring, err = promptSetUpMonitoring(); err != nil {
 			return err
 		}
 	}
-	if utils.IsE2E() {
+	if cloudService == constants.DockerCloudService {
 		usr, err := user.Current()
 		if err != nil {
 			return err
 		}
-		// override cloudConfig for E2E testing
+		dockerProvider := getDockerProvider()
 		defaultAvalancheCLIPrefix := usr.Username + constants.AvalancheCLISuffix
 		keyPairName := fmt.Sprintf("%s-keypair", defaultAvalancheCLIPrefix)
 		certPath, err := app.GetSSHCertFilePath(keyPairName)
+		if err != nil {
+			return err
+		}
+		if !utils.FileExists(certPath) {
+			if err := dockerProvider.CreateKeyPair("", keyPairName, certPath); err != nil {
+				return err
+			}
+		}
+		pubKeyString, err := os.ReadFile(fmt.Sprintf("%s.pub", certPath))
+		if err != nil {
+			return err
+		}
 		if globalNetworkFlags.UseDevnet {
 			for i, num := range numAPINodes {
 				numValidatorsNodes[i] += num
 			}
 		}
 		dockerNumNodes := utils.Sum(numValidatorsNodes)
-		var dockerNodesPublicIPs []string
-		var monitoringHostIP string
+		numInstances := dockerNumNodes
 		if addMonitoring {
-			generatedPublicIPs := utils.GenerateDockerHostIPs(dockerNumNodes + 1)
-			monitoringHostIP = generatedPublicIPs[len(generatedPublicIPs)-1]
-			dockerNodesPublicIPs = generatedPublicIPs[:len(generatedPublicIPs)-1]
-		} else {
-			dockerNodesPublicIPs = utils.GenerateDockerHostIPs(dockerNumNodes)
+			numInstances++
+		}
+		instanceSpec := cloud.InstanceSpec{
+			Prefix:        constants.DockerCloudService,
+			ImageID:       constants.DockerUbuntuVersion,
+			InstanceType:  constants.DockerCloudService,
+			KeyPairName:   keyPairName,
+			SecurityGroup: constants.DockerCloudService,
+			SSHPublicKey:  string(pubKeyString),
+		}
+		instanceIDs, err := dockerProvider.CreateInstances("", numInstances, instanceSpec)
+		if err != nil {
+			return err
 		}
-		dockerHostIDs := utils.GenerateDockerHostIDs(dockerNumNodes)
+		instanceIPs, err := dockerProvider.GetInstancePublicIPs("", instanceIDs)
 		if err != nil {
 			return err
 		}
+		dockerHostIDs := instanceIDs[:dockerNumNodes]
+		dockerNodesPublicIPs := utils.Map(dockerHostIDs, func(id string) string { return instanceIPs[id] })
 		cloudConfigMap = models.CloudConfig{
-			"docker": {
+			constants.DockerCloudService: {
 				InstanceIDs:       dockerHostIDs,
 				PublicIPs:         dockerNodesPublicIPs,
 				KeyPair:           keyPairName,
-				SecurityGroup:     "docker",
+				SecurityGroup:     constants.DockerCloudService,
 				CertFilePath:      certPath,
-				ImageID:           "docker",
-				Prefix:            "docker",
-				CertName:          "docker",
-				SecurityGroupName: "docker",
+				ImageID:           constants.DockerCloudService,
+				Prefix:            constants.DockerCloudService,
+				CertName:          constants.DockerCloudService,
+				SecurityGroupName: constants.DockerCloudService,
 				NumNodes:          dockerNumNodes,
-				InstanceType:      "docker",
+				InstanceType:      constants.DockerCloudService,
 			},
 		}
-		currentRegionConfig := cloudConfigMap["docker"]
+		currentRegionConfig := cloudConfigMap[constants.DockerCloudService]
 		for i, ip := range currentRegionConfig.PublicIPs {
 			publicIPMap[dockerHostIDs[i]] = ip
 		}
@@ -335,37 +440,58 @@ func createNodes(cmd *cobra.Command, args []string) error {
 		for _, node := range currentRegionConfig.APIInstanceIDs {
 			apiNodeIPMap[node] = publicIPMap[node]
 		}
-		cloudConfigMap["docker"] = currentRegionConfig
+		cloudConfigMap[constants.DockerCloudService] = currentRegionConfig
 		if addMonitoring {
-			monitoringDockerHostID := utils.GenerateDockerHostIDs(1)
-			dockerHostIDs = append(dockerHostIDs, monitoringDockerHostID[0])
-			monitoringCloudConfig := models.CloudConfig{
-				"monitoringDocker": {
-					InstanceIDs:       monitoringDockerHostID,
-					PublicIPs:         []string{monitoringHostIP},
-					KeyPair:           keyPairName,
-					SecurityGroup:     "docker",
-					CertFilePath:      certPath,
-					ImageID:           "docker",
-					Prefix:            "docker",
-					CertName:          "docker",
-					SecurityGroupName: "docker",
-					NumNodes:          1,
-					InstanceType:      "docker",
-				},
+			monitoringDockerHostID := instanceIDs[dockerNumNodes]
+			monitoringNodeConfig = models.RegionConfig{
+				InstanceIDs:       []string{monitoringDockerHostID},
+				PublicIPs:         []string{instanceIPs[monitoringDockerHostID]},
+				KeyPair:           keyPairName,
+				SecurityGroup:     constants.DockerCloudService,
+				CertFilePath:      certPath,
+				ImageID:           constants.DockerCloudService,
+				Prefix:            constants.DockerCloudService,
+				CertName:          constants.DockerCloudService,
+				SecurityGroupName: constants.DockerCloudService,
+				NumNodes:          1,
+				InstanceType:      constants.DockerCloudService,
 			}
-			monitoringNodeConfig = monitoringCloudConfig["monitoringDocker"]
 		}
-		pubKeyString, err := os.ReadFile(fmt.Sprintf("%s.pub", certPath))
+	} else if cloudService == constants.ExistingHostsCloudService {
+		existingHosts, err = models.LoadExistingHosts(existingHostsFile)
 		if err != nil {
 			return err
 		}
-		dockerComposeFile, err := utils.SaveDockerComposeFile(constants.E2EDockerComposeFile, len(dockerHostIDs), "focal", strings.TrimSuffix(string(pubKeyString), "\n"))
-		if err != nil {
+		if err := checkExistingHosts(existingHosts, globalNetworkFlags.UseDevnet); err != nil {
 			return err
 		}
-		if err := utils.StartDockerCompose(dockerComposeFile); err != nil {
-			return err
+		// existing hosts keep their IPs
+		useStaticIP = true
+		var monitoringHost *models.ExistingHost
+		cloudConfigMap, monitoringHost = getExistingHostsCloudConfig(existingHosts)
+		for _, regionConfig := range cloudConfigMap {
+			for i, instanceID := range regionConfig.InstanceIDs {
+				publicIPMap[instanceID] = regionConfig.PublicIPs[i]
+			}
+			for _, instanceID := range regionConfig.APIInstanceIDs {
+				apiNodeIPMap[instanceID] = publicIPMap[instanceID]
+			}
+		}
+		switch {
+		case monitoringHost != nil && existingMonitoringInstance != "":
+			return fmt.Errorf("cluster %s already has a monitoring host", clusterName)
+		case monitoringHost != nil:
+			addMonitoring = true
+			monitoringHostRegion = monitoringHost.Region
+			monitoringNodeConfig = getExistingHostRegionConfig(*monitoringHost)
+		case existingMonitoringInstance != "":
+			addMonitoring = true
+			monitoringNodeConfig, monitoringHostRegion, err = getNodeCloudConfig(existingMonitoringInstance)
+			if err != nil {
+				return err
+			}
+		case addMonitoring:
+			return fmt.Errorf("monitoring requires a host marked with 'monitoring: true' in %s", existingHostsFile)
 		}
 	} else {
 		if cloudService == constants.AWSCloudService {
@@ -412,6 +538,7 @@ func createNodes(cmd *cobra.Command, args []string) error {
 				}
 				monitoringNodeConfig.PublicIPs = []string{monitoringPublicIPMap[monitoringNodeConfig.InstanceIDs[0]]}
 			}
+			provider := awsAPI.NewProvider(awsProfile)
 			for region, numNodes := range numNodesMap {
 				currentRegionConfig := cloudConfigMap[region]
 				if !useStaticIP {
@@ -435,7 +562,7 @@ func createNodes(cmd *cobra.Command, args []string) error {
 				}
 				cloudConfigMap[region] = currentRegionConfig
 				if addMonitoring {
-					if err = AddMonitoringSecurityGroupRule(ec2SvcMap, monitoringNodeConfig.PublicIPs[0], currentRegionConfig.SecurityGroup, region); err != nil {
+					if err = provider.AddFirewallRule(region, currentRegionConfig.SecurityGroup, monitoringNodeConfig.PublicIPs[0], separateHostPorts); err != nil {
 						return err
 					}
 				}
@@ -545,7 +672,12 @@ func createNodes(cmd *cobra.Command, args []string) error {
 	}
 
 	inventoryPath := app.GetAnsibleInventoryDirPath(clusterName)
-	if err = ansible.CreateAnsibleHostInventory(inventoryPath, "", cloudService, publicIPMap, cloudConfigMap); err != nil {
+	if cloudService == constants.ExistingHostsCloudService {
+		err = addExistingHostsToInventory(inventoryPath, utils.Filter(existingHosts, func(h models.ExistingHost) bool { return !h.Monitoring }))
+	} else {
+		err = ansible.CreateAnsibleHostInventory(inventoryPath, "", cloudService, publicIPMap, cloudConfigMap)
+	}
+	if err != nil {
 		return err
 	}
 	monitoringInventoryPath := ""
@@ -553,7 +685,12 @@ func createNodes(cmd *cobra.Command, args []string) error {
 	if addMonitoring {
 		monitoringInventoryPath = app.GetMonitoringInventoryDir(clusterName)
 		if existingMonitoringInstance == "" {
-			if err = ansible.CreateAnsibleHostInventory(monitoringInventoryPath, monitoringNodeConfig.CertFilePath, cloudService, map[string]string{monitoringNodeConfig.InstanceIDs[0]: monitoringNodeConfig.PublicIPs[0]}, nil); err != nil {
+			if cloudService == constants.ExistingHostsCloudService {
+				err = addExistingHostsToInventory(monitoringInventoryPath, utils.Filter(existingHosts, func(h models.ExistingHost) bool { return h.Monitoring }))
+			} else {
+				err = ansible.CreateAnsibleHostInventory(monitoringInventoryPath, monitoringNodeConfig.CertFilePath, cloudService, map[string]string{monitoringNodeConfig.InstanceIDs[0]: monitoringNodeConfig.PublicIPs[0]}, nil)
+			}
+			if err != nil {
 				return err
 			}
 		}
@@ -583,11 +720,18 @@ func createNodes(cmd *cobra.Command, args []string) error {
 	wg := sync.WaitGroup{}
 	wgResults := models.NodeResults{}
 	spinSession := ux.NewUserSpinner()
-	// setup monitoring in parallel with node setup
-	avalancheGoPorts, machinePorts, ltPorts, err := getPrometheusTargets(clusterName)
-	if err != nil {
-		return err
+	monitoringIP := ""
+	if addMonitoring {
+		monitoringIP = monitoringNodeConfig.PublicIPs[0]
+	}
+	provisioner := &nodeProvisioner{
+		clusterName:        clusterName,
+		network:            network,
+		avalancheGoVersion: avalancheGoVersion,
+		monitoringIP:       monitoringIP,
+		spinSession:        spinSession,
 	}
+	// setup monitoring in parallel with node setup
 	if addMonitoring {
 		if len(monitoringHosts) != 1 {
 			return fmt.Errorf("expected only one monitoring host, found %d", len(monitoringHosts))
@@ -598,42 +742,9 @@ func createNodes(cmd *cobra.Command, args []string) error {
 			wg.Add(1)
 			go func(nodeResults *models.NodeResults, monitoringHost *models.Host) {
 				defer wg.Done()
-				if err := monitoringHost.Connect(0); err != nil {
-					nodeResults.AddResult(monitoringHost.NodeID, nil, err)
-					return
-				}
-				spinner := spinSession.SpinToUser(utils.ScriptLog(monitoringHost.NodeID, "Setup Monitoring"))
-				if err = app.SetupMonitoringEnv(); err != nil {
+				if err := provisioner.provisionMonitoring(monitoringHost); err != nil {
 					nodeResults.AddResult(monitoringHost.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
 				}
-				if err := ssh.RunSSHSetupSeparateMonitoring(monitoringHost, grafanaPkg); err != nil {
-					nodeResults.AddResult(monitoringHost.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				if err := ssh.RunSSHCopyMonitoringDashboards(monitoringHost, app.GetMonitoringDashboardDir()+"/"); err != nil {
-					nodeResults.AddResult(monitoringHost.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts); err != nil {
-					nodeResults.AddResult(monitoringHost.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				if err := ssh.RunSSHSetupLoki(monitoringHost, grafanaPkg); err != nil {
-					nodeResults.AddResult(monitoringHost.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				if err := ssh.RunSSHUpdateLokiConfig(monitoringHost, constants.AvalanchegoLokiPort); err != nil {
-					nodeResults.AddResult(monitoringHost.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				ux.SpinComplete(spinner)
 			}(&wgResults, monitoringHost)
 		}
 	}
@@ -641,129 +752,64 @@ func createNodes(cmd *cobra.Command, args []string) error {
 		wg.Add(1)
 		go func(nodeResults *models.NodeResults, host *models.Host) {
 			defer wg.Done()
-			if err := host.Connect(0); err != nil {
-				nodeResults.AddResult(host.NodeID, nil, err)
-				return
-			}
-			if err := provideStakingCertAndKey(host); err != nil {
-				nodeResults.AddResult(host.NodeID, nil, err)
-				return
-			}
-			spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Setup Build Env"))
-			if err := ssh.RunSSHSetupBuildEnv(host); err != nil {
-				nodeResults.AddResult(host.NodeID, nil, err)
-				ux.SpinFailWithError(spinner, "", err)
-				return
-			}
-			ux.SpinComplete(spinner)
-			spinner = spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Setup Node"))
-			if err := ssh.RunSSHSetupNode(host, app.Conf.GetConfigPath(), avalancheGoVersion, remoteCLIVersion, network.Kind == models.Devnet); err != nil {
+			if err := provisioner.provisionNode(host); err != nil {
 				nodeResults.AddResult(host.NodeID, nil, err)
-				ux.SpinFailWithError(spinner, "", err)
-				return
-			}
-			ux.SpinComplete(spinner)
-			if addMonitoring {
-				spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Setup Metrics"))
-				if err := ssh.RunSSHSetupMachineMetrics(host); err != nil {
-					nodeResults.AddResult(host.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				ux.SpinComplete(spinner)
-				spinner = spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Setup Logging"))
-				if err := ssh.RunSSHSetupPromtail(host); err != nil {
-					nodeResults.AddResult(host.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				cloudID := host.GetCloudID()
-				nodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID))
-				if err != nil {
-					nodeResults.AddResult(host.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				if err = ssh.RunSSHUpdatePromtailConfig(host, monitoringNodeConfig.PublicIPs[0], constants.AvalanchegoLokiPort, cloudID, nodeID.String()); err != nil {
-					nodeResults.AddResult(host.NodeID, nil, err)
-					ux.SpinFailWithError(spinner, "", err)
-					return
-				}
-				ux.SpinComplete(spinner)
 			}
-			spinner = spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Setup Avalanche-CLI"))
-			if err := ssh.RunSSHSetupCLIFromSource(host, constants.SetupCLIFromSourceBranch); err != nil {
-				nodeResults.AddResult(host.NodeID, nil, err)
-				ux.SpinFailWithError(spinner, "", err)
-				return
-			}
-			ux.SpinComplete(spinner)
 		}(&wgResults, host)
 	}
 	wg.Wait()
-	ansibleHostIDs, err := utils.MapWithError(cloudConfigMap.GetAllInstanceIDs(), func(s string) (string, error) { return models.HostCloudIDToAnsibleID(cloudService, s) })
-	if err != nil {
-		return err
-	}
 	if addMonitoring {
 		monitoringHost := monitoringHosts[0]
 		// remove monitoring host from created hosts list
 		hosts = utils.Filter(hosts, func(h *models.Host) bool { return h.NodeID != monitoringHost.NodeID })
 		if existingMonitoringInstance != "" {
 			spinner := spinSession.SpinToUser(utils.ScriptLog(monitoringHost.NodeID, "Update Monitoring Targets"))
-			if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts); err != nil {
+			avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err := getPrometheusTargets(clusterName)
+			if err != nil {
 				ux.SpinFailWithError(spinner, "", err)
 				return err
 			}
-			ux.SpinComplete(spinner)
-		}
-		for _, ansibleNodeID := range ansibleHostIDs {
-			if err = app.CreateAnsibleNodeConfigDir(ansibleNodeID); err != nil {
+			if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts, relayerPorts); err != nil {
+				ux.SpinFailWithError(spinner, "", err)
 				return err
 			}
+			if err := updateClusterAlertRules(clusterName, monitoringHost); err != nil {
+				ux.SpinFailWithError(spinner, "", err)
+				return err
+			}
+			ux.SpinComplete(spinner)
 		}
-		// download node configs
-		wg := sync.WaitGroup{}
-		wgResults := models.NodeResults{}
-		spinner := spinSession.SpinToUser("Configure Monitoring Agents")
+		// point node metrics to the monitoring host
 		for _, host := range hosts {
+			if wgResults.HasNodeIDWithError(host.NodeID) {
+				continue
+			}
 			wg.Add(1)
 			go func(nodeResults *models.NodeResults, host *models.Host) {
 				defer wg.Done()
-				nodeDirPath := app.GetNodeInstanceAvaGoConfigDirPath(host.NodeID)
-				if err := ssh.RunSSHDownloadNodeMonitoringConfig(host, nodeDirPath); err != nil {
-					nodeResults.AddResult(host.NodeID, nil, err)
-					return
-				}
-				if err = addHTTPHostToConfigFile(app.GetNodeConfigJSONFile(host.NodeID)); err != nil {
-					nodeResults.AddResult(host.NodeID, nil, err)
-					return
-				}
-				if err := ssh.RunSSHUploadNodeMonitoringConfig(host, nodeDirPath); err != nil {
+				if err := provisioner.provisionMonitoringAgent(host); err != nil {
 					nodeResults.AddResult(host.NodeID, nil, err)
-					return
-				}
-				if err := ssh.RunSSHRestartNode(host); err != nil {
-					nodeResults.AddResult(host.NodeID, nil, err)
-					return
-				}
-				if err := os.RemoveAll(nodeDirPath); err != nil {
-					return
 				}
 			}(&wgResults, host)
 		}
 		wg.Wait()
-		for _, node := range hosts {
-			if wgResults.HasNodeIDWithError(node.NodeID) {
-				ux.SpinFailWithError(spinner, node.NodeID, wgResults.GetErrorHostMap()[node.NodeID])
-				return fmt.Errorf("node %s failed to setup with error: %w", node.NodeID, wgResults.GetErrorHostMap()[node.NodeID])
-			}
+		if wgResults.HasErrors() {
+			spinSession.Stop()
+			ux.Logger.PrintToUser("Use metal node provision %s --resume to retry the failed steps", clusterName)
+			return fmt.Errorf("failed to deploy node(s) %s", wgResults.GetErrorHostMap())
 		}
-		ux.SpinComplete(spinner)
 	}
 	spinSession.Stop()
 	if network.Kind == models.Devnet {
-		if err := setupDevnet(clusterName, hosts, apiNodeIPMap); err != nil {
+		if extendingCluster {
+			err = joinDevnet(clusterName, hosts)
+		} else {
+			err = setupDevnet(clusterName, hosts, apiNodeIPMap)
+		}
+		if err != nil {
+			return err
+		}
+		if err := provisioner.markDevnetJoined(hosts); err != nil {
 			return err
 		}
 	}
@@ -774,6 +820,7 @@ func createNodes(cmd *cobra.Command, args []string) error {
 	}
 
 	if wgResults.HasErrors() {
+		ux.Logger.PrintToUser("Use metal node provision %s --resume to retry the failed steps", clusterName)
 		return fmt.Errorf("failed to deploy node(s) %s", wgResults.GetErrorHostMap())
 	} else {
 		monitoringPublicIP := ""
@@ -822,7 +869,13 @@ func CreateClusterNodeConfig(
 				CloudService:  cloudService,
 				UseStaticIP:   useStaticIP,
 				IsMonitor:     false,
+				IsSpot:        useSpot && cloudService == constants.AWSCloudService,
+				InstanceType:  cloudConfig.InstanceType,
 			}
+			if nodeConfig.IsSpot {
+				nodeConfig.SpotMaxPrice = spotMaxPrice
+			}
+			setNodeVolume(&nodeConfig, false)
 			err := app.CreateNodeCloudConfigFile(cloudConfig.InstanceIDs[i], &nodeConfig)
 			if err != nil {
 				return err
@@ -858,7 +911,9 @@ func saveExternalHostConfig(externalHostConfig models.RegionConfig, hostRegion,
 		UseStaticIP:   useStaticIP,
 		IsMonitor:     isMonitoring,
 		IsLoadTest:    isLoadTest,
+		InstanceType:  externalHostConfig.InstanceType,
 	}
+	setNodeVolume(&nodeConfig, true)
 	if err := app.CreateNodeCloudConfigFile(externalHostConfig.InstanceIDs[0], &nodeConfig); err != nil {
 		return err
 	}
@@ -1157,6 +1212,12 @@ func setCloudService() (string, error) {
 		}
 		return constants.E2EDocker, nil
 	}
+	if useDocker {
+		return constants.DockerCloudService, nil
+	}
+	if existingHostsFile != "" {
+		return constants.ExistingHostsCloudService, nil
+	}
 	if useAWS {
 		return constants.AWSCloudService, nil
 	}
@@ -1173,8 +1234,11 @@ func setCloudService() (string, error) {
 }
 
 func setCloudInstanceType(cloudService string) (string, error) {
-	if utils.IsE2E() && utils.E2EDocker() {
-		return constants.E2EDocker, nil
+	if cloudService == constants.DockerCloudService {
+		return constants.DockerCloudService, nil
+	}
+	if cloudService == constants.ExistingHostsCloudService {
+		return "", nil
 	}
 	switch { // backwards compatibility
 	case nodeType == constants.DefaultNodeType && cloudService == constants.AWSCloudService:
@@ -1476,14 +1540,15 @@ func defaultAvalancheCLIPrefix(region string) (string, error) {
 	return usr.Username + "-" + region + constants.AvalancheCLISuffix, nil
 }
 
-func getPrometheusTargets(clusterName string) ([]string, []string, []string, error) {
+func getPrometheusTargets(clusterName string) ([]string, []string, []string, []string, error) {
 	const loadTestPort = 8082
 	avalancheGoPorts := []string{}
 	machinePorts := []string{}
 	ltPorts := []string{}
+	relayerPorts := []string{}
 	inventoryHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
 	if err != nil {
-		return avalancheGoPorts, machinePorts, ltPorts, err
+		return avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err
 	}
 	for _, host := range inventoryHosts {
 		avalancheGoPorts = append(avalancheGoPorts, fmt.Sprintf("'%s:%s'", host.IP, strconv.Itoa(constants.AvalanchegoAPIPort)))
@@ -1494,5 +1559,12 @@ func getPrometheusTargets(clusterName string) ([]string, []string, []string, err
 	for _, host := range separateHosts {
 		ltPorts = append(ltPorts, fmt.Sprintf("'%s:%s'", host.IP, strconv.Itoa(loadTestPort)))
 	}
-	return avalancheGoPorts, machinePorts, ltPorts, nil
+	relayerHost, err := node.GetAWMRelayerHost(app, clusterName)
+	if err != nil {
+		return avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err
+	}
+	if relayerHost != nil {
+		relayerPorts = append(relayerPorts, fmt.Sprintf("'%s:%s'", relayerHost.IP, strconv.Itoa(constants.AWMRelayerMetricsPort)))
+	}
+	return avalancheGoPorts, machinePorts, ltPorts, relayerPorts, nil
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/maps"
 
@@ -188,6 +189,9 @@ func getAWSCloudConfig(awsProfile string, singleNode bool, clusterSgRegions []st
 }
 
 // createEC2Instances creates  ec2 instances
+//
+// Key pair import, security group setup and elastic IPs are AWS specific and are not
+// part of cloud.Provider, so cluster creation uses the AwsCloud clients directly
 func createEC2Instances(ec2Svc map[string]*awsAPI.AwsCloud,
 	regions []string,
 	regionConf map[string]models.RegionConfig,
@@ -329,6 +333,7 @@ func createEC2Instances(ec2Svc map[string]*awsAPI.AwsCloud,
 			throughput,
 			stringToAWSVolumeType(volumeType),
 			volumeSize,
+			getInstanceLifecycle(forMonitoring),
 		); err != nil {
 			return instanceIDs, elasticIPs, sshCertPath, keyPairName, err
 		}
@@ -387,76 +392,6 @@ func createEC2Instances(ec2Svc map[string]*awsAPI.AwsCloud,
 	return instanceIDs, elasticIPs, sshCertPath, keyPairName, nil
 }
 
-func AddMonitoringSecurityGroupRule(ec2Svc map[string]*awsAPI.AwsCloud, monitoringHostPublicIP, securityGroupName, region string) error {
-	securityGroupExists, sg, err := ec2Svc[region].CheckSecurityGroupExists(securityGroupName)
-	if err != nil {
-		return err
-	}
-	if !securityGroupExists {
-		return fmt.Errorf("security group %s doesn't exist in region %s", securityGroupName, region)
-	}
-	metricsPortInSG := awsAPI.CheckIPInSg(&sg, monitoringHostPublicIP, constants.AvalanchegoMachineMetricsPort)
-	apiPortInSG := awsAPI.CheckIPInSg(&sg, monitoringHostPublicIP, constants.AvalanchegoAPIPort)
-	if !metricsPortInSG {
-		if err = ec2Svc[region].AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", monitoringHostPublicIP+constants.IPAddressSuffix, constants.AvalanchegoMachineMetricsPort); err != nil {
-			return err
-		}
-	}
-	if !apiPortInSG {
-		if err = ec2Svc[region].AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", monitoringHostPublicIP+constants.IPAddressSuffix, constants.AvalanchegoAPIPort); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func deleteHostSecurityGroupRule(ec2Svc *awsAPI.AwsCloud, hostPublicIP, securityGroupName string) error {
-	securityGroupExists, sg, err := ec2Svc.CheckSecurityGroupExists(securityGroupName)
-	if err != nil {
-		return err
-	}
-	// exit early if security group doesn't exist
-	if !securityGroupExists {
-		return nil
-	}
-	metricsPortInSG := awsAPI.CheckIPInSg(&sg, hostPublicIP, constants.AvalanchegoMachineMetricsPort)
-	apiPortInSG := awsAPI.CheckIPInSg(&sg, hostPublicIP, constants.AvalanchegoAPIPort)
-	if metricsPortInSG {
-		if err = ec2Svc.DeleteSecurityGroupRule(*sg.GroupId, "ingress", "tcp", hostPublicIP+constants.IPAddressSuffix, constants.AvalanchegoMachineMetricsPort); err != nil {
-			return err
-		}
-	}
-	if apiPortInSG {
-		if err = ec2Svc.DeleteSecurityGroupRule(*sg.GroupId, "ingress", "tcp", hostPublicIP+constants.IPAddressSuffix, constants.AvalanchegoAPIPort); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func grantAccessToPublicIPViaSecurityGroup(ec2Svc *awsAPI.AwsCloud, publicIP, securityGroupName, region string) error {
-	securityGroupExists, sg, err := ec2Svc.CheckSecurityGroupExists(securityGroupName)
-	if err != nil {
-		return err
-	}
-	if !securityGroupExists {
-		return fmt.Errorf("security group %s doesn't exist in region %s", securityGroupName, region)
-	}
-	metricsPortInSG := awsAPI.CheckIPInSg(&sg, publicIP, constants.AvalanchegoMachineMetricsPort)
-	apiPortInSG := awsAPI.CheckIPInSg(&sg, publicIP, constants.AvalanchegoAPIPort)
-	if !metricsPortInSG {
-		if err = ec2Svc.AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", publicIP+constants.IPAddressSuffix, constants.AvalanchegoMachineMetricsPort); err != nil {
-			return err
-		}
-	}
-	if !apiPortInSG {
-		if err = ec2Svc.AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", publicIP+constants.IPAddressSuffix, constants.AvalanchegoAPIPort); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func createAWSInstances(
 	ec2Svc map[string]*awsAPI.AwsCloud,
 	nodeType string,
@@ -520,11 +455,25 @@ func createAWSInstances(
 			SecurityGroup: regionConf[region].SecurityGroupName,
 			CertFilePath:  certFilePath[region],
 			ImageID:       ami[region],
+			InstanceType:  nodeType,
 		}
 	}
 	return awsCloudConfig, nil
 }
 
+// getInstanceLifecycle returns the spot and expiry settings for new EC2 instances.
+// Monitoring and load test instances are always on-demand
+func getInstanceLifecycle(forMonitoring bool) awsAPI.InstanceLifecycle {
+	lifecycle := awsAPI.InstanceLifecycle{
+		Spot:         useSpot && !forMonitoring,
+		SpotMaxPrice: spotMaxPrice,
+	}
+	if expireAfter > 0 {
+		lifecycle.ExpiresAt = time.Now().Add(expireAfter)
+	}
+	return lifecycle
+}
+
 // addCertToSSH takes the cert file downloaded from AWS and moves it to .ssh directory
 func addCertToSSH(certName string) error {
 	certFilePath, err := app.GetSSHCertFilePath(certName)
@@ -210,21 +210,7 @@ func setupDevnet(clusterName string, hosts []*models.Host, apiNodeIPMap map[stri
 	bootstrapIDs := []string{}
 	// append makes sure that hostsWithoutAPI i.e. validators are proccessed first and API nodes will have full list of validators to bootstrap
 	for _, host := range append(hostsWithoutAPI, hostsAPI...) {
-		confMap := map[string]interface{}{}
-		confMap[config.HTTPHostKey] = ""
-		confMap[config.PublicIPKey] = host.IP
-		confMap[config.NetworkNameKey] = fmt.Sprintf("network-%d", network.ID)
-		confMap[config.BootstrapIDsKey] = strings.Join(bootstrapIDs, ",")
-		confMap[config.BootstrapIPsKey] = strings.Join(bootstrapIPs, ",")
-		confMap[config.GenesisFileKey] = filepath.Join(constants.CloudNodeConfigPath, "genesis.json")
-		confBytes, err := json.MarshalIndent(confMap, "", " ")
-		if err != nil {
-			return err
-		}
-		if err := os.WriteFile(filepath.Join(app.GetNodeInstanceDirPath(host.GetCloudID()), "genesis.json"), genesisBytes, constants.WriteReadReadPerms); err != nil {
-			return err
-		}
-		if err := os.WriteFile(filepath.Join(app.GetNodeInstanceDirPath(host.GetCloudID()), "node.json"), confBytes, constants.WriteReadReadPerms); err != nil {
+		if err := writeDevnetNodeConfig(host, network.ID, genesisBytes, bootstrapIDs, bootstrapIPs); err != nil {
 			return err
 		}
 		if slices.Contains(hostsWithoutAPIIDs, host.NodeID) {
@@ -236,7 +222,46 @@ func setupDevnet(clusterName string, hosts []*models.Host, apiNodeIPMap map[stri
 			bootstrapIPs = append(bootstrapIPs, fmt.Sprintf("%s:9651", host.IP))
 		}
 	}
-	// update node/s genesis + conf and start
+	if err := runSSHSetupDevnet(hosts); err != nil {
+		return err
+	}
+	ux.Logger.PrintLineSeparator()
+	ux.Logger.PrintToUser("Devnet Network Id: %s", logging.Green.Wrap(strconv.FormatUint(uint64(network.ID), 10)))
+	ux.Logger.PrintToUser("Devnet Endpoint: %s", logging.Green.Wrap(network.Endpoint))
+	ux.Logger.PrintLineSeparator()
+	// update cluster config with network information
+	clustersConfig, err := app.LoadClustersConfig()
+	if err != nil {
+		return err
+	}
+	clusterConfig := clustersConfig.Clusters[clusterName]
+	clusterConfig.Network = network
+	clustersConfig.Clusters[clusterName] = clusterConfig
+	return app.WriteClustersConfigFile(&clustersConfig)
+}
+
+// writeDevnetNodeConfig writes the devnet genesis and the avalanchego conf node.json
+// bootstrapping from the given nodes into the node dir of the host
+func writeDevnetNodeConfig(host *models.Host, networkID uint32, genesisBytes []byte, bootstrapIDs []string, bootstrapIPs []string) error {
+	confMap := map[string]interface{}{}
+	confMap[config.HTTPHostKey] = ""
+	confMap[config.PublicIPKey] = host.IP
+	confMap[config.NetworkNameKey] = fmt.Sprintf("network-%d", networkID)
+	confMap[config.BootstrapIDsKey] = strings.Join(bootstrapIDs, ",")
+	confMap[config.BootstrapIPsKey] = strings.Join(bootstrapIPs, ",")
+	confMap[config.GenesisFileKey] = filepath.Join(constants.CloudNodeConfigPath, "genesis.json")
+	confBytes, err := json.MarshalIndent(confMap, "", " ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(app.GetNodeInstanceDirPath(host.GetCloudID()), "genesis.json"), genesisBytes, constants.WriteReadReadPerms); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(app.GetNodeInstanceDirPath(host.GetCloudID()), "node.json"), confBytes, constants.WriteReadReadPerms)
+}
+
+// runSSHSetupDevnet uploads the devnet genesis and node conf to the hosts and starts them
+func runSSHSetupDevnet(hosts []*models.Host) error {
 	wg := sync.WaitGroup{}
 	wgResults := models.NodeResults{}
 	for _, host := range hosts {
@@ -270,17 +295,45 @@ func setupDevnet(clusterName string, hosts []*models.Host, apiNodeIPMap map[stri
 	if wgResults.HasErrors() {
 		return fmt.Errorf("failed to deploy node(s) %s", wgResults.GetErrorHostMap())
 	}
-	ux.Logger.PrintLineSeparator()
-	ux.Logger.PrintToUser("Devnet Network Id: %s", logging.Green.Wrap(strconv.FormatUint(uint64(network.ID), 10)))
-	ux.Logger.PrintToUser("Devnet Endpoint: %s", logging.Green.Wrap(network.Endpoint))
-	ux.Logger.PrintLineSeparator()
-	// update cluster config with network information
-	clustersConfig, err := app.LoadClustersConfig()
+	return nil
+}
+
+// joinDevnet sets up new [hosts] of an existing devnet cluster, reusing the genesis
+// of the cluster and bootstrapping from its current validators
+func joinDevnet(clusterName string, hosts []*models.Host) error {
+	clusterConfig, err := app.GetClusterConfig(clusterName)
 	if err != nil {
 		return err
 	}
-	clusterConfig := clustersConfig.Clusters[clusterName]
-	clusterConfig.Network = network
-	clustersConfig.Clusters[clusterName] = clusterConfig
-	return app.WriteClustersConfigFile(&clustersConfig)
+	allHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return err
+	}
+	newCloudIDs := utils.Map(hosts, func(h *models.Host) string { return h.GetCloudID() })
+	bootstrapHosts := utils.Filter(clusterConfig.GetValidatorHosts(allHosts), func(h *models.Host) bool {
+		return !slices.Contains(newCloudIDs, h.GetCloudID())
+	})
+	if len(bootstrapHosts) == 0 {
+		return fmt.Errorf("no existing validators found in cluster %s to bootstrap from", clusterName)
+	}
+	genesisBytes, err := os.ReadFile(filepath.Join(app.GetNodeInstanceDirPath(bootstrapHosts[0].GetCloudID()), "genesis.json"))
+	if err != nil {
+		return fmt.Errorf("unable to read devnet genesis of cluster %s: %w", clusterName, err)
+	}
+	bootstrapIPs := []string{}
+	bootstrapIDs := []string{}
+	for _, host := range bootstrapHosts {
+		nodeID, err := getNodeID(app.GetNodeInstanceDirPath(host.GetCloudID()))
+		if err != nil {
+			return err
+		}
+		bootstrapIDs = append(bootstrapIDs, nodeID.String())
+		bootstrapIPs = append(bootstrapIPs, fmt.Sprintf("%s:9651", host.IP))
+	}
+	for _, host := range hosts {
+		if err := writeDevnetNodeConfig(host, clusterConfig.Network.ID, genesisBytes, bootstrapIDs, bootstrapIPs); err != nil {
+			return err
+		}
+	}
+	return runSSHSetupDevnet(hosts)
 }
@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/utils"
+)
+
+// checkExistingHosts validates that the hosts can be adopted into a cluster on the given network
+func checkExistingHosts(existingHosts []models.ExistingHost, isDevnet bool) error {
+	for _, existingHost := range existingHosts {
+		if existingHost.API && !isDevnet {
+			return fmt.Errorf("host %s: API nodes can only be used in Devnet", existingHost.Name)
+		}
+		if !existingHost.UsesSSHAgent() {
+			continue
+		}
+		if !utils.IsSSHAgentAvailable() {
+			return fmt.Errorf("host %s: ssh agent is not available", existingHost.Name)
+		}
+		identityValid, err := utils.IsSSHAgentIdentityValid(existingHost.SSHAgentIdentity)
+		if err != nil {
+			return err
+		}
+		if !identityValid {
+			return fmt.Errorf("host %s: ssh-agent identity %s not found", existingHost.Name, existingHost.SSHAgentIdentity)
+		}
+	}
+	return nil
+}
+
+// getExistingHostsCloudConfig groups the node hosts by region label, and returns
+// the monitoring host separately, if any
+func getExistingHostsCloudConfig(existingHosts []models.ExistingHost) (models.CloudConfig, *models.ExistingHost) {
+	cloudConfigMap := models.CloudConfig{}
+	var monitoringHost *models.ExistingHost
+	for i, existingHost := range existingHosts {
+		if existingHost.Monitoring {
+			monitoringHost = &existingHosts[i]
+			continue
+		}
+		regionConfig := cloudConfigMap[existingHost.Region]
+		regionConfig.InstanceIDs = append(regionConfig.InstanceIDs, existingHost.Name)
+		regionConfig.PublicIPs = append(regionConfig.PublicIPs, existingHost.IP)
+		if existingHost.API {
+			regionConfig.APIInstanceIDs = append(regionConfig.APIInstanceIDs, existingHost.Name)
+		}
+		if regionConfig.CertFilePath == "" {
+			regionConfig.CertFilePath = existingHost.SSHKey
+		}
+		regionConfig.NumNodes++
+		cloudConfigMap[existingHost.Region] = regionConfig
+	}
+	return cloudConfigMap, monitoringHost
+}
+
+// getExistingHostRegionConfig returns the region config of a single existing host
+func getExistingHostRegionConfig(existingHost models.ExistingHost) models.RegionConfig {
+	return models.RegionConfig{
+		InstanceIDs:  []string{existingHost.Name},
+		PublicIPs:    []string{existingHost.IP},
+		CertFilePath: existingHost.SSHKey,
+		NumNodes:     1,
+	}
+}
+
+// addExistingHostsToInventory adds the hosts to the inventory, keeping the ssh user
+// and credentials of each host
+func addExistingHostsToInventory(inventoryDirPath string, existingHosts []models.ExistingHost) error {
+	hosts, err := utils.MapWithError(existingHosts, func(existingHost models.ExistingHost) (*models.Host, error) {
+		return existingHost.ToHost()
+	})
+	if err != nil {
+		return err
+	}
+	return ansible.AddHostsToInventory(inventoryDirPath, hosts)
+}
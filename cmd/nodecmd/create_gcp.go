@@ -152,6 +152,9 @@ func getGCPConfig(singleNode bool) (*gcpAPI.GcpCloud, map[string]NumNodes, strin
 }
 
 // createGCEInstances creates Google Compute Engine VM instances
+//
+// Network setup and static IPs are GCP specific and are not part of cloud.Provider,
+// so cluster creation uses the GcpCloud client directly
 func createGCEInstances(gcpClient *gcpAPI.GcpCloud,
 	instanceType string,
 	numNodesMap map[string]NumNodes,
@@ -374,6 +377,7 @@ func createGCPInstance(
 			SecurityGroup: fmt.Sprintf("%s-network", prefix),
 			CertFilePath:  certFilePath,
 			ImageID:       imageID,
+			InstanceType:  instanceType,
 		}
 	}
 	return ccm, nil
@@ -397,30 +401,6 @@ func updateClustersConfigGCPKeyFilepath(projectName, serviceAccountKeyFilepath s
 	return app.WriteClustersConfigFile(&clustersConfig)
 }
 
-func grantAccessToPublicIPViaFirewall(gcpClient *gcpAPI.GcpCloud, projectName string, publicIP string, label string) error {
-	prefix, err := defaultAvalancheCLIPrefix("")
-	if err != nil {
-		return err
-	}
-	networkName := fmt.Sprintf("%s-network", prefix)
-	firewallName := fmt.Sprintf("%s-%s-%s", networkName, strings.ReplaceAll(publicIP, ".", ""), label)
-	ports := []string{
-		strconv.Itoa(constants.AvalanchegoMachineMetricsPort), strconv.Itoa(constants.AvalanchegoAPIPort),
-		strconv.Itoa(constants.AvalanchegoMonitoringPort), strconv.Itoa(constants.AvalanchegoGrafanaPort),
-		strconv.Itoa(constants.AvalanchegoLokiPort),
-	}
-	if err = gcpClient.AddFirewall(
-		publicIP,
-		networkName,
-		projectName,
-		firewallName,
-		ports,
-		true); err != nil {
-		return err
-	}
-	return nil
-}
-
 func setGCPAWMRelayerSecurityGroupRule(awmRelayerHost *models.Host) error {
 	gcpClient, _, _, _, projectName, err := getGCPConfig(true)
 	if err != nil {
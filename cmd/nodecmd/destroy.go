@@ -7,16 +7,16 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ixAnkit/cryft/pkg/utils"
 
+	"github.com/ixAnkit/cryft/pkg/cloud"
 	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
-	gcpAPI "github.com/ixAnkit/cryft/pkg/cloud/gcp"
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"golang.org/x/exp/maps"
-	"golang.org/x/net/context"
 
 	"github.com/spf13/cobra"
 )
@@ -24,6 +24,7 @@ import (
 var (
 	authorizeRemove bool
 	authorizeAll    bool
+	destroyExpired  bool
 )
 
 func newDestroyCmd() *cobra.Command {
@@ -34,15 +35,27 @@ func newDestroyCmd() *cobra.Command {
 
 The node destroy command terminates all running nodes in cloud server and deletes all storage disks.
 
-If there is a static IP address attached, it will be released.`,
+If there is a static IP address attached, it will be released.
+
+Existing hosts adopted with node create --existing-hosts are only detached
+from the cluster, the servers themselves are left untouched.
+
+With --expired, all clusters (or the given one) whose AWS instances were tagged
+to expire with node create --expire-after, and are past that time, are destroyed.
+
+With --selector, only the nodes whose labels match it are destroyed, the same way
+as node remove does for each of them, and the rest of the cluster is kept.
+--selector can't be combined with --expired.`,
 		SilenceUsage: true,
-		Args:         cobra.ExactArgs(1),
+		Args:         cobra.RangeArgs(0, 1),
 		RunE:         destroyNodes,
 	}
 	cmd.Flags().BoolVar(&authorizeAccess, "authorize-access", false, "authorize CLI to release cloud resources")
 	cmd.Flags().BoolVar(&authorizeRemove, "authorize-remove", false, "authorize CLI to remove all local files related to cloud nodes")
 	cmd.Flags().BoolVarP(&authorizeAll, "authorize-all", "y", false, "authorize all CLI requests")
 	cmd.Flags().StringVar(&awsProfile, "aws-profile", constants.AWSDefaultCredential, "aws profile to use")
+	cmd.Flags().BoolVar(&destroyExpired, "expired", false, "destroy the clusters whose instances are past their expiry time")
+	addSelectorFlag(cmd)
 
 	return cmd
 }
@@ -95,7 +108,135 @@ func removeClustersConfigFiles(clusterName string) error {
 }
 
 func destroyNodes(_ *cobra.Command, args []string) error {
-	clusterName := args[0]
+	if destroyExpired {
+		if nodeSelector != "" {
+			return fmt.Errorf("--expired and --selector can't be used together")
+		}
+		return destroyExpiredClusters(args)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("a cluster name must be given, or --expired")
+	}
+	if nodeSelector != "" {
+		return destroySelectedNodes(args[0])
+	}
+	return destroyCluster(args[0])
+}
+
+// destroySelectedNodes removes the cluster nodes matching --selector from the cluster
+// and terminates them
+func destroySelectedNodes(clusterName string) error {
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	selectedNodes, err := utils.FilterWithError(clusterConf.Nodes, matchesNodeSelector)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(selectedNodes) == 0:
+		return fmt.Errorf("no nodes match selector %q", nodeSelector)
+	case len(selectedNodes) == len(clusterConf.Nodes):
+		return fmt.Errorf("selector %q matches all nodes of cluster %s, use node destroy without --selector to destroy it", nodeSelector, clusterName)
+	}
+	for _, cloudID := range selectedNodes {
+		if err := removeClusterNode(clusterName, cloudID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destroyExpiredClusters destroys the given clusters, or all of them, that are past
+// their expiry time
+func destroyExpiredClusters(clusterNames []string) error {
+	var err error
+	if len(clusterNames) == 0 {
+		if clusterNames, err = app.ListClusterNames(); err != nil {
+			return err
+		}
+	}
+	expiredClusters := []string{}
+	for _, clusterName := range clusterNames {
+		if err := checkCluster(clusterName); err != nil {
+			ux.Logger.PrintToUser("Skipping cluster %s due to %s", clusterName, err)
+			continue
+		}
+		expiresAt, err := getClusterExpiry(clusterName)
+		if err != nil {
+			ux.Logger.PrintToUser("Unable to get expiry time of cluster %s due to %s", clusterName, err)
+			continue
+		}
+		if !expiresAt.IsZero() && time.Now().After(expiresAt) {
+			ux.Logger.PrintToUser("Cluster %s expired at %s", clusterName, expiresAt.Local().Format(time.RFC1123))
+			expiredClusters = append(expiredClusters, clusterName)
+		}
+	}
+	if len(expiredClusters) == 0 {
+		ux.Logger.PrintToUser("No expired clusters found")
+		return nil
+	}
+	if authorizeAll {
+		authorizeAccess = true
+		authorizeRemove = true
+	}
+	if err := getDeleteConfigConfirmation(); err != nil {
+		return err
+	}
+	authorizeRemove = true
+	for _, clusterName := range expiredClusters {
+		if err := destroyCluster(clusterName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getClusterExpiry returns the earliest expiry time tagged into the AWS instances of
+// the cluster, or the zero time if none is
+func getClusterExpiry(clusterName string) (time.Time, error) {
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var (
+		expiresAt time.Time
+		provider  *awsAPI.Provider
+	)
+	for _, cloudID := range clusterConf.GetCloudIDs() {
+		nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if nodeConfig.CloudService != constants.AWSCloudService {
+			continue
+		}
+		if provider == nil {
+			if err := checkCloudAccess(nodeConfig.CloudService); err != nil {
+				return time.Time{}, err
+			}
+			provider = awsAPI.NewProvider(awsProfile)
+		}
+		ec2Svc, err := provider.Cloud(nodeConfig.Region)
+		if err != nil {
+			return time.Time{}, err
+		}
+		nodeExpiresAt, err := ec2Svc.GetInstanceExpiry(cloudID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !nodeExpiresAt.IsZero() && (expiresAt.IsZero() || nodeExpiresAt.Before(expiresAt)) {
+			expiresAt = nodeExpiresAt
+		}
+	}
+	return expiresAt, nil
+}
+
+func destroyCluster(clusterName string) error {
 	if err := checkCluster(clusterName); err != nil {
 		return err
 	}
@@ -143,18 +284,7 @@ func destroyNodes(_ *cobra.Command, args []string) error {
 	if len(filteredSGList) == 0 {
 		return fmt.Errorf("no endpoint found in the  %s", nodeToStopConfig.CloudService)
 	}
-	var gcpCloud *gcpAPI.GcpCloud
-	ec2SvcMap := make(map[string]*awsAPI.AwsCloud)
-	// TODO: need implementation for GCP
-	if nodeToStopConfig.CloudService == constants.AWSCloudService {
-		for _, sg := range filteredSGList {
-			sgEc2Svc, err := awsAPI.NewAwsCloud(awsProfile, sg.region)
-			if err != nil {
-				return err
-			}
-			ec2SvcMap[sg.region] = sgEc2Svc
-		}
-	}
+	cloudProviders := map[string]cloud.Provider{}
 	for _, node := range nodesToStop {
 		nodeConfig, err := app.LoadClusterNodeConfig(node)
 		if err != nil {
@@ -162,49 +292,36 @@ func destroyNodes(_ *cobra.Command, args []string) error {
 			ux.Logger.PrintToUser("Failed to destroy node %s due to %s", node, err.Error())
 			continue
 		}
-		if nodeConfig.CloudService == "" || nodeConfig.CloudService == constants.AWSCloudService {
-			if !(authorizeAccess || authorizedAccessFromSettings()) && (requestCloudAuth(constants.AWSCloudService) != nil) {
-				return fmt.Errorf("cloud access is required")
+		if err := checkCloudAccess(nodeConfig.CloudService); err != nil {
+			return err
+		}
+		provider, ok := cloudProviders[nodeConfig.CloudService]
+		if !ok {
+			provider, err = getCloudProvider(nodeConfig.CloudService)
+			if err != nil {
+				return err
 			}
-			if err = ec2SvcMap[nodeConfig.Region].DestroyAWSNode(nodeConfig, clusterName); err != nil {
-				if isExpiredCredentialError(err) {
-					ux.Logger.PrintToUser("")
-					printExpiredCredentialsOutput(awsProfile)
-					return nil
-				}
-				if !errors.Is(err, awsAPI.ErrNodeNotFoundToBeRunning) {
-					nodeErrors[node] = err
-					continue
-				}
-				ux.Logger.PrintToUser("node %s is already destroyed", nodeConfig.NodeID)
+			cloudProviders[nodeConfig.CloudService] = provider
+		}
+		if err = provider.DestroyNode(nodeConfig, clusterName); err != nil {
+			if isExpiredCredentialError(err) {
+				ux.Logger.PrintToUser("")
+				printExpiredCredentialsOutput(awsProfile)
+				return nil
 			}
+			if !errors.Is(err, cloud.ErrNodeNotFoundToBeRunning) {
+				nodeErrors[node] = err
+				continue
+			}
+			ux.Logger.PrintToUser("node %s is already destroyed", nodeConfig.NodeID)
+		}
+		if provider.Name() == constants.AWSCloudService {
 			for _, sg := range filteredSGList {
-				if err = deleteHostSecurityGroupRule(ec2SvcMap[sg.region], nodeConfig.ElasticIP, sg.securityGroup); err != nil {
+				if err = provider.RemoveFirewallRule(sg.region, sg.securityGroup, nodeConfig.ElasticIP, []int{constants.AvalanchegoMachineMetricsPort, constants.AvalanchegoAPIPort}); err != nil {
 					ux.Logger.RedXToUser("unable to delete IP address %s from security group %s in region %s due to %s, please delete it manually",
 						nodeConfig.ElasticIP, sg.securityGroup, sg.region, err.Error())
 				}
 			}
-		} else {
-			if !(authorizeAccess || authorizedAccessFromSettings()) && (requestCloudAuth(constants.GCPCloudService) != nil) {
-				return fmt.Errorf("cloud access is required")
-			}
-			if gcpCloud == nil {
-				gcpClient, projectName, _, err := getGCPCloudCredentials()
-				if err != nil {
-					return err
-				}
-				gcpCloud, err = gcpAPI.NewGcpCloud(gcpClient, projectName, context.Background())
-				if err != nil {
-					return err
-				}
-			}
-			if err = gcpCloud.DestroyGCPNode(nodeConfig, clusterName); err != nil {
-				if !errors.Is(err, gcpAPI.ErrNodeNotFoundToBeRunning) {
-					nodeErrors[node] = err
-					continue
-				}
-				ux.Logger.PrintToUser("node %s is already destroyed", nodeConfig.NodeID)
-			}
 		}
 		ux.Logger.PrintToUser("Node instance %s in cluster %s successfully destroyed!", nodeConfig.NodeID, clusterName)
 		if err := removeDeletedNodeDirectory(node); err != nil {
@@ -226,9 +343,33 @@ func destroyNodes(_ *cobra.Command, args []string) error {
 		ux.Logger.PrintToUser("All nodes in cluster %s are successfully destroyed!", clusterName)
 	}
 
+	if nodeToStopConfig.CloudService == constants.DockerCloudService && !dockerUsedByOtherClusters(clusterName) {
+		if err := getDockerProvider().Cleanup(); err != nil {
+			ux.Logger.RedXToUser("unable to clean up docker compose project due to %s", err.Error())
+		}
+	}
 	return removeClustersConfigFiles(clusterName)
 }
 
+// dockerUsedByOtherClusters checks if any cluster other than [clusterName] has docker hosts,
+// in which case the shared docker compose project must be kept
+func dockerUsedByOtherClusters(clusterName string) bool {
+	clustersConfig, err := app.LoadClustersConfig()
+	if err != nil {
+		return true
+	}
+	for otherClusterName, clusterConfig := range clustersConfig.Clusters {
+		if otherClusterName == clusterName || len(clusterConfig.Nodes) == 0 {
+			continue
+		}
+		nodeConfig, err := app.LoadClusterNodeConfig(clusterConfig.Nodes[0])
+		if err != nil || nodeConfig.CloudService == constants.DockerCloudService {
+			return true
+		}
+	}
+	return false
+}
+
 func getClusterMonitoringNode(clusterName string) (string, error) {
 	clustersConfig := models.ClustersConfig{}
 	if app.ClustersConfigExists() {
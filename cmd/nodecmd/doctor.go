@@ -0,0 +1,555 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	doctorOK   = "OK"
+	doctorWarn = "WARN"
+	doctorFail = "FAIL"
+	doctorSkip = "SKIP"
+)
+
+var (
+	doctorFix          bool
+	nodeAgentServices  = []string{"node_exporter", "promtail"}
+	monitoringServices = []string{"prometheus", "grafana-server", "loki"}
+)
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor [clusterName]",
+		Short: "(ALPHA Warning) Detect drift between local cluster state and the cluster hosts",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node doctor command compares the local cluster configuration, node configs and
+ansible inventory with the cloud APIs and with what is running on each host
+(instance state, IPs, avalanchego version, node ID, tracked subnets and monitoring
+agents), and prints a report of the detected drift.
+
+With --fix, inventory entries are repaired, changed IPs are updated and nodes whose
+instances the cloud reports as not found or terminated are removed from the cluster,
+after confirmation. Stopped instances are only reported. The local files of removed
+nodes, including their staking keys, are archived and never deleted. Hosts themselves
+are never modified.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         doctor,
+	}
+	cmd.Flags().BoolVar(&doctorFix, "fix", false, "repair inventory entries and remove dead nodes from the cluster")
+	cmd.Flags().BoolVar(&authorizeRemove, "authorize-remove", false, "authorize CLI to remove dead nodes from the cluster without confirmation")
+	cmd.Flags().StringVar(&awsProfile, "aws-profile", constants.AWSDefaultCredential, "aws profile to use")
+	return cmd
+}
+
+type doctorCheck struct {
+	host   string
+	check  string
+	status string
+	detail string
+}
+
+// doctorReport collects check results and the repairs to apply with --fix
+type doctorReport struct {
+	lock   sync.Mutex
+	checks []doctorCheck
+	// repairs descriptions, in the order they were found
+	repairs []string
+	// cloud IDs of hosts to remove from the cluster
+	removeHosts []string
+	// inventory entries to add or replace, by ansible ID
+	inventoryHosts map[string]*models.Host
+	// inventory entries to drop, by ansible ID
+	dropInventory []string
+	// node configs to rewrite
+	nodeConfigs []models.NodeConfig
+}
+
+func (r *doctorReport) add(host, check, status, detail string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checks = append(r.checks, doctorCheck{host: host, check: check, status: status, detail: detail})
+}
+
+func (r *doctorReport) addRepair(description string, apply func(r *doctorReport)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.repairs = append(r.repairs, description)
+	apply(r)
+}
+
+func (r *doctorReport) count(status string) int {
+	return len(utils.Filter(r.checks, func(c doctorCheck) bool { return c.status == status }))
+}
+
+func doctor(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	inventoryPath := app.GetAnsibleInventoryDirPath(clusterName)
+	inventory, err := ansible.GetHostMapfromAnsibleInventory(inventoryPath)
+	if err != nil {
+		return err
+	}
+	report := &doctorReport{inventoryHosts: map[string]*models.Host{}}
+	expectedSubnets, err := getClusterSubnetIDs(clusterConf)
+	if err != nil {
+		return err
+	}
+	providers := map[string]cloud.Provider{}
+	hosts := []*models.Host{}
+	nodeConfigs := map[string]models.NodeConfig{}
+	for _, cloudID := range clusterConf.Nodes {
+		nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+		if err != nil {
+			// the instance can't be checked without its config, so it is never removed
+			report.add(cloudID, "config", doctorFail, fmt.Sprintf("node config can't be loaded: %s", err))
+			continue
+		}
+		report.add(cloudID, "config", doctorOK, "")
+		nodeConfigs[cloudID] = nodeConfig
+		if _, ok := providers[nodeConfig.CloudService]; !ok {
+			provider, err := getCloudProvider(nodeConfig.CloudService)
+			if err != nil {
+				return err
+			}
+			providers[nodeConfig.CloudService] = provider
+		}
+		host := checkDoctorInventory(report, inventory, nodeConfig)
+		if host != nil {
+			hosts = append(hosts, host)
+		}
+	}
+	// inventory entries not belonging to the cluster
+	for ansibleID := range inventory {
+		_, cloudID, err := models.HostAnsibleIDToCloudID(ansibleID)
+		if err != nil || !slices.Contains(clusterConf.Nodes, cloudID) {
+			report.add(ansibleID, "inventory", doctorWarn, "inventory entry does not belong to any cluster node")
+			report.addRepair(fmt.Sprintf("drop inventory entry %s", ansibleID), func(r *doctorReport) {
+				r.dropInventory = append(r.dropInventory, ansibleID)
+			})
+		}
+	}
+	defer disconnectHosts(hosts)
+
+	ux.Logger.PrintToUser("Checking %d node(s) in cluster %s...", len(hosts), logging.LightBlue.Wrap(clusterName))
+	versions := map[string]string{}
+	versionsLock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(host *models.Host) {
+			defer wg.Done()
+			nodeConfig := nodeConfigs[host.GetCloudID()]
+			if !checkDoctorInstance(report, providers[nodeConfig.CloudService], nodeConfig, host) {
+				return
+			}
+			version := checkDoctorNode(report, clusterConf, host, expectedSubnets)
+			if version != "" {
+				versionsLock.Lock()
+				versions[host.GetCloudID()] = version
+				versionsLock.Unlock()
+			}
+		}(host)
+	}
+	wg.Wait()
+	checkDoctorVersions(report, versions)
+	if clusterConf.MonitoringInstance != "" {
+		monitoringHosts := checkDoctorMonitoring(report, clusterName, clusterConf.MonitoringInstance)
+		defer disconnectHosts(monitoringHosts)
+	}
+
+	printDoctorReport(clusterName, report)
+	if len(report.repairs) == 0 {
+		return nil
+	}
+	if !doctorFix {
+		ux.Logger.PrintToUser("")
+		ux.Logger.PrintToUser("Run %s to apply the following repairs:", logging.LightBlue.Wrap(fmt.Sprintf("avalanche node doctor %s --fix", clusterName)))
+		for _, repair := range report.repairs {
+			ux.Logger.PrintToUser("  - %s", repair)
+		}
+		return nil
+	}
+	return applyDoctorRepairs(clusterName, report)
+}
+
+// checkDoctorInventory checks the inventory entry of the node against its node config,
+// returning the host to use for further checks
+func checkDoctorInventory(report *doctorReport, inventory map[string]*models.Host, nodeConfig models.NodeConfig) *models.Host {
+	cloudID := nodeConfig.NodeID
+	ansibleID, err := models.HostCloudIDToAnsibleID(nodeConfig.CloudService, cloudID)
+	if err != nil {
+		report.add(cloudID, "inventory", doctorFail, err.Error())
+		return nil
+	}
+	host, ok := inventory[ansibleID]
+	switch {
+	case !ok:
+		report.add(cloudID, "inventory", doctorFail, "missing inventory entry")
+		host = &models.Host{
+			NodeID:            ansibleID,
+			IP:                nodeConfig.ElasticIP,
+			SSHUser:           constants.AnsibleSSHUser,
+			SSHPrivateKeyPath: nodeConfig.CertPath,
+			SSHCommonArgs:     constants.AnsibleSSHUseAgentParams,
+		}
+		report.addRepair(fmt.Sprintf("add inventory entry for %s with IP %s", cloudID, nodeConfig.ElasticIP), func(r *doctorReport) {
+			r.inventoryHosts[ansibleID] = host
+		})
+	case host.IP != nodeConfig.ElasticIP:
+		report.add(cloudID, "inventory", doctorFail, fmt.Sprintf("inventory IP %s differs from node config IP %s", host.IP, nodeConfig.ElasticIP))
+		fixed := *host
+		fixed.IP = nodeConfig.ElasticIP
+		host = &fixed
+		report.addRepair(fmt.Sprintf("set inventory IP of %s to %s", cloudID, nodeConfig.ElasticIP), func(r *doctorReport) {
+			r.inventoryHosts[ansibleID] = host
+		})
+	default:
+		report.add(cloudID, "inventory", doctorOK, "")
+	}
+	return host
+}
+
+// checkDoctorInstance checks the cloud instance of the node. Returns false if the host
+// can't be reached and no further checks should be done
+func checkDoctorInstance(report *doctorReport, provider cloud.Provider, nodeConfig models.NodeConfig, host *models.Host) bool {
+	cloudID := nodeConfig.NodeID
+	isRunning, err := provider.IsInstanceRunning(nodeConfig.Region, cloudID)
+	switch {
+	case errors.Is(err, cloud.ErrNotSupported):
+		report.add(cloudID, "instance", doctorSkip, fmt.Sprintf("not available for %s", provider.Name()))
+	case err != nil:
+		report.add(cloudID, "instance", doctorWarn, fmt.Sprintf("unable to get instance state: %s", err))
+	case !isRunning:
+		exists, err := provider.InstanceExists(nodeConfig.Region, cloudID)
+		switch {
+		case err != nil:
+			report.add(cloudID, "instance", doctorFail, fmt.Sprintf("instance not running, unable to check if it exists: %s", err))
+		case exists:
+			report.add(cloudID, "instance", doctorFail, "instance is stopped, start it to bring the node back")
+		default:
+			report.add(cloudID, "instance", doctorFail, "instance not found or terminated")
+			report.addRepair(fmt.Sprintf("remove %s from cluster: instance not found or terminated", cloudID), func(r *doctorReport) {
+				r.removeHosts = append(r.removeHosts, cloudID)
+			})
+		}
+		return false
+	default:
+		report.add(cloudID, "instance", doctorOK, "running")
+		if !nodeConfig.UseStaticIP {
+			ips, err := provider.GetInstancePublicIPs(nodeConfig.Region, []string{cloudID})
+			if err == nil && ips[cloudID] != "" && ips[cloudID] != host.IP {
+				report.add(cloudID, "ip", doctorFail, fmt.Sprintf("public IP changed from %s to %s", host.IP, ips[cloudID]))
+				nodeConfig.ElasticIP = ips[cloudID]
+				host.IP = ips[cloudID]
+				report.addRepair(fmt.Sprintf("update IP of %s to %s", cloudID, host.IP), func(r *doctorReport) {
+					r.nodeConfigs = append(r.nodeConfigs, nodeConfig)
+					r.inventoryHosts[host.NodeID] = host
+				})
+			}
+		}
+	}
+	if err := host.Connect(0); err != nil {
+		report.add(cloudID, "ssh", doctorFail, err.Error())
+		return false
+	}
+	report.add(cloudID, "ssh", doctorOK, "")
+	return true
+}
+
+// checkDoctorNode checks avalanchego and agents running on the node. Returns the
+// avalanchego version, or an empty string if it is not responding
+func checkDoctorNode(report *doctorReport, clusterConf models.ClusterConfig, host *models.Host, expectedSubnets []string) string {
+	cloudID := host.GetCloudID()
+	version := ""
+	if resp, err := ssh.RunSSHCheckAvalancheGoVersion(host); err != nil {
+		report.add(cloudID, "avalanchego", doctorFail, "not responding, node may be half configured")
+	} else if version, _, err = parseAvalancheGoOutput(resp); err != nil {
+		report.add(cloudID, "avalanchego", doctorFail, err.Error())
+	} else {
+		report.add(cloudID, "avalanchego", doctorOK, version)
+	}
+	if version != "" {
+		localNodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID))
+		resp, sshErr := ssh.RunSSHGetNodeID(host)
+		remoteNodeID := ""
+		if sshErr == nil {
+			remoteNodeID, sshErr = parseNodeIDOutput(resp)
+		}
+		switch {
+		case err != nil:
+			report.add(cloudID, "node ID", doctorWarn, fmt.Sprintf("local staking files can't be read: %s", err))
+		case sshErr != nil:
+			report.add(cloudID, "node ID", doctorWarn, sshErr.Error())
+		case remoteNodeID != localNodeID.String():
+			report.add(cloudID, "node ID", doctorFail, fmt.Sprintf("host runs %s, local staking files are for %s", remoteNodeID, localNodeID))
+		default:
+			report.add(cloudID, "node ID", doctorOK, remoteNodeID)
+		}
+	}
+	if len(expectedSubnets) > 0 {
+		trackedSubnets, err := getTrackedSubnets(host)
+		if err != nil {
+			report.add(cloudID, "subnets", doctorWarn, err.Error())
+		} else if missing := utils.Filter(expectedSubnets, func(s string) bool { return !slices.Contains(trackedSubnets, s) }); len(missing) > 0 {
+			report.add(cloudID, "subnets", doctorFail, fmt.Sprintf("not tracking %s, run node sync", strings.Join(missing, ",")))
+		} else {
+			report.add(cloudID, "subnets", doctorOK, "")
+		}
+	}
+	if clusterConf.MonitoringInstance != "" {
+		checkDoctorServices(report, host, "monitoring agents", nodeAgentServices)
+	}
+	return version
+}
+
+// checkDoctorVersions warns about nodes running a different avalanchego version than most of the cluster
+func checkDoctorVersions(report *doctorReport, versions map[string]string) {
+	count := map[string]int{}
+	for _, version := range versions {
+		count[version]++
+	}
+	majority := ""
+	for version, n := range count {
+		if n > count[majority] || (n == count[majority] && version > majority) {
+			majority = version
+		}
+	}
+	for cloudID, version := range versions {
+		if version != majority {
+			report.add(cloudID, "avalanchego", doctorWarn, fmt.Sprintf("runs %s while most of the cluster runs %s", version, majority))
+		}
+	}
+}
+
+// checkDoctorMonitoring checks the monitoring host of the cluster
+func checkDoctorMonitoring(report *doctorReport, clusterName string, monitoringCloudID string) []*models.Host {
+	nodeConfig, err := app.LoadClusterNodeConfig(monitoringCloudID)
+	if err != nil {
+		report.add(monitoringCloudID, "config", doctorFail, fmt.Sprintf("monitoring node config can't be loaded: %s", err))
+		return nil
+	}
+	monitoringHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetMonitoringInventoryDir(clusterName))
+	if err != nil || len(monitoringHosts) != 1 {
+		report.add(monitoringCloudID, "inventory", doctorFail, "missing monitoring inventory")
+		return nil
+	}
+	provider, err := getCloudProvider(nodeConfig.CloudService)
+	if err != nil {
+		report.add(monitoringCloudID, "instance", doctorWarn, err.Error())
+		return monitoringHosts
+	}
+	if !checkDoctorInstance(report, provider, nodeConfig, monitoringHosts[0]) {
+		return monitoringHosts
+	}
+	checkDoctorServices(report, monitoringHosts[0], "monitoring", monitoringServices)
+	return monitoringHosts
+}
+
+// checkDoctorServices checks that the given systemd services are active on the host
+func checkDoctorServices(report *doctorReport, host *models.Host, check string, services []string) {
+	if host.IsDocker() {
+		report.add(host.GetCloudID(), check, doctorSkip, "docker hosts run without systemd")
+		return
+	}
+	out, _ := host.Command(fmt.Sprintf("systemctl is-active %s", strings.Join(services, " ")), nil, constants.SSHScriptTimeout)
+	states := strings.Fields(string(out))
+	inactive := []string{}
+	for i, service := range services {
+		if i >= len(states) || states[i] != "active" {
+			inactive = append(inactive, service)
+		}
+	}
+	if len(inactive) > 0 {
+		report.add(host.GetCloudID(), check, doctorFail, fmt.Sprintf("%s not active", strings.Join(inactive, ",")))
+		return
+	}
+	report.add(host.GetCloudID(), check, doctorOK, "")
+}
+
+// getClusterSubnetIDs returns the IDs of the subnets the cluster nodes are expected to track
+func getClusterSubnetIDs(clusterConf models.ClusterConfig) ([]string, error) {
+	subnetIDs := []string{}
+	for _, subnetName := range clusterConf.Subnets {
+		sc, err := app.LoadSidecar(subnetName)
+		if err != nil {
+			return nil, err
+		}
+		if subnetID := sc.Networks[clusterConf.Network.Name()].SubnetID; subnetID != ids.Empty {
+			subnetIDs = append(subnetIDs, subnetID.String())
+		}
+	}
+	return subnetIDs, nil
+}
+
+// getTrackedSubnets reads the subnets tracked by avalanchego from the host node config
+func getTrackedSubnets(host *models.Host) ([]string, error) {
+	out, err := host.Command(fmt.Sprintf("cat %s", filepath.Join(constants.CloudNodeConfigPath, constants.NodeFileName)), nil, constants.SSHScriptTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read node config: %w", err)
+	}
+	nodeConfig := map[string]interface{}{}
+	if err := json.Unmarshal(out, &nodeConfig); err != nil {
+		return nil, fmt.Errorf("invalid node config: %w", err)
+	}
+	trackSubnets, _ := nodeConfig["track-subnets"].(string)
+	return utils.Filter(strings.Split(trackSubnets, ","), func(s string) bool { return s != "" }), nil
+}
+
+func parseNodeIDOutput(byteValue []byte) (string, error) {
+	reply := struct {
+		Result struct {
+			NodeID string `json:"nodeID"`
+		} `json:"result"`
+	}{}
+	if err := json.Unmarshal(byteValue, &reply); err != nil {
+		return "", err
+	}
+	return reply.Result.NodeID, nil
+}
+
+func printDoctorReport(clusterName string, report *doctorReport) {
+	sort.SliceStable(report.checks, func(i, j int) bool { return report.checks[i].host < report.checks[j].host })
+	ux.Logger.PrintToUser("")
+	tit := fmt.Sprintf("DOCTOR REPORT FOR CLUSTER: %s", logging.LightBlue.Wrap(clusterName))
+	ux.Logger.PrintToUser(tit)
+	ux.Logger.PrintToUser(strings.Repeat("=", len(removeColors(tit))))
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Host", "Check", "Status", "Detail"})
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	table.SetRowLine(true)
+	for _, check := range report.checks {
+		status := check.status
+		switch status {
+		case doctorOK:
+			status = logging.Green.Wrap(status)
+		case doctorWarn:
+			status = logging.Yellow.Wrap(status)
+		case doctorFail:
+			status = logging.Red.Wrap(status)
+		}
+		table.Append([]string{check.host, check.check, status, check.detail})
+	}
+	table.Render()
+	ux.Logger.PrintToUser("%d failure(s), %d warning(s)", report.count(doctorFail), report.count(doctorWarn))
+}
+
+// applyDoctorRepairs updates the cluster config, node configs and inventory as planned in the report
+func applyDoctorRepairs(clusterName string, report *doctorReport) error {
+	ux.Logger.PrintToUser("")
+	for _, nodeConfig := range report.nodeConfigs {
+		nodeConfig := nodeConfig
+		if err := app.CreateNodeCloudConfigFile(nodeConfig.NodeID, &nodeConfig); err != nil {
+			return err
+		}
+	}
+	inventoryPath := app.GetAnsibleInventoryDirPath(clusterName)
+	inventory, err := ansible.GetHostMapfromAnsibleInventory(inventoryPath)
+	if err != nil {
+		return err
+	}
+	maps.Copy(inventory, report.inventoryHosts)
+	for _, ansibleID := range report.dropInventory {
+		delete(inventory, ansibleID)
+	}
+	if len(report.removeHosts) > 0 && !authorizeRemove {
+		ux.Logger.PrintToUser("Nodes %s will be removed from cluster %s. Their local files, including staking keys, will be archived at %s",
+			strings.Join(report.removeHosts, ", "), clusterName, app.GetArchivedNodesDir())
+		yes, err := app.Prompt.CaptureYesNo("Do you want to remove them?")
+		if err != nil {
+			return err
+		}
+		if !yes {
+			ux.Logger.PrintToUser("Skipping removal of nodes %s", strings.Join(report.removeHosts, ", "))
+			report.repairs = utils.Filter(report.repairs, func(repair string) bool { return !strings.HasPrefix(repair, "remove ") })
+			report.removeHosts = nil
+		}
+	}
+	if len(report.removeHosts) > 0 {
+		clusterConf, err := app.GetClusterConfig(clusterName)
+		if err != nil {
+			return err
+		}
+		for _, cloudID := range report.removeHosts {
+			if cloudID == clusterConf.MonitoringInstance {
+				if err := os.RemoveAll(app.GetMonitoringInventoryDir(clusterName)); err != nil {
+					return err
+				}
+			}
+			clusterConf.RemoveHost(cloudID)
+			for ansibleID, host := range inventory {
+				if host.GetCloudID() == cloudID {
+					delete(inventory, ansibleID)
+				}
+			}
+			archivePath, err := archiveNodeDirectory(cloudID)
+			if err != nil {
+				return err
+			}
+			if archivePath != "" {
+				ux.Logger.PrintToUser("Archived local files of %s at %s", cloudID, archivePath)
+			}
+		}
+		if err := app.SetClusterConfig(clusterName, clusterConf); err != nil {
+			return err
+		}
+	}
+	hosts := maps.Values(inventory)
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].NodeID < hosts[j].NodeID })
+	if err := ansible.WriteInventory(inventoryPath, hosts); err != nil {
+		return err
+	}
+	for _, repair := range report.repairs {
+		ux.Logger.GreenCheckmarkToUser(repair)
+	}
+	return nil
+}
+
+// archiveNodeDirectory moves the node instance dir of [cloudID], holding its staking keys,
+// into the archived nodes dir. Returns the archive path, or an empty string if there was
+// no dir to archive
+func archiveNodeDirectory(cloudID string) (string, error) {
+	nodeDir := app.GetNodeInstanceDirPath(cloudID)
+	if !utils.DirectoryExists(nodeDir) {
+		return "", nil
+	}
+	if err := os.MkdirAll(app.GetArchivedNodesDir(), constants.DefaultPerms755); err != nil {
+		return "", err
+	}
+	archivePath := filepath.Join(app.GetArchivedNodesDir(), fmt.Sprintf("%s-%d", cloudID, time.Now().Unix()))
+	if err := os.Rename(nodeDir, archivePath); err != nil {
+		return "", fmt.Errorf("failed to archive %s: %w", nodeDir, err)
+	}
+	return archivePath, nil
+}
@@ -3,13 +3,8 @@
 package nodecmd
 
 import (
-	"context"
-	"fmt"
-
-	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
-	gcpAPI "github.com/ixAnkit/cryft/pkg/cloud/gcp"
-
 	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/cloud"
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/utils"
@@ -32,51 +27,30 @@ func getNodesWithDynamicIP(clusterNodes []string) ([]models.NodeConfig, error) {
 
 func getPublicIPsForNodesWithDynamicIP(nodesWithDynamicIP []models.NodeConfig) (map[string]string, error) {
 	publicIPMap := make(map[string]string)
-	var (
-		err        error
-		lastRegion string
-		ec2Svc     *awsAPI.AwsCloud
-		gcpCloud   *gcpAPI.GcpCloud
-	)
+	cloudProviders := map[string]cloud.Provider{}
 	ux.Logger.PrintToUser("Getting Public IP(s) for node(s) with dynamic IP ...")
 	for _, node := range nodesWithDynamicIP {
-		if lastRegion == "" || node.Region != lastRegion {
-			if node.CloudService == "" || node.CloudService == constants.AWSCloudService {
-				ec2Svc, err = awsAPI.NewAwsCloud(awsProfile, node.Region)
-				if err != nil {
+		provider, ok := cloudProviders[node.CloudService]
+		if !ok {
+			if node.CloudService == constants.GCPCloudService {
+				if err := checkCloudAccess(node.CloudService); err != nil {
 					return nil, err
 				}
 			}
-			lastRegion = node.Region
-		}
-		var publicIP map[string]string
-		if node.CloudService == constants.GCPCloudService {
-			if !(authorizeAccess || authorizedAccessFromSettings()) && (requestCloudAuth(constants.GCPCloudService) != nil) {
-				return nil, fmt.Errorf("cloud access is required")
-			}
-			if gcpCloud == nil {
-				gcpClient, projectName, _, err := getGCPCloudCredentials()
-				if err != nil {
-					return nil, err
-				}
-				gcpCloud, err = gcpAPI.NewGcpCloud(gcpClient, projectName, context.Background())
-				if err != nil {
-					return nil, err
-				}
-			}
-			publicIP, err = gcpCloud.GetInstancePublicIPs(node.Region, []string{node.NodeID})
+			var err error
+			provider, err = getCloudProvider(node.CloudService)
 			if err != nil {
 				return nil, err
 			}
-		} else {
-			publicIP, err = ec2Svc.GetInstancePublicIPs([]string{node.NodeID})
-			if err != nil {
-				if isExpiredCredentialError(err) {
-					ux.Logger.PrintToUser("")
-					printExpiredCredentialsOutput(awsProfile)
-				}
-				return nil, err
+			cloudProviders[node.CloudService] = provider
+		}
+		publicIP, err := provider.GetInstancePublicIPs(node.Region, []string{node.NodeID})
+		if err != nil {
+			if isExpiredCredentialError(err) {
+				ux.Logger.PrintToUser("")
+				printExpiredCredentialsOutput(awsProfile)
 			}
+			return nil, err
 		}
 		publicIPMap[node.NodeID] = publicIP[node.NodeID]
 	}
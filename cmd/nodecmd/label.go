@@ -0,0 +1,196 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	nodeSelector string
+	labelNodes   []string
+)
+
+func newLabelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "label [clusterName] [key=value|key-]...",
+		Short: "(ALPHA Warning) Set or show the labels of the nodes of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node label command sets arbitrary key=value labels on the nodes of a cluster,
+such as region, tier, team or canary, or removes them with key-. Without labels,
+it shows the labels of the nodes.
+
+The labels apply to all the nodes of the cluster, or to the ones given by --nodes
+or matching --selector.
+
+Labels are used to target a subset of a cluster with the --selector flag of the
+ssh, upgrade, status, sync, config, loadtest start and destroy commands. A selector
+is a comma separated list of requirements that must all hold: key=value,
+key!=value, key (the label is set) or !key (the label is not set), for example
+--selector tier=canary,region!=us-east-1`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE:         labelNodesCmd,
+	}
+	cmd.Flags().StringSliceVar(&labelNodes, "nodes", []string{}, "comma separated list of nodes (cloud ID, IP or NodeID) to label. defaults to all cluster nodes")
+	addSelectorFlag(cmd)
+	return cmd
+}
+
+func addSelectorFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&nodeSelector, "selector", "", "only target the nodes whose labels match the selector, e.g. tier=canary,region!=us-east-1")
+}
+
+// matchesNodeSelector tells if the labels of the node match --selector
+func matchesNodeSelector(cloudID string) (bool, error) {
+	if nodeSelector == "" {
+		return true, nil
+	}
+	selector, err := models.ParseLabelSelector(nodeSelector)
+	if err != nil {
+		return false, err
+	}
+	nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(nodeConfig.Labels), nil
+}
+
+// filterHostsBySelector returns the hosts whose labels match --selector
+func filterHostsBySelector(hosts []*models.Host) ([]*models.Host, error) {
+	if nodeSelector == "" {
+		return hosts, nil
+	}
+	filteredHosts := []*models.Host{}
+	for _, host := range hosts {
+		matches, err := matchesNodeSelector(host.GetCloudID())
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			filteredHosts = append(filteredHosts, host)
+		}
+	}
+	if len(filteredHosts) == 0 {
+		return nil, fmt.Errorf("no nodes match selector %q", nodeSelector)
+	}
+	return filteredHosts, nil
+}
+
+func labelNodesCmd(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	setLabels, removeLabels, err := models.ParseLabelChanges(args[1:])
+	if err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	cloudIDs, err := getLabelCloudIDs(clusterConf)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		for _, cloudID := range cloudIDs {
+			nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+			if err != nil {
+				return err
+			}
+			if nodeConfig.Labels == nil {
+				nodeConfig.Labels = map[string]string{}
+			}
+			for key, value := range setLabels {
+				nodeConfig.Labels[key] = value
+			}
+			for _, key := range removeLabels {
+				delete(nodeConfig.Labels, key)
+			}
+			if err := app.CreateNodeCloudConfigFile(cloudID, &nodeConfig); err != nil {
+				return err
+			}
+		}
+		ux.Logger.GreenCheckmarkToUser("Labels of %d node(s) of cluster %s updated", len(cloudIDs), clusterName)
+	}
+	return printNodeLabels(clusterConf, cloudIDs)
+}
+
+// getLabelCloudIDs returns the cloud IDs of the cluster nodes selected by --nodes and
+// --selector
+func getLabelCloudIDs(clusterConf models.ClusterConfig) ([]string, error) {
+	cloudIDs := []string{}
+	for _, cloudID := range clusterConf.GetCloudIDs() {
+		if len(labelNodes) > 0 {
+			nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+			if err != nil {
+				return nil, err
+			}
+			nodeIDStr := ""
+			if clusterConf.IsAvalancheGoHost(cloudID) {
+				nodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID))
+				if err != nil {
+					return nil, err
+				}
+				nodeIDStr = nodeID.String()
+			}
+			if !slices.ContainsFunc(labelNodes, func(node string) bool {
+				return node == cloudID || node == nodeConfig.ElasticIP || (nodeIDStr != "" && node == nodeIDStr)
+			}) {
+				continue
+			}
+		}
+		matches, err := matchesNodeSelector(cloudID)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			cloudIDs = append(cloudIDs, cloudID)
+		}
+	}
+	if len(cloudIDs) == 0 {
+		return nil, fmt.Errorf("no nodes selected")
+	}
+	return cloudIDs, nil
+}
+
+func printNodeLabels(clusterConf models.ClusterConfig, cloudIDs []string) error {
+	header := []string{"Cloud ID", "IP", "Roles", "Labels"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	for _, cloudID := range cloudIDs {
+		nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+		if err != nil {
+			return err
+		}
+		keys := maps.Keys(nodeConfig.Labels)
+		sort.Strings(keys)
+		labels := []string{}
+		for _, key := range keys {
+			labels = append(labels, fmt.Sprintf("%s=%s", key, nodeConfig.Labels[key]))
+		}
+		table.Append([]string{
+			cloudID,
+			nodeConfig.ElasticIP,
+			strings.Join(clusterConf.GetHostRoles(nodeConfig), ","),
+			strings.Join(labels, "\n"),
+		})
+	}
+	table.Render()
+	return nil
+}
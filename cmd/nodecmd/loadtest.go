@@ -14,7 +14,9 @@ func NewLoadTestCmd() *cobra.Command {
 		Short: "(ALPHA Warning) Load test suite for an existing subnet on an existing cloud cluster",
 		Long: `(ALPHA Warning) This command is currently in experimental mode. 
 
-The node loadtest command suite starts and stops a load test for an existing devnet cluster.`,
+The node loadtest command suite starts and stops a load test for an existing devnet cluster,
+or runs the built-in EVM load generator against a subnet. Load tests on clusters are recorded
+with the metrics of their time window, to be reported and compared.`,
 		Run: func(cmd *cobra.Command, _ []string) {
 			err := cmd.Help()
 			if err != nil {
@@ -26,5 +28,11 @@ The node loadtest command suite starts and stops a load test for an existing dev
 	cmd.AddCommand(newLoadTestStartCmd())
 	// node loadtest stop cluster
 	cmd.AddCommand(newLoadTestStopCmd())
+	// node loadtest run subnetName
+	cmd.AddCommand(newLoadTestRunCmd())
+	// node loadtest report clusterName loadtestName
+	cmd.AddCommand(newLoadTestReportCmd())
+	// node loadtest compare clusterName loadtestNameA loadtestNameB
+	cmd.AddCommand(newLoadTestCompareCmd())
 	return cmd
 }
@@ -0,0 +1,274 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/loadtest"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+const defaultRegressionThreshold = 5.0
+
+var (
+	loadTestReportFormat   string
+	loadTestReportOutput   string
+	loadTestReportRefresh  bool
+	loadTestRegressionRate float64
+	loadTestCompareCluster string
+)
+
+func newLoadTestReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report [clusterName] [loadtestName]",
+		Short: "(ALPHA Warning) Show the metrics collected for a load test",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node loadtest report command shows the record of a load test run on a cluster: the
+avalanchego and Subnet-EVM versions it ran against, its time window, the result of the built-in
+load generator if it was used, and the metrics collected from the cluster Prometheus for the
+window: TPS, block time and gas used of the chain, and CPU, memory and disk of each validator.
+
+The metrics are collected by node loadtest stop. For a running load test, or with --refresh,
+they are collected from the cluster Prometheus when the report is made.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE:         loadTestReport,
+	}
+	addLoadTestReportFlags(cmd)
+	cmd.Flags().BoolVar(&loadTestReportRefresh, "refresh", false, "collect again the metrics of the load test from the cluster Prometheus")
+	return cmd
+}
+
+func newLoadTestCompareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare [clusterName] [loadtestNameA] [loadtestNameB]",
+		Short: "(ALPHA Warning) Compare the metrics of two load tests",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node loadtest compare command compares the metrics recorded for two stopped load tests,
+for example before and after an avalanchego or Subnet-EVM upgrade, and shows the change of
+each metric from the first to the second one. Changes for the worse bigger than
+--regression-threshold percent are flagged as regressions.
+
+Both load tests are looked up in the given cluster, unless --cluster-b sets a different
+cluster for the second one.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(3),
+		RunE:         loadTestCompare,
+	}
+	addLoadTestReportFlags(cmd)
+	cmd.Flags().Float64Var(&loadTestRegressionRate, "regression-threshold", defaultRegressionThreshold, "percent of change for the worse flagged as a regression")
+	cmd.Flags().StringVar(&loadTestCompareCluster, "cluster-b", "", "cluster of loadtestNameB, if different from clusterName")
+	return cmd
+}
+
+func addLoadTestReportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&loadTestReportFormat, "format", loadtest.TextFormat, fmt.Sprintf("output format (%s)", strings.Join(loadtest.ReportFormats, ", ")))
+	cmd.Flags().StringVar(&loadTestReportOutput, "output", "", "write the output to the given file instead of stdout")
+}
+
+func writeLoadTestReport(report string) error {
+	if loadTestReportOutput == "" {
+		fmt.Print(report)
+		return nil
+	}
+	if err := os.WriteFile(loadTestReportOutput, []byte(report), constants.WriteReadReadPerms); err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Report saved to %s", loadTestReportOutput)
+	return nil
+}
+
+func loadTestReport(_ *cobra.Command, args []string) error {
+	run, err := loadLoadTestRecord(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	if run.Chain == nil || !run.Finished() || loadTestReportRefresh {
+		if err := collectLoadTestMetrics(run); err != nil {
+			ux.Logger.RedXToUser("Unable to collect load test %s metrics: %s", run.Name, err)
+		} else if run.Finished() {
+			if err := loadtest.SaveRecord(app.GetLoadTestRunPath(run.ClusterName, run.Name), run); err != nil {
+				return err
+			}
+		}
+	}
+	report, err := loadtest.FormatReport(run, loadTestReportFormat)
+	if err != nil {
+		return err
+	}
+	return writeLoadTestReport(report)
+}
+
+func loadTestCompare(_ *cobra.Command, args []string) error {
+	clusterNames := []string{args[0], args[0]}
+	if loadTestCompareCluster != "" {
+		clusterNames[1] = loadTestCompareCluster
+	}
+	runs := []*loadtest.Record{}
+	for i, loadTestName := range args[1:] {
+		run, err := loadLoadTestRecord(clusterNames[i], loadTestName)
+		if err != nil {
+			return err
+		}
+		if !run.Finished() {
+			return fmt.Errorf("load test %s is still running, please stop it first", loadTestName)
+		}
+		runs = append(runs, run)
+	}
+	report, err := loadtest.FormatComparison(runs[0], runs[1], loadTestReportFormat, loadTestRegressionRate)
+	if err != nil {
+		return err
+	}
+	return writeLoadTestReport(report)
+}
+
+// recordLoadTestStart saves the record of a load test that just started
+func recordLoadTestStart(loadTestName, clusterName, subnetName, blockchainID string) error {
+	run := &loadtest.Record{
+		Name:         loadTestName,
+		ClusterName:  clusterName,
+		SubnetName:   subnetName,
+		BlockchainID: blockchainID,
+		Start:        time.Now(),
+	}
+	if sc, err := app.LoadSidecar(subnetName); err == nil {
+		run.SubnetEVMVersion = sc.VMVersion
+	}
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	if version, err := getClusterAvalancheGoVersion(clusterName, hosts); err == nil {
+		run.AvalancheGoVersion = version
+	}
+	if err := os.MkdirAll(app.GetLoadTestRunsDir(clusterName), constants.DefaultPerms755); err != nil {
+		return err
+	}
+	return loadtest.SaveRecord(app.GetLoadTestRunPath(clusterName, loadTestName), run)
+}
+
+// loadLoadTestRecord loads the record of load test [loadTestName] of [clusterName]
+func loadLoadTestRecord(clusterName, loadTestName string) (*loadtest.Record, error) {
+	if err := migrateLoadTestRecord(clusterName, loadTestName); err != nil {
+		return nil, err
+	}
+	return loadtest.LoadRecord(app.GetLoadTestRunPath(clusterName, loadTestName))
+}
+
+// migrateLoadTestRecord moves the record of load test [loadTestName] of [clusterName], if it
+// was saved before the records were kept by cluster, to the records dir of the cluster
+func migrateLoadTestRecord(clusterName, loadTestName string) error {
+	legacyPath := app.GetLegacyLoadTestRunPath(loadTestName)
+	runPath := app.GetLoadTestRunPath(clusterName, loadTestName)
+	if !utils.FileExists(legacyPath) || utils.FileExists(runPath) {
+		return nil
+	}
+	run, err := loadtest.LoadRecord(legacyPath)
+	if err != nil {
+		return err
+	}
+	if run.ClusterName != clusterName {
+		return nil
+	}
+	if err := os.MkdirAll(app.GetLoadTestRunsDir(clusterName), constants.DefaultPerms755); err != nil {
+		return err
+	}
+	return os.Rename(legacyPath, runPath)
+}
+
+// finishLoadTestRun ends the record of a load test being stopped, adding the result of the
+// built-in load generator if it was downloaded and the metrics of the cluster
+func finishLoadTestRun(loadTestName, clusterName string) error {
+	if err := migrateLoadTestRecord(clusterName, loadTestName); err != nil {
+		return err
+	}
+	runPath := app.GetLoadTestRunPath(clusterName, loadTestName)
+	if !utils.FileExists(runPath) {
+		// started before load tests were recorded
+		return nil
+	}
+	run, err := loadtest.LoadRecord(runPath)
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+	run.End = &end
+	resultPath := filepath.Join(app.GetAnsibleInventoryDirPath(clusterName), getLoadTestReportFileName(loadTestName))
+	if utils.FileExists(resultPath) {
+		if run.Generator, err = loadtest.LoadResult(resultPath); err != nil {
+			return err
+		}
+	}
+	metricsErr := collectLoadTestMetrics(run)
+	if err := loadtest.SaveRecord(runPath, run); err != nil {
+		return err
+	}
+	return metricsErr
+}
+
+// collectLoadTestMetrics queries the cluster Prometheus for the metrics of the load test
+// window, up to now if it is still running
+func collectLoadTestMetrics(run *loadtest.Record) error {
+	monitoringHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetMonitoringInventoryDir(run.ClusterName))
+	if err != nil {
+		return err
+	}
+	if len(monitoringHosts) == 0 {
+		return fmt.Errorf("cluster %s has no monitoring host", run.ClusterName)
+	}
+	defer disconnectHosts(monitoringHosts)
+	end := time.Now()
+	if run.Finished() {
+		end = *run.End
+	}
+	window := end.Sub(run.Start)
+	values := map[string]map[string]float64{}
+	for _, query := range loadtest.MetricQueries(run.BlockchainID, window) {
+		resp, err := ssh.RunSSHQueryPrometheus(monitoringHosts[0], query.Query, end)
+		if err != nil {
+			return fmt.Errorf("failure querying prometheus for %s: %w", query.Name, err)
+		}
+		if values[query.Name], err = loadtest.ParsePrometheusVector(resp); err != nil {
+			return err
+		}
+	}
+	clusterConf, err := app.GetClusterConfig(run.ClusterName)
+	if err != nil {
+		return err
+	}
+	validators := []loadtest.NodeMetrics{}
+	for _, cloudID := range clusterConf.GetCloudIDs() {
+		nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(clusterConf.GetHostRoles(nodeConfig), constants.ValidatorRole) {
+			continue
+		}
+		validators = append(validators, loadtest.NodeMetrics{
+			CloudID:   cloudID,
+			IP:        nodeConfig.ElasticIP,
+			CPU:       values[loadtest.CPUMetric][nodeConfig.ElasticIP],
+			Memory:    values[loadtest.MemoryMetric][nodeConfig.ElasticIP],
+			Disk:      values[loadtest.DiskMetric][nodeConfig.ElasticIP],
+			DiskWrite: values[loadtest.DiskWriteMetric][nodeConfig.ElasticIP],
+		})
+	}
+	run.Chain = loadtest.NewChainMetrics(values, window)
+	run.Validators = validators
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/loadtest"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLoadTestRecord(t *testing.T) {
+	require := require.New(t)
+	app = application.New()
+	app.Setup(t.TempDir(), logging.NoLog{}, nil, nil, nil)
+	for _, clusterName := range []string{"clusterA", "clusterB"} {
+		require.NoError(os.MkdirAll(app.GetLoadTestRunsDir(clusterName), constants.DefaultPerms755))
+		require.NoError(loadtest.SaveRecord(app.GetLoadTestRunPath(clusterName, "lt"), &loadtest.Record{
+			Name:        "lt",
+			ClusterName: clusterName,
+			Start:       time.Now(),
+		}))
+	}
+	// records of the same name are kept apart by cluster
+	for _, clusterName := range []string{"clusterA", "clusterB"} {
+		run, err := loadLoadTestRecord(clusterName, "lt")
+		require.NoError(err)
+		require.Equal(clusterName, run.ClusterName)
+	}
+	_, err := loadLoadTestRecord("clusterC", "lt")
+	require.ErrorContains(err, "no load test record")
+
+	// records saved by name only are moved to the dir of their cluster
+	legacyPath := app.GetLegacyLoadTestRunPath("old")
+	require.NoError(loadtest.SaveRecord(legacyPath, &loadtest.Record{Name: "old", ClusterName: "clusterA"}))
+	_, err = loadLoadTestRecord("clusterB", "old")
+	require.ErrorContains(err, "no load test record")
+	require.True(utils.FileExists(legacyPath))
+	run, err := loadLoadTestRecord("clusterA", "old")
+	require.NoError(err)
+	require.Equal("old", run.Name)
+	require.False(utils.FileExists(legacyPath))
+	require.True(utils.FileExists(filepath.Join(app.GetLoadTestRunsDir("clusterA"), "old.json")))
+}
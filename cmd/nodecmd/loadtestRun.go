@@ -0,0 +1,241 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ixAnkit/cryft/cmd/flags"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/key"
+	"github.com/ixAnkit/cryft/pkg/loadtest"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/ixAnkit/cryft/pkg/vm"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	loadTestTxType         string
+	loadTestWorkers        int
+	loadTestTPS            int
+	loadTestDuration       time.Duration
+	loadTestConfirmTimeout time.Duration
+	loadTestLocal          bool
+	loadTestCluster        string
+	loadTestEndpoints      []string
+	loadTestKeyFile        string
+	loadTestOutput         string
+)
+
+func newLoadTestRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run [subnetName]",
+		Short: "(ALPHA Warning) Run the built-in EVM load generator against a subnet",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node loadtest run command runs the built-in EVM load generator from this machine. It funds
+--workers accounts from the subnet airdrop key (or from --key-file), and makes them send native
+transfers, ERC-20 transfers or contract calls (--tx-type) at --tps transactions per second for
+--duration. It then reports the accepted TPS and the confirmation latency percentiles.
+
+The transactions are sent to the subnet deployed on the local network (--local), to the API
+nodes of a cluster, or to its validators if it has no API nodes (--cluster, honoring --selector),
+or to the given RPC endpoints (--endpoint), in which case the subnet name is not needed.
+
+The load generator is run on a separate cloud server with node loadtest start --builtin.`,
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+		RunE:         runLoadTest,
+	}
+	cmd.Flags().BoolVar(&loadTestLocal, "local", false, "load test the subnet deployed on the local network")
+	cmd.Flags().StringVar(&loadTestCluster, "cluster", "", "load test the subnet deployed on the given cluster")
+	cmd.Flags().StringSliceVar(&loadTestEndpoints, "endpoint", []string{}, "load test the given RPC endpoints")
+	cmd.Flags().StringVar(&loadTestKeyFile, "key-file", "", "file with the private key funding the workers. defaults to the subnet airdrop key")
+	cmd.Flags().StringVar(&loadTestOutput, "output", "", "save the load test result as JSON to the given file")
+	addLoadTestGeneratorFlags(cmd)
+	addSelectorFlag(cmd)
+	return cmd
+}
+
+func addLoadTestGeneratorFlags(cmd *cobra.Command) {
+	txTypes := utils.Map(loadtest.TxTypes, func(txType loadtest.TxType) string { return string(txType) })
+	cmd.Flags().StringVar(&loadTestTxType, "tx-type", string(loadtest.NativeTransfer), fmt.Sprintf("type of the load test transactions (%s)", strings.Join(txTypes, ", ")))
+	cmd.Flags().IntVar(&loadTestWorkers, "workers", loadtest.DefaultWorkers, "number of funded accounts sending transactions")
+	cmd.Flags().IntVar(&loadTestTPS, "tps", loadtest.DefaultTPS, "target transactions per second")
+	cmd.Flags().DurationVar(&loadTestDuration, "duration", loadtest.DefaultDuration, "duration of the load test")
+	cmd.Flags().DurationVar(&loadTestConfirmTimeout, "confirm-timeout", loadtest.DefaultConfirmTimeout, "how long to wait for the transactions to be accepted once the load test ends")
+}
+
+func getLoadTestConfig() loadtest.Config {
+	return loadtest.Config{
+		TxType:         loadtest.TxType(loadTestTxType),
+		Workers:        loadTestWorkers,
+		TPS:            loadTestTPS,
+		Duration:       loadTestDuration,
+		ConfirmTimeout: loadTestConfirmTimeout,
+	}
+}
+
+func runLoadTest(_ *cobra.Command, args []string) error {
+	if !flags.EnsureMutuallyExclusive([]bool{loadTestLocal, loadTestCluster != "", len(loadTestEndpoints) > 0}) {
+		return fmt.Errorf("--local, --cluster and --endpoint are mutually exclusive")
+	}
+	config := getLoadTestConfig()
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	subnetName := ""
+	if len(args) > 0 {
+		subnetName = args[0]
+		if !app.SidecarExists(subnetName) {
+			return fmt.Errorf("subnet %s doesn't exist, please create it first", subnetName)
+		}
+	}
+	config.Endpoints = loadTestEndpoints
+	switch {
+	case len(config.Endpoints) > 0:
+	case subnetName == "":
+		return fmt.Errorf("a subnet name is required unless --endpoint is given")
+	case loadTestLocal:
+		blockchainID, err := getLocalBlockchainID(subnetName)
+		if err != nil {
+			return err
+		}
+		config.Endpoints = []string{models.NewLocalNetwork().BlockchainEndpoint(blockchainID)}
+	case loadTestCluster != "":
+		if err := checkCluster(loadTestCluster); err != nil {
+			return err
+		}
+		_, blockchainID, err := getDeployedSubnetInfo(loadTestCluster, subnetName)
+		if err != nil {
+			return err
+		}
+		if config.Endpoints, err = getClusterRPCEndpoints(loadTestCluster, blockchainID); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("one of --local, --cluster or --endpoint is required")
+	}
+	var err error
+	config.FundingKey, err = getLoadTestFundingKey(subnetName, loadTestKeyFile)
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Load testing %s", strings.Join(config.Endpoints, ", "))
+	result, err := loadtest.Run(config)
+	if err != nil {
+		return err
+	}
+	if loadTestOutput != "" {
+		if err := loadtest.WriteResult(loadTestOutput, result); err != nil {
+			return err
+		}
+		ux.Logger.PrintToUser("Load test result saved to %s", loadTestOutput)
+	}
+	printLoadTestResult(result)
+	return nil
+}
+
+func getLocalBlockchainID(subnetName string) (string, error) {
+	sc, err := app.LoadSidecar(subnetName)
+	if err != nil {
+		return "", err
+	}
+	if model, ok := sc.Networks[models.NewLocalNetwork().Name()]; ok && model.BlockchainID != ids.Empty {
+		return model.BlockchainID.String(), nil
+	}
+	return "", fmt.Errorf("subnet %s is not deployed on the local network, please call avalanche subnet deploy %s --local first", subnetName, subnetName)
+}
+
+// getClusterRPCEndpoints returns the RPC endpoints of [blockchainID] on the API nodes of the
+// cluster, or on its validators if it has no API nodes, matching --selector
+func getClusterRPCEndpoints(clusterName, blockchainID string) ([]string, error) {
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	apiEndpoints := []string{}
+	validatorEndpoints := []string{}
+	for _, cloudID := range clusterConf.GetCloudIDs() {
+		if !clusterConf.IsAvalancheGoHost(cloudID) {
+			continue
+		}
+		matches, err := matchesNodeSelector(cloudID)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			continue
+		}
+		nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+		if err != nil {
+			return nil, err
+		}
+		endpoint := fmt.Sprintf("http://%s:%s/ext/bc/%s/rpc", nodeConfig.ElasticIP, strconv.Itoa(constants.AvalanchegoAPIPort), blockchainID)
+		if slices.Contains(clusterConf.GetHostRoles(nodeConfig), constants.APIRole) {
+			apiEndpoints = append(apiEndpoints, endpoint)
+		} else {
+			validatorEndpoints = append(validatorEndpoints, endpoint)
+		}
+	}
+	if len(apiEndpoints) > 0 {
+		return apiEndpoints, nil
+	}
+	if len(validatorEndpoints) == 0 {
+		return nil, fmt.Errorf("no nodes of cluster %s to load test", clusterName)
+	}
+	return validatorEndpoints, nil
+}
+
+// getLoadTestFundingKey returns the hex encoded private key in [keyFile], or else the
+// airdrop key of the subnet, or else the prefunded ewoq key
+func getLoadTestFundingKey(subnetName, keyFile string) (string, error) {
+	if keyFile != "" {
+		k, err := key.LoadSoft(models.NewLocalNetwork().ID, utils.ExpandHome(keyFile))
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(k.Raw()), nil
+	}
+	if subnetName != "" {
+		_, _, privKey, err := subnet.GetSubnetAirdropKeyInfo(app, subnetName)
+		if err != nil {
+			return "", err
+		}
+		if privKey != "" {
+			return privKey, nil
+		}
+	}
+	ux.Logger.PrintToUser("Funding the load test workers with the prefunded ewoq key")
+	return vm.PrefundedEwoqPrivate, nil
+}
+
+func printLoadTestResult(result *loadtest.Result) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Tx Type", "Target TPS", "Sent", "Accepted", "Failed", "Unconfirmed", "Accepted TPS", "Latency p50", "Latency p90", "Latency p99", "Latency max"})
+	table.Append([]string{
+		string(result.TxType),
+		strconv.Itoa(result.TargetTPS),
+		strconv.Itoa(result.Sent),
+		strconv.Itoa(result.Accepted),
+		strconv.Itoa(result.Failed),
+		strconv.Itoa(result.Unconfirmed),
+		fmt.Sprintf("%.2f", result.AcceptedTPS),
+		fmt.Sprintf("%.0fms", result.Latency.P50),
+		fmt.Sprintf("%.0fms", result.Latency.P90),
+		fmt.Sprintf("%.0fms", result.Latency.P99),
+		fmt.Sprintf("%.0fms", result.Latency.Max),
+	})
+	table.Render()
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/ixAnkit/cryft/pkg/ssh"
 	"github.com/MetalBlockchain/metalgo/utils/logging"
@@ -15,7 +16,6 @@ import (
 	"github.com/ixAnkit/cryft/pkg/ansible"
 	"github.com/ixAnkit/cryft/pkg/application"
 	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
-	gcpAPI "github.com/ixAnkit/cryft/pkg/cloud/gcp"
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/prompts"
@@ -35,6 +35,7 @@ var (
 	repoDirName        string
 	loadTestHostRegion string
 	loadTestBranch     string
+	loadTestBuiltin    bool
 )
 
 type clusterInfo struct {
@@ -62,7 +63,13 @@ The node loadtest command starts load testing for an existing devnet cluster. If
 not have an existing load test host, the command creates a separate cloud server and builds the load 
 test binary based on the provided load test Git Repo URL and load test binary build command. 
 
-The command will then run the load test binary based on the provided load test run command.`,
+The command will then run the load test binary based on the provided load test run command.
+--selector only load tests the nodes whose labels match it.
+
+With --builtin, the load test host builds the CLI from source and runs its built-in EVM load
+generator against the API nodes of the cluster, or its validators if it has no API nodes,
+instead of building a load test repo (see node loadtest run). Its JSON result is downloaded,
+and its funding key removed from the host, by node loadtest stop.`,
 
 		SilenceUsage: true,
 		Args:         cobra.ExactArgs(3),
@@ -80,6 +87,11 @@ The command will then run the load test binary based on the provided load test r
 	cmd.Flags().StringVar(&loadTestCmd, "load-test-cmd", "", "command to run load test")
 	cmd.Flags().StringVar(&loadTestHostRegion, "region", "", "create load test node in a given region")
 	cmd.Flags().StringVar(&loadTestBranch, "load-test-branch", "", "load test branch or commit")
+	cmd.Flags().BoolVar(&loadTestBuiltin, "builtin", false, "run the built-in EVM load generator instead of a load test repo")
+	cmd.Flags().StringVar(&loadTestKeyFile, "key-file", "", "file with the private key funding the built-in load generator workers. defaults to the subnet airdrop key")
+	cmd.Flags().StringVar(&remoteCLIVersion, "remote-cli-version", "", "build given CLI branch or tag on the load test host for --builtin. defaults to "+constants.SetupCLIFromSourceBranch)
+	addLoadTestGeneratorFlags(cmd)
+	addSelectorFlag(cmd)
 	return cmd
 }
 
@@ -119,6 +131,12 @@ func startLoadTest(_ *cobra.Command, args []string) error {
 	if err := preLoadTestChecks(clusterName); err != nil {
 		return err
 	}
+	if loadTestBuiltin {
+		config := getLoadTestConfig()
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
 	loadTestExists, err := checkLoadTestExists(clusterName, loadTestName)
 	if err != nil {
 		return err
@@ -185,16 +203,13 @@ func startLoadTest(_ *cobra.Command, args []string) error {
 	}
 	switch cloudService {
 	case constants.AWSCloudService:
-		var ec2SvcMap map[string]*awsAPI.AwsCloud
-		var ami map[string]string
-		loadTestEc2SvcMap := make(map[string]*awsAPI.AwsCloud)
 		if existingSeparateInstance == "" {
-			ec2SvcMap, ami, _, err = getAWSCloudConfig(awsProfile, true, sgRegions, nodeType)
+			ec2SvcMap, ami, _, err := getAWSCloudConfig(awsProfile, true, sgRegions, nodeType)
 			if err != nil {
 				return err
 			}
 			separateHostRegion = loadTestHostRegion
-			loadTestEc2SvcMap[separateHostRegion] = ec2SvcMap[separateHostRegion]
+			loadTestEc2SvcMap := map[string]*awsAPI.AwsCloud{separateHostRegion: ec2SvcMap[separateHostRegion]}
 			loadTestCloudConfig, err = createAWSInstances(loadTestEc2SvcMap, nodeType, map[string]NumNodes{separateHostRegion: {1, 0}}, []string{separateHostRegion}, ami, true)
 			if err != nil {
 				return err
@@ -205,34 +220,11 @@ func startLoadTest(_ *cobra.Command, args []string) error {
 			if err != nil {
 				return err
 			}
-			loadTestEc2SvcMap, err = getAWSMonitoringEC2Svc(awsProfile, separateHostRegion)
-			if err != nil {
-				return err
-			}
-		}
-		if !useStaticIP {
-			// get loadtest public
-			loadTestPublicIPMap, err := loadTestEc2SvcMap[separateHostRegion].GetInstancePublicIPs(loadTestNodeConfig.InstanceIDs)
-			if err != nil {
-				return err
-			}
-			loadTestNodeConfig.PublicIPs = []string{loadTestPublicIPMap[loadTestNodeConfig.InstanceIDs[0]]}
-		}
-		if existingSeparateInstance == "" {
-			for _, sg := range filteredSGList {
-				if err = grantAccessToPublicIPViaSecurityGroup(ec2SvcMap[sg.region], loadTestNodeConfig.PublicIPs[0], sg.securityGroup, sg.region); err != nil {
-					return err
-				}
-			}
 		}
 	case constants.GCPCloudService:
-		var gcpClient *gcpAPI.GcpCloud
-		var gcpRegions map[string]NumNodes
-		var imageID string
-		var projectName string
 		if existingSeparateInstance == "" {
 			// Get GCP Credential, zone, Image ID, service account key file path, and GCP project name
-			gcpClient, gcpRegions, imageID, _, projectName, err = getGCPConfig(true)
+			gcpClient, gcpRegions, imageID, _, _, err := getGCPConfig(true)
 			if err != nil {
 				return err
 			}
@@ -244,29 +236,31 @@ func startLoadTest(_ *cobra.Command, args []string) error {
 			}
 			loadTestNodeConfig = loadTestCloudConfig[separateHostRegion]
 		} else {
-			_, projectName, _, err = getGCPCloudCredentials()
-			if err != nil {
-				return err
-			}
 			loadTestNodeConfig, separateHostRegion, err = getNodeCloudConfig(existingSeparateInstance)
 			if err != nil {
 				return err
 			}
 		}
-		if !useStaticIP {
-			loadTestPublicIPMap, err := gcpClient.GetInstancePublicIPs(separateHostRegion, loadTestNodeConfig.InstanceIDs)
-			if err != nil {
-				return err
-			}
-			loadTestNodeConfig.PublicIPs = []string{loadTestPublicIPMap[loadTestNodeConfig.InstanceIDs[0]]}
+	default:
+		return fmt.Errorf("cloud service %s is not supported", cloudService)
+	}
+	provider, err := getCloudProvider(cloudService)
+	if err != nil {
+		return err
+	}
+	if !useStaticIP {
+		loadTestPublicIPMap, err := provider.GetInstancePublicIPs(separateHostRegion, loadTestNodeConfig.InstanceIDs)
+		if err != nil {
+			return err
 		}
-		if existingSeparateInstance == "" {
-			if err = grantAccessToPublicIPViaFirewall(gcpClient, projectName, loadTestNodeConfig.PublicIPs[0], "loadtest"); err != nil {
+		loadTestNodeConfig.PublicIPs = []string{loadTestPublicIPMap[loadTestNodeConfig.InstanceIDs[0]]}
+	}
+	if existingSeparateInstance == "" {
+		for _, sg := range filteredSGList {
+			if err = provider.AddFirewallRule(sg.region, sg.securityGroup, loadTestNodeConfig.PublicIPs[0], separateHostPorts); err != nil {
 				return err
 			}
 		}
-	default:
-		return fmt.Errorf("cloud service %s is not supported", cloudService)
 	}
 	if existingSeparateInstance == "" {
 		if err := saveExternalHostConfig(loadTestNodeConfig, separateHostRegion, cloudService, clusterName, constants.LoadTestRole, loadTestName); err != nil {
@@ -293,8 +287,10 @@ func startLoadTest(_ *cobra.Command, args []string) error {
 			currentLoadTestHost = append(currentLoadTestHost, host)
 		}
 	}
-	if err := GetLoadTestScript(app); err != nil {
-		return err
+	if !loadTestBuiltin {
+		if err := GetLoadTestScript(app); err != nil {
+			return err
+		}
 	}
 
 	// waiting for all nodes to become accessible
@@ -324,11 +320,11 @@ func startLoadTest(_ *cobra.Command, args []string) error {
 		if err := ssh.RunSSHUpdatePromtailConfig(currentLoadTestHost[0], monitoringHosts[0].IP, constants.AvalanchegoLokiPort, currentLoadTestHost[0].GetCloudID(), "NodeID-Loadtest"); err != nil {
 			return err
 		}
-		avalancheGoPorts, machinePorts, ltPorts, err := getPrometheusTargets(clusterName)
+		avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err := getPrometheusTargets(clusterName)
 		if err != nil {
 			return err
 		}
-		if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHosts[0], avalancheGoPorts, machinePorts, ltPorts); err != nil {
+		if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHosts[0], avalancheGoPorts, machinePorts, ltPorts, relayerPorts); err != nil {
 			return err
 		}
 	}
@@ -345,6 +341,12 @@ func startLoadTest(_ *cobra.Command, args []string) error {
 	if err := ssh.RunSSHCopyYAMLFile(currentLoadTestHost[0], app.GetClusterYAMLFilePath(clusterName)); err != nil {
 		return err
 	}
+	if loadTestBuiltin {
+		if err := startBuiltinLoadTest(currentLoadTestHost[0], clusterName, chainID, loadTestName); err != nil {
+			return err
+		}
+		return recordLoadTestStartOrWarn(loadTestName, clusterName, chainID)
+	}
 	checkoutCommit := false
 	if loadTestRepoCommit != "" {
 		checkoutCommit = true
@@ -361,9 +363,64 @@ func startLoadTest(_ *cobra.Command, args []string) error {
 		return err
 	}
 	ux.Logger.PrintToUser("Load test successfully run!")
+	return recordLoadTestStartOrWarn(loadTestName, clusterName, chainID)
+}
+
+// recordLoadTestStartOrWarn records the start of the load test, warning on failure as
+// the load test is already running
+func recordLoadTestStartOrWarn(loadTestName, clusterName, chainID string) error {
+	if err := recordLoadTestStart(loadTestName, clusterName, subnetName, chainID); err != nil {
+		ux.Logger.RedXToUser("Unable to record load test %s: %s", loadTestName, err)
+	}
+	return nil
+}
+
+// startBuiltinLoadTest builds the CLI on the load test host and runs its built-in
+// load generator against the cluster nodes in the background
+func startBuiltinLoadTest(host *models.Host, clusterName, chainID, loadTestName string) error {
+	endpoints, err := getClusterRPCEndpoints(clusterName, chainID)
+	if err != nil {
+		return err
+	}
+	fundingKey, err := getLoadTestFundingKey(subnetName, loadTestKeyFile)
+	if err != nil {
+		return err
+	}
+	cliBranch := remoteCLIVersion
+	if cliBranch == "" {
+		cliBranch = constants.SetupCLIFromSourceBranch
+	}
+	if err := ssh.RunSSHSetupLoadTestCLI(host, cliBranch); err != nil {
+		return err
+	}
+	remoteKeyPath, err := ssh.RunSSHUploadLoadTestKey(host, loadTestName, fundingKey)
+	if err != nil {
+		return err
+	}
+	ux.Logger.GreenCheckmarkToUser("Load test environment is ready!")
+	loadTestCmd = fmt.Sprintf(
+		"/home/ubuntu/bin/metal node loadtest run --endpoint %s --key-file %s --tx-type %s --workers %d --tps %d --duration %s --confirm-timeout %s --output /home/ubuntu/%s",
+		strings.Join(endpoints, ","),
+		remoteKeyPath,
+		loadTestTxType,
+		loadTestWorkers,
+		loadTestTPS,
+		loadTestDuration,
+		loadTestConfirmTimeout,
+		getLoadTestReportFileName(loadTestName),
+	)
+	ux.Logger.PrintToUser("%s Running built-in load test", logging.Green.Wrap(">"))
+	if err := ssh.RunSSHRunLoadTest(host, loadTestCmd, loadTestName); err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Load test successfully started! Its result will be downloaded by node loadtest stop")
 	return nil
 }
 
+func getLoadTestReportFileName(loadTestName string) string {
+	return fmt.Sprintf("loadtest_%s.json", loadTestName)
+}
+
 func getDeployedSubnetInfo(clusterName string, subnetName string) (string, string, error) {
 	sc, err := app.LoadSidecar(subnetName)
 	if err != nil {
@@ -427,6 +484,16 @@ func createClusterYAMLFile(clusterName, subnetID, chainID string, separateHost *
 		if len(roles) == 0 {
 			return fmt.Errorf("incorrect node config file at %s", app.GetNodeConfigPath(cloudID))
 		}
+		// only the nodes matching the selector are load tested
+		if roles[0] != constants.MonitorRole {
+			matches, err := matchesNodeSelector(cloudID)
+			if err != nil {
+				return err
+			}
+			if !matches {
+				continue
+			}
+		}
 		switch roles[0] {
 		case constants.ValidatorRole:
 			validatorNode := nodeInfo{
@@ -452,6 +519,9 @@ func createClusterYAMLFile(clusterName, subnetID, chainID string, separateHost *
 		default:
 		}
 	}
+	if len(validatorNodes)+len(apiNodes) == 0 {
+		return fmt.Errorf("no nodes match selector %q", nodeSelector)
+	}
 	var separateHostInfo nodeInfo
 	if separateHost != nil {
 		_, separateHostRegion, err := getNodeCloudConfig(separateHost.GetCloudID())
@@ -15,8 +15,7 @@ import (
 	"github.com/ixAnkit/cryft/pkg/ansible"
 	"github.com/ixAnkit/cryft/pkg/ssh"
 
-	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
-	gcpAPI "github.com/ixAnkit/cryft/pkg/cloud/gcp"
+	"github.com/ixAnkit/cryft/pkg/cloud"
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/ux"
@@ -32,7 +31,8 @@ func newLoadTestStopCmd() *cobra.Command {
 		Long: `(ALPHA Warning) This command is currently in experimental mode. 
 
 The node loadtest stop command stops load testing for an existing devnet cluster and terminates the 
-separate cloud server created to host the load test.`,
+separate cloud server created to host the load test. The metrics of the load test window are
+collected from the cluster Prometheus into the load test record, see node loadtest report.`,
 
 		SilenceUsage: true,
 		Args:         cobra.ExactArgs(1),
@@ -117,15 +117,9 @@ func stopLoadTest(_ *cobra.Command, args []string) error {
 	if len(filteredSGList) == 0 {
 		return fmt.Errorf("no hosts with cloud service %s found in cluster %s", nodeToStopConfig.CloudService, clusterName)
 	}
-	ec2SvcMap := make(map[string]*awsAPI.AwsCloud)
-	for _, sg := range filteredSGList {
-		sgEc2Svc, err := awsAPI.NewAwsCloud(awsProfile, sg.region)
-		if err != nil {
-			return err
-		}
-		if _, ok := ec2SvcMap[sg.region]; !ok {
-			ec2SvcMap[sg.region] = sgEc2Svc
-		}
+	provider, err := getCloudProvider(nodeToStopConfig.CloudService)
+	if err != nil {
+		return err
 	}
 	for _, loadTestName := range loadTestsToStop {
 		existingSeparateInstance, err = getExistingLoadTestInstance(clusterName, loadTestName)
@@ -149,37 +143,35 @@ func stopLoadTest(_ *cobra.Command, args []string) error {
 		if err = ssh.RunSSHDownloadFile(host, fmt.Sprintf("/home/ubuntu/%s", loadTestResultFileName), filepath.Join(app.GetAnsibleInventoryDirPath(clusterName), loadTestResultFileName)); err != nil {
 			ux.Logger.RedXToUser("Unable to download load test result %s to local machine due to %s", loadTestResultFileName, err.Error())
 		}
-		switch nodeConfig.CloudService {
-		case constants.AWSCloudService:
-			loadTestNodeConfig, separateHostRegion, err := getNodeCloudConfig(existingSeparateInstance)
-			if err != nil {
-				return err
-			}
-			loadTestEc2SvcMap, err := getAWSMonitoringEC2Svc(awsProfile, separateHostRegion)
-			if err != nil {
-				return err
-			}
-			if err = destroyNode(existingSeparateInstance, clusterName, loadTestName, loadTestEc2SvcMap[separateHostRegion], nil); err != nil {
-				return err
-			}
-			for _, sg := range filteredSGList {
-				if err = deleteHostSecurityGroupRule(ec2SvcMap[sg.region], loadTestNodeConfig.PublicIPs[0], sg.securityGroup); err != nil {
-					ux.Logger.RedXToUser("unable to delete IP address %s from security group %s in region %s due to %s, please delete it manually",
-						loadTestNodeConfig.PublicIPs[0], sg.securityGroup, sg.region, err.Error())
-				}
-			}
-		case constants.GCPCloudService:
-			var gcpClient *gcpAPI.GcpCloud
-			gcpClient, _, _, _, _, err = getGCPConfig(true)
-			if err != nil {
-				return err
-			}
-			if err = destroyNode(existingSeparateInstance, clusterName, loadTestName, nil, gcpClient); err != nil {
-				return err
+		// the result of the built-in load generator, if it was used and has finished
+		loadTestReportFileName := getLoadTestReportFileName(loadTestName)
+		if _, err := host.Command(fmt.Sprintf("test -f /home/ubuntu/%s", loadTestReportFileName), nil, constants.SSHScriptTimeout); err == nil {
+			if err = ssh.RunSSHDownloadFile(host, fmt.Sprintf("/home/ubuntu/%s", loadTestReportFileName), filepath.Join(app.GetAnsibleInventoryDirPath(clusterName), loadTestReportFileName)); err != nil {
+				ux.Logger.RedXToUser("Unable to download load test result %s to local machine due to %s", loadTestReportFileName, err.Error())
 			}
-		default:
+		}
+		if err := ssh.RunSSHRemoveLoadTestKey(host, loadTestName); err != nil {
+			ux.Logger.RedXToUser("Unable to remove load test %s funding key from %s due to %s, please delete it manually", loadTestName, host.GetCloudID(), err.Error())
+		}
+		if err := finishLoadTestRun(loadTestName, clusterName); err != nil {
+			ux.Logger.RedXToUser("Unable to collect load test %s metrics due to %s", loadTestName, err.Error())
+		}
+		if nodeConfig.CloudService != constants.AWSCloudService && nodeConfig.CloudService != constants.GCPCloudService {
 			return fmt.Errorf("cloud service %s is not supported", nodeConfig.CloudService)
 		}
+		loadTestNodeConfig, _, err := getNodeCloudConfig(existingSeparateInstance)
+		if err != nil {
+			return err
+		}
+		if err = destroyNode(provider, existingSeparateInstance, clusterName, loadTestName); err != nil {
+			return err
+		}
+		for _, sg := range filteredSGList {
+			if err = provider.RemoveFirewallRule(sg.region, sg.securityGroup, loadTestNodeConfig.PublicIPs[0], separateHostPorts); err != nil {
+				ux.Logger.RedXToUser("unable to delete IP address %s from security group %s in region %s due to %s, please delete it manually",
+					loadTestNodeConfig.PublicIPs[0], sg.securityGroup, sg.region, err.Error())
+			}
+		}
 		removedLoadTestHosts = append(removedLoadTestHosts, host)
 	}
 	return updateLoadTestInventory(separateHosts, removedLoadTestHosts, clusterName, separateHostInventoryPath)
@@ -210,37 +202,25 @@ func updateLoadTestInventory(separateHosts, removedLoadTestHosts []*models.Host,
 	return nil
 }
 
-func destroyNode(node, clusterName, loadTestName string, ec2Svc *awsAPI.AwsCloud, gcpClient *gcpAPI.GcpCloud) error {
+func destroyNode(provider cloud.Provider, node, clusterName, loadTestName string) error {
 	nodeConfig, err := app.LoadClusterNodeConfig(node)
 	if err != nil {
 		ux.Logger.RedXToUser("Failed to destroy node %s", node)
 		return err
 	}
-	if nodeConfig.CloudService == "" || nodeConfig.CloudService == constants.AWSCloudService {
-		if !(authorizeAccess || authorizedAccessFromSettings()) && (requestCloudAuth(constants.AWSCloudService) != nil) {
-			return fmt.Errorf("cloud access is required")
-		}
-		if err = ec2Svc.DestroyAWSNode(nodeConfig, ""); err != nil {
-			if isExpiredCredentialError(err) {
-				ux.Logger.PrintToUser("")
-				printExpiredCredentialsOutput(awsProfile)
-				return nil
-			}
-			if !errors.Is(err, awsAPI.ErrNodeNotFoundToBeRunning) {
-				return err
-			}
-			ux.Logger.PrintToUser("node %s is already destroyed", nodeConfig.NodeID)
-		}
-	} else {
-		if !(authorizeAccess || authorizedAccessFromSettings()) && (requestCloudAuth(constants.GCPCloudService) != nil) {
-			return fmt.Errorf("cloud access is required")
+	if !(authorizeAccess || authorizedAccessFromSettings()) && (requestCloudAuth(provider.Name()) != nil) {
+		return fmt.Errorf("cloud access is required")
+	}
+	if err = provider.DestroyNode(nodeConfig, ""); err != nil {
+		if isExpiredCredentialError(err) {
+			ux.Logger.PrintToUser("")
+			printExpiredCredentialsOutput(awsProfile)
+			return nil
 		}
-		if err = gcpClient.DestroyGCPNode(nodeConfig, ""); err != nil {
-			if !errors.Is(err, gcpAPI.ErrNodeNotFoundToBeRunning) {
-				return err
-			}
-			ux.Logger.PrintToUser("node %s is already destroyed", nodeConfig.NodeID)
+		if !errors.Is(err, cloud.ErrNodeNotFoundToBeRunning) {
+			return err
 		}
+		ux.Logger.PrintToUser("node %s is already destroyed", nodeConfig.NodeID)
 	}
 	ux.Logger.GreenCheckmarkToUser("Node instance %s successfully destroyed!", nodeConfig.NodeID)
 	if err := removeDeletedNodeDirectory(node); err != nil {
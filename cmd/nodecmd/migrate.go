@@ -0,0 +1,260 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/spf13/cobra"
+)
+
+const (
+	migrateNodeIDTimeout  = 3 * time.Minute
+	migrateNodeIDPoolTime = 10 * time.Second
+)
+
+var (
+	migrateFrom string
+	migrateTo   string
+)
+
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate [clusterName]",
+		Short: "(ALPHA Warning) Move the identity of a node to another node of the cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node migrate command moves the staking files (staker.crt, staker.key and
+signer.key) of the --from node to the --to node, so the validator keeps its NodeID
+while running on a different machine.
+
+The staking files of the source are backed up locally, the source is stopped and its
+staking files are moved away on the host before the target is started, so two hosts
+never run the same identity at once. The target takes the role of the source in the
+cluster and the source is detached from the cluster. If the target fails to start with
+the migrated identity, it is stopped and the source is restored.
+
+Nodes can be given by cloud ID, IP or NodeID.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         migrate,
+	}
+	cmd.Flags().StringVar(&migrateFrom, "from", "", "node to take the identity from")
+	cmd.Flags().StringVar(&migrateTo, "to", "", "node to move the identity to")
+	return cmd
+}
+
+func migrate(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if migrateFrom == "" || migrateTo == "" {
+		return fmt.Errorf("both --from and --to must be given")
+	}
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	inventoryPath := app.GetAnsibleInventoryDirPath(clusterName)
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(inventoryPath)
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	fromHost, err := getMigrateHost(hosts, migrateFrom)
+	if err != nil {
+		return err
+	}
+	toHost, err := getMigrateHost(hosts, migrateTo)
+	if err != nil {
+		return err
+	}
+	fromCloudID := fromHost.GetCloudID()
+	toCloudID := toHost.GetCloudID()
+	if fromCloudID == toCloudID {
+		return fmt.Errorf("--from and --to refer to the same node %s", fromCloudID)
+	}
+	fromNodeID, err := getNodeID(app.GetNodeInstanceDirPath(fromCloudID))
+	if err != nil {
+		return err
+	}
+	// the previous identity of the target is lost, so it should not be validating
+	if toNodeID, err := getNodeID(app.GetNodeInstanceDirPath(toCloudID)); err == nil {
+		isValidator, err := checkNodeIsPrimaryNetworkValidator(toNodeID, clusterConf.Network)
+		if err != nil {
+			return err
+		}
+		if isValidator {
+			return fmt.Errorf("target node %s is a Primary Network validator with NodeID %s, migrating would replace its identity", toCloudID, toNodeID)
+		}
+	}
+
+	ux.Logger.PrintToUser("Migrating NodeID %s from %s to %s", logging.LightBlue.Wrap(fromNodeID.String()), fromCloudID, toCloudID)
+	timestamp := time.Now().Unix()
+	backupDir := filepath.Join(app.GetNodeInstanceDirPath(fromCloudID), fmt.Sprintf("staking-backup-%d", timestamp))
+	spinSession := ux.NewUserSpinner()
+	defer spinSession.Stop()
+
+	spinner := spinSession.SpinToUser(utils.ScriptLog(fromCloudID, "Backing up staking files"))
+	if err := ssh.RunSSHDownloadStakingFiles(fromHost, backupDir); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if err := checkStakingBackup(backupDir, fromNodeID); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	ux.SpinComplete(spinner)
+
+	spinner = spinSession.SpinToUser(utils.ScriptLog(fromCloudID, "Stopping node and retiring staking files"))
+	if err := ssh.RunSSHStopNode(fromHost); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if err := ssh.RunSSHRetireStakingFiles(fromHost); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if _, err := ssh.RunSSHCheckAvalancheGoVersion(fromHost); err == nil {
+		err := fmt.Errorf("avalanchego is still running on %s", fromCloudID)
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	ux.SpinComplete(spinner)
+
+	spinner = spinSession.SpinToUser(utils.ScriptLog(toCloudID, "Uploading staking files and restarting node"))
+	if err := startMigratedIdentity(toHost, backupDir, fromNodeID); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		ux.Logger.PrintToUser("Restoring the identity on %s...", fromCloudID)
+		if restoreErr := restoreMigrationSource(fromHost, toHost, backupDir); restoreErr != nil {
+			return fmt.Errorf("%w, and failed to restore source node: %w. Staking files are backed up at %s", err, restoreErr, backupDir)
+		}
+		return err
+	}
+	ux.SpinComplete(spinner)
+	spinSession.Stop()
+
+	if err := moveLocalStakingFiles(app.GetNodeInstanceDirPath(toCloudID), backupDir, timestamp); err != nil {
+		return err
+	}
+	if clusterConf.IsAPIHost(fromCloudID) && !clusterConf.IsAPIHost(toCloudID) {
+		clusterConf.APINodes = append(clusterConf.APINodes, toCloudID)
+	}
+	if !clusterConf.IsAPIHost(fromCloudID) {
+		clusterConf.APINodes = utils.Filter(clusterConf.APINodes, func(s string) bool { return s != toCloudID })
+	}
+	clusterConf.RemoveHost(fromCloudID)
+	if err := app.SetClusterConfig(clusterName, clusterConf); err != nil {
+		return err
+	}
+	if err := ansible.WriteInventory(inventoryPath, utils.Filter(hosts, func(h *models.Host) bool { return h.GetCloudID() != fromCloudID })); err != nil {
+		return err
+	}
+	ux.Logger.GreenCheckmarkToUser("NodeID %s is now running on %s", fromNodeID, toCloudID)
+	ux.Logger.PrintToUser("Node %s has been detached from cluster %s. Staking files are backed up at %s", fromCloudID, clusterName, backupDir)
+	return nil
+}
+
+func getMigrateHost(hosts []*models.Host, node string) (*models.Host, error) {
+	filteredHosts, err := filterHosts(hosts, []string{node})
+	if err != nil {
+		return nil, err
+	}
+	if len(filteredHosts) != 1 {
+		return nil, fmt.Errorf("node %s matches %d nodes of the cluster", node, len(filteredHosts))
+	}
+	return filteredHosts[0], nil
+}
+
+// checkStakingBackup returns an error if the staking files backed up at [backupDir] are not
+// the ones of [nodeID], so no identity other than the expected one is migrated
+func checkStakingBackup(backupDir string, nodeID ids.NodeID) error {
+	backupNodeID, err := getNodeID(backupDir)
+	if err != nil {
+		return err
+	}
+	if backupNodeID != nodeID {
+		return fmt.Errorf("staking files on host are for NodeID %s, expected %s", backupNodeID, nodeID)
+	}
+	return nil
+}
+
+// startMigratedIdentity uploads the staking files at [stakingDir] to the host, restarts it
+// and waits until it reports [nodeID]. The host is stopped if it doesn't
+func startMigratedIdentity(host *models.Host, stakingDir string, nodeID ids.NodeID) error {
+	if err := ssh.RunSSHStopNode(host); err != nil {
+		return err
+	}
+	if err := ssh.RunSSHUploadStakingFiles(host, stakingDir); err != nil {
+		return err
+	}
+	if err := ssh.RunSSHStartNode(host); err != nil {
+		return err
+	}
+	startTime := time.Now()
+	for {
+		resp, err := ssh.RunSSHGetNodeID(host)
+		if err == nil {
+			remoteNodeID, err := parseNodeIDOutput(resp)
+			if err == nil && remoteNodeID == nodeID.String() {
+				return nil
+			}
+			if err == nil && remoteNodeID != "" {
+				_ = ssh.RunSSHStopNode(host)
+				return fmt.Errorf("node %s reports NodeID %s, expected %s", host.GetCloudID(), remoteNodeID, nodeID)
+			}
+		}
+		if time.Since(startTime) > migrateNodeIDTimeout {
+			_ = ssh.RunSSHStopNode(host)
+			return fmt.Errorf("node %s did not report NodeID %s after %d seconds", host.GetCloudID(), nodeID, uint32(migrateNodeIDTimeout.Seconds()))
+		}
+		time.Sleep(migrateNodeIDPoolTime)
+	}
+}
+
+// restoreMigrationSource puts the identity back on the source host after a failed migration,
+// making sure the target is not running it
+func restoreMigrationSource(fromHost *models.Host, toHost *models.Host, stakingDir string) error {
+	if err := ssh.RunSSHStopNode(toHost); err != nil {
+		return err
+	}
+	if _, err := ssh.RunSSHCheckAvalancheGoVersion(toHost); err == nil {
+		return fmt.Errorf("avalanchego is still running on %s", toHost.GetCloudID())
+	}
+	if err := ssh.RunSSHUploadStakingFiles(fromHost, stakingDir); err != nil {
+		return err
+	}
+	return ssh.RunSSHStartNode(fromHost)
+}
+
+// moveLocalStakingFiles replaces the staking files at [nodeInstanceDirPath] with the ones at [stakingDir],
+// keeping a backup of the replaced files
+func moveLocalStakingFiles(nodeInstanceDirPath string, stakingDir string, timestamp int64) error {
+	backupDir := filepath.Join(nodeInstanceDirPath, fmt.Sprintf("staking-backup-%d", timestamp))
+	if err := os.MkdirAll(backupDir, constants.DefaultPerms755); err != nil {
+		return err
+	}
+	for _, fileName := range []string{constants.StakerCertFileName, constants.StakerKeyFileName, constants.BLSKeyFileName} {
+		if utils.FileExists(filepath.Join(nodeInstanceDirPath, fileName)) {
+			if err := os.Rename(filepath.Join(nodeInstanceDirPath, fileName), filepath.Join(backupDir, fileName)); err != nil {
+				return err
+			}
+		}
+		if err := utils.FileCopy(filepath.Join(stakingDir, fileName), filepath.Join(nodeInstanceDirPath, fileName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
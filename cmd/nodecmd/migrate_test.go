@@ -0,0 +1,114 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestStakingFiles(t *testing.T, dir string) ids.NodeID {
+	nodeID, err := generateNodeCertAndKeys(
+		filepath.Join(dir, constants.StakerCertFileName),
+		filepath.Join(dir, constants.StakerKeyFileName),
+		filepath.Join(dir, constants.BLSKeyFileName),
+	)
+	require.NoError(t, err)
+	return nodeID
+}
+
+func TestMoveLocalStakingFiles(t *testing.T) {
+	t.Run("existing files are backed up", func(t *testing.T) {
+		require := require.New(t)
+		nodeDir, stakingDir := t.TempDir(), t.TempDir()
+		oldNodeID := generateTestStakingFiles(t, nodeDir)
+		newNodeID := generateTestStakingFiles(t, stakingDir)
+		require.NoError(moveLocalStakingFiles(nodeDir, stakingDir, 42))
+		nodeID, err := getNodeID(nodeDir)
+		require.NoError(err)
+		require.Equal(newNodeID, nodeID)
+		// the previous identity can be restored from the backup
+		backupDir := filepath.Join(nodeDir, "staking-backup-42")
+		require.True(utils.FileExists(filepath.Join(backupDir, constants.BLSKeyFileName)))
+		backupNodeID, err := getNodeID(backupDir)
+		require.NoError(err)
+		require.Equal(oldNodeID, backupNodeID)
+		require.NoError(moveLocalStakingFiles(nodeDir, backupDir, 43))
+		nodeID, err = getNodeID(nodeDir)
+		require.NoError(err)
+		require.Equal(oldNodeID, nodeID)
+	})
+
+	t.Run("node without staking files", func(t *testing.T) {
+		require := require.New(t)
+		nodeDir, stakingDir := t.TempDir(), t.TempDir()
+		newNodeID := generateTestStakingFiles(t, stakingDir)
+		require.NoError(moveLocalStakingFiles(nodeDir, stakingDir, 42))
+		nodeID, err := getNodeID(nodeDir)
+		require.NoError(err)
+		require.Equal(newNodeID, nodeID)
+		require.False(utils.FileExists(filepath.Join(nodeDir, "staking-backup-42", constants.StakerCertFileName)))
+	})
+
+	t.Run("missing source files", func(t *testing.T) {
+		require.Error(t, moveLocalStakingFiles(t.TempDir(), t.TempDir(), 42))
+	})
+}
+
+func TestGetMigrateHost(t *testing.T) {
+	_, clusterHosts, nodeIDs := setupSSHTestCluster(t)
+	// the monitoring host has no staking files
+	hosts := clusterHosts[:3]
+	// a host sharing the IP of i-1
+	sharedIPHost := &models.Host{NodeID: hosts[1].NodeID, IP: hosts[0].IP}
+	tests := []struct {
+		name          string
+		hosts         []*models.Host
+		node          string
+		expected      string
+		expectedError string
+	}{
+		{name: "by cloud id", hosts: hosts, node: "i-2", expected: "i-2"},
+		{name: "by ip", hosts: hosts, node: hosts[2].IP, expected: "i-3"},
+		{name: "by node id", hosts: hosts, node: nodeIDs["i-1"], expected: "i-1"},
+		{name: "unknown node", hosts: hosts, node: "i-4", expectedError: `node "i-4" not found`},
+		{
+			name:          "ambiguous node",
+			hosts:         append([]*models.Host{sharedIPHost}, hosts...),
+			node:          hosts[0].IP,
+			expectedError: fmt.Sprintf("node %s matches 2 nodes of the cluster", hosts[0].IP),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			host, err := getMigrateHost(tt.hosts, tt.node)
+			if tt.expectedError != "" {
+				require.EqualError(err, tt.expectedError)
+				return
+			}
+			require.NoError(err)
+			require.Equal(tt.expected, host.GetCloudID())
+		})
+	}
+}
+
+func TestCheckStakingBackup(t *testing.T) {
+	require := require.New(t)
+	backupDir := t.TempDir()
+	nodeID := generateTestStakingFiles(t, backupDir)
+	require.NoError(checkStakingBackup(backupDir, nodeID))
+	otherNodeID := generateTestStakingFiles(t, t.TempDir())
+	require.EqualError(
+		checkStakingBackup(backupDir, otherNodeID),
+		fmt.Sprintf("staking files on host are for NodeID %s, expected %s", nodeID, otherNodeID),
+	)
+	require.Error(checkStakingBackup(t.TempDir(), nodeID))
+}
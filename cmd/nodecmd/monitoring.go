@@ -0,0 +1,274 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/monitoring"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	alertWebhooks      []string
+	alertSlackWebhooks []string
+	alertEmails        []string
+	alertSMTP          monitoring.SMTPConfig
+	alertRulesFile     string
+	clearAlertTargets  bool
+)
+
+func newMonitoringCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monitoring",
+		Short: "(ALPHA Warning) Manage the monitoring of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node monitoring command suite provides a collection of commands to manage the
+monitoring instance of a cluster.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := cmd.Help()
+			if err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+	// node monitoring alerts clusterName
+	cmd.AddCommand(newMonitoringAlertsCmd())
+	return cmd
+}
+
+func newMonitoringAlertsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alerts [clusterName]",
+		Short: "(ALPHA Warning) Configure the alerts of a monitored cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node monitoring alerts command configures where the alerts of a cluster with
+monitoring are sent, and which alert rules are evaluated. Alertmanager runs on the
+monitoring instance, and is installed by this command on clusters created before
+alerting was available.
+
+Alerts can be sent to generic webhooks (--webhook), Slack-compatible incoming
+webhooks (--slack-webhook) and email addresses (--email, with --smtp-host and
+--smtp-from). Given receivers are added to the current ones, --clear removes all
+of them first.
+
+The curated alert rules fire when a node is down or unhealthy, stops bootstrapping,
+loses its peers or stake connectivity, falls behind on a cluster subnet chain, runs
+low on disk, or has a validation ending within the avalanche config validation
+--expiry-warning setting. They can be overridden with --rules, a yaml file of rules matched
+by alert name:
+
+  rules:
+    - alert: NodeLowDisk
+      for: 30m
+    - alert: NodeLowStakeConnected
+      disabled: true
+    - alert: HighCPU
+      expr: 100 * (1 - avg by (instance) (irate(node_cpu_seconds_total{mode="idle"}[5m]))) > 90
+      labels:
+        severity: warning
+
+The rules are regenerated whenever nodes are added to the cluster.
+Without flags, the command shows the current receivers and rules.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         monitoringAlerts,
+	}
+	cmd.Flags().StringSliceVar(&alertWebhooks, "webhook", nil, "send alerts to the given webhook URLs")
+	cmd.Flags().StringSliceVar(&alertSlackWebhooks, "slack-webhook", nil, "send alerts to the given Slack-compatible incoming webhook URLs")
+	cmd.Flags().StringSliceVar(&alertEmails, "email", nil, "send alerts to the given email addresses")
+	cmd.Flags().StringVar(&alertSMTP.Smarthost, "smtp-host", "", "smtp server host:port used to send email alerts")
+	cmd.Flags().StringVar(&alertSMTP.From, "smtp-from", "", "sender address of email alerts")
+	cmd.Flags().StringVar(&alertSMTP.Username, "smtp-user", "", "smtp server username")
+	cmd.Flags().StringVar(&alertSMTP.Password, "smtp-password", "", "smtp server password")
+	cmd.Flags().StringVar(&alertRulesFile, "rules", "", "override the curated alert rules with the rules in the given yaml file")
+	cmd.Flags().BoolVar(&clearAlertTargets, "clear", false, "remove the current alert receivers")
+	return cmd
+}
+
+func monitoringAlerts(cmd *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	monitoringInventoryPath := app.GetMonitoringInventoryDir(clusterName)
+	if !utils.DirectoryExists(monitoringInventoryPath) {
+		return fmt.Errorf("cluster %s has no monitoring, create it with --enable-monitoring", clusterName)
+	}
+	receiversPath := app.GetClusterAlertReceiversPath(clusterName)
+	receivers, err := monitoring.LoadAlertReceivers(receiversPath)
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().NFlag() == 0 {
+		return printClusterAlerts(clusterName, receivers)
+	}
+	if alertRulesFile != "" {
+		if !utils.FileExists(alertRulesFile) {
+			return fmt.Errorf("alert rules file %s does not exist", alertRulesFile)
+		}
+		// validate the overrides before replacing the current ones
+		overrides, err := monitoring.LoadAlertRuleOverrides(alertRulesFile)
+		if err != nil {
+			return err
+		}
+		if _, err := monitoring.ApplyAlertRuleOverrides(monitoring.CuratedAlertRules(nil, constants.DefaultValidationExpiryWarning), overrides); err != nil {
+			return err
+		}
+		rulesBytes, err := os.ReadFile(alertRulesFile)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(app.GetClusterAlertRulesPath(clusterName), rulesBytes, constants.WriteReadUserOnlyPerms); err != nil {
+			return err
+		}
+	}
+	if clearAlertTargets {
+		receivers = monitoring.AlertReceivers{}
+	}
+	receivers.Webhooks = append(receivers.Webhooks, alertWebhooks...)
+	receivers.SlackWebhooks = append(receivers.SlackWebhooks, alertSlackWebhooks...)
+	receivers.Emails = append(receivers.Emails, alertEmails...)
+	if alertSMTP != (monitoring.SMTPConfig{}) {
+		receivers.SMTP = alertSMTP
+	}
+	if err := receivers.Validate(); err != nil {
+		return err
+	}
+	if err := monitoring.SaveAlertReceivers(receiversPath, receivers); err != nil {
+		return err
+	}
+	monitoringHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(monitoringInventoryPath)
+	if err != nil {
+		return err
+	}
+	if len(monitoringHosts) == 0 {
+		return fmt.Errorf("cluster %s has no monitoring host", clusterName)
+	}
+	defer disconnectHosts(monitoringHosts)
+	monitoringHost := monitoringHosts[0]
+	// older clusters need the alerting section of the prometheus config
+	avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err := getPrometheusTargets(clusterName)
+	if err != nil {
+		return err
+	}
+	spinSession := ux.NewUserSpinner()
+	defer spinSession.Stop()
+	spinner := spinSession.SpinToUser(utils.ScriptLog(monitoringHost.NodeID, "Setup Alerting"))
+	if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts, relayerPorts); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if err := setupClusterAlerting(clusterName, monitoringHost); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	ux.SpinComplete(spinner)
+	spinSession.Stop()
+	ux.Logger.GreenCheckmarkToUser("Alerting of cluster %s updated", clusterName)
+	return printClusterAlerts(clusterName, receivers)
+}
+
+// setupClusterAlerting installs Alertmanager on the monitoring host, sending alerts
+// to the saved cluster receivers, and uploads the cluster alert rules
+func setupClusterAlerting(clusterName string, monitoringHost *models.Host) error {
+	receivers, err := monitoring.LoadAlertReceivers(app.GetClusterAlertReceiversPath(clusterName))
+	if err != nil {
+		return err
+	}
+	if err := ssh.RunSSHSetupAlertmanager(monitoringHost); err != nil {
+		return err
+	}
+	if err := ssh.RunSSHUpdateAlertmanagerConfig(monitoringHost, receivers); err != nil {
+		return err
+	}
+	return updateClusterAlertRules(clusterName, monitoringHost)
+}
+
+// updateClusterAlertRules regenerates the alert rules of the cluster, watching its
+// current subnets, and uploads them to the monitoring host
+func updateClusterAlertRules(clusterName string, monitoringHost *models.Host) error {
+	rules, err := getClusterAlertRules(clusterName)
+	if err != nil {
+		return err
+	}
+	return ssh.RunSSHUpdateAlertRules(monitoringHost, rules)
+}
+
+// getClusterAlertRules returns the curated alert rules for the cluster, with the user
+// overrides applied
+func getClusterAlertRules(clusterName string) ([]monitoring.AlertRule, error) {
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	chains := []monitoring.AlertChain{}
+	for _, subnetName := range clusterConf.Subnets {
+		sc, err := app.LoadSidecar(subnetName)
+		if err != nil {
+			return nil, err
+		}
+		blockchainID := sc.Networks[clusterConf.Network.Name()].BlockchainID
+		if blockchainID == ids.Empty {
+			continue
+		}
+		chains = append(chains, monitoring.AlertChain{Name: subnetName, BlockchainID: blockchainID.String()})
+	}
+	validationExpiryWarning, err := app.Conf.GetValidationExpiryWarning()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := monitoring.LoadAlertRuleOverrides(app.GetClusterAlertRulesPath(clusterName))
+	if err != nil {
+		return nil, err
+	}
+	return monitoring.ApplyAlertRuleOverrides(monitoring.CuratedAlertRules(chains, validationExpiryWarning), overrides)
+}
+
+func printClusterAlerts(clusterName string, receivers monitoring.AlertReceivers) error {
+	rules, err := getClusterAlertRules(clusterName)
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Alert receivers of cluster %s:", clusterName)
+	switch {
+	case len(receivers.Webhooks)+len(receivers.SlackWebhooks)+len(receivers.Emails) == 0:
+		ux.Logger.PrintToUser("  none, alerts are only shown in Alertmanager")
+	default:
+		for _, url := range receivers.Webhooks {
+			ux.Logger.PrintToUser("  webhook: %s", url)
+		}
+		for _, url := range receivers.SlackWebhooks {
+			ux.Logger.PrintToUser("  slack webhook: %s", url)
+		}
+		for _, email := range receivers.Emails {
+			ux.Logger.PrintToUser("  email: %s (via %s)", email, receivers.SMTP.Smarthost)
+		}
+	}
+	ux.Logger.PrintToUser("")
+	header := []string{"Alert", "Severity", "For", "Expr"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	table.SetAutoWrapText(false)
+	for _, rule := range rules {
+		table.Append([]string{rule.Alert, rule.Labels["severity"], rule.For, strings.TrimSpace(rule.Expr)})
+	}
+	table.Render()
+	if utils.FileExists(app.GetClusterAlertRulesPath(clusterName)) {
+		ux.Logger.PrintToUser("Rule overrides are read from %s", app.GetClusterAlertRulesPath(clusterName))
+	}
+	return nil
+}
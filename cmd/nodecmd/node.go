@@ -4,8 +4,10 @@ package nodecmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +29,26 @@ rest of the commands to maintain your node and make your node a Subnet Validator
 			}
 		},
 	}
+	// cobra only runs the closest persistent hooks, so the root ones are called from here
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		if root := c.Root(); root != cmd && root.PersistentPreRunE != nil {
+			if err := root.PersistentPreRunE(c, args); err != nil {
+				return err
+			}
+		}
+		return setSSHSettings(c)
+	}
+	cmd.PersistentPostRun = func(c *cobra.Command, args []string) {
+		models.CloseSSHConnections()
+		if root := c.Root(); root != cmd && root.PersistentPostRun != nil {
+			root.PersistentPostRun(c, args)
+		}
+	}
 	app = injectedApp
+	cmd.PersistentFlags().IntVar(&sshSettingsMaxParallel, "ssh-max-parallel", 0, "override the maximum ssh connection attempts and remote operations running at the same time")
+	cmd.PersistentFlags().IntVar(&sshSettingsRetries, "ssh-retries", 0, "override the ssh connection attempts on transient errors")
+	cmd.PersistentFlags().DurationVar(&sshSettingsKeepAlive, "ssh-keepalive", 0, "override the interval between keepalive requests on open ssh connections")
+	cmd.PersistentFlags().BoolVar(&sshSettingsReuse, "ssh-reuse", true, "override the reuse of ssh connections across the steps of the command")
 	// node create
 	cmd.AddCommand(newCreateCmd())
 	// node validate
@@ -58,5 +79,66 @@ rest of the commands to maintain your node and make your node a Subnet Validator
 	cmd.AddCommand(newResizeCmd())
 	// node addDashboard
 	cmd.AddCommand(newAddDashboardCmd())
+	// node relayer
+	cmd.AddCommand(newRelayerCmd())
+	// node doctor
+	cmd.AddCommand(newDoctorCmd())
+	// node migrate
+	cmd.AddCommand(newMigrateCmd())
+	// node config
+	cmd.AddCommand(newConfigCmd())
+	// node backup
+	cmd.AddCommand(newBackupCmd())
+	// node restore
+	cmd.AddCommand(newRestoreCmd())
+	// node add
+	cmd.AddCommand(newAddCmd())
+	// node remove
+	cmd.AddCommand(newRemoveCmd())
+	// node cost
+	cmd.AddCommand(newCostCmd())
+	// node monitoring
+	cmd.AddCommand(newMonitoringCmd())
+	// node provision
+	cmd.AddCommand(newProvisionCmd())
+	// node label
+	cmd.AddCommand(newLabelCmd())
 	return cmd
 }
+
+var (
+	sshSettingsMaxParallel int
+	sshSettingsRetries     int
+	sshSettingsKeepAlive   time.Duration
+	sshSettingsReuse       bool
+)
+
+// setSSHSettings applies the ssh settings of the config, overridden by the command
+// --ssh-* flags
+func setSSHSettings(cmd *cobra.Command) error {
+	settings, err := app.Conf.GetSSHSettings()
+	if err != nil {
+		return err
+	}
+	flags := cmd.Flags()
+	if flags.Changed("ssh-max-parallel") {
+		if sshSettingsMaxParallel < 1 {
+			return fmt.Errorf("--ssh-max-parallel must be at least 1")
+		}
+		settings.MaxParallel = sshSettingsMaxParallel
+	}
+	if flags.Changed("ssh-retries") {
+		if sshSettingsRetries < 1 {
+			return fmt.Errorf("--ssh-retries must be at least 1")
+		}
+		settings.Retries = sshSettingsRetries
+	}
+	if flags.Changed("ssh-keepalive") {
+		settings.KeepAlive = sshSettingsKeepAlive
+	}
+	if flags.Changed("ssh-reuse") {
+		settings.Reuse = sshSettingsReuse
+	}
+	models.SetSSHSettings(settings)
+	return nil
+}
@@ -0,0 +1,551 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// provisioning steps, in the order they run
+const (
+	provisionStepStakingFiles    = "staking-files"
+	provisionStepBuildEnv        = "build-env"
+	provisionStepNode            = "node"
+	provisionStepMetrics         = "metrics"
+	provisionStepLogging         = "logging"
+	provisionStepCLI             = "cli"
+	provisionStepMonitoringAgent = "monitoring-agent"
+	provisionStepDevnet          = "devnet"
+	provisionStepMonitoring      = "monitoring"
+	provisionStepAlerting        = "alerting"
+	provisionStepLoki            = "loki"
+)
+
+// provisionStepServices are the services that hosts provisioned before steps were marked
+// run once the steps are completed
+var provisionStepServices = map[string][]string{
+	"avalanchego":             {provisionStepStakingFiles, provisionStepBuildEnv, provisionStepNode, provisionStepCLI},
+	"node_exporter":           {provisionStepMetrics},
+	"promtail":                {provisionStepLogging, provisionStepMonitoringAgent},
+	"prometheus":              {provisionStepMonitoring},
+	"prometheus-alertmanager": {provisionStepAlerting},
+	"loki":                    {provisionStepLoki},
+}
+
+var (
+	provisionResume             bool
+	provisionAvalancheGoVersion string
+)
+
+func newProvisionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "provision [clusterName]",
+		Short: "(ALPHA Warning) Provision the nodes of a cluster, resuming a failed node create",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node provision command installs avalanchego, the CLI and the monitoring agents
+on the nodes of a cluster, and sets up its monitoring instance, the same way node
+create does.
+
+Provisioning is split in steps (staking-files, build-env, node, metrics, logging, cli,
+monitoring-agent and devnet on the nodes, monitoring, alerting and loki on the
+monitoring instance). Each completed step is marked both on the host and in the node
+config. With --resume, steps marked in both places are skipped, so that only the
+hosts and steps that failed, for example on an apt mirror timeout during node create,
+are retried. Without --resume all steps run again. Hosts provisioned before steps were
+marked get their completed steps detected from the services they run and their devnet
+genesis.
+
+The devnet genesis of a cluster is never regenerated once set up.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         provisionCluster,
+	}
+	cmd.Flags().BoolVar(&provisionResume, "resume", false, "skip the steps already completed on each host")
+	cmd.Flags().StringVar(&provisionAvalancheGoVersion, "avalanchego-version", "", "install the given avalanchego version. defaults to the version the nodes were created with")
+	cmd.Flags().StringVar(&remoteCLIVersion, "remote-cli-version", "", "install given CLI version on remote nodes. defaults to latest CLI release")
+	cmd.Flags().StringVar(&grafanaPkg, "grafana-pkg", "", "use grafana pkg instead of apt repo(by default), for example https://dl.grafana.com/oss/release/grafana_10.4.1_amd64.deb")
+	return cmd
+}
+
+// nodeProvisioner runs the provisioning steps of the hosts of a cluster, marking the
+// completed ones on the host and in its node config so that they can be skipped later
+type nodeProvisioner struct {
+	clusterName        string
+	network            models.Network
+	avalancheGoVersion string
+	// monitoringIP is the IP of the cluster monitoring instance, if any
+	monitoringIP string
+	resume       bool
+	spinSession  *ux.UserSpinner
+	steps        provisionStepMarker
+}
+
+// provisionStepMarker reads and writes the markers of the provisioning steps completed
+// on the hosts
+type provisionStepMarker interface {
+	// configSteps returns the steps marked in the node config of the host
+	configSteps(host *models.Host) ([]string, error)
+	// hostSteps returns the steps marked on the host
+	hostSteps(host *models.Host) ([]string, error)
+	// legacySteps returns the steps completed on a host provisioned before steps were marked
+	legacySteps(host *models.Host) ([]string, error)
+	// mark marks [step] on the host and in its node config
+	mark(host *models.Host, step string, avalancheGoVersion string) error
+}
+
+// sshStepMarker keeps the step markers on the hosts, reached over ssh, and in the node configs
+type sshStepMarker struct{}
+
+func (sshStepMarker) configSteps(host *models.Host) ([]string, error) {
+	nodeConfig, err := app.LoadClusterNodeConfig(host.GetCloudID())
+	if err != nil {
+		return nil, err
+	}
+	return nodeConfig.ProvisionedSteps, nil
+}
+
+func (sshStepMarker) hostSteps(host *models.Host) ([]string, error) {
+	return ssh.RunSSHGetProvisionedSteps(host)
+}
+
+func (sshStepMarker) legacySteps(host *models.Host) ([]string, error) {
+	services, err := ssh.RunSSHGetActiveServices(host, maps.Keys(provisionStepServices))
+	if err != nil {
+		return nil, err
+	}
+	hasGenesis, err := ssh.RunSSHFileExists(host, filepath.Join(constants.CloudNodeConfigPath, constants.GenesisFileName))
+	if err != nil {
+		return nil, err
+	}
+	return legacyProvisionedSteps(services, hasGenesis), nil
+}
+
+func (sshStepMarker) mark(host *models.Host, step string, avalancheGoVersion string) error {
+	if err := ssh.RunSSHMarkProvisionedStep(host, step); err != nil {
+		return err
+	}
+	nodeConfig, err := app.LoadClusterNodeConfig(host.GetCloudID())
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(nodeConfig.ProvisionedSteps, step) {
+		nodeConfig.ProvisionedSteps = append(nodeConfig.ProvisionedSteps, step)
+	}
+	if step == provisionStepNode {
+		nodeConfig.AvalancheGoVersion = avalancheGoVersion
+	}
+	return app.CreateNodeCloudConfigFile(host.GetCloudID(), &nodeConfig)
+}
+
+// legacyProvisionedSteps returns the steps completed on a host running [services], and
+// the devnet step if it has the devnet genesis, in the order steps run
+func legacyProvisionedSteps(services []string, hasGenesis bool) []string {
+	completed := []string{}
+	for _, service := range services {
+		completed = append(completed, provisionStepServices[service]...)
+	}
+	if hasGenesis {
+		completed = append(completed, provisionStepDevnet)
+	}
+	return utils.Filter([]string{
+		provisionStepStakingFiles,
+		provisionStepBuildEnv,
+		provisionStepNode,
+		provisionStepMetrics,
+		provisionStepLogging,
+		provisionStepCLI,
+		provisionStepMonitoringAgent,
+		provisionStepDevnet,
+		provisionStepMonitoring,
+		provisionStepAlerting,
+		provisionStepLoki,
+	}, func(step string) bool { return slices.Contains(completed, step) })
+}
+
+// completedSteps returns the steps marked as completed both on the host and in its
+// node config. A re-created instance has no host markers, and a host marker is not
+// trusted without the node config one. Hosts provisioned before steps were marked have
+// no markers at all, so their completed steps are detected and marked
+func (p *nodeProvisioner) completedSteps(host *models.Host) ([]string, error) {
+	configSteps, err := p.steps.configSteps(host)
+	if err != nil {
+		return nil, err
+	}
+	hostSteps, err := p.steps.hostSteps(host)
+	if err != nil {
+		return nil, err
+	}
+	if len(configSteps) == 0 && len(hostSteps) == 0 {
+		legacySteps, err := p.steps.legacySteps(host)
+		if err != nil {
+			return nil, err
+		}
+		for _, step := range legacySteps {
+			if err := p.markStep(host, step); err != nil {
+				return nil, err
+			}
+		}
+		return legacySteps, nil
+	}
+	return utils.Filter(configSteps, func(step string) bool { return slices.Contains(hostSteps, step) }), nil
+}
+
+func (p *nodeProvisioner) markStep(host *models.Host, step string) error {
+	return p.steps.mark(host, step, p.avalancheGoVersion)
+}
+
+// runStep runs [step] on the host unless resuming and it is in [completed]
+func (p *nodeProvisioner) runStep(host *models.Host, completed []string, step string, desc string, f func() error) error {
+	if p.resume && slices.Contains(completed, step) {
+		return nil
+	}
+	spinner := p.spinSession.SpinToUser(utils.ScriptLog(host.NodeID, desc))
+	if err := f(); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return fmt.Errorf("provisioning step %s failed: %w", step, err)
+	}
+	if err := p.markStep(host, step); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	ux.SpinComplete(spinner)
+	return nil
+}
+
+// provisionNode installs avalanchego, the CLI and, if the cluster has monitoring, the
+// metrics and logging agents on the host
+func (p *nodeProvisioner) provisionNode(host *models.Host) error {
+	if err := host.Connect(0); err != nil {
+		return err
+	}
+	completed, err := p.completedSteps(host)
+	if err != nil {
+		return err
+	}
+	if err := p.runStep(host, completed, provisionStepStakingFiles, "Upload Staking Files", func() error {
+		return provideStakingCertAndKey(host)
+	}); err != nil {
+		return err
+	}
+	if err := p.runStep(host, completed, provisionStepBuildEnv, "Setup Build Env", func() error {
+		return ssh.RunSSHSetupBuildEnv(host)
+	}); err != nil {
+		return err
+	}
+	if err := p.runStep(host, completed, provisionStepNode, "Setup Node", func() error {
+		return ssh.RunSSHSetupNode(host, app.Conf.GetConfigPath(), p.avalancheGoVersion, remoteCLIVersion, p.network.Kind == models.Devnet)
+	}); err != nil {
+		return err
+	}
+	if p.monitoringIP != "" {
+		if err := p.runStep(host, completed, provisionStepMetrics, "Setup Metrics", func() error {
+			return ssh.RunSSHSetupMachineMetrics(host)
+		}); err != nil {
+			return err
+		}
+		if err := p.runStep(host, completed, provisionStepLogging, "Setup Logging", func() error {
+			if err := ssh.RunSSHSetupPromtail(host); err != nil {
+				return err
+			}
+			cloudID := host.GetCloudID()
+			nodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID))
+			if err != nil {
+				return err
+			}
+			return ssh.RunSSHUpdatePromtailConfig(host, p.monitoringIP, constants.AvalanchegoLokiPort, cloudID, nodeID.String())
+		}); err != nil {
+			return err
+		}
+	}
+	return p.runStep(host, completed, provisionStepCLI, "Setup Avalanche-CLI", func() error {
+		return ssh.RunSSHSetupCLIFromSource(host, constants.SetupCLIFromSourceBranch)
+	})
+}
+
+// provisionMonitoringAgent points the avalanchego metrics of the host to the monitoring
+// instance, restarting the node
+func (p *nodeProvisioner) provisionMonitoringAgent(host *models.Host) error {
+	completed, err := p.completedSteps(host)
+	if err != nil {
+		return err
+	}
+	return p.runStep(host, completed, provisionStepMonitoringAgent, "Configure Monitoring Agent", func() error {
+		if err := app.CreateAnsibleNodeConfigDir(host.NodeID); err != nil {
+			return err
+		}
+		nodeDirPath := app.GetNodeInstanceAvaGoConfigDirPath(host.NodeID)
+		if err := ssh.RunSSHDownloadNodeMonitoringConfig(host, nodeDirPath); err != nil {
+			return err
+		}
+		if err := addHTTPHostToConfigFile(app.GetNodeConfigJSONFile(host.NodeID)); err != nil {
+			return err
+		}
+		if err := ssh.RunSSHUploadNodeMonitoringConfig(host, nodeDirPath); err != nil {
+			return err
+		}
+		if err := ssh.RunSSHRestartNode(host); err != nil {
+			return err
+		}
+		return os.RemoveAll(nodeDirPath)
+	})
+}
+
+// provisionMonitoring sets up prometheus, grafana, alerting and loki on the monitoring host
+func (p *nodeProvisioner) provisionMonitoring(monitoringHost *models.Host) error {
+	if err := monitoringHost.Connect(0); err != nil {
+		return err
+	}
+	completed, err := p.completedSteps(monitoringHost)
+	if err != nil {
+		return err
+	}
+	if err := p.runStep(monitoringHost, completed, provisionStepMonitoring, "Setup Monitoring", func() error {
+		if err := app.SetupMonitoringEnv(); err != nil {
+			return err
+		}
+		if err := ssh.RunSSHSetupSeparateMonitoring(monitoringHost, grafanaPkg); err != nil {
+			return err
+		}
+		if err := ssh.RunSSHCopyMonitoringDashboards(monitoringHost, app.GetMonitoringDashboardDir()+"/"); err != nil {
+			return err
+		}
+		avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err := getPrometheusTargets(p.clusterName)
+		if err != nil {
+			return err
+		}
+		return ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts, relayerPorts)
+	}); err != nil {
+		return err
+	}
+	if err := p.runStep(monitoringHost, completed, provisionStepAlerting, "Setup Alerting", func() error {
+		return setupClusterAlerting(p.clusterName, monitoringHost)
+	}); err != nil {
+		return err
+	}
+	return p.runStep(monitoringHost, completed, provisionStepLoki, "Setup Logging", func() error {
+		if err := ssh.RunSSHSetupLoki(monitoringHost, grafanaPkg); err != nil {
+			return err
+		}
+		return ssh.RunSSHUpdateLokiConfig(monitoringHost, constants.AvalanchegoLokiPort)
+	})
+}
+
+// markDevnetJoined records that the hosts are part of the cluster devnet
+func (p *nodeProvisioner) markDevnetJoined(hosts []*models.Host) error {
+	for _, host := range hosts {
+		if err := p.markStep(host, provisionStepDevnet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func provisionCluster(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return err
+	}
+	hosts = utils.Filter(hosts, func(h *models.Host) bool { return slices.Contains(clusterConf.Nodes, h.GetCloudID()) })
+	if len(hosts) == 0 {
+		return fmt.Errorf("cluster %s has no nodes", clusterName)
+	}
+	defer disconnectHosts(hosts)
+	var monitoringHost *models.Host
+	monitoringIP := ""
+	if clusterConf.MonitoringInstance != "" {
+		monitoringHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetMonitoringInventoryDir(clusterName))
+		if err != nil {
+			return err
+		}
+		if len(monitoringHosts) != 1 {
+			return fmt.Errorf("expected only one monitoring host, found %d", len(monitoringHosts))
+		}
+		monitoringHost = monitoringHosts[0]
+		defer disconnectHosts(monitoringHosts)
+		monitoringNodeConfig, err := app.LoadClusterNodeConfig(clusterConf.MonitoringInstance)
+		if err != nil {
+			return err
+		}
+		monitoringIP = monitoringNodeConfig.ElasticIP
+	}
+	avalancheGoVersion, err := getProvisionAvalancheGoVersion(clusterName, hosts)
+	if err != nil {
+		return err
+	}
+	checkHosts := hosts
+	if monitoringHost != nil {
+		checkHosts = append(checkHosts, monitoringHost)
+	}
+	if failedHosts := waitForHosts(checkHosts); failedHosts.Len() > 0 {
+		for _, result := range failedHosts.GetResults() {
+			ux.Logger.PrintToUser("Instance %s is not reachable with error %s", result.NodeID, result.Err)
+		}
+		return fmt.Errorf("failed to reach node(s) %s", failedHosts.GetNodeList())
+	}
+	provisioner := &nodeProvisioner{
+		clusterName:        clusterName,
+		network:            clusterConf.Network,
+		avalancheGoVersion: avalancheGoVersion,
+		monitoringIP:       monitoringIP,
+		resume:             provisionResume,
+		spinSession:        ux.NewUserSpinner(),
+		steps:              sshStepMarker{},
+	}
+	defer provisioner.spinSession.Stop()
+	wg := sync.WaitGroup{}
+	wgResults := models.NodeResults{}
+	if monitoringHost != nil {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, monitoringHost *models.Host) {
+			defer wg.Done()
+			if err := provisioner.provisionMonitoring(monitoringHost); err != nil {
+				nodeResults.AddResult(monitoringHost.NodeID, nil, err)
+			}
+		}(&wgResults, monitoringHost)
+	}
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, host *models.Host) {
+			defer wg.Done()
+			if err := provisioner.provisionNode(host); err != nil {
+				nodeResults.AddResult(host.NodeID, nil, err)
+			}
+		}(&wgResults, host)
+	}
+	wg.Wait()
+	if monitoringHost != nil && !wgResults.HasNodeIDWithError(monitoringHost.NodeID) {
+		for _, host := range hosts {
+			if wgResults.HasNodeIDWithError(host.NodeID) {
+				continue
+			}
+			wg.Add(1)
+			go func(nodeResults *models.NodeResults, host *models.Host) {
+				defer wg.Done()
+				if err := provisioner.provisionMonitoringAgent(host); err != nil {
+					nodeResults.AddResult(host.NodeID, nil, err)
+				}
+			}(&wgResults, host)
+		}
+		wg.Wait()
+	}
+	provisioner.spinSession.Stop()
+	if clusterConf.Network.Kind == models.Devnet {
+		if err := provisionClusterDevnet(provisioner, clusterConf, hosts, &wgResults); err != nil {
+			return err
+		}
+	}
+	if monitoringHost != nil && !wgResults.HasNodeIDWithError(monitoringHost.NodeID) {
+		if err := updateClusterPrometheusTargets(clusterName); err != nil {
+			ux.Logger.RedXToUser("unable to update monitoring targets of cluster %s due to %s", clusterName, err)
+		}
+	}
+	for _, host := range checkHosts {
+		if wgResults.HasNodeIDWithError(host.NodeID) {
+			ux.Logger.RedXToUser("Node %s failed to provision with error: %s", host.NodeID, wgResults.GetErrorHostMap()[host.NodeID])
+		} else {
+			ux.Logger.GreenCheckmarkToUser("Node %s provisioned", host.NodeID)
+		}
+	}
+	if wgResults.HasErrors() {
+		ux.Logger.PrintToUser("Use metal node provision %s --resume to retry the failed steps", clusterName)
+		return fmt.Errorf("failed to provision node(s) %s", wgResults.GetErrorHosts())
+	}
+	return nil
+}
+
+// getProvisionAvalancheGoVersion returns the avalanchego version given by flag, or else
+// the one the nodes were created with, or else the one the cluster runs
+func getProvisionAvalancheGoVersion(clusterName string, hosts []*models.Host) (string, error) {
+	if provisionAvalancheGoVersion != "" {
+		return provisionAvalancheGoVersion, nil
+	}
+	for _, host := range hosts {
+		nodeConfig, err := app.LoadClusterNodeConfig(host.GetCloudID())
+		if err != nil {
+			return "", err
+		}
+		if nodeConfig.AvalancheGoVersion != "" {
+			return nodeConfig.AvalancheGoVersion, nil
+		}
+	}
+	version, err := getClusterAvalancheGoVersion(clusterName, hosts)
+	if err != nil {
+		return "", fmt.Errorf("%w, please set it with --avalanchego-version", err)
+	}
+	return version, nil
+}
+
+// provisionClusterDevnet sets up the devnet of the cluster if no node has joined it yet,
+// which requires all nodes to be provisioned, or else joins the provisioned nodes
+// that are not part of it
+func provisionClusterDevnet(provisioner *nodeProvisioner, clusterConf models.ClusterConfig, hosts []*models.Host, nodeResults *models.NodeResults) error {
+	joined, pending, err := selectDevnetHosts(provisioner, hosts, nodeResults)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if len(joined) == 0 {
+		if len(pending) != len(hosts) {
+			ux.Logger.PrintToUser("Devnet setup is skipped until all nodes of cluster %s are provisioned", provisioner.clusterName)
+			return nil
+		}
+		apiNodeIPMap := map[string]string{}
+		for _, cloudID := range clusterConf.APINodes {
+			nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+			if err != nil {
+				return err
+			}
+			apiNodeIPMap[cloudID] = nodeConfig.ElasticIP
+		}
+		if err := setupDevnet(provisioner.clusterName, hosts, apiNodeIPMap); err != nil {
+			return err
+		}
+	} else if err := joinDevnet(provisioner.clusterName, pending); err != nil {
+		return err
+	}
+	return provisioner.markDevnetJoined(pending)
+}
+
+// selectDevnetHosts returns the hosts that joined the cluster devnet, and the provisioned
+// hosts pending to join it. Hosts that failed to provision are in neither
+func selectDevnetHosts(provisioner *nodeProvisioner, hosts []*models.Host, nodeResults *models.NodeResults) ([]*models.Host, []*models.Host, error) {
+	joined := []*models.Host{}
+	pending := []*models.Host{}
+	for _, host := range hosts {
+		completed, err := provisioner.completedSteps(host)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case slices.Contains(completed, provisionStepDevnet):
+			joined = append(joined, host)
+		case !nodeResults.HasNodeIDWithError(host.NodeID):
+			pending = append(pending, host)
+		}
+	}
+	return joined, pending, nil
+}
@@ -0,0 +1,258 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStepMarker keeps the step markers of hosts in memory, by cloud ID
+type fakeStepMarker struct {
+	config   map[string][]string
+	host     map[string][]string
+	services map[string][]string
+	genesis  map[string]bool
+}
+
+func newFakeStepMarker() *fakeStepMarker {
+	return &fakeStepMarker{
+		config:   map[string][]string{},
+		host:     map[string][]string{},
+		services: map[string][]string{},
+		genesis:  map[string]bool{},
+	}
+}
+
+func (f *fakeStepMarker) configSteps(host *models.Host) ([]string, error) {
+	return f.config[host.GetCloudID()], nil
+}
+
+func (f *fakeStepMarker) hostSteps(host *models.Host) ([]string, error) {
+	return f.host[host.GetCloudID()], nil
+}
+
+func (f *fakeStepMarker) legacySteps(host *models.Host) ([]string, error) {
+	return legacyProvisionedSteps(f.services[host.GetCloudID()], f.genesis[host.GetCloudID()]), nil
+}
+
+func (f *fakeStepMarker) mark(host *models.Host, step string, _ string) error {
+	f.config[host.GetCloudID()] = append(f.config[host.GetCloudID()], step)
+	f.host[host.GetCloudID()] = append(f.host[host.GetCloudID()], step)
+	return nil
+}
+
+func newTestProvisioner(steps provisionStepMarker, resume bool) *nodeProvisioner {
+	ux.NewUserLog(logging.NoLog{}, io.Discard)
+	return &nodeProvisioner{
+		clusterName: "cluster",
+		resume:      resume,
+		spinSession: ux.NewUserSpinner(),
+		steps:       steps,
+	}
+}
+
+func TestCompletedSteps(t *testing.T) {
+	require := require.New(t)
+	marker := newFakeStepMarker()
+	p := newTestProvisioner(marker, true)
+	host := &models.Host{NodeID: "aws_node_i-1"}
+
+	// only steps marked in both places are completed
+	marker.config["i-1"] = []string{provisionStepStakingFiles, provisionStepBuildEnv, provisionStepNode}
+	marker.host["i-1"] = []string{provisionStepStakingFiles, provisionStepNode, provisionStepCLI}
+	completed, err := p.completedSteps(host)
+	require.NoError(err)
+	require.Equal([]string{provisionStepStakingFiles, provisionStepNode}, completed)
+
+	// a re-created instance has no host markers, and is not detected as legacy
+	marker.host["i-1"] = nil
+	marker.services["i-1"] = []string{"avalanchego"}
+	completed, err = p.completedSteps(host)
+	require.NoError(err)
+	require.Empty(completed)
+	require.Empty(marker.host["i-1"])
+}
+
+func TestCompletedStepsLegacyHost(t *testing.T) {
+	require := require.New(t)
+	marker := newFakeStepMarker()
+	p := newTestProvisioner(marker, true)
+	host := &models.Host{NodeID: "aws_node_i-1"}
+	fresh := &models.Host{NodeID: "aws_node_i-2"}
+
+	marker.services["i-1"] = []string{"promtail", "avalanchego", "node_exporter"}
+	marker.genesis["i-1"] = true
+	expected := []string{
+		provisionStepStakingFiles,
+		provisionStepBuildEnv,
+		provisionStepNode,
+		provisionStepMetrics,
+		provisionStepLogging,
+		provisionStepCLI,
+		provisionStepMonitoringAgent,
+		provisionStepDevnet,
+	}
+	completed, err := p.completedSteps(host)
+	require.NoError(err)
+	require.Equal(expected, completed)
+	// the detected steps are backfilled
+	require.Equal(expected, marker.config["i-1"])
+	require.Equal(expected, marker.host["i-1"])
+	completed, err = p.completedSteps(host)
+	require.NoError(err)
+	require.Equal(expected, completed)
+
+	completed, err = p.completedSteps(fresh)
+	require.NoError(err)
+	require.Empty(completed)
+	require.Empty(marker.config["i-2"])
+}
+
+func TestRunStep(t *testing.T) {
+	host := &models.Host{NodeID: "aws_node_i-1"}
+	tests := []struct {
+		name          string
+		resume        bool
+		completed     []string
+		stepErr       error
+		expectedRun   bool
+		expectedErr   string
+		expectedMarks []string
+	}{
+		{
+			name:          "runs and marks step",
+			expectedRun:   true,
+			expectedMarks: []string{provisionStepNode},
+		},
+		{
+			name:          "reruns completed step without resume",
+			completed:     []string{provisionStepNode},
+			expectedRun:   true,
+			expectedMarks: []string{provisionStepNode},
+		},
+		{
+			name:      "skips completed step on resume",
+			resume:    true,
+			completed: []string{provisionStepNode},
+		},
+		{
+			name:          "runs pending step on resume",
+			resume:        true,
+			completed:     []string{provisionStepBuildEnv},
+			expectedRun:   true,
+			expectedMarks: []string{provisionStepNode},
+		},
+		{
+			name:        "does not mark failed step",
+			stepErr:     errors.New("apt timeout"),
+			expectedRun: true,
+			expectedErr: "provisioning step node failed: apt timeout",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			marker := newFakeStepMarker()
+			p := newTestProvisioner(marker, tt.resume)
+			defer p.spinSession.Stop()
+			run := false
+			err := p.runStep(host, tt.completed, provisionStepNode, "Setup Node", func() error {
+				run = true
+				return tt.stepErr
+			})
+			if tt.expectedErr != "" {
+				require.EqualError(err, tt.expectedErr)
+			} else {
+				require.NoError(err)
+			}
+			require.Equal(tt.expectedRun, run)
+			require.Equal(tt.expectedMarks, marker.config["i-1"])
+		})
+	}
+}
+
+func TestSelectDevnetHosts(t *testing.T) {
+	hosts := []*models.Host{
+		{NodeID: "aws_node_i-1"},
+		{NodeID: "aws_node_i-2"},
+		{NodeID: "aws_node_i-3"},
+	}
+	tests := []struct {
+		name            string
+		devnetSteps     map[string]bool
+		legacyGenesis   map[string]bool
+		failed          []string
+		expectedJoined  []string
+		expectedPending []string
+	}{
+		{
+			name:            "new cluster",
+			expectedJoined:  []string{},
+			expectedPending: []string{"aws_node_i-1", "aws_node_i-2", "aws_node_i-3"},
+		},
+		{
+			name:            "new cluster with failed node",
+			failed:          []string{"aws_node_i-2"},
+			expectedJoined:  []string{},
+			expectedPending: []string{"aws_node_i-1", "aws_node_i-3"},
+		},
+		{
+			name:            "added node",
+			devnetSteps:     map[string]bool{"i-1": true, "i-2": true},
+			expectedJoined:  []string{"aws_node_i-1", "aws_node_i-2"},
+			expectedPending: []string{"aws_node_i-3"},
+		},
+		{
+			name:            "legacy cluster without markers",
+			legacyGenesis:   map[string]bool{"i-1": true, "i-2": true, "i-3": true},
+			expectedJoined:  []string{"aws_node_i-1", "aws_node_i-2", "aws_node_i-3"},
+			expectedPending: []string{},
+		},
+		{
+			name:            "node added to legacy cluster",
+			legacyGenesis:   map[string]bool{"i-1": true, "i-2": true},
+			expectedJoined:  []string{"aws_node_i-1", "aws_node_i-2"},
+			expectedPending: []string{"aws_node_i-3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			marker := newFakeStepMarker()
+			for _, host := range hosts {
+				cloudID := host.GetCloudID()
+				marker.config[cloudID] = []string{provisionStepNode}
+				marker.host[cloudID] = []string{provisionStepNode}
+				if tt.devnetSteps[cloudID] {
+					marker.config[cloudID] = append(marker.config[cloudID], provisionStepDevnet)
+					marker.host[cloudID] = append(marker.host[cloudID], provisionStepDevnet)
+				}
+				if tt.legacyGenesis != nil {
+					marker.config[cloudID] = nil
+					marker.host[cloudID] = nil
+					marker.services[cloudID] = []string{"avalanchego"}
+					marker.genesis[cloudID] = tt.legacyGenesis[cloudID]
+				}
+			}
+			nodeResults := models.NodeResults{}
+			for _, nodeID := range tt.failed {
+				nodeResults.AddResult(nodeID, nil, errors.New("failed"))
+			}
+			p := newTestProvisioner(marker, false)
+			defer p.spinSession.Stop()
+			joined, pending, err := selectDevnetHosts(p, hosts, &nodeResults)
+			require.NoError(err)
+			nodeID := func(h *models.Host) string { return h.NodeID }
+			require.Equal(tt.expectedJoined, utils.Map(joined, nodeID))
+			require.Equal(tt.expectedPending, utils.Map(pending, nodeID))
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/node"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+)
+
+func newRelayerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relayer",
+		Short: "(ALPHA Warning) Manage the AWM relayer service of a cloud cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node relayer command suite provides a collection of commands to set up, move,
+configure and monitor the AWM relayer service that delivers Teleporter messages
+between the teleporter-ready Subnets synced to a cluster.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := cmd.Help()
+			if err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+	// node relayer setup clusterName
+	cmd.AddCommand(newRelayerSetupCmd())
+	// node relayer start clusterName
+	cmd.AddCommand(newRelayerStartCmd())
+	// node relayer stop clusterName
+	cmd.AddCommand(newRelayerStopCmd())
+	// node relayer status clusterName
+	cmd.AddCommand(newRelayerStatusCmd())
+	// node relayer logs clusterName
+	cmd.AddCommand(newRelayerLogsCmd())
+	// node relayer add-subnet clusterName subnetName
+	cmd.AddCommand(newRelayerAddSubnetCmd())
+	return cmd
+}
+
+func newRelayerStartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "start [clusterName]",
+		Short: "(ALPHA Warning) Starts the AWM relayer service of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node relayer start command starts the AWM relayer service on the cluster relayer host.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         startRelayer,
+	}
+}
+
+func newRelayerStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop [clusterName]",
+		Short: "(ALPHA Warning) Stops the AWM relayer service of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node relayer stop command stops the AWM relayer service on the cluster relayer host.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         stopRelayer,
+	}
+}
+
+func startRelayer(_ *cobra.Command, args []string) error {
+	relayerHost, err := getClusterRelayerHost(args[0])
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts([]*models.Host{relayerHost})
+	if err := ssh.RunSSHStartAWMRelayerService(relayerHost); err != nil {
+		return err
+	}
+	ux.Logger.GreenCheckmarkToUser("AWM Relayer started on %s", relayerHost.GetCloudID())
+	return nil
+}
+
+func stopRelayer(_ *cobra.Command, args []string) error {
+	relayerHost, err := getClusterRelayerHost(args[0])
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts([]*models.Host{relayerHost})
+	if err := ssh.RunSSHStopAWMRelayerService(relayerHost); err != nil {
+		return err
+	}
+	ux.Logger.GreenCheckmarkToUser("AWM Relayer stopped on %s", relayerHost.GetCloudID())
+	return nil
+}
+
+// getClusterRelayerHost returns the host running the relayer service of [clusterName]
+func getClusterRelayerHost(clusterName string) (*models.Host, error) {
+	if err := checkCluster(clusterName); err != nil {
+		return nil, err
+	}
+	relayerHost, err := node.GetAWMRelayerHost(app, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if relayerHost == nil {
+		return nil, fmt.Errorf("cluster %s has no AWM relayer. set it up with 'node relayer setup %s'", clusterName, clusterName)
+	}
+	return relayerHost, nil
+}
+
+// updateClusterRelayer adds [subnetNames] to the relayer config of [relayerHost], uploads
+// it and restarts the relayer service so the changes take effect
+func updateClusterRelayer(clusterName string, relayerHost *models.Host, subnetNames []string) error {
+	cloudID := relayerHost.GetCloudID()
+	spinSession := ux.NewUserSpinner()
+	spinner := spinSession.SpinToUser(utils.ScriptLog(cloudID, "Update AWM Relayer Config"))
+	if _, err := subnet.UpdateClusterRelayerConfig(app, clusterName, cloudID, subnetNames); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if err := ssh.RunSSHUploadNodeAWMRelayerConfig(relayerHost, app.GetNodeInstanceDirPath(cloudID)); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	ux.SpinComplete(spinner)
+	spinSession.Stop()
+	if err := ssh.RunSSHStopAWMRelayerService(relayerHost); err != nil {
+		return err
+	}
+	return ssh.RunSSHStartAWMRelayerService(relayerHost)
+}
+
+// updateClusterPrometheusTargets regenerates the scrape targets and alert rules of the
+// cluster monitoring host, if there is one
+func updateClusterPrometheusTargets(clusterName string) error {
+	monitoringInventoryPath := app.GetMonitoringInventoryDir(clusterName)
+	if !utils.DirectoryExists(monitoringInventoryPath) {
+		return nil
+	}
+	monitoringHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(monitoringInventoryPath)
+	if err != nil {
+		return err
+	}
+	if len(monitoringHosts) == 0 {
+		return nil
+	}
+	defer disconnectHosts(monitoringHosts)
+	avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err := getPrometheusTargets(clusterName)
+	if err != nil {
+		return err
+	}
+	return updateMonitoringHost(clusterName, monitoringHosts[0], avalancheGoPorts, machinePorts, ltPorts, relayerPorts)
+}
+
+// updateMonitoringHost sets the prometheus targets and the alert rules of the cluster
+// on its monitoring host
+var updateMonitoringHost = func(
+	clusterName string,
+	monitoringHost *models.Host,
+	avalancheGoPorts []string,
+	machinePorts []string,
+	ltPorts []string,
+	relayerPorts []string,
+) error {
+	if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts, relayerPorts); err != nil {
+		return err
+	}
+	return updateClusterAlertRules(clusterName, monitoringHost)
+}
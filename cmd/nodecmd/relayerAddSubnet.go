@@ -0,0 +1,55 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+func newRelayerAddSubnetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add-subnet [clusterName] [subnetName]",
+		Short: "(ALPHA Warning) Adds a synced Subnet to the AWM relayer of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node relayer add-subnet command adds a teleporter-ready Subnet, already synced to the
+cluster, as both source and destination of the cluster AWM relayer, and restarts it.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE:         relayerAddSubnet,
+	}
+}
+
+func relayerAddSubnet(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	subnetName := args[1]
+	relayerHost, err := getClusterRelayerHost(clusterName)
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts([]*models.Host{relayerHost})
+	clusterConfig, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(clusterConfig.Subnets, subnetName) {
+		return fmt.Errorf("subnet %s is not synced to cluster %s. sync it with 'node sync %s %s'", subnetName, clusterName, clusterName, subnetName)
+	}
+	sc, err := app.LoadSidecar(subnetName)
+	if err != nil {
+		return err
+	}
+	if !sc.TeleporterReady || sc.Networks[clusterConfig.Network.Name()].TeleporterMessengerAddress == "" {
+		return fmt.Errorf("subnet %s has no teleporter deployed on %s", subnetName, clusterConfig.Network.Name())
+	}
+	if err := updateClusterRelayer(clusterName, relayerHost, []string{subnetName}); err != nil {
+		return err
+	}
+	ux.Logger.GreenCheckmarkToUser("Subnet %s added to the AWM Relayer of cluster %s", subnetName, clusterName)
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/node"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var relayerHostCloudID string
+
+func newRelayerSetupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "setup [clusterName]",
+		Short: "(ALPHA Warning) Sets up the AWM relayer service of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node relayer setup command installs the AWM relayer service on a cluster host, configures
+it for every teleporter-ready Subnet synced to the cluster, and starts it. Relayer metrics
+are added to the cluster Prometheus config, if the cluster has monitoring.
+
+If the cluster already has a relayer and --host points to a different cluster host, the
+relayer is moved there: the service is stopped on the old host and its config is kept.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         setupRelayer,
+	}
+	cmd.Flags().StringVar(&relayerHostCloudID, "host", "", "cloud ID of the cluster host to run the relayer on")
+	cmd.Flags().StringVar(&awsProfile, "aws-profile", constants.AWSDefaultCredential, "aws profile to use")
+	return cmd
+}
+
+func setupRelayer(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	clusterConfig, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	currentHost, err := node.GetAWMRelayerHost(app, clusterName)
+	if err != nil {
+		return err
+	}
+	var relayerHost *models.Host
+	switch {
+	case relayerHostCloudID != "":
+		relayerHost, err = node.GetHostWithCloudID(app, clusterName, relayerHostCloudID)
+		if err != nil {
+			return err
+		}
+		if relayerHost == nil {
+			return fmt.Errorf("host %s not found on cluster %s", relayerHostCloudID, clusterName)
+		}
+	case currentHost != nil:
+		relayerHost = currentHost
+	default:
+		relayerHost, err = chooseAWMRelayerHost(clusterName)
+		if err != nil {
+			return err
+		}
+	}
+	hosts := []*models.Host{relayerHost}
+	if currentHost != nil {
+		hosts = append(hosts, currentHost)
+	}
+	defer disconnectHosts(hosts)
+	if currentHost == nil || currentHost.GetCloudID() != relayerHost.GetCloudID() {
+		if currentHost != nil {
+			if err := unsetAWMRelayerHost(currentHost, relayerHost); err != nil {
+				return err
+			}
+		}
+		if err := setAWMRelayerHost(relayerHost); err != nil {
+			return err
+		}
+		if err := setAWMRelayerSecurityGroupRule(clusterName, relayerHost); err != nil {
+			return err
+		}
+	}
+	if err := updateClusterRelayer(clusterName, relayerHost, clusterConfig.Subnets); err != nil {
+		return err
+	}
+	if err := updateClusterPrometheusTargets(clusterName); err != nil {
+		ux.Logger.PrintToUser(logging.Yellow.Wrap("failure adding relayer metrics to prometheus: %s"), err)
+	}
+	ux.Logger.PrintToUser("")
+	ux.Logger.GreenCheckmarkToUser("AWM Relayer is running on %s", relayerHost.GetCloudID())
+	return nil
+}
+
+// unsetAWMRelayerHost stops the relayer service on [oldHost] and hands its config over
+// to [newHost], so manual additions to it are kept
+func unsetAWMRelayerHost(oldHost *models.Host, newHost *models.Host) error {
+	oldCloudID := oldHost.GetCloudID()
+	ux.Logger.PrintToUser("moving AWM Relayer from host %s to host %s", oldCloudID, newHost.GetCloudID())
+	if err := ssh.RunSSHStopAWMRelayerService(oldHost); err != nil {
+		app.Log.Warn("failed stopping relayer service", zap.String("host", oldCloudID), zap.Error(err))
+	}
+	nodeConfig, err := app.LoadClusterNodeConfig(oldCloudID)
+	if err != nil {
+		return err
+	}
+	nodeConfig.IsAWMRelayer = false
+	if err := app.CreateNodeCloudConfigFile(oldCloudID, &nodeConfig); err != nil {
+		return err
+	}
+	oldConfigPath := app.GetAWMRelayerServiceConfigPath(app.GetNodeInstanceDirPath(oldCloudID))
+	newConfigPath := app.GetAWMRelayerServiceConfigPath(app.GetNodeInstanceDirPath(newHost.GetCloudID()))
+	if !utils.FileExists(oldConfigPath) || utils.FileExists(newConfigPath) {
+		return nil
+	}
+	bs, err := os.ReadFile(oldConfigPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(app.GetAWMRelayerServiceDir(app.GetNodeInstanceDirPath(newHost.GetCloudID())), constants.DefaultPerms755); err != nil {
+		return err
+	}
+	return os.WriteFile(newConfigPath, bs, constants.WriteReadUserOnlyPerms)
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var relayerLogLines int
+
+func newRelayerStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [clusterName]",
+		Short: "(ALPHA Warning) Shows the health of the AWM relayer service of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node relayer status command prints the state of the AWM relayer service, whether its
+metrics endpoint is answering, and the blockchains it is configured to relay between.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         relayerStatus,
+	}
+}
+
+func newRelayerLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs [clusterName]",
+		Short: "(ALPHA Warning) Prints the logs of the AWM relayer service of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node relayer logs command prints the last lines of the AWM relayer service journal.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         relayerLogs,
+	}
+	cmd.Flags().IntVar(&relayerLogLines, "lines", 100, "number of log lines to print")
+	return cmd
+}
+
+func relayerStatus(_ *cobra.Command, args []string) error {
+	relayerHost, err := getClusterRelayerHost(args[0])
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts([]*models.Host{relayerHost})
+	serviceState, _ := relayerHost.Command("systemctl is-active awm-relayer", nil, constants.SSHScriptTimeout)
+	metricsState := "down"
+	metricsCmd := fmt.Sprintf("curl -sf -o /dev/null http://localhost:%d/metrics && echo up", constants.AWMRelayerMetricsPort)
+	if out, err := relayerHost.Command(metricsCmd, nil, constants.SSHScriptTimeout); err == nil && strings.TrimSpace(string(out)) == "up" {
+		metricsState = "up"
+	}
+	ux.Logger.PrintToUser("AWM Relayer host: %s (%s)", relayerHost.GetCloudID(), relayerHost.IP)
+	ux.Logger.PrintToUser("Service: %s", strings.TrimSpace(string(serviceState)))
+	ux.Logger.PrintToUser("Metrics: %s (port %d)", metricsState, constants.AWMRelayerMetricsPort)
+	configPath := app.GetAWMRelayerServiceConfigPath(app.GetNodeInstanceDirPath(relayerHost.GetCloudID()))
+	config, err := teleporter.LoadRelayerConfig(configPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			ux.Logger.PrintToUser("No relayer config found")
+			return nil
+		}
+		return err
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Blockchain ID", "Subnet ID", "RPC Endpoint"})
+	table.SetRowLine(true)
+	for _, source := range config.SourceBlockchains {
+		table.Append([]string{source.BlockchainID, source.SubnetID, source.RPCEndpoint})
+	}
+	table.Render()
+	return nil
+}
+
+func relayerLogs(_ *cobra.Command, args []string) error {
+	relayerHost, err := getClusterRelayerHost(args[0])
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts([]*models.Host{relayerHost})
+	out, err := relayerHost.Command(
+		fmt.Sprintf("journalctl -u awm-relayer --no-pager -n %d", relayerLogLines),
+		nil,
+		constants.SSHScriptTimeout,
+	)
+	if err != nil {
+		return fmt.Errorf("failed getting relayer logs: %w: %s", err, string(out))
+	}
+	fmt.Print(string(out))
+	return nil
+}
@@ -0,0 +1,275 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/keychain"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/prompts"
+	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/ixAnkit/cryft/pkg/txutils"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/spf13/cobra"
+)
+
+var removeSubnetAuthKeys []string
+
+func newRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove [clusterName] [node]",
+		Short: "(ALPHA Warning) Remove a node from a cluster and terminate it",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node remove command removes a single node, given by cloud ID, IP or NodeID,
+from a cluster.
+
+If the node validates any of the cluster subnets, it is first removed from their
+validator sets, paying the fees with --key, --ledger or --ewoq. The cloud instance
+is then terminated, releasing its static IP, the node is dropped from the cluster
+and its inventory, and the cluster monitoring targets are updated.
+
+Existing hosts are only detached from the cluster, the servers themselves are
+left untouched.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE:         removeNode,
+	}
+	cmd.Flags().StringVarP(&keyName, "key", "k", "", "select the key to use [tahoe/devnet only]")
+	cmd.Flags().BoolVarP(&useLedger, "ledger", "g", false, "use ledger instead of key (always true on mainnet, defaults to false on tahoe/devnet)")
+	cmd.Flags().BoolVarP(&useEwoq, "ewoq", "e", false, "use ewoq key [tahoe/devnet only]")
+	cmd.Flags().StringSliceVar(&ledgerAddresses, "ledger-addrs", []string{}, "use the given ledger addresses")
+	cmd.Flags().StringSliceVar(&removeSubnetAuthKeys, "subnet-auth-keys", nil, "control keys that will be used to authenticate the remove validator txs")
+	cmd.Flags().BoolVarP(&authorizeAll, "authorize-all", "y", false, "authorize all CLI requests")
+	cmd.Flags().StringVar(&awsProfile, "aws-profile", constants.AWSDefaultCredential, "aws profile to use")
+	return cmd
+}
+
+func removeNode(_ *cobra.Command, args []string) error {
+	return removeClusterNode(args[0], args[1])
+}
+
+// removeClusterNode removes [node], given by cloud ID, IP or NodeID, from its subnets and
+// the cluster, and terminates it
+func removeClusterNode(clusterName string, node string) error {
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	inventoryPath := app.GetAnsibleInventoryDirPath(clusterName)
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(inventoryPath)
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	host, err := getRemoveHost(clusterName, clusterConf, hosts, node)
+	if err != nil {
+		return err
+	}
+	cloudID := host.GetCloudID()
+	nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+	if err != nil {
+		return err
+	}
+	nodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID))
+	if err != nil {
+		return err
+	}
+	if !authorizeAll {
+		yes, err := app.Prompt.CaptureYesNo(fmt.Sprintf("Node %s[%s] will be removed from cluster %s and terminated. Do you want to proceed?", cloudID, nodeID, clusterName))
+		if err != nil {
+			return err
+		}
+		if !yes {
+			return errors.New("abort avalanche node remove command")
+		}
+	}
+	if !clusterConf.IsAPIHost(cloudID) {
+		if err := removeNodeFromSubnets(clusterConf, nodeID); err != nil {
+			return err
+		}
+	}
+
+	if err := checkCloudAccess(nodeConfig.CloudService); err != nil {
+		return err
+	}
+	provider, err := getCloudProvider(nodeConfig.CloudService)
+	if err != nil {
+		return err
+	}
+	if err := provider.DestroyNode(nodeConfig, clusterName); err != nil {
+		if isExpiredCredentialError(err) {
+			ux.Logger.PrintToUser("")
+			printExpiredCredentialsOutput(awsProfile)
+			return nil
+		}
+		if !errors.Is(err, cloud.ErrNodeNotFoundToBeRunning) {
+			return fmt.Errorf("failed to terminate node %s: %w", cloudID, err)
+		}
+		ux.Logger.PrintToUser("node %s is already destroyed", cloudID)
+	}
+	if provider.Name() == constants.AWSCloudService {
+		cloudSecurityGroupList, err := getCloudSecurityGroupList(clusterConf.GetCloudIDs())
+		if err != nil {
+			return err
+		}
+		for _, sg := range utils.Filter(cloudSecurityGroupList, func(sg regionSecurityGroup) bool { return sg.cloud == nodeConfig.CloudService }) {
+			if err = provider.RemoveFirewallRule(sg.region, sg.securityGroup, nodeConfig.ElasticIP, []int{constants.AvalanchegoMachineMetricsPort, constants.AvalanchegoAPIPort}); err != nil {
+				ux.Logger.RedXToUser("unable to delete IP address %s from security group %s in region %s due to %s, please delete it manually",
+					nodeConfig.ElasticIP, sg.securityGroup, sg.region, err.Error())
+			}
+		}
+	}
+	ux.Logger.PrintToUser("Node instance %s in cluster %s successfully destroyed!", cloudID, clusterName)
+
+	if err := removeHostFromCluster(clusterName, clusterConf, hosts, cloudID); err != nil {
+		return err
+	}
+	ux.Logger.GreenCheckmarkToUser("Node %s[%s] removed from cluster %s", cloudID, nodeID, clusterName)
+	return nil
+}
+
+// getRemoveHost returns the cluster host matching [node], failing if it is the monitoring
+// host, or if removing it would leave the cluster without nodes or validators
+func getRemoveHost(clusterName string, clusterConf models.ClusterConfig, hosts []*models.Host, node string) (*models.Host, error) {
+	if clusterConf.MonitoringInstance != "" {
+		monitoringHosts := []*models.Host{}
+		if monitoringInventoryPath := app.GetMonitoringInventoryDir(clusterName); utils.DirectoryExists(monitoringInventoryPath) {
+			var err error
+			monitoringHosts, err = ansible.GetInventoryFromAnsibleInventoryFile(monitoringInventoryPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		isMonitoringHost := node == clusterConf.MonitoringInstance
+		for _, monitoringHost := range monitoringHosts {
+			isMonitoringHost = isMonitoringHost || node == monitoringHost.IP
+		}
+		if isMonitoringHost {
+			return nil, fmt.Errorf("node %s is the monitoring host of cluster %s and can't be removed", node, clusterName)
+		}
+	}
+	host, err := getMigrateHost(hosts, node)
+	if err != nil {
+		return nil, err
+	}
+	cloudID := host.GetCloudID()
+	if len(hosts) == 1 {
+		return nil, fmt.Errorf("node %s is the last node of cluster %s, use avalanche node destroy instead", cloudID, clusterName)
+	}
+	if !clusterConf.IsAPIHost(cloudID) && len(clusterConf.GetValidatorHosts(hosts)) == 1 {
+		return nil, fmt.Errorf("node %s is the last validator of cluster %s, use avalanche node destroy instead", cloudID, clusterName)
+	}
+	return host, nil
+}
+
+// removeHostFromCluster drops the terminated host [cloudID] from the cluster config, the
+// cluster inventory and the monitoring targets, and deletes its local files
+func removeHostFromCluster(clusterName string, clusterConf models.ClusterConfig, hosts []*models.Host, cloudID string) error {
+	clusterConf.RemoveHost(cloudID)
+	if err := app.SetClusterConfig(clusterName, clusterConf); err != nil {
+		return err
+	}
+	inventoryPath := app.GetAnsibleInventoryDirPath(clusterName)
+	if err := ansible.WriteInventory(inventoryPath, utils.Filter(hosts, func(h *models.Host) bool { return h.GetCloudID() != cloudID })); err != nil {
+		return err
+	}
+	if err := removeDeletedNodeDirectory(cloudID); err != nil {
+		return err
+	}
+	if err := updateClusterPrometheusTargets(clusterName); err != nil {
+		ux.Logger.RedXToUser("unable to update monitoring targets of cluster %s due to %s", clusterName, err)
+	}
+	return nil
+}
+
+// removeNodeFromSubnets removes [nodeID] from the validator set of each cluster
+// subnet it validates
+func removeNodeFromSubnets(clusterConf models.ClusterConfig, nodeID ids.NodeID) error {
+	network := clusterConf.Network
+	var (
+		kc       *keychain.Keychain
+		deployer *subnet.PublicDeployer
+	)
+	for _, subnetName := range clusterConf.Subnets {
+		sc, err := app.LoadSidecar(subnetName)
+		if err != nil {
+			return err
+		}
+		subnetID := sc.Networks[network.Name()].SubnetID
+		if subnetID == ids.Empty {
+			continue
+		}
+		isValidator, err := subnet.IsSubnetValidator(subnetID, nodeID, network)
+		if err != nil {
+			return fmt.Errorf("failed to check if node %s is a validator of subnet %s: %w", nodeID, subnetName, err)
+		}
+		if !isValidator {
+			continue
+		}
+		if kc == nil {
+			kc, err = keychain.GetKeychainFromCmdLineFlags(
+				app,
+				constants.PayTxsFeesMsg,
+				network,
+				keyName,
+				useEwoq,
+				useLedger,
+				ledgerAddresses,
+				network.GenesisParams().TxFee*uint64(len(clusterConf.Subnets)),
+			)
+			if err != nil {
+				return err
+			}
+			deployer = subnet.NewPublicDeployer(app, kc, network)
+		}
+		transferSubnetOwnershipTxID := sc.Networks[network.Name()].TransferSubnetOwnershipTxID
+		controlKeys, threshold, err := txutils.GetOwners(network, subnetID, transferSubnetOwnershipTxID)
+		if err != nil {
+			return err
+		}
+		if err := kc.AddAddresses(controlKeys); err != nil {
+			return err
+		}
+		kcKeys, err := kc.PChainFormattedStrAddresses()
+		if err != nil {
+			return err
+		}
+		subnetAuthKeys := removeSubnetAuthKeys
+		if subnetAuthKeys != nil {
+			if err := prompts.CheckSubnetAuthKeys(kcKeys, subnetAuthKeys, controlKeys, threshold); err != nil {
+				return err
+			}
+		} else {
+			subnetAuthKeys, err = prompts.GetSubnetAuthKeys(app.Prompt, kcKeys, controlKeys, threshold)
+			if err != nil {
+				return err
+			}
+		}
+		ux.Logger.PrintToUser("Removing node %s from the validators of subnet %s", nodeID, subnetName)
+		isFullySigned, _, _, err := deployer.RemoveValidator(
+			controlKeys,
+			subnetAuthKeys,
+			subnetID,
+			transferSubnetOwnershipTxID,
+			nodeID,
+		)
+		if err != nil {
+			return err
+		}
+		if !isFullySigned {
+			return fmt.Errorf("removing node %s from subnet %s requires more signatures, use avalanche subnet removeValidator and run this command again once the tx is committed", nodeID, subnetName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRemoveTestCluster saves the ssh test cluster with its node and monitoring inventories
+func setupRemoveTestCluster(t *testing.T) (models.ClusterConfig, []*models.Host) {
+	require := require.New(t)
+	clusterConf, hosts, _ := setupSSHTestCluster(t)
+	for _, host := range hosts {
+		host.SSHUser = constants.AnsibleSSHUser
+	}
+	require.NoError(app.WriteClustersConfigFile(&models.ClustersConfig{
+		Clusters: map[string]models.ClusterConfig{"cluster": clusterConf},
+	}))
+	require.NoError(ansible.WriteInventory(app.GetAnsibleInventoryDirPath("cluster"), hosts[:3]))
+	require.NoError(ansible.WriteInventory(app.GetMonitoringInventoryDir("cluster"), hosts[3:]))
+	return clusterConf, hosts[:3]
+}
+
+func TestGetRemoveHost(t *testing.T) {
+	clusterConf, hosts := setupRemoveTestCluster(t)
+	oneValidatorConf := clusterConf
+	oneValidatorConf.APINodes = []string{"i-2", "i-3"}
+	tests := []struct {
+		name          string
+		clusterConf   models.ClusterConfig
+		hosts         []*models.Host
+		node          string
+		expected      string
+		expectedError string
+	}{
+		{name: "validator", clusterConf: clusterConf, hosts: hosts, node: "i-2", expected: "i-2"},
+		{name: "api node", clusterConf: clusterConf, hosts: hosts, node: hosts[2].IP, expected: "i-3"},
+		{
+			name:          "monitoring host by cloud id",
+			clusterConf:   clusterConf,
+			hosts:         hosts,
+			node:          "i-mon",
+			expectedError: "node i-mon is the monitoring host of cluster cluster and can't be removed",
+		},
+		{
+			name:          "monitoring host by ip",
+			clusterConf:   clusterConf,
+			hosts:         hosts,
+			node:          "10.0.0.4",
+			expectedError: "node 10.0.0.4 is the monitoring host of cluster cluster and can't be removed",
+		},
+		{
+			name:          "node not in the cluster",
+			clusterConf:   clusterConf,
+			hosts:         hosts,
+			node:          "i-9",
+			expectedError: `node "i-9" not found`,
+		},
+		{
+			name:          "last node",
+			clusterConf:   clusterConf,
+			hosts:         hosts[:1],
+			node:          "i-1",
+			expectedError: "node i-1 is the last node of cluster cluster, use avalanche node destroy instead",
+		},
+		{
+			name:          "last validator",
+			clusterConf:   oneValidatorConf,
+			hosts:         hosts,
+			node:          "i-1",
+			expectedError: "node i-1 is the last validator of cluster cluster, use avalanche node destroy instead",
+		},
+		{name: "api node next to the last validator", clusterConf: oneValidatorConf, hosts: hosts, node: "i-2", expected: "i-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			host, err := getRemoveHost("cluster", tt.clusterConf, tt.hosts, tt.node)
+			if tt.expectedError != "" {
+				require.EqualError(err, tt.expectedError)
+				return
+			}
+			require.NoError(err)
+			require.Equal(tt.expected, host.GetCloudID())
+		})
+	}
+}
+
+func TestRemoveHostFromCluster(t *testing.T) {
+	require := require.New(t)
+	ux.NewUserLog(logging.NoLog{}, io.Discard)
+	clusterConf, hosts := setupRemoveTestCluster(t)
+	defaultUpdateMonitoringHost := updateMonitoringHost
+	t.Cleanup(func() { updateMonitoringHost = defaultUpdateMonitoringHost })
+	updatedHosts, avalancheGoTargets, machineTargets := []string{}, []string{}, []string{}
+	updateMonitoringHost = func(_ string, monitoringHost *models.Host, avalancheGoPorts, machinePorts, _, _ []string) error {
+		updatedHosts = append(updatedHosts, monitoringHost.GetCloudID())
+		avalancheGoTargets, machineTargets = avalancheGoPorts, machinePorts
+		return nil
+	}
+
+	require.NoError(removeHostFromCluster("cluster", clusterConf, hosts, "i-2"))
+	updatedClusterConf, err := app.GetClusterConfig("cluster")
+	require.NoError(err)
+	require.Equal([]string{"i-1", "i-3"}, updatedClusterConf.Nodes)
+	require.Equal([]string{"i-3"}, updatedClusterConf.APINodes)
+	require.Equal("i-mon", updatedClusterConf.MonitoringInstance)
+	inventoryHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath("cluster"))
+	require.NoError(err)
+	require.Equal([]string{"i-1", "i-3"}, hostCloudIDs(inventoryHosts))
+	require.False(utils.DirectoryExists(app.GetNodeInstanceDirPath("i-2")))
+	// the monitoring host only scrapes the remaining nodes
+	require.Equal([]string{"i-mon"}, updatedHosts)
+	expectedTargets := func(port int) []string {
+		return []string{
+			fmt.Sprintf("'%s:%s'", hosts[0].IP, strconv.Itoa(port)),
+			fmt.Sprintf("'%s:%s'", hosts[2].IP, strconv.Itoa(port)),
+		}
+	}
+	require.Equal(expectedTargets(constants.AvalanchegoAPIPort), avalancheGoTargets)
+	require.Equal(expectedTargets(constants.AvalanchegoMachineMetricsPort), machineTargets)
+
+	// monitoring update failures don't fail the removal
+	updateMonitoringHost = func(string, *models.Host, []string, []string, []string, []string) error {
+		return errors.New("monitoring host unreachable")
+	}
+	clusterConf.RemoveHost("i-2")
+	require.NoError(removeHostFromCluster("cluster", clusterConf, inventoryHosts, "i-3"))
+	inventoryHosts, err = ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath("cluster"))
+	require.NoError(err)
+	require.Equal([]string{"i-1"}, hostCloudIDs(inventoryHosts))
+}
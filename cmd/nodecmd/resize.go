@@ -4,20 +4,16 @@ package nodecmd
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 	"strings"
 
 	"github.com/ixAnkit/cryft/pkg/ansible"
-	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
-	gcpAPI "github.com/ixAnkit/cryft/pkg/cloud/gcp"
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/ssh"
 	"github.com/ixAnkit/cryft/pkg/utils"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/spf13/cobra"
-	"golang.org/x/net/context"
 )
 
 var diskSize string
@@ -31,6 +27,9 @@ func newResizeCmd() *cobra.Command {
 The node resize command can be used to resize cluster instance size 
 and/or size of the permanent storage attached to the instance. In another words, it can 
 change amount of CPU, memory and disk space available for the cluster nodes.
+
+--estimate prints the cost of the cluster after resizing, compared to its
+current cost, and exits without resizing it.
 `,
 		SilenceUsage: true,
 		Args:         cobra.MinimumNArgs(1),
@@ -39,6 +38,7 @@ change amount of CPU, memory and disk space available for the cluster nodes.
 	cmd.Flags().StringVar(&nodeType, "node-type", "", "Node type to resize (e.g. t3.2xlarge)")
 	cmd.Flags().StringVar(&diskSize, "disk-size", "", "Disk size to resize in Gb (e.g. 1000Gb)")
 	cmd.Flags().StringVar(&awsProfile, "aws-profile", constants.AWSDefaultCredential, "aws profile to use")
+	cmd.Flags().BoolVar(&estimateCost, "estimate", false, "print the estimated cost of the cluster after resizing, without resizing it")
 	return cmd
 }
 
@@ -77,6 +77,13 @@ func resize(_ *cobra.Command, args []string) error {
 	nodesToResize := utils.Filter(clusterNodes, func(node string) bool {
 		return node != monitoringNode
 	})
+	if estimateCost {
+		newDiskSize := 0
+		if diskSize != "" {
+			newDiskSize, _ = strconv.Atoi(strings.TrimSuffix(diskSize, "Gb"))
+		}
+		return estimateResizeCost(clusterName, nodesToResize, newDiskSize)
+	}
 
 	if nodeType != "" {
 		ux.Logger.PrintLineSeparator()
@@ -104,14 +111,14 @@ func resize(_ *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		if !(authorizeAccess || authorizedAccessFromSettings()) && (requestCloudAuth(nodeConfig.CloudService) != nil) {
-			return fmt.Errorf("cloud access is required")
+		if err := checkCloudAccess(nodeConfig.CloudService); err != nil {
+			return err
 		}
 		spinSession := ux.NewUserSpinner()
 		// resize node and disk. If error occurs, log it and continue to next host
 		if nodeType != "" {
 			spinner := spinSession.SpinToUser(utils.ScriptLog(nodeConfig.NodeID, "Resizing Instance Type"))
-			if err := resizeNode(nodeConfig); err != nil {
+			if err := resizeNode(&nodeConfig); err != nil {
 				ux.SpinFailWithError(spinner, "", err)
 			} else {
 				ux.SpinComplete(spinner)
@@ -120,7 +127,7 @@ func resize(_ *cobra.Command, args []string) error {
 		if diskSize != "" {
 			spinner := spinSession.SpinToUser(utils.ScriptLog(nodeConfig.NodeID, "Resizing Disk"))
 			diskSizeGb, _ := strconv.Atoi(strings.TrimSuffix(diskSize, "Gb"))
-			if err := resizeDisk(nodeConfig, diskSizeGb); err != nil {
+			if err := resizeDisk(&nodeConfig, diskSizeGb); err != nil {
 				ux.SpinFailWithError(spinner, "", err)
 			} else if err := ssh.RunSSHUpsizeRootDisk(host); err != nil {
 				ux.SpinFailWithError(spinner, "", err)
@@ -134,77 +141,34 @@ func resize(_ *cobra.Command, args []string) error {
 }
 
 // resizeDisk resizes the disk size of the node
-func resizeDisk(nodeConfig models.NodeConfig, diskSize int) error {
-	if diskSize > math.MaxInt32 {
-		return fmt.Errorf("disk size exceeds maximum supported value")
+func resizeDisk(nodeConfig *models.NodeConfig, diskSize int) error {
+	provider, err := getCloudProvider(nodeConfig.CloudService)
+	if err != nil {
+		return err
 	}
-	switch nodeConfig.CloudService {
-	case "", constants.AWSCloudService:
-		ec2Svc, err := awsAPI.NewAwsCloud(awsProfile, nodeConfig.Region)
-		if err != nil {
-			return err
-		}
-		rootVolume, err := ec2Svc.GetRootVolumeID(nodeConfig.NodeID)
-		if err != nil {
-			return err
-		}
-		return ec2Svc.ResizeVolume(rootVolume, int32(diskSize))
-	case constants.GCPCloudService:
-		gcpClient, projectName, _, err := getGCPCloudCredentials()
-		if err != nil {
-			return err
-		}
-		gcpCloud, err := gcpAPI.NewGcpCloud(gcpClient, projectName, context.Background())
-		if err != nil {
-			return err
-		}
-		rootVolume, err := gcpCloud.GetRootVolumeID(nodeConfig.NodeID, nodeConfig.Region)
-		if err != nil {
-			return err
-		}
-		if diskSize > math.MaxInt {
-			return fmt.Errorf("disk size exceeds maximum supported value")
-		}
-		return gcpCloud.ResizeVolume(rootVolume, nodeConfig.Region, int64(diskSize))
-	default:
-		return fmt.Errorf("cloud service %s is not supported", nodeConfig.CloudService)
+	if err := provider.ResizeRootVolume(*nodeConfig, diskSize); err != nil {
+		return err
 	}
+	nodeConfig.VolumeSize = diskSize
+	return app.CreateNodeCloudConfigFile(nodeConfig.NodeID, nodeConfig)
 }
 
 // resizeNode changes the node type of the instance
-func resizeNode(nodeConfig models.NodeConfig) error {
-	switch nodeConfig.CloudService {
-	case "", constants.AWSCloudService:
-		ec2Svc, err := awsAPI.NewAwsCloud(awsProfile, nodeConfig.Region)
-		if err != nil {
-			return err
-		}
-		isSupported, err := ec2Svc.IsInstanceTypeSupported(nodeType)
-		if err != nil {
-			return err
-		}
-		if !isSupported {
-			return fmt.Errorf("instance type %s is not supported", nodeType)
-		}
-		return ec2Svc.ChangeInstanceType(nodeConfig.NodeID, nodeType)
-	case constants.GCPCloudService:
-		gcpClient, projectName, _, err := getGCPCloudCredentials()
-		if err != nil {
-			return err
-		}
-		gcpCloud, err := gcpAPI.NewGcpCloud(gcpClient, projectName, context.Background())
-		if err != nil {
-			return err
-		}
-		isSupported, err := gcpCloud.IsInstanceTypeSupported(nodeType, nodeConfig.Region)
-		if err != nil {
-			return err
-		}
-		if !isSupported {
-			return fmt.Errorf("instance type %s is not supported", nodeType)
-		}
-		return gcpCloud.ChangeInstanceType(nodeConfig.NodeID, nodeConfig.Region, nodeType)
-	default:
-		return fmt.Errorf("cloud service %s is not supported", nodeConfig.CloudService)
+func resizeNode(nodeConfig *models.NodeConfig) error {
+	provider, err := getCloudProvider(nodeConfig.CloudService)
+	if err != nil {
+		return err
+	}
+	isSupported, err := provider.IsInstanceTypeSupported(nodeConfig.Region, nodeType)
+	if err != nil {
+		return err
+	}
+	if !isSupported {
+		return fmt.Errorf("instance type %s is not supported", nodeType)
+	}
+	if err := provider.ChangeInstanceType(*nodeConfig, nodeType); err != nil {
+		return err
 	}
+	nodeConfig.InstanceType = nodeType
+	return app.CreateNodeCloudConfigFile(nodeConfig.NodeID, nodeConfig)
 }
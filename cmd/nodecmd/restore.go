@@ -0,0 +1,241 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/snapshot"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+const restoreStagingDir = "restore"
+
+var (
+	restoreNode        string
+	restoreWithStaking bool
+)
+
+func newRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [clusterName] [snapshot]",
+		Short: "(ALPHA Warning) Seed a node of a cluster from a snapshot",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node restore command seeds the avalanchego database of --node from a snapshot taken
+with avalanche node backup, so it doesn't need to bootstrap from scratch. [snapshot] is
+the snapshot manifest, given as a local path or s3://bucket/prefix/name.json.
+
+The archive is streamed to the node and extracted into a staging dir, and is only put in
+place after its SHA256 checksum matches the manifest. The node keeps its own identity
+unless --with-staking is given, in which case the snapshot staking files are restored too,
+decrypted with the passphrase given to avalanche node backup. That is refused if the
+snapshot identity belongs to another node of the cluster, so two hosts never run the same
+identity at once.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE:         restore,
+	}
+	cmd.Flags().StringVar(&restoreNode, "node", "", "node (cloud ID, IP or NodeID) to restore the snapshot to")
+	cmd.Flags().BoolVar(&restoreWithStaking, "with-staking", false, "also restore the snapshot staking files, giving the node the snapshot identity")
+	addSnapshotStoreFlags(cmd)
+	addSnapshotPassphraseFlag(cmd)
+	return cmd
+}
+
+func restore(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if restoreNode == "" {
+		return fmt.Errorf("--node must be given")
+	}
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	location, name := snapshot.SplitLocation(args[1])
+	store, err := snapshot.NewStore(location, snapshotS3Options)
+	if err != nil {
+		return err
+	}
+	manifest, err := loadSnapshotManifest(store, name)
+	if err != nil {
+		return err
+	}
+	if manifest.Network != clusterConf.Network.Name() {
+		return fmt.Errorf("snapshot %s was taken on %s, but cluster %s is on %s", manifest.Name, manifest.Network, clusterName, clusterConf.Network.Name())
+	}
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	host, err := getMigrateHost(hosts, restoreNode)
+	if err != nil {
+		return err
+	}
+	var passphrase []byte
+	if restoreWithStaking {
+		switch {
+		case manifest.StakingArchive != "":
+			if passphrase, err = getSnapshotPassphrase(); err != nil {
+				return err
+			}
+		case !slices.Contains(manifest.Contents, constants.CloudNodeStakingDir):
+			return fmt.Errorf("snapshot %s does not include staking files", manifest.Name)
+		}
+		for _, h := range hosts {
+			if h.GetCloudID() == host.GetCloudID() {
+				continue
+			}
+			if nodeID, err := getNodeID(app.GetNodeInstanceDirPath(h.GetCloudID())); err == nil && nodeID.String() == manifest.NodeID {
+				return fmt.Errorf("snapshot identity %s belongs to node %s of the cluster, use avalanche node migrate to move it", manifest.NodeID, h.GetCloudID())
+			}
+		}
+	}
+	ux.Logger.PrintToUser("Restoring snapshot %s (avalanchego %s, P-Chain height %d) to node %s", logging.LightBlue.Wrap(manifest.Name), manifest.AvalancheGoVersion, manifest.PChainHeight, host.GetCloudID())
+	if resp, err := ssh.RunSSHCheckAvalancheGoVersion(host); err == nil {
+		if version, _, err := parseAvalancheGoOutput(resp); err == nil && version != manifest.AvalancheGoVersion {
+			ux.Logger.PrintToUser(logging.Yellow.Wrap(fmt.Sprintf("Node runs avalanchego %s, snapshot was taken with %s", version, manifest.AvalancheGoVersion)))
+		}
+	}
+	spinSession := ux.NewUserSpinner()
+	defer spinSession.Stop()
+
+	spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Streaming snapshot %s", manifest.Archive))
+	if err := stageSnapshot(host, store, manifest); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		_, _ = host.Command(fmt.Sprintf("rm -rf %s", restoreStagingPath()), nil, constants.SSHScriptTimeout)
+		return err
+	}
+	if restoreWithStaking && manifest.StakingArchive != "" {
+		if err := stageStakingArchive(host, store, manifest.StakingArchive, passphrase); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			_, _ = host.Command(fmt.Sprintf("rm -rf %s", restoreStagingPath()), nil, constants.SSHScriptTimeout)
+			return err
+		}
+	}
+	ux.SpinComplete(spinner)
+
+	spinner = spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Replacing database and restarting avalanchego"))
+	if err := ssh.RunSSHStopNode(host); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if out, err := host.Command(restoreSwapCommand(restoreWithStaking), nil, constants.SSHLongRunningScriptTimeout); err != nil {
+		err = fmt.Errorf("failed to put snapshot in place: %w: %s", err, strings.TrimSpace(string(out)))
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if err := ssh.RunSSHStartNode(host); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	ux.SpinComplete(spinner)
+	spinSession.Stop()
+	if restoreWithStaking {
+		if err := ssh.RunSSHDownloadStakingFiles(host, app.GetNodeInstanceDirPath(host.GetCloudID())); err != nil {
+			return err
+		}
+	}
+	ux.Logger.GreenCheckmarkToUser("Snapshot %s restored to node %s", manifest.Name, host.GetCloudID())
+	ux.Logger.PrintToUser("Use avalanche node status %s to follow the node bootstrap", clusterName)
+	return nil
+}
+
+func loadSnapshotManifest(store snapshot.Store, name string) (*snapshot.Manifest, error) {
+	r, err := store.Get(snapshot.ManifestName(name))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return snapshot.ReadManifest(r)
+}
+
+func restoreStagingPath() string {
+	return constants.CloudNodeConfigBasePath + restoreStagingDir
+}
+
+// stageSnapshot streams the snapshot archive into the staging dir of the host,
+// verifying its checksum against the manifest
+func stageSnapshot(host *models.Host, store snapshot.Store, manifest *snapshot.Manifest) error {
+	archive, err := store.Get(manifest.Archive)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	r := io.TeeReader(archive, io.MultiWriter(hasher, counter))
+	stagingPath := restoreStagingPath()
+	extractCmd := fmt.Sprintf("rm -rf %[1]s && mkdir -p %[1]s && tar -xzf - -C %[1]s", stagingPath)
+	if err := host.PipeSSHCommand(extractCmd, r, io.Discard, snapshotTimeout); err != nil {
+		return err
+	}
+	// tar may not read the archive trailing padding
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	if counter.n != manifest.Size {
+		return fmt.Errorf("snapshot archive size %d does not match manifest size %d", counter.n, manifest.Size)
+	}
+	if checksum := hex.EncodeToString(hasher.Sum(nil)); checksum != manifest.SHA256 {
+		return fmt.Errorf("snapshot archive checksum %s does not match manifest checksum %s", checksum, manifest.SHA256)
+	}
+	return nil
+}
+
+// stageStakingArchive decrypts the staking files archive [name] with [passphrase] and
+// extracts it into the staging dir of the host
+func stageStakingArchive(host *models.Host, store snapshot.Store, name string, passphrase []byte) error {
+	r, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	encrypted, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	archive, err := snapshot.Decrypt(encrypted, passphrase)
+	if err != nil {
+		return err
+	}
+	extractCmd := fmt.Sprintf("tar -xzf - -C %s", restoreStagingPath())
+	return host.PipeSSHCommand(extractCmd, bytes.NewReader(archive), io.Discard, constants.SSHScriptTimeout)
+}
+
+// restoreSwapCommand replaces the node database, and optionally staking files, with the
+// staged ones. The replaced staking files are kept next to the new ones
+func restoreSwapCommand(withStaking bool) string {
+	cmds := []string{
+		fmt.Sprintf("cd %s", constants.CloudNodeConfigBasePath),
+		fmt.Sprintf("rm -rf %s.old", constants.CloudNodeDBDir),
+		fmt.Sprintf("if [ -d %[1]s ]; then mv %[1]s %[1]s.old; fi", constants.CloudNodeDBDir),
+		fmt.Sprintf("mv %s/%s %s", restoreStagingDir, constants.CloudNodeDBDir, constants.CloudNodeDBDir),
+	}
+	if withStaking {
+		cmds = append(cmds,
+			fmt.Sprintf("rm -rf %s.old", constants.CloudNodeStakingDir),
+			fmt.Sprintf("if [ -d %[1]s ]; then mv %[1]s %[1]s.old; fi", constants.CloudNodeStakingDir),
+			fmt.Sprintf("mv %s/%s %s", restoreStagingDir, constants.CloudNodeStakingDir, constants.CloudNodeStakingDir),
+		)
+	}
+	cmds = append(cmds, fmt.Sprintf("rm -rf %s %s.old", restoreStagingDir, constants.CloudNodeDBDir))
+	return strings.Join(cmds, " && ")
+}
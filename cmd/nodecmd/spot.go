@@ -0,0 +1,301 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+)
+
+// getInterruptedSpotNodes returns the configs of the spot nodes of the cluster whose
+// instance is no longer running
+func getInterruptedSpotNodes(clusterConf models.ClusterConfig) ([]models.NodeConfig, error) {
+	interrupted := []models.NodeConfig{}
+	var provider cloud.Provider
+	for _, cloudID := range clusterConf.Nodes {
+		nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+		if err != nil {
+			return nil, err
+		}
+		if !nodeConfig.IsSpot {
+			continue
+		}
+		if provider == nil {
+			if err := checkCloudAccess(nodeConfig.CloudService); err != nil {
+				return nil, err
+			}
+			if provider, err = getCloudProvider(nodeConfig.CloudService); err != nil {
+				return nil, err
+			}
+		}
+		isRunning, err := provider.IsInstanceRunning(nodeConfig.Region, cloudID)
+		if err != nil {
+			return nil, err
+		}
+		if !isRunning {
+			interrupted = append(interrupted, nodeConfig)
+		}
+	}
+	return interrupted, nil
+}
+
+// hasSpotNodes tells if any node of the cluster runs on a spot instance
+func hasSpotNodes(clusterConf models.ClusterConfig) (bool, error) {
+	for _, cloudID := range clusterConf.Nodes {
+		nodeConfig, err := app.LoadClusterNodeConfig(cloudID)
+		if err != nil {
+			return false, err
+		}
+		if nodeConfig.IsSpot {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// warnSpotInterruptions warns about the interrupted spot instances of the cluster. Cloud
+// access is not requested, and failing to check them is only warned about
+func warnSpotInterruptions(clusterName string) {
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		ux.Logger.RedXToUser("Unable to check spot instances of cluster %s: %s", clusterName, err)
+		return
+	}
+	if hasSpot, err := hasSpotNodes(clusterConf); err != nil || !hasSpot {
+		return
+	}
+	if !(authorizeAccess || authorizedAccessFromSettings()) {
+		ux.Logger.PrintToUser(logging.Yellow.Wrap(fmt.Sprintf("Spot instances of cluster %s are not checked for interruptions, as cloud access is not authorized", clusterName)))
+		return
+	}
+	interrupted, err := getInterruptedSpotNodes(clusterConf)
+	if err != nil {
+		ux.Logger.RedXToUser("Unable to check spot instances of cluster %s: %s", clusterName, err)
+		return
+	}
+	for _, nodeConfig := range interrupted {
+		ux.Logger.PrintToUser(logging.Yellow.Wrap(fmt.Sprintf("Spot instance %s in %s was interrupted", nodeConfig.NodeID, nodeConfig.Region)))
+	}
+	if len(interrupted) > 0 {
+		ux.Logger.PrintToUser("Use avalanche node status %s --recreate-spot to re-create them with the same staking keys", clusterName)
+	}
+}
+
+// recreateInterruptedSpotNodes re-creates the interrupted spot instances of the cluster
+// with the same staking keys
+func recreateInterruptedSpotNodes(clusterName string) error {
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	interrupted, err := getInterruptedSpotNodes(clusterConf)
+	if err != nil {
+		return err
+	}
+	if len(interrupted) == 0 {
+		ux.Logger.PrintToUser("No interrupted spot instances found in cluster %s", clusterName)
+		return nil
+	}
+	for _, nodeConfig := range interrupted {
+		if err := recreateSpotNode(clusterName, nodeConfig); err != nil {
+			return fmt.Errorf("failed to re-create node %s: %w", nodeConfig.NodeID, err)
+		}
+	}
+	return nil
+}
+
+// recreateSpotNode creates a new spot instance in place of the interrupted one, with
+// its volume, max price and expiry, keeping its static IP if any, and sets it up with the staking
+// keys of the interrupted node
+func recreateSpotNode(clusterName string, oldNodeConfig models.NodeConfig) error {
+	oldCloudID := oldNodeConfig.NodeID
+	if oldNodeConfig.InstanceType == "" {
+		return fmt.Errorf("instance type of node %s is unknown", oldCloudID)
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	// the tags of the interrupted instance are kept for a while, so its expiry is found too
+	expiresAt, err := getClusterExpiry(clusterName)
+	if err != nil {
+		return err
+	}
+	provider := awsAPI.NewProvider(awsProfile)
+	prefix, err := defaultAvalancheCLIPrefix(oldNodeConfig.Region)
+	if err != nil {
+		return err
+	}
+	// nodes created before their volume was saved get the node create defaults
+	volume := oldNodeConfig
+	if volume.VolumeSize == 0 {
+		volume.VolumeSize = constants.CloudServerStorageSize
+	}
+	if volume.VolumeType == "" {
+		volume.VolumeType = constants.AWSVolumeTypeGP3
+		volume.VolumeIOPS = constants.AWSGP3DefaultIOPS
+		volume.VolumeThroughput = constants.AWSGP3DefaultThroughput
+	}
+	ux.Logger.PrintToUser("Re-creating spot instance of node %s in AWS[%s]...", oldCloudID, oldNodeConfig.Region)
+	instanceIDs, err := provider.CreateInstances(oldNodeConfig.Region, 1, cloud.InstanceSpec{
+		Prefix:        prefix,
+		ImageID:       oldNodeConfig.AMI,
+		InstanceType:  oldNodeConfig.InstanceType,
+		KeyPairName:   oldNodeConfig.KeyPair,
+		SecurityGroup: oldNodeConfig.SecurityGroup,
+		VolumeSize:    volume.VolumeSize,
+		VolumeType:    volume.VolumeType,
+		IOPS:          volume.VolumeIOPS,
+		Throughput:    volume.VolumeThroughput,
+		Spot:          true,
+		SpotMaxPrice:  oldNodeConfig.SpotMaxPrice,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	newCloudID := instanceIDs[0]
+	if err := provider.WaitForInstances(oldNodeConfig.Region, instanceIDs); err != nil {
+		return err
+	}
+	publicIP := ""
+	if oldNodeConfig.UseStaticIP && oldNodeConfig.ElasticIP != "" {
+		// the static IP outlives the interrupted instance, so the node keeps its address
+		ec2Svc, err := provider.Cloud(oldNodeConfig.Region)
+		if err != nil {
+			return err
+		}
+		allocationID, err := ec2Svc.GetEIPAllocationID(oldNodeConfig.ElasticIP)
+		if err != nil {
+			return err
+		}
+		if err := ec2Svc.AssociateEIP(newCloudID, allocationID); err != nil {
+			return err
+		}
+		publicIP = oldNodeConfig.ElasticIP
+	} else {
+		publicIPs, err := provider.GetInstancePublicIPs(oldNodeConfig.Region, instanceIDs)
+		if err != nil {
+			return err
+		}
+		publicIP = publicIPs[newCloudID]
+	}
+
+	// move the local node dir, with its staking files, to the new instance
+	if err := os.Rename(app.GetNodeInstanceDirPath(oldCloudID), app.GetNodeInstanceDirPath(newCloudID)); err != nil {
+		return err
+	}
+	newNodeConfig := oldNodeConfig
+	newNodeConfig.NodeID = newCloudID
+	newNodeConfig.ElasticIP = publicIP
+	// the new instance is provisioned from scratch
+	newNodeConfig.ProvisionedSteps = nil
+	if err := app.CreateNodeCloudConfigFile(newCloudID, &newNodeConfig); err != nil {
+		return err
+	}
+	clusterConf.ReplaceHost(oldCloudID, newCloudID)
+	if err := app.SetClusterConfig(clusterName, clusterConf); err != nil {
+		return err
+	}
+	inventoryPath := app.GetAnsibleInventoryDirPath(clusterName)
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(inventoryPath)
+	if err != nil {
+		return err
+	}
+	if err := ansible.WriteInventory(inventoryPath, utils.Filter(hosts, func(h *models.Host) bool { return h.GetCloudID() != oldCloudID })); err != nil {
+		return err
+	}
+	if err := ansible.CreateAnsibleHostInventory(inventoryPath, oldNodeConfig.CertPath, constants.AWSCloudService, map[string]string{newCloudID: publicIP}, nil); err != nil {
+		return err
+	}
+	hosts, err = ansible.GetInventoryFromAnsibleInventoryFile(inventoryPath)
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	host, err := getMigrateHost(hosts, newCloudID)
+	if err != nil {
+		return err
+	}
+	if failedHosts := waitForHosts([]*models.Host{host}); failedHosts.Len() > 0 {
+		return fmt.Errorf("instance %s failed to provision: %w", newCloudID, failedHosts.GetErrorHostMap()[host.NodeID])
+	}
+	return setupRecreatedNode(clusterName, clusterConf, host, utils.Filter(hosts, func(h *models.Host) bool { return h.GetCloudID() != newCloudID }))
+}
+
+// setupRecreatedNode installs avalanchego on [host] with the staking files kept in its
+// node dir, running the same version as the [otherHosts] of the cluster
+func setupRecreatedNode(clusterName string, clusterConf models.ClusterConfig, host *models.Host, otherHosts []*models.Host) error {
+	avalancheGoVersion, err := getClusterAvalancheGoVersion(clusterName, otherHosts)
+	if err != nil {
+		return err
+	}
+	cloudID := host.GetCloudID()
+	spinSession := ux.NewUserSpinner()
+	defer spinSession.Stop()
+	spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Setup Node"))
+	if err := ssh.RunSSHUploadStakingFiles(host, filepath.Join(app.GetNodesDir(), cloudID)); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if err := ssh.RunSSHSetupBuildEnv(host); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	if err := ssh.RunSSHSetupNode(host, app.Conf.GetConfigPath(), avalancheGoVersion, remoteCLIVersion, clusterConf.Network.Kind == models.Devnet); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	ux.SpinComplete(spinner)
+	if clusterConf.MonitoringInstance != "" {
+		spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, "Setup Metrics and Logging"))
+		monitoringNodeConfig, err := app.LoadClusterNodeConfig(clusterConf.MonitoringInstance)
+		if err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		nodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID))
+		if err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		if err := ssh.RunSSHSetupMachineMetrics(host); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		if err := ssh.RunSSHSetupPromtail(host); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		if err := ssh.RunSSHUpdatePromtailConfig(host, monitoringNodeConfig.ElasticIP, constants.AvalanchegoLokiPort, cloudID, nodeID.String()); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		ux.SpinComplete(spinner)
+	}
+	spinSession.Stop()
+	if clusterConf.Network.Kind == models.Devnet {
+		if err := joinDevnet(clusterName, []*models.Host{host}); err != nil {
+			return err
+		}
+	}
+	if err := updateClusterPrometheusTargets(clusterName); err != nil {
+		ux.Logger.RedXToUser("unable to update monitoring targets of cluster %s due to %s", clusterName, err)
+	}
+	ux.Logger.GreenCheckmarkToUser("Node re-created on instance %s, it is now bootstrapping", cloudID)
+	if len(clusterConf.Subnets) > 0 {
+		ux.Logger.PrintToUser("Use avalanche node sync %s <subnetName> to track the cluster subnets again", clusterName)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@
 package nodecmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -17,9 +19,28 @@ import (
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/MetalBlockchain/metalgo/utils/logging"
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
 )
 
-var isParallel bool
+var (
+	isParallel     bool
+	sshNodes       []string
+	sshRole        string
+	sshMaxParallel int
+	sshJSONOutput  bool
+)
+
+// sshCommandResult is the outcome of running a command on a host
+type sshCommandResult struct {
+	CloudID  string   `json:"cloudID"`
+	NodeID   string   `json:"nodeID,omitempty"`
+	IP       string   `json:"ip"`
+	Roles    []string `json:"roles,omitempty"`
+	ExitCode int      `json:"exitCode"`
+	Stdout   string   `json:"stdout"`
+	Stderr   string   `json:"stderr"`
+	Error    string   `json:"error,omitempty"`
+}
 
 func newSSHCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -31,17 +52,36 @@ The node ssh command execute a given command [cmd] using ssh on all nodes in the
 If no command is given, just prints the ssh command to be used to connect to each node in the cluster.
 For provided NodeID or InstanceID or IP, the command [cmd] will be executed on that node.
 If no [cmd] is provided for the node, it will open ssh shell there.
+
+With --parallel the command runs on the cluster nodes concurrently, at most
+--max-parallel at a time, and the output of each node is collected. Nodes with
+identical output and exit code are grouped together in the summary. --nodes and
+--role restrict the command to some nodes of the cluster, as does --selector
+with the node labels. --json prints the
+stdout, stderr and exit code of each node as JSON instead, and implies --parallel.
+The command exits with a non-zero code if it fails on any node.
 `,
 		SilenceUsage: true,
 		Args:         cobra.MinimumNArgs(0),
 		RunE:         sshNode,
 	}
 	cmd.Flags().BoolVar(&isParallel, "parallel", false, "run ssh command on all nodes in parallel")
+	cmd.Flags().StringSliceVar(&sshNodes, "nodes", []string{}, "comma separated list of nodes (cloud ID, IP or NodeID) to run the command on. defaults to all cluster nodes")
+	cmd.Flags().StringVar(&sshRole, "role", "", "only run the command on nodes with the given role (validator, api or monitor)")
+	cmd.Flags().IntVar(&sshMaxParallel, "max-parallel", 10, "maximum number of nodes running the command at the same time")
+	cmd.Flags().BoolVar(&sshJSONOutput, "json", false, "print the output and exit code of each node as JSON")
+	addSelectorFlag(cmd)
 	return cmd
 }
 
 func sshNode(_ *cobra.Command, args []string) error {
 	var err error
+	if sshMaxParallel < 1 {
+		return fmt.Errorf("--max-parallel must be at least 1")
+	}
+	if sshJSONOutput {
+		isParallel = true
+	}
 	clustersConfig := models.ClustersConfig{}
 	if app.ClustersConfigExists() {
 		clustersConfig, err = app.LoadClustersConfig()
@@ -82,6 +122,10 @@ func sshNode(_ *cobra.Command, args []string) error {
 					}
 					clusterHosts = append(clusterHosts, monitoringHosts...)
 				}
+				clusterHosts, err = selectSSHHosts(clustersConfig.Clusters[clusterNameOrNodeID], clusterHosts)
+				if err != nil {
+					return err
+				}
 				return sshHosts(clusterHosts, cmd, clustersConfig.Clusters[clusterNameOrNodeID])
 			}
 		} else {
@@ -119,28 +163,93 @@ func sshNode(_ *cobra.Command, args []string) error {
 }
 
 func printNodeInfo(host *models.Host, clusterConf models.ClusterConfig, result string) error {
-	nodeConfig, err := app.LoadClusterNodeConfig(host.GetCloudID())
+	hostInfo, err := getSSHHostInfo(host, clusterConf)
 	if err != nil {
 		return err
 	}
-	nodeIDStr := "----------------------------------------"
-	if clusterConf.IsAvalancheGoHost(host.GetCloudID()) {
-		nodeID, err := getNodeID(app.GetNodeInstanceDirPath(host.GetCloudID()))
+	ux.Logger.PrintToUser("  %s %s", formatSSHHostInfo(hostInfo), result)
+	return nil
+}
+
+// getSSHHostInfo returns the identification of a host, without command results
+func getSSHHostInfo(host *models.Host, clusterConf models.ClusterConfig) (sshCommandResult, error) {
+	hostInfo := sshCommandResult{CloudID: host.GetCloudID(), IP: host.IP}
+	nodeConfig, err := app.LoadClusterNodeConfig(hostInfo.CloudID)
+	if err != nil {
+		return hostInfo, err
+	}
+	if clusterConf.IsAvalancheGoHost(hostInfo.CloudID) {
+		nodeID, err := getNodeID(app.GetNodeInstanceDirPath(hostInfo.CloudID))
 		if err != nil {
-			return err
+			return hostInfo, err
 		}
-		nodeIDStr = nodeID.String()
+		hostInfo.NodeID = nodeID.String()
 	}
-	roles := clusterConf.GetHostRoles(nodeConfig)
-	rolesStr := strings.Join(roles, ",")
+	hostInfo.Roles = clusterConf.GetHostRoles(nodeConfig)
+	return hostInfo, nil
+}
+
+func formatSSHHostInfo(hostInfo sshCommandResult) string {
+	nodeIDStr := hostInfo.NodeID
+	if nodeIDStr == "" {
+		nodeIDStr = "----------------------------------------"
+	}
+	rolesStr := strings.Join(hostInfo.Roles, ",")
 	if rolesStr != "" {
 		rolesStr = " [" + rolesStr + "]"
 	}
-	ux.Logger.PrintToUser("  [Node %s (%s) %s%s] %s", host.GetCloudID(), nodeIDStr, nodeConfig.ElasticIP, rolesStr, result)
-	return nil
+	return fmt.Sprintf("[Node %s (%s) %s%s]", hostInfo.CloudID, nodeIDStr, hostInfo.IP, rolesStr)
+}
+
+// selectSSHHosts filters the cluster hosts by the --role and --nodes flags
+func selectSSHHosts(clusterConf models.ClusterConfig, hosts []*models.Host) ([]*models.Host, error) {
+	switch {
+	case sshRole == "":
+	case strings.EqualFold(sshRole, constants.ValidatorRole):
+		hosts = utils.Filter(clusterConf.GetValidatorHosts(hosts), func(h *models.Host) bool { return clusterConf.IsAvalancheGoHost(h.GetCloudID()) })
+	case strings.EqualFold(sshRole, constants.APIRole):
+		hosts = clusterConf.GetAPIHosts(hosts)
+	case strings.EqualFold(sshRole, constants.MonitorRole):
+		hosts = utils.Filter(hosts, func(h *models.Host) bool { return h.GetCloudID() == clusterConf.MonitoringInstance })
+	default:
+		return nil, fmt.Errorf("invalid role %q, expected %s, %s or %s", sshRole, constants.ValidatorRole, constants.APIRole, constants.MonitorRole)
+	}
+	if len(sshNodes) > 0 {
+		selectedHosts := []*models.Host{}
+		for _, node := range sshNodes {
+			found := false
+			for _, host := range hosts {
+				hostInfo, err := getSSHHostInfo(host, clusterConf)
+				if err != nil {
+					return nil, err
+				}
+				if slices.Contains([]string{hostInfo.CloudID, hostInfo.IP, hostInfo.NodeID}, node) {
+					found = true
+					if !slices.Contains(selectedHosts, host) {
+						selectedHosts = append(selectedHosts, host)
+					}
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("node %q not found", node)
+			}
+		}
+		hosts = selectedHosts
+	}
+	hosts, err := filterHostsBySelector(hosts)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no nodes selected")
+	}
+	return hosts, nil
 }
 
 func sshHosts(hosts []*models.Host, cmd string, clusterConf models.ClusterConfig) error {
+	if cmd != "" && isParallel {
+		return sshHostsFanOut(hosts, cmd, clusterConf)
+	}
 	if cmd != "" {
 		// execute cmd
 		wg := sync.WaitGroup{}
@@ -179,17 +288,6 @@ func sshHosts(hosts []*models.Host, cmd string, clusterConf models.ClusterConfig
 		if wgResults.HasErrors() {
 			return fmt.Errorf("failed to ssh node(s) %s", wgResults.GetErrorHostMap())
 		}
-		if isParallel {
-			for hostID, result := range wgResults.GetResultMap() {
-				for _, host := range hosts {
-					if host.GetCloudID() == hostID {
-						if err := printNodeInfo(host, clusterConf, fmt.Sprintf("%v", result)); err != nil {
-							ux.Logger.RedXToUser("Error getting node %s info due to : %s", host.GetCloudID(), err)
-						}
-					}
-				}
-			}
-		}
 	} else {
 		// open shell
 		switch {
@@ -215,6 +313,141 @@ func sshHosts(hosts []*models.Host, cmd string, clusterConf models.ClusterConfig
 	return nil
 }
 
+// runSSHHostCommand runs [cmd] on [host] with the ssh client, returning its stdout and stderr
+var runSSHHostCommand = func(host *models.Host, cmd string) (string, string, error) {
+	splitCmdLine := strings.Split(utils.GetSSHConnectionString(host.IP, host.SSHPrivateKeyPath), " ")
+	splitCmdLine = append(splitCmdLine, cmd)
+	sshCmd := exec.Command(splitCmdLine[0], splitCmdLine[1:]...)
+	sshCmd.Env = os.Environ()
+	outBuf, errBuf := utils.SetupRealtimeCLIOutput(sshCmd, false, false)
+	err := sshCmd.Run()
+	return outBuf.String(), errBuf.String(), err
+}
+
+// sshHostsFanOut runs [cmd] on the hosts, at most --max-parallel at a time, and prints
+// a summary of the results grouped by output, or the results as JSON
+func sshHostsFanOut(hosts []*models.Host, cmd string, clusterConf models.ClusterConfig) error {
+	wg := sync.WaitGroup{}
+	wgResults := models.NodeResults{}
+	semaphore := make(chan struct{}, sshMaxParallel)
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, host *models.Host) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			result, err := getSSHHostInfo(host, clusterConf)
+			if err != nil {
+				nodeResults.AddResult(host.GetCloudID(), result, err)
+				return
+			}
+			result.Stdout, result.Stderr, err = runSSHHostCommand(host, cmd)
+			var exitErr *exec.ExitError
+			switch {
+			case errors.As(err, &exitErr):
+				result.ExitCode = exitErr.ExitCode()
+			case err != nil:
+				result.ExitCode = -1
+				result.Error = err.Error()
+			}
+			if err != nil {
+				nodeResults.AddResult(host.GetCloudID(), result, err)
+				return
+			}
+			nodeResults.AddResult(host.GetCloudID(), result, nil)
+		}(&wgResults, host)
+	}
+	wg.Wait()
+	resultMap := wgResults.GetResultMap()
+	results := []sshCommandResult{}
+	for _, host := range hosts {
+		result, ok := resultMap[host.GetCloudID()].(sshCommandResult)
+		if !ok {
+			continue
+		}
+		if err := wgResults.GetErrorHostMap()[host.GetCloudID()]; err != nil && result.Error == "" && result.ExitCode == 0 {
+			result.ExitCode = -1
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	if sshJSONOutput {
+		resultsBytes, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(resultsBytes))
+	} else {
+		printSSHResultsSummary(results)
+	}
+	failedHosts := utils.Filter(results, func(result sshCommandResult) bool { return result.ExitCode != 0 })
+	if len(failedHosts) > 0 {
+		return fmt.Errorf("command failed on %d of %d node(s): %s",
+			len(failedHosts),
+			len(results),
+			strings.Join(utils.Map(failedHosts, func(result sshCommandResult) string { return result.CloudID }), ", "),
+		)
+	}
+	return nil
+}
+
+// sshResultGroup are the hosts with identical command output and exit code
+type sshResultGroup struct {
+	result sshCommandResult
+	hosts  []sshCommandResult
+}
+
+// groupSSHResults groups the command results by output and exit code, keeping the
+// order in which each output first appears
+func groupSSHResults(results []sshCommandResult) []*sshResultGroup {
+	groups := []*sshResultGroup{}
+	for _, result := range results {
+		var group *sshResultGroup
+		for _, g := range groups {
+			if g.result.ExitCode == result.ExitCode && g.result.Stdout == result.Stdout && g.result.Stderr == result.Stderr && g.result.Error == result.Error {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			group = &sshResultGroup{result: result}
+			groups = append(groups, group)
+		}
+		group.hosts = append(group.hosts, result)
+	}
+	return groups
+}
+
+// printSSHResultsSummary prints the command results, grouping the hosts with identical
+// output and exit code
+func printSSHResultsSummary(results []sshCommandResult) {
+	for _, group := range groupSSHResults(results) {
+		status := logging.Green.Wrap(fmt.Sprintf("exit code %d", group.result.ExitCode))
+		if group.result.ExitCode != 0 {
+			status = logging.Red.Wrap(fmt.Sprintf("exit code %d", group.result.ExitCode))
+		}
+		ux.Logger.PrintToUser("%d node(s) with %s:", len(group.hosts), status)
+		for _, hostInfo := range group.hosts {
+			ux.Logger.PrintToUser("  %s", formatSSHHostInfo(hostInfo))
+		}
+		if group.result.Error != "" {
+			ux.Logger.PrintToUser("  error: %s", group.result.Error)
+		}
+		for _, output := range []string{group.result.Stdout, group.result.Stderr} {
+			output = strings.TrimRight(output, "\n")
+			if output == "" {
+				continue
+			}
+			for _, line := range strings.Split(output, "\n") {
+				ux.Logger.PrintToUser("    %s", line)
+			}
+		}
+		ux.Logger.PrintToUser("")
+	}
+	failed := len(utils.Filter(results, func(result sshCommandResult) bool { return result.ExitCode != 0 }))
+	ux.Logger.PrintToUser("Command succeeded on %d node(s), failed on %d node(s)", len(results)-failed, failed)
+}
+
 func printClusterConnectionString(clusterName string, networkName string) error {
 	ux.Logger.PrintToUser("Cluster: %s (%s)", logging.LightBlue.Wrap(clusterName), logging.Green.Wrap(networkName))
 	clusterHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
@@ -0,0 +1,297 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSSHTestCluster creates a cluster with validators i-1 and i-2, API node i-3 and
+// separate monitoring host i-mon, and returns its config, hosts and avalanchego node IDs
+func setupSSHTestCluster(t *testing.T) (models.ClusterConfig, []*models.Host, map[string]string) {
+	require := require.New(t)
+	app = application.New()
+	app.Setup(t.TempDir(), logging.NoLog{}, nil, nil, nil)
+	sshRole, sshNodes, nodeSelector, sshMaxParallel, sshJSONOutput = "", nil, "", 10, false
+	clusterConf := models.ClusterConfig{
+		Nodes:              []string{"i-1", "i-2", "i-3"},
+		APINodes:           []string{"i-3"},
+		MonitoringInstance: "i-mon",
+	}
+	hosts := []*models.Host{}
+	nodeIDs := map[string]string{}
+	for i, cloudID := range []string{"i-1", "i-2", "i-3", "i-mon"} {
+		require.NoError(app.CreateNodeCloudConfigFile(cloudID, &models.NodeConfig{
+			NodeID:       cloudID,
+			CloudService: constants.AWSCloudService,
+			IsMonitor:    cloudID == clusterConf.MonitoringInstance,
+		}))
+		if cloudID != clusterConf.MonitoringInstance {
+			nodeDir := app.GetNodeInstanceDirPath(cloudID)
+			nodeID, err := generateNodeCertAndKeys(
+				filepath.Join(nodeDir, constants.StakerCertFileName),
+				filepath.Join(nodeDir, constants.StakerKeyFileName),
+				filepath.Join(nodeDir, constants.BLSKeyFileName),
+			)
+			require.NoError(err)
+			nodeIDs[cloudID] = nodeID.String()
+		}
+		hosts = append(hosts, &models.Host{
+			NodeID: constants.AWSNodeAnsiblePrefix + "_" + cloudID,
+			IP:     "10.0.0." + string(rune('1'+i)),
+		})
+	}
+	return clusterConf, hosts, nodeIDs
+}
+
+// captureUserLog redirects the user log to the returned buffer for the duration of the test
+func captureUserLog(t *testing.T) *bytes.Buffer {
+	out := &bytes.Buffer{}
+	defaultLogger := ux.Logger
+	t.Cleanup(func() { ux.Logger = defaultLogger })
+	ux.Logger = nil
+	ux.NewUserLog(logging.NoLog{}, out)
+	return out
+}
+
+func hostCloudIDs(hosts []*models.Host) []string {
+	cloudIDs := []string{}
+	for _, host := range hosts {
+		cloudIDs = append(cloudIDs, host.GetCloudID())
+	}
+	return cloudIDs
+}
+
+func TestSelectSSHHosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		role          string
+		nodes         func(nodeIDs map[string]string) []string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "all hosts",
+			expected: []string{"i-1", "i-2", "i-3", "i-mon"},
+		},
+		{
+			name:     "validators",
+			role:     "validator",
+			expected: []string{"i-1", "i-2"},
+		},
+		{
+			name:     "api nodes",
+			role:     constants.APIRole,
+			expected: []string{"i-3"},
+		},
+		{
+			name:     "monitoring host",
+			role:     constants.MonitorRole,
+			expected: []string{"i-mon"},
+		},
+		{
+			name:          "invalid role",
+			role:          "relayer",
+			expectedError: `invalid role "relayer"`,
+		},
+		{
+			name:     "nodes by cloud ID, IP and node ID",
+			nodes:    func(nodeIDs map[string]string) []string { return []string{"i-mon", "10.0.0.1", nodeIDs["i-3"]} },
+			expected: []string{"i-mon", "i-1", "i-3"},
+		},
+		{
+			name:     "repeated nodes are selected once",
+			nodes:    func(nodeIDs map[string]string) []string { return []string{"i-2", nodeIDs["i-2"], "10.0.0.2"} },
+			expected: []string{"i-2"},
+		},
+		{
+			name:          "unknown node",
+			nodes:         func(map[string]string) []string { return []string{"i-1", "i-9"} },
+			expectedError: `node "i-9" not found`,
+		},
+		{
+			name:     "nodes of the role",
+			role:     constants.ValidatorRole,
+			nodes:    func(map[string]string) []string { return []string{"i-2"} },
+			expected: []string{"i-2"},
+		},
+		{
+			name:          "nodes outside of the role",
+			role:          constants.ValidatorRole,
+			nodes:         func(map[string]string) []string { return []string{"i-3"} },
+			expectedError: `node "i-3" not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			clusterConf, hosts, nodeIDs := setupSSHTestCluster(t)
+			sshRole = tt.role
+			if tt.nodes != nil {
+				sshNodes = tt.nodes(nodeIDs)
+			}
+			selected, err := selectSSHHosts(clusterConf, hosts)
+			if tt.expectedError != "" {
+				require.ErrorContains(err, tt.expectedError)
+				return
+			}
+			require.NoError(err)
+			require.Equal(tt.expected, hostCloudIDs(selected))
+		})
+	}
+}
+
+func TestGroupSSHResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []sshCommandResult
+		expected [][]string
+	}{
+		{
+			name: "identical output",
+			results: []sshCommandResult{
+				{CloudID: "i-1", Stdout: "ok\n"},
+				{CloudID: "i-2", Stdout: "ok\n"},
+			},
+			expected: [][]string{{"i-1", "i-2"}},
+		},
+		{
+			name: "different output",
+			results: []sshCommandResult{
+				{CloudID: "i-1", Stdout: "a\n"},
+				{CloudID: "i-2", Stdout: "b\n"},
+				{CloudID: "i-3", Stdout: "a\n"},
+			},
+			expected: [][]string{{"i-1", "i-3"}, {"i-2"}},
+		},
+		{
+			name: "same output with different exit code, stderr or error",
+			results: []sshCommandResult{
+				{CloudID: "i-1", Stdout: "a\n"},
+				{CloudID: "i-2", Stdout: "a\n", ExitCode: 1},
+				{CloudID: "i-3", Stdout: "a\n", Stderr: "warning\n"},
+				{CloudID: "i-4", Stdout: "a\n", ExitCode: -1, Error: "connection refused"},
+				{CloudID: "i-5", Stdout: "a\n", ExitCode: 1},
+			},
+			expected: [][]string{{"i-1"}, {"i-2", "i-5"}, {"i-3"}, {"i-4"}},
+		},
+		{
+			name:     "no results",
+			expected: [][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := [][]string{}
+			for _, group := range groupSSHResults(tt.results) {
+				cloudIDs := []string{}
+				for _, result := range group.hosts {
+					cloudIDs = append(cloudIDs, result.CloudID)
+				}
+				groups = append(groups, cloudIDs)
+			}
+			require.Equal(t, tt.expected, groups)
+		})
+	}
+}
+
+func TestPrintSSHResultsSummary(t *testing.T) {
+	require := require.New(t)
+	out := captureUserLog(t)
+	printSSHResultsSummary([]sshCommandResult{
+		{CloudID: "i-1", IP: "10.0.0.1", Stdout: "v1.11.0\n"},
+		{CloudID: "i-2", IP: "10.0.0.2", Stdout: "v1.11.0\n"},
+		{CloudID: "i-3", IP: "10.0.0.3", ExitCode: 127, Stderr: "not found\n"},
+	})
+	summary := out.String()
+	require.Contains(summary, "2 node(s) with "+logging.Green.Wrap("exit code 0"))
+	require.Contains(summary, "1 node(s) with "+logging.Red.Wrap("exit code 127"))
+	require.Equal(1, bytes.Count(out.Bytes(), []byte("    v1.11.0")))
+	require.Contains(summary, "    not found")
+	require.Contains(summary, "Command succeeded on 2 node(s), failed on 1 node(s)")
+}
+
+// exitError returns the error of a local command exiting with [code]
+func exitError(code string) error {
+	return exec.Command("sh", "-c", "exit "+code).Run()
+}
+
+func TestSSHHostsFanOut(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxParallel     int
+		outputs         map[string]string
+		errors          map[string]error
+		expectedError   string
+		expectedSummary []string
+	}{
+		{
+			name:            "all succeed",
+			maxParallel:     10,
+			outputs:         map[string]string{"10.0.0.1": "ok", "10.0.0.2": "ok", "10.0.0.3": "ok", "10.0.0.4": "ok"},
+			expectedSummary: []string{"4 node(s) with", "Command succeeded on 4 node(s), failed on 0 node(s)"},
+		},
+		{
+			name:            "exit codes are propagated",
+			maxParallel:     2,
+			outputs:         map[string]string{"10.0.0.1": "ok", "10.0.0.3": "ok"},
+			errors:          map[string]error{"10.0.0.2": exitError("3"), "10.0.0.4": exitError("3")},
+			expectedError:   "command failed on 2 of 4 node(s): i-2, i-mon",
+			expectedSummary: []string{"2 node(s) with " + logging.Red.Wrap("exit code 3"), "Command succeeded on 2 node(s), failed on 2 node(s)"},
+		},
+		{
+			name:            "connection errors",
+			maxParallel:     1,
+			errors:          map[string]error{"10.0.0.1": errors.New("connection refused")},
+			expectedError:   "command failed on 1 of 4 node(s): i-1",
+			expectedSummary: []string{logging.Red.Wrap("exit code -1"), "error: connection refused"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			clusterConf, hosts, _ := setupSSHTestCluster(t)
+			sshMaxParallel = tt.maxParallel
+			out := captureUserLog(t)
+			lock := sync.Mutex{}
+			running, maxRunning := 0, 0
+			defaultRunSSHHostCommand := runSSHHostCommand
+			t.Cleanup(func() { runSSHHostCommand = defaultRunSSHHostCommand })
+			runSSHHostCommand = func(host *models.Host, cmd string) (string, string, error) {
+				require.Equal("uptime", cmd)
+				lock.Lock()
+				running++
+				maxRunning = max(maxRunning, running)
+				lock.Unlock()
+				defer func() {
+					lock.Lock()
+					running--
+					lock.Unlock()
+				}()
+				return tt.outputs[host.IP], "", tt.errors[host.IP]
+			}
+			err := sshHostsFanOut(hosts, "uptime", clusterConf)
+			if tt.expectedError != "" {
+				require.EqualError(err, tt.expectedError)
+			} else {
+				require.NoError(err)
+			}
+			require.LessOrEqual(maxRunning, tt.maxParallel)
+			for _, expected := range tt.expectedSummary {
+				require.Contains(out.String(), expected)
+			}
+		})
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ixAnkit/cryft/cmd/subnetcmd"
 	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/node"
 	"github.com/ixAnkit/cryft/pkg/ssh"
 	"github.com/ixAnkit/cryft/pkg/utils"
 	"github.com/ixAnkit/cryft/pkg/ux"
@@ -23,7 +26,10 @@ import (
 	"golang.org/x/exp/slices"
 )
 
-var subnetName string
+var (
+	subnetName         string
+	statusRecreateSpot bool
+)
 
 func newStatusCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -34,17 +40,27 @@ func newStatusCmd() *cobra.Command {
 The node status command gets the bootstrap status of all nodes in a cluster with the Primary Network. 
 If no cluster is given, defaults to node list behaviour.
 
-To get the bootstrap status of a node with a Subnet, use --subnet flag`,
+To get the bootstrap status of a node with a Subnet, use --subnet flag.
+--selector only shows the nodes whose labels match it.
+
+The time remaining of the Primary Network validation of each node is shown, and
+validations ending within --expiry-warning are flagged, as in node validate status.
+
+Interrupted AWS spot instances are warned about when cloud access is authorized.
+Use --recreate-spot to re-create them with the same staking keys.`,
 		SilenceUsage: true,
 		Args:         cobra.MinimumNArgs(0),
 		RunE:         statusNode,
 	}
 	cmd.Flags().StringVar(&subnetName, "subnet", "", "specify the subnet the node is syncing with")
+	cmd.Flags().BoolVar(&statusRecreateSpot, "recreate-spot", false, "re-create the interrupted spot instances with the same staking keys")
+	addExpiryWarningFlag(cmd)
+	addSelectorFlag(cmd)
 
 	return cmd
 }
 
-func statusNode(_ *cobra.Command, args []string) error {
+func statusNode(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
 		return list(nil, nil)
 	}
@@ -52,6 +68,17 @@ func statusNode(_ *cobra.Command, args []string) error {
 	if err := checkCluster(clusterName); err != nil {
 		return err
 	}
+	if statusRecreateSpot {
+		if err := recreateInterruptedSpotNodes(clusterName); err != nil {
+			return err
+		}
+	} else {
+		warnSpotInterruptions(clusterName)
+	}
+	threshold, err := getValidationExpiryWarning(cmd)
+	if err != nil {
+		return err
+	}
 	clusterConf, err := app.GetClusterConfig(clusterName)
 	if err != nil {
 		return err
@@ -68,6 +95,13 @@ func statusNode(_ *cobra.Command, args []string) error {
 		}
 	}
 	hostIDs := utils.Filter(clusterConf.GetCloudIDs(), clusterConf.IsAvalancheGoHost)
+	hostIDs, err = utils.FilterWithError(hostIDs, matchesNodeSelector)
+	if err != nil {
+		return err
+	}
+	if len(hostIDs) == 0 && nodeSelector != "" {
+		return fmt.Errorf("no nodes match selector %q", nodeSelector)
+	}
 	nodeIDs, err := utils.MapWithError(hostIDs, func(s string) (string, error) {
 		n, err := getNodeID(app.GetNodeInstanceDirPath(s))
 		return n.String(), err
@@ -87,6 +121,7 @@ func statusNode(_ *cobra.Command, args []string) error {
 		return err
 	}
 	defer disconnectHosts(hosts)
+	hosts = utils.Filter(hosts, func(h *models.Host) bool { return slices.Contains(hostIDs, h.GetCloudID()) })
 
 	notBootstrappedNodes, err := getNotBootstrappedNodes(hosts)
 	if err != nil {
@@ -178,9 +213,19 @@ func statusNode(_ *cobra.Command, args []string) error {
 			}
 		}
 	}
+	validations, err := getClusterValidations(clusterName, hostIDs)
+	if err != nil {
+		ux.Logger.RedXToUser("Unable to get the validations of cluster %s: %s", clusterName, err)
+	}
 	if clusterConf.MonitoringInstance != "" {
-		hostIDs = append(hostIDs, clusterConf.MonitoringInstance)
-		nodeIDs = append(nodeIDs, "")
+		matches, err := matchesNodeSelector(clusterConf.MonitoringInstance)
+		if err != nil {
+			return err
+		}
+		if matches {
+			hostIDs = append(hostIDs, clusterConf.MonitoringInstance)
+			nodeIDs = append(nodeIDs, "")
+		}
 	}
 	nodeConfigs := []models.NodeConfig{}
 	for _, hostID := range hostIDs {
@@ -203,7 +248,10 @@ func statusNode(_ *cobra.Command, args []string) error {
 		clusterName,
 		subnetName,
 		nodeConfigs,
+		validations,
+		threshold,
 	)
+	printExpiringValidations(clusterName, validations, threshold)
 	return nil
 }
 
@@ -220,7 +268,10 @@ func printOutput(
 	clusterName string,
 	subnetName string,
 	nodeConfigs []models.NodeConfig,
+	validations []node.Validation,
+	expiryWarning time.Duration,
 ) {
+	now := time.Now()
 	if subnetName == "" && len(notBootstrappedHosts) == 0 {
 		ux.Logger.PrintToUser("All nodes in cluster %s are bootstrapped to Primary Network!", clusterName)
 	}
@@ -237,7 +288,7 @@ func printOutput(
 	ux.Logger.PrintToUser(tit)
 	ux.Logger.PrintToUser(strings.Repeat("=", len(removeColors(tit))))
 	ux.Logger.PrintToUser("")
-	header := []string{"Cloud ID", "Node ID", "IP", "Network", "Role", "Avago Version", "Primary Network", "Healthy"}
+	header := []string{"Cloud ID", "Node ID", "IP", "Network", "Role", "Avago Version", "Primary Network", "Validation Ends In", "Healthy"}
 	if subnetName != "" {
 		header = append(header, "Subnet "+subnetName)
 	}
@@ -249,6 +300,7 @@ func printOutput(
 		healthyStatus := ""
 		nodeIDStr := ""
 		avagoVersion := ""
+		validationStatus := ""
 		roles := clusterConf.GetHostRoles(nodeConfigs[i])
 		if clusterConf.IsAvalancheGoHost(cloudID) {
 			boostrappedStatus = logging.Green.Wrap("BOOTSTRAPPED")
@@ -261,6 +313,15 @@ func printOutput(
 			}
 			nodeIDStr = nodeIDs[i]
 			avagoVersion = avagoVersions[cloudID]
+			validation, found := node.FindValidation(validations, cloudID, ids.Empty)
+			switch {
+			case validations == nil:
+				validationStatus = constants.NotAvailableLabel
+			case found:
+				validationStatus = formatValidationRemaining(validation, now, expiryWarning)
+			case slices.Contains(roles, constants.ValidatorRole):
+				validationStatus = logging.Red.Wrap("NOT_VALIDATING")
+			}
 		}
 		row := []string{
 			cloudID,
@@ -270,6 +331,7 @@ func printOutput(
 			strings.Join(roles, ","),
 			avagoVersion,
 			boostrappedStatus,
+			validationStatus,
 			healthyStatus,
 		}
 		if subnetName != "" {
@@ -34,6 +34,7 @@ You can check the subnet bootstrap status by calling avalanche node status <clus
 
 	cmd.Flags().StringSliceVar(&validators, "validators", []string{}, "sync subnet into given comma separated list of validators. defaults to all cluster nodes")
 	cmd.Flags().BoolVar(&avoidChecks, "no-checks", false, "do not check for bootstrapped/healthy status or rpc compatibility of nodes against subnet")
+	addSelectorFlag(cmd)
 
 	return cmd
 }
@@ -58,6 +59,10 @@ func syncSubnet(_ *cobra.Command, args []string) error {
 		}
 	}
 	defer disconnectHosts(hosts)
+	hosts, err = filterHostsBySelector(hosts)
+	if err != nil {
+		return err
+	}
 	if !avoidChecks {
 		if err := checkHostsAreBootstrapped(hosts); err != nil {
 			return err
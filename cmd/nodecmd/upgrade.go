@@ -4,9 +4,11 @@ package nodecmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ixAnkit/cryft/pkg/ansible"
 	"github.com/ixAnkit/cryft/pkg/binutils"
@@ -16,17 +18,28 @@ import (
 	"github.com/ixAnkit/cryft/pkg/utils"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/ixAnkit/cryft/pkg/vm"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/MetalBlockchain/metalgo/vms/platformvm/status"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
 
 type nodeUpgradeInfo struct {
-	AvalancheGoVersion    string   // avalanche go version to update to on cloud server
-	SubnetEVMVersion      string   // subnet EVM version to update to on cloud server
-	SubnetEVMIDsToUpgrade []string // list of ID of Subnet EVM to be upgraded to subnet EVM version to update to
+	AvalancheGoVersion        string            // avalanche go version to update to on cloud server
+	SubnetEVMVersion          string            // subnet EVM version to update to on cloud server
+	SubnetEVMIDsToUpgrade     []string          // list of ID of Subnet EVM to be upgraded to subnet EVM version to update to
+	CurrentAvalancheGoVersion string            // avalanche go version running on cloud server before the upgrade
+	CurrentSubnetEVMVersions  map[string]string // subnet EVM version of each VM ID running on cloud server before the upgrade
 }
 
+var (
+	rollingUpgrade       bool
+	maxUnavailable       int
+	rollbackOnFailure    bool
+	batchRecoveryTimeout time.Duration
+)
+
 func newUpgradeCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "upgrade",
@@ -36,12 +49,22 @@ func newUpgradeCmd() *cobra.Command {
 The node update command suite provides a collection of commands for nodes to update
 their avalanchego or VM version.
 
+With --rolling, nodes are upgraded in batches of at most --max-unavailable nodes.
+After each batch, the command waits until the upgraded nodes are bootstrapped,
+healthy and syncing the cluster subnets before continuing, and halts if a batch
+does not recover within --batch-timeout. With --rollback, the binaries of a
+batch that fails to recover are reverted to their previous versions.
+
 You can check the status after upgrade by calling avalanche node status`,
 		SilenceUsage: true,
 		Args:         cobra.ExactArgs(1),
 		RunE:         upgrade,
 	}
-
+	cmd.Flags().BoolVar(&rollingUpgrade, "rolling", false, "upgrade nodes in batches, waiting for each batch to recover before continuing")
+	cmd.Flags().IntVar(&maxUnavailable, "max-unavailable", 1, "maximum number of nodes upgraded at the same time on a rolling upgrade")
+	cmd.Flags().BoolVar(&rollbackOnFailure, "rollback", false, "roll back the binaries of a batch that fails to recover on a rolling upgrade")
+	cmd.Flags().DurationVar(&batchRecoveryTimeout, "batch-timeout", 10*time.Minute, "time to wait for a batch to be healthy, bootstrapped and synced on a rolling upgrade")
+	addSelectorFlag(cmd)
 	return cmd
 }
 
@@ -50,53 +73,285 @@ func upgrade(_ *cobra.Command, args []string) error {
 	if err := checkCluster(clusterName); err != nil {
 		return err
 	}
+	if !rollingUpgrade && (maxUnavailable != 1 || rollbackOnFailure) {
+		return fmt.Errorf("--max-unavailable and --rollback can only be used with --rolling")
+	}
+	if maxUnavailable < 1 {
+		return fmt.Errorf("--max-unavailable must be at least 1")
+	}
 	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
 	if err != nil {
 		return err
 	}
 	defer disconnectHosts(hosts)
+	hosts, err = filterHostsBySelector(hosts)
+	if err != nil {
+		return err
+	}
 	toUpgradeNodesMap, err := getNodesUpgradeInfo(hosts)
 	if err != nil {
 		return err
 	}
+	if rollingUpgrade {
+		return upgradeRolling(clusterName, hosts, toUpgradeNodesMap)
+	}
 	spinSession := ux.NewUserSpinner()
 	for host, upgradeInfo := range toUpgradeNodesMap {
-		if upgradeInfo.AvalancheGoVersion != "" {
-			spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, fmt.Sprintf("Upgrading avalanchego to version %s...", upgradeInfo.AvalancheGoVersion)))
-			if err := upgradeAvalancheGo(host, upgradeInfo.AvalancheGoVersion); err != nil {
-				ux.SpinFailWithError(spinner, "", err)
-				return err
-			}
-			ux.SpinComplete(spinner)
+		if err := upgradeHost(spinSession, host, upgradeInfo); err != nil {
+			return err
 		}
-		if upgradeInfo.SubnetEVMVersion != "" {
-			subnetEVMVersionToUpgradeToWoPrefix := strings.TrimPrefix(upgradeInfo.SubnetEVMVersion, "v")
-			subnetEVMArchive := fmt.Sprintf(constants.SubnetEVMArchive, subnetEVMVersionToUpgradeToWoPrefix)
-			subnetEVMReleaseURL := fmt.Sprintf(constants.SubnetEVMReleaseURL, upgradeInfo.SubnetEVMVersion, subnetEVMArchive)
-			spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, fmt.Sprintf("Upgrading SubnetEVM to version %s...", upgradeInfo.SubnetEVMVersion)))
-			if err := getNewSubnetEVMRelease(host, subnetEVMReleaseURL, subnetEVMArchive); err != nil {
+	}
+	spinSession.Stop()
+	return nil
+}
+
+// upgradeHost upgrades avalanchego and/or Subnet EVM on the host as given by [upgradeInfo]
+var upgradeHost = func(spinSession *ux.UserSpinner, host *models.Host, upgradeInfo nodeUpgradeInfo) error {
+	if upgradeInfo.AvalancheGoVersion != "" {
+		spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, fmt.Sprintf("Upgrading avalanchego to version %s...", upgradeInfo.AvalancheGoVersion)))
+		if err := upgradeAvalancheGo(host, upgradeInfo.AvalancheGoVersion); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		ux.SpinComplete(spinner)
+	}
+	if upgradeInfo.SubnetEVMVersion != "" {
+		subnetEVMVersionToUpgradeToWoPrefix := strings.TrimPrefix(upgradeInfo.SubnetEVMVersion, "v")
+		subnetEVMArchive := fmt.Sprintf(constants.SubnetEVMArchive, subnetEVMVersionToUpgradeToWoPrefix)
+		subnetEVMReleaseURL := fmt.Sprintf(constants.SubnetEVMReleaseURL, upgradeInfo.SubnetEVMVersion, subnetEVMArchive)
+		spinner := spinSession.SpinToUser(utils.ScriptLog(host.NodeID, fmt.Sprintf("Upgrading SubnetEVM to version %s...", upgradeInfo.SubnetEVMVersion)))
+		if err := getNewSubnetEVMRelease(host, subnetEVMReleaseURL, subnetEVMArchive); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		if err := ssh.RunSSHStopNode(host); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		for _, vmID := range upgradeInfo.SubnetEVMIDsToUpgrade {
+			subnetEVMBinaryPath := fmt.Sprintf(constants.CloudNodeSubnetEvmBinaryPath, vmID)
+			if err := upgradeSubnetEVM(host, subnetEVMBinaryPath); err != nil {
 				ux.SpinFailWithError(spinner, "", err)
 				return err
 			}
-			if err := ssh.RunSSHStopNode(host); err != nil {
-				ux.SpinFailWithError(spinner, "", err)
-				return err
+		}
+		if err := ssh.RunSSHStartNode(host); err != nil {
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		ux.SpinComplete(spinner)
+	}
+	return nil
+}
+
+// rollbackInfos returns the upgrades needed to go back to the versions the host was
+// running before [upgradeInfo] was applied, with one Subnet EVM upgrade for each of
+// the previous versions of its VMs
+func (upgradeInfo nodeUpgradeInfo) rollbackInfos() []nodeUpgradeInfo {
+	rollbacks := []nodeUpgradeInfo{}
+	if upgradeInfo.AvalancheGoVersion != "" {
+		rollbacks = append(rollbacks, nodeUpgradeInfo{AvalancheGoVersion: upgradeInfo.CurrentAvalancheGoVersion})
+	}
+	if upgradeInfo.SubnetEVMVersion == "" {
+		return rollbacks
+	}
+	versions := []string{}
+	vmIDsByVersion := map[string][]string{}
+	for _, vmID := range upgradeInfo.SubnetEVMIDsToUpgrade {
+		version := upgradeInfo.CurrentSubnetEVMVersions[vmID]
+		if _, ok := vmIDsByVersion[version]; !ok {
+			versions = append(versions, version)
+		}
+		vmIDsByVersion[version] = append(vmIDsByVersion[version], vmID)
+	}
+	for _, version := range versions {
+		rollbacks = append(rollbacks, nodeUpgradeInfo{SubnetEVMVersion: version, SubnetEVMIDsToUpgrade: vmIDsByVersion[version]})
+	}
+	return rollbacks
+}
+
+func (upgradeInfo nodeUpgradeInfo) isEmpty() bool {
+	return upgradeInfo.AvalancheGoVersion == "" && upgradeInfo.SubnetEVMVersion == ""
+}
+
+// upgradeRolling upgrades the hosts in batches of at most [maxUnavailable] nodes,
+// waiting for each batch to be healthy, bootstrapped and synced to the cluster subnets
+// before proceeding with the next one. Halts on the first batch that fails to recover
+func upgradeRolling(clusterName string, hosts []*models.Host, toUpgradeNodesMap map[*models.Host]nodeUpgradeInfo) error {
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	blockchainIDs, err := getClusterBlockchainIDs(clusterConf)
+	if err != nil {
+		return err
+	}
+	toUpgrade := utils.Filter(hosts, func(h *models.Host) bool {
+		upgradeInfo, ok := toUpgradeNodesMap[h]
+		return ok && !upgradeInfo.isEmpty()
+	})
+	if len(toUpgrade) == 0 {
+		ux.Logger.PrintToUser("All nodes are already up to date")
+		return nil
+	}
+	batches := getUpgradeBatches(toUpgrade, maxUnavailable)
+	for i, batch := range batches {
+		ux.Logger.PrintToUser("")
+		ux.Logger.PrintToUser("Upgrading batch %d / %d: %s", i+1, len(batches), utils.Map(batch, func(h *models.Host) string { return h.GetCloudID() }))
+		spinSession := ux.NewUserSpinner()
+		if err := upgradeBatch(spinSession, batch, toUpgradeNodesMap, false); err != nil {
+			spinSession.Stop()
+			return rollbackBatch(batch, toUpgradeNodesMap, err)
+		}
+		spinSession.Stop()
+		if err := waitForBatchRecovery(batch, blockchainIDs, batchRecoveryTimeout, healthCheckPoolTime); err != nil {
+			return rollbackBatch(batch, toUpgradeNodesMap, err)
+		}
+	}
+	ux.Logger.GreenCheckmarkToUser("Rolling upgrade of cluster %s completed", clusterName)
+	return nil
+}
+
+// getUpgradeBatches splits [hosts] into consecutive batches of at most [maxUnavailable] hosts
+func getUpgradeBatches(hosts []*models.Host, maxUnavailable int) [][]*models.Host {
+	numBatches := (len(hosts) + maxUnavailable - 1) / maxUnavailable
+	batches := [][]*models.Host{}
+	for i := 0; i < numBatches; i++ {
+		batches = append(batches, hosts[i*maxUnavailable:min((i+1)*maxUnavailable, len(hosts))])
+	}
+	return batches
+}
+
+// getClusterBlockchainIDs returns the IDs of the blockchains deployed on the subnets synced to the cluster
+func getClusterBlockchainIDs(clusterConf models.ClusterConfig) ([]string, error) {
+	blockchainIDs := []string{}
+	for _, subnetName := range clusterConf.Subnets {
+		sc, err := app.LoadSidecar(subnetName)
+		if err != nil {
+			return nil, err
+		}
+		if blockchainID := sc.Networks[clusterConf.Network.Name()].BlockchainID; blockchainID != ids.Empty {
+			blockchainIDs = append(blockchainIDs, blockchainID.String())
+		}
+	}
+	return blockchainIDs, nil
+}
+
+// upgradeBatch upgrades all hosts in the batch in parallel, or rolls them back if [rollback] is set
+func upgradeBatch(spinSession *ux.UserSpinner, batch []*models.Host, toUpgradeNodesMap map[*models.Host]nodeUpgradeInfo, rollback bool) error {
+	wg := sync.WaitGroup{}
+	wgResults := models.NodeResults{}
+	for _, host := range batch {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, host *models.Host) {
+			defer wg.Done()
+			upgradeInfos := []nodeUpgradeInfo{toUpgradeNodesMap[host]}
+			if rollback {
+				upgradeInfos = toUpgradeNodesMap[host].rollbackInfos()
 			}
-			for _, vmID := range upgradeInfo.SubnetEVMIDsToUpgrade {
-				subnetEVMBinaryPath := fmt.Sprintf(constants.CloudNodeSubnetEvmBinaryPath, vmID)
-				if err := upgradeSubnetEVM(host, subnetEVMBinaryPath); err != nil {
-					ux.SpinFailWithError(spinner, "", err)
-					return err
+			for _, upgradeInfo := range upgradeInfos {
+				if err := upgradeHost(spinSession, host, upgradeInfo); err != nil {
+					nodeResults.AddResult(host.GetCloudID(), nil, err)
+					return
 				}
 			}
-			if err := ssh.RunSSHStartNode(host); err != nil {
-				ux.SpinFailWithError(spinner, "", err)
-				return err
-			}
+			nodeResults.AddResult(host.GetCloudID(), nil, nil)
+		}(&wgResults, host)
+	}
+	wg.Wait()
+	if wgResults.HasErrors() {
+		return fmt.Errorf("failed to upgrade node(s) %s", wgResults.GetErrorHostMap())
+	}
+	return nil
+}
+
+// rollbackBatch reverts the binaries of the batch if --rollback is set, and returns
+// the error that halted the rolling upgrade
+func rollbackBatch(batch []*models.Host, toUpgradeNodesMap map[*models.Host]nodeUpgradeInfo, batchErr error) error {
+	ux.Logger.RedXToUser("Halting rolling upgrade: %s", batchErr)
+	if !rollbackOnFailure {
+		return batchErr
+	}
+	ux.Logger.PrintToUser("Rolling back node(s) %s to their previous versions...", utils.Map(batch, func(h *models.Host) string { return h.GetCloudID() }))
+	spinSession := ux.NewUserSpinner()
+	defer spinSession.Stop()
+	if err := upgradeBatch(spinSession, batch, toUpgradeNodesMap, true); err != nil {
+		return fmt.Errorf("%w, and rollback failed: %w", batchErr, err)
+	}
+	return fmt.Errorf("%w, batch was rolled back", batchErr)
+}
+
+// waitForBatchRecovery waits until all hosts in the batch are bootstrapped, healthy
+// and syncing or validating the given blockchains
+func waitForBatchRecovery(
+	batch []*models.Host,
+	blockchainIDs []string,
+	timeout time.Duration,
+	poolTime time.Duration,
+) error {
+	spinSession := ux.NewUserSpinner()
+	defer spinSession.Stop()
+	spinner := spinSession.SpinToUser("Waiting for node(s) to be bootstrapped, healthy and synced...")
+	startTime := time.Now()
+	for {
+		wg := sync.WaitGroup{}
+		wgResults := models.NodeResults{}
+		for _, host := range batch {
+			wg.Add(1)
+			go func(nodeResults *models.NodeResults, host *models.Host) {
+				defer wg.Done()
+				nodeResults.AddResult(host.GetCloudID(), nil, checkHostRecovered(host, blockchainIDs))
+			}(&wgResults, host)
+		}
+		wg.Wait()
+		if !wgResults.HasErrors() {
 			ux.SpinComplete(spinner)
+			ux.Logger.GreenCheckmarkToUser("Node(s) recovered after %d seconds", uint32(time.Since(startTime).Seconds()))
+			return nil
+		}
+		if time.Since(startTime) > timeout {
+			err := fmt.Errorf("node(s) not recovered after %d seconds: %s", uint32(timeout.Seconds()), wgResults.GetErrorHostMap())
+			ux.SpinFailWithError(spinner, "", err)
+			return err
+		}
+		time.Sleep(poolTime)
+	}
+}
+
+// checkHostRecovered returns an error if the host is not bootstrapped, healthy and
+// syncing or validating all given blockchains
+var checkHostRecovered = func(host *models.Host, blockchainIDs []string) error {
+	resp, err := ssh.RunSSHCheckBootstrapped(host)
+	if err != nil {
+		return err
+	}
+	if isBootstrapped, err := parseBootstrappedOutput(resp); err != nil {
+		return err
+	} else if !isBootstrapped {
+		return errors.New("not bootstrapped")
+	}
+	resp, err = ssh.RunSSHCheckHealthy(host)
+	if err != nil {
+		return err
+	}
+	if isHealthy, err := parseHealthyOutput(resp); err != nil {
+		return err
+	} else if !isHealthy {
+		return errors.New("not healthy")
+	}
+	for _, blockchainID := range blockchainIDs {
+		resp, err := ssh.RunSSHSubnetSyncStatus(host, blockchainID)
+		if err != nil {
+			return err
+		}
+		syncStatus, err := parseSubnetSyncOutput(resp)
+		if err != nil {
+			return err
+		}
+		if syncStatus != status.Syncing.String() && syncStatus != status.Validating.String() {
+			return fmt.Errorf("blockchain %s is %s", blockchainID, strings.ToLower(syncStatus))
 		}
 	}
-	spinSession.Stop()
 	return nil
 }
 
@@ -163,6 +418,8 @@ func getNodesUpgradeInfo(hosts []*models.Host) (map[*models.Host]nodeUpgradeInfo
 		avalancheGoVersionToUpdateTo := latestAvagoVersion
 		nodeUpgradeInfo := nodeUpgradeInfo{}
 		nodeUpgradeInfo.SubnetEVMIDsToUpgrade = []string{}
+		nodeUpgradeInfo.CurrentSubnetEVMVersions = map[string]string{}
+		nodeUpgradeInfo.CurrentAvalancheGoVersion = fmt.Sprint(currentAvalancheGoVersion)
 		for vmName, vmVersion := range vmVersions {
 			// when calling info.getNodeVersion, this is what we get
 			// "vmVersions":{"avm":"v1.10.12","evm":"v0.12.5","n8Anw9kErmgk7KHviddYtecCmziLZTphDwfL1V2DfnFjWZXbE":"v0.5.6","platform":"v1.10.12"}},
@@ -172,6 +429,7 @@ func getNodesUpgradeInfo(hosts []*models.Host) (map[*models.Host]nodeUpgradeInfo
 					// update subnet EVM version
 					ux.Logger.PrintToUser("Upgrading Subnet EVM version for node %s from version %s to version %s", hostID, vmVersion, latestSubnetEVMVersion)
 					nodeUpgradeInfo.SubnetEVMVersion = latestSubnetEVMVersion
+					nodeUpgradeInfo.CurrentSubnetEVMVersions[vmName] = fmt.Sprint(vmVersion)
 					nodeUpgradeInfo.SubnetEVMIDsToUpgrade = append(nodeUpgradeInfo.SubnetEVMIDsToUpgrade, vmName)
 				}
 				// find the highest version of avalanche go that is still compatible with current highest rpc
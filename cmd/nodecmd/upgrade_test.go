@@ -0,0 +1,264 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func newUpgradeTestHosts(cloudIDs ...string) []*models.Host {
+	hosts := []*models.Host{}
+	for _, cloudID := range cloudIDs {
+		hosts = append(hosts, &models.Host{NodeID: constants.AWSNodeAnsiblePrefix + "_" + cloudID})
+	}
+	return hosts
+}
+
+func TestGetUpgradeBatches(t *testing.T) {
+	hosts := newUpgradeTestHosts("i-1", "i-2", "i-3", "i-4", "i-5")
+	tests := []struct {
+		name           string
+		maxUnavailable int
+		expected       [][]string
+	}{
+		{
+			name:           "one node at a time",
+			maxUnavailable: 1,
+			expected:       [][]string{{"i-1"}, {"i-2"}, {"i-3"}, {"i-4"}, {"i-5"}},
+		},
+		{
+			name:           "last batch is smaller",
+			maxUnavailable: 2,
+			expected:       [][]string{{"i-1", "i-2"}, {"i-3", "i-4"}, {"i-5"}},
+		},
+		{
+			name:           "exact batches",
+			maxUnavailable: 5,
+			expected:       [][]string{{"i-1", "i-2", "i-3", "i-4", "i-5"}},
+		},
+		{
+			name:           "more unavailable than nodes",
+			maxUnavailable: 10,
+			expected:       [][]string{{"i-1", "i-2", "i-3", "i-4", "i-5"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := [][]string{}
+			for _, batch := range getUpgradeBatches(hosts, tt.maxUnavailable) {
+				batches = append(batches, hostCloudIDs(batch))
+			}
+			require.Equal(t, tt.expected, batches)
+		})
+	}
+	require.Empty(t, getUpgradeBatches(nil, 2))
+}
+
+func TestRollbackInfos(t *testing.T) {
+	tests := []struct {
+		name        string
+		upgradeInfo nodeUpgradeInfo
+		expected    []nodeUpgradeInfo
+	}{
+		{
+			name: "avalanchego only",
+			upgradeInfo: nodeUpgradeInfo{
+				AvalancheGoVersion:        "v1.11.0",
+				CurrentAvalancheGoVersion: "v1.10.0",
+				SubnetEVMIDsToUpgrade:     []string{},
+			},
+			expected: []nodeUpgradeInfo{{AvalancheGoVersion: "v1.10.0"}},
+		},
+		{
+			name: "each vm goes back to its own version",
+			upgradeInfo: nodeUpgradeInfo{
+				SubnetEVMVersion:      "v0.6.0",
+				SubnetEVMIDsToUpgrade: []string{"vmA", "vmB", "vmC"},
+				CurrentSubnetEVMVersions: map[string]string{
+					"vmA": "v0.5.1",
+					"vmB": "v0.5.2",
+					"vmC": "v0.5.1",
+				},
+			},
+			expected: []nodeUpgradeInfo{
+				{SubnetEVMVersion: "v0.5.1", SubnetEVMIDsToUpgrade: []string{"vmA", "vmC"}},
+				{SubnetEVMVersion: "v0.5.2", SubnetEVMIDsToUpgrade: []string{"vmB"}},
+			},
+		},
+		{
+			name: "avalanchego and subnet evm",
+			upgradeInfo: nodeUpgradeInfo{
+				AvalancheGoVersion:        "v1.11.0",
+				SubnetEVMVersion:          "v0.6.0",
+				SubnetEVMIDsToUpgrade:     []string{"vmA"},
+				CurrentAvalancheGoVersion: "v1.10.0",
+				CurrentSubnetEVMVersions:  map[string]string{"vmA": "v0.5.1"},
+			},
+			expected: []nodeUpgradeInfo{
+				{AvalancheGoVersion: "v1.10.0"},
+				{SubnetEVMVersion: "v0.5.1", SubnetEVMIDsToUpgrade: []string{"vmA"}},
+			},
+		},
+		{
+			name:        "nothing upgraded",
+			upgradeInfo: nodeUpgradeInfo{CurrentAvalancheGoVersion: "v1.10.0"},
+			expected:    []nodeUpgradeInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.upgradeInfo.rollbackInfos())
+		})
+	}
+}
+
+func TestWaitForBatchRecovery(t *testing.T) {
+	require := require.New(t)
+	ux.NewUserLog(logging.NoLog{}, io.Discard)
+	defaultCheckHostRecovered := checkHostRecovered
+	t.Cleanup(func() { checkHostRecovered = defaultCheckHostRecovered })
+	batch := newUpgradeTestHosts("i-1", "i-2")
+
+	lock := sync.Mutex{}
+	checks := map[string]int{}
+	checkHostRecovered = func(host *models.Host, blockchainIDs []string) error {
+		require.Equal([]string{"chain"}, blockchainIDs)
+		lock.Lock()
+		defer lock.Unlock()
+		checks[host.GetCloudID()]++
+		if host.GetCloudID() == "i-2" && checks["i-2"] < 3 {
+			return errors.New("not bootstrapped")
+		}
+		return nil
+	}
+	require.NoError(waitForBatchRecovery(batch, []string{"chain"}, time.Minute, time.Millisecond))
+	require.Equal(3, checks["i-2"])
+
+	checkHostRecovered = func(host *models.Host, _ []string) error {
+		if host.GetCloudID() == "i-2" {
+			return errors.New("not healthy")
+		}
+		return nil
+	}
+	err := waitForBatchRecovery(batch, []string{"chain"}, 10*time.Millisecond, time.Millisecond)
+	require.ErrorContains(err, "node(s) not recovered")
+	require.ErrorContains(err, "i-2")
+	require.NotContains(err.Error(), "i-1")
+}
+
+func TestUpgradeRolling(t *testing.T) {
+	upgradeInfo := nodeUpgradeInfo{
+		AvalancheGoVersion:        "v1.11.0",
+		SubnetEVMIDsToUpgrade:     []string{},
+		CurrentAvalancheGoVersion: "v1.10.0",
+	}
+	tests := []struct {
+		name              string
+		maxUnavailable    int
+		rollback          bool
+		failingUpgrade    string
+		notRecovered      string
+		expectedError     string
+		expectedUpgrades  []string
+		expectedRollbacks []string
+	}{
+		{
+			name:             "all batches recover",
+			maxUnavailable:   2,
+			expectedUpgrades: []string{"i-1", "i-2", "i-3", "i-4", "i-5"},
+		},
+		{
+			name:             "halts on a batch that doesn't recover",
+			maxUnavailable:   2,
+			notRecovered:     "i-3",
+			expectedError:    "node(s) not recovered",
+			expectedUpgrades: []string{"i-1", "i-2", "i-3", "i-4"},
+		},
+		{
+			name:              "rolls back a batch that doesn't recover",
+			maxUnavailable:    2,
+			rollback:          true,
+			notRecovered:      "i-4",
+			expectedError:     "batch was rolled back",
+			expectedUpgrades:  []string{"i-1", "i-2", "i-3", "i-4"},
+			expectedRollbacks: []string{"i-3", "i-4"},
+		},
+		{
+			name:              "rolls back a batch that fails to upgrade",
+			maxUnavailable:    1,
+			rollback:          true,
+			failingUpgrade:    "i-2",
+			expectedError:     "failed to upgrade node(s)",
+			expectedUpgrades:  []string{"i-1", "i-2"},
+			expectedRollbacks: []string{"i-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ux.NewUserLog(logging.NoLog{}, io.Discard)
+			app = application.New()
+			app.Setup(t.TempDir(), logging.NoLog{}, nil, nil, nil)
+			require.NoError(app.WriteClustersConfigFile(&models.ClustersConfig{
+				Clusters: map[string]models.ClusterConfig{"cluster": {Nodes: []string{"i-1", "i-2", "i-3", "i-4", "i-5", "i-6"}}},
+			}))
+			defaultMaxUnavailable, defaultRollbackOnFailure, defaultBatchRecoveryTimeout := maxUnavailable, rollbackOnFailure, batchRecoveryTimeout
+			defaultUpgradeHost, defaultCheckHostRecovered := upgradeHost, checkHostRecovered
+			t.Cleanup(func() {
+				maxUnavailable, rollbackOnFailure, batchRecoveryTimeout = defaultMaxUnavailable, defaultRollbackOnFailure, defaultBatchRecoveryTimeout
+				upgradeHost, checkHostRecovered = defaultUpgradeHost, defaultCheckHostRecovered
+			})
+			maxUnavailable, rollbackOnFailure, batchRecoveryTimeout = tt.maxUnavailable, tt.rollback, 0
+
+			hosts := newUpgradeTestHosts("i-1", "i-2", "i-3", "i-4", "i-5", "i-6")
+			toUpgradeNodesMap := map[*models.Host]nodeUpgradeInfo{}
+			for _, host := range hosts[:5] {
+				toUpgradeNodesMap[host] = upgradeInfo
+			}
+			// i-6 is already up to date
+			toUpgradeNodesMap[hosts[5]] = nodeUpgradeInfo{CurrentAvalancheGoVersion: "v1.11.0"}
+
+			lock := sync.Mutex{}
+			upgrades, rollbacks := []string{}, []string{}
+			upgradeHost = func(_ *ux.UserSpinner, host *models.Host, info nodeUpgradeInfo) error {
+				lock.Lock()
+				defer lock.Unlock()
+				if info.AvalancheGoVersion == upgradeInfo.CurrentAvalancheGoVersion {
+					rollbacks = append(rollbacks, host.GetCloudID())
+					return nil
+				}
+				require.Equal(upgradeInfo, info)
+				upgrades = append(upgrades, host.GetCloudID())
+				if host.GetCloudID() == tt.failingUpgrade {
+					return errors.New("download failed")
+				}
+				return nil
+			}
+			checkHostRecovered = func(host *models.Host, _ []string) error {
+				if host.GetCloudID() == tt.notRecovered {
+					return errors.New("not bootstrapped")
+				}
+				return nil
+			}
+			err := upgradeRolling("cluster", hosts, toUpgradeNodesMap)
+			if tt.expectedError != "" {
+				require.ErrorContains(err, tt.expectedError)
+			} else {
+				require.NoError(err)
+			}
+			require.ElementsMatch(tt.expectedUpgrades, upgrades)
+			require.ElementsMatch(tt.expectedRollbacks, rollbacks)
+		})
+	}
+}
@@ -29,5 +29,9 @@ will fail. You can check the bootstrap status by calling avalanche node status <
 	cmd.AddCommand(newValidatePrimaryCmd())
 	// node validate subnet cluster subnetName
 	cmd.AddCommand(newValidateSubnetCmd())
+	// node validate status cluster
+	cmd.AddCommand(newValidateStatusCmd())
+	// node validate renew cluster
+	cmd.AddCommand(newValidateRenewCmd())
 	return cmd
 }
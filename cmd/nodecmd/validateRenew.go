@@ -0,0 +1,423 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ixAnkit/cryft/cmd/flags"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/keychain"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/node"
+	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/ixAnkit/cryft/pkg/txutils"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/MetalBlockchain/metalgo/utils/crypto/bls"
+	"github.com/MetalBlockchain/metalgo/vms/platformvm/signer"
+	"github.com/MetalBlockchain/metalgo/vms/platformvm/txs"
+	"github.com/MetalBlockchain/metalgo/vms/secp256k1fx"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultRenewWatchInterval = time.Minute
+	// a renewal is retried until the ended validation leaves the validator set
+	renewIssueTimeout = 30 * time.Minute
+)
+
+var (
+	renewWindow        time.Duration
+	renewWatch         bool
+	renewWatchInterval time.Duration
+	renewOutputTxPath  string
+	renewInputTxPath   string
+)
+
+// pendingRenewal is the renewal of the validation of a node, waiting for it to end
+type pendingRenewal struct {
+	cloudID string
+	due     time.Time
+	issue   func() (ids.ID, error)
+}
+
+func newValidateRenewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "renew [clusterName]",
+		Short: "(ALPHA Warning) Renew the Primary Network validations of a cluster before they end",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node validate renew command finds the nodes of a cluster whose Primary Network validation
+ends within --window (defaulting to the avalanche config validation --expiry-warning setting),
+and renews them with a new validation using the same BLS keys, starting right after the
+current one ends. The new validations stake --stake-amount and last --staking-period, or the
+same as the current ones when not given.
+
+A node can't be added again to the validator set while it validates, so renewals are issued
+once the current validations end:
+
+  --watch waits for the current validations to end and issues the renewals, so it can be
+  scheduled ahead of time, for example daily with cron:
+    avalanche node validate renew <clusterName> --window 24h --watch --key <keyName>
+
+  --output-tx-path creates and signs the renewal transactions now, with a key or a ledger,
+  and saves them to the given directory. They are committed later, from any machine with
+  the cluster, with --input-tx-path, also waiting with --watch for the current validations
+  to end.
+
+Without any of them, the command shows the renewals to be made.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         renewValidations,
+	}
+	cmd.Flags().StringVarP(&keyName, "key", "k", "", "select the key to use [tahoe/devnet only]")
+	cmd.Flags().BoolVarP(&useLedger, "ledger", "g", false, "use ledger instead of key (always true on mainnet, defaults to false on tahoe/devnet)")
+	cmd.Flags().BoolVarP(&useEwoq, "ewoq", "e", false, "use ewoq key [tahoe/devnet only]")
+	cmd.Flags().StringSliceVar(&ledgerAddresses, "ledger-addrs", []string{}, "use the given ledger addresses")
+
+	cmd.Flags().Uint64Var(&weight, "stake-amount", 0, "how many AVAX to stake in the renewed validators. defaults to their current stake")
+	cmd.Flags().DurationVar(&duration, "staking-period", 0, "how long the renewed validators validate for. defaults to their current staking period")
+
+	cmd.Flags().DurationVar(&renewWindow, "window", constants.DefaultValidationExpiryWarning, "renew the validations ending within the given duration")
+	cmd.Flags().BoolVar(&renewWatch, "watch", false, "wait for the current validations to end and issue the renewals")
+	cmd.Flags().DurationVar(&renewWatchInterval, "watch-interval", defaultRenewWatchInterval, "how often to retry the renewals not yet accepted with --watch")
+	cmd.Flags().StringVar(&renewOutputTxPath, "output-tx-path", "", "save the signed renewal transactions to the given directory instead of issuing them")
+	cmd.Flags().StringVar(&renewInputTxPath, "input-tx-path", "", "commit the renewal transactions saved to the given directory")
+	addSelectorFlag(cmd)
+	return cmd
+}
+
+func renewValidations(cmd *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	if !flags.EnsureMutuallyExclusive([]bool{renewOutputTxPath != "", renewInputTxPath != ""}) {
+		return fmt.Errorf("--output-tx-path and --input-tx-path are mutually exclusive")
+	}
+	if renewWatchInterval <= 0 {
+		return fmt.Errorf("--watch-interval must be positive")
+	}
+	if !cmd.Flags().Changed("window") {
+		var err error
+		if renewWindow, err = app.Conf.GetValidationExpiryWarning(); err != nil {
+			return err
+		}
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	network := clusterConf.Network
+	hostIDs := utils.Filter(clusterConf.GetCloudIDs(), clusterConf.IsAvalancheGoHost)
+	hostIDs, err = utils.FilterWithError(hostIDs, matchesNodeSelector)
+	if err != nil {
+		return err
+	}
+	if len(hostIDs) == 0 {
+		return fmt.Errorf("no nodes match selector %q", nodeSelector)
+	}
+	validations, err := getClusterValidations(clusterName, hostIDs)
+	if err != nil {
+		return err
+	}
+	if renewInputTxPath != "" {
+		if err := commitRenewalTxs(network, hostIDs, validations); err != nil {
+			return err
+		}
+		refreshClusterValidatorMetrics(clusterName, clusterConf)
+		return nil
+	}
+	renewals := node.NewRenewals(
+		validations,
+		time.Now(),
+		renewWindow,
+		constants.PrimaryNetworkValidatingStartLeadTimeNodeCmd,
+		duration,
+		weight,
+	)
+	if len(renewals) == 0 {
+		ux.Logger.PrintToUser("No Primary Network validations of cluster %s end within %s", clusterName, ux.FormatDuration(renewWindow))
+		return nil
+	}
+	minValStake, err := GetMinStakingAmount(network)
+	if err != nil {
+		return err
+	}
+	for _, renewal := range renewals {
+		if renewal.Weight < minValStake {
+			return fmt.Errorf("illegal weight for %s, must be greater than or equal to %d: %d", renewal.Current.CloudID, minValStake, renewal.Weight)
+		}
+	}
+	printRenewals(renewals)
+	if !renewWatch && renewOutputTxPath == "" {
+		ux.Logger.PrintToUser("")
+		ux.Logger.PrintToUser("Renewals are issued once the current validations end. Use --watch to wait for them and issue")
+		ux.Logger.PrintToUser("the renewals, or --output-tx-path to sign them now and commit them later")
+		return nil
+	}
+	fee := network.GenesisParams().AddPrimaryNetworkValidatorFee * uint64(len(renewals))
+	kc, err := keychain.GetKeychainFromCmdLineFlags(
+		app,
+		constants.PayTxsFeesMsg,
+		network,
+		keyName,
+		useEwoq,
+		useLedger,
+		ledgerAddresses,
+		fee,
+	)
+	if err != nil {
+		return err
+	}
+	deployer := subnet.NewPublicDeployer(app, kc, network)
+	recipientAddr := kc.Addresses().List()[0]
+	delegationFee := network.GenesisParams().MinDelegationFee
+	if renewOutputTxPath != "" {
+		return saveRenewalTxs(clusterName, deployer, renewals, recipientAddr, delegationFee)
+	}
+	pending := []pendingRenewal{}
+	for _, renewal := range renewals {
+		renewal := renewal
+		pop, err := getNodeProofOfPossession(renewal.Current.CloudID)
+		if err != nil {
+			return err
+		}
+		pending = append(pending, pendingRenewal{
+			cloudID: renewal.Current.CloudID,
+			due:     renewal.Current.End,
+			issue: func() (ids.ID, error) {
+				// the start time must be in the future before Durango, and is ignored after it
+				start := renewal.Start
+				if minStart := time.Now().Add(constants.PrimaryNetworkValidatingStartLeadTimeNodeCmd); start.Before(minStart) {
+					start = minStart
+				}
+				return deployer.AddPermissionlessValidator(
+					ids.Empty,
+					ids.Empty,
+					renewal.Current.NodeID,
+					renewal.Weight,
+					uint64(start.Unix()),
+					uint64(renewal.End.Unix()),
+					recipientAddr,
+					delegationFee,
+					nil,
+					pop,
+				)
+			},
+		})
+	}
+	if err := waitAndIssueRenewals(pending); err != nil {
+		return err
+	}
+	refreshClusterValidatorMetrics(clusterName, clusterConf)
+	return nil
+}
+
+// refreshClusterValidatorMetrics exports the validations of all the nodes of the cluster
+// to its monitoring host, if any, so the renewed ones stop alerting as ending soon
+func refreshClusterValidatorMetrics(clusterName string, clusterConf models.ClusterConfig) {
+	if clusterConf.MonitoringInstance == "" {
+		return
+	}
+	hostIDs := utils.Filter(clusterConf.GetCloudIDs(), clusterConf.IsAvalancheGoHost)
+	validations, err := getClusterValidations(clusterName, hostIDs)
+	if err == nil {
+		err = exportClusterValidatorMetrics(clusterName, validations)
+	}
+	if err != nil {
+		ux.Logger.RedXToUser("Unable to refresh the validator metrics of cluster %s: %s", clusterName, err)
+	}
+}
+
+// getNodeProofOfPossession returns the proof of possession of the BLS key of the node at [cloudID]
+func getNodeProofOfPossession(cloudID string) (*signer.ProofOfPossession, error) {
+	blsKeyBytes, err := os.ReadFile(app.GetNodeBLSSecretKeyPath(cloudID))
+	if err != nil {
+		return nil, err
+	}
+	blsSk, err := bls.SecretKeyFromBytes(blsKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	return signer.NewProofOfPossession(blsSk), nil
+}
+
+func getRenewalTxPath(dir string, nodeID ids.NodeID) string {
+	return filepath.Join(dir, fmt.Sprintf("renew_%s.tx", nodeID))
+}
+
+// saveRenewalTxs creates and signs the transactions of [renewals], and saves them to
+// --output-tx-path to be committed later
+func saveRenewalTxs(
+	clusterName string,
+	deployer *subnet.PublicDeployer,
+	renewals []node.Renewal,
+	recipientAddr ids.ShortID,
+	delegationFee uint32,
+) error {
+	if err := os.MkdirAll(renewOutputTxPath, constants.DefaultPerms755); err != nil {
+		return err
+	}
+	for _, renewal := range renewals {
+		pop, err := getNodeProofOfPossession(renewal.Current.CloudID)
+		if err != nil {
+			return err
+		}
+		tx, err := deployer.CreateAddPermissionlessValidatorTx(
+			renewal.Current.NodeID,
+			renewal.Weight,
+			uint64(renewal.Start.Unix()),
+			uint64(renewal.End.Unix()),
+			recipientAddr,
+			delegationFee,
+			pop,
+		)
+		if err != nil {
+			return fmt.Errorf("failure creating the renewal of %s: %w", renewal.Current.CloudID, err)
+		}
+		txPath := getRenewalTxPath(renewOutputTxPath, renewal.Current.NodeID)
+		if err := txutils.SaveToDisk(tx, txPath, true); err != nil {
+			return err
+		}
+		ux.Logger.PrintToUser("Renewal of %s saved to %s", renewal.Current.CloudID, txPath)
+	}
+	ux.Logger.PrintToUser("")
+	ux.Logger.PrintToUser("Commit the renewals once the current validations end with:")
+	ux.Logger.PrintToUser("  avalanche node validate renew %s --input-tx-path %s --watch", clusterName, renewOutputTxPath)
+	ux.Logger.PrintToUser("The funds spent by the transactions must not be used until then")
+	return nil
+}
+
+// commitRenewalTxs commits the renewal transactions saved in --input-tx-path for the nodes at
+// [cloudIDs], once the current validations of their nodes end
+func commitRenewalTxs(network models.Network, cloudIDs []string, validations []node.Validation) error {
+	txPaths, err := filepath.Glob(filepath.Join(renewInputTxPath, "renew_*.tx"))
+	if err != nil {
+		return err
+	}
+	if len(txPaths) == 0 {
+		return fmt.Errorf("no renewal transactions found in %s", renewInputTxPath)
+	}
+	cloudIDByNodeID := map[ids.NodeID]string{}
+	for _, cloudID := range cloudIDs {
+		nodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID))
+		if err != nil {
+			return err
+		}
+		cloudIDByNodeID[nodeID] = cloudID
+	}
+	// get kc with some random address, to pass wallet creation checks
+	kc := secp256k1fx.NewKeychain()
+	if _, err := kc.New(); err != nil {
+		return err
+	}
+	deployer := subnet.NewPublicDeployer(app, keychain.NewKeychain(network, kc, nil, nil), network)
+	pending := []pendingRenewal{}
+	for _, txPath := range txPaths {
+		tx, err := txutils.LoadFromDisk(txPath)
+		if err != nil {
+			return err
+		}
+		unsignedTx, ok := tx.Unsigned.(*txs.AddPermissionlessValidatorTx)
+		if !ok || unsignedTx.Subnet != ids.Empty {
+			return fmt.Errorf("%s is not a Primary Network validator transaction", txPath)
+		}
+		if unsignedTx.NetworkID != network.ID {
+			return fmt.Errorf("%s is not a transaction of network %s", txPath, network.Name())
+		}
+		cloudID, ok := cloudIDByNodeID[unsignedTx.Validator.NodeID]
+		if !ok {
+			ux.Logger.PrintToUser("Skipping %s, its node %s is not a selected node of the cluster", txPath, unsignedTx.Validator.NodeID)
+			continue
+		}
+		// nodes not validating can be renewed right away
+		due := time.Now()
+		if validation, found := node.FindValidation(validations, cloudID, ids.Empty); found {
+			due = validation.End
+		}
+		if !renewWatch && time.Now().Before(due) {
+			ux.Logger.PrintToUser("Validation of %s ends at %s, use --watch to wait for it", cloudID, due.Local().Format(constants.TimeParseLayout))
+			continue
+		}
+		pending = append(pending, pendingRenewal{
+			cloudID: cloudID,
+			due:     due,
+			issue:   func() (ids.ID, error) { return deployer.Commit(tx, false) },
+		})
+	}
+	return waitAndIssueRenewals(pending)
+}
+
+// waitAndIssueRenewals issues each of [pending] once the validation it renews ends,
+// retrying it every --watch-interval until the ended validation leaves the validator set
+func waitAndIssueRenewals(pending []pendingRenewal) error {
+	if slices.ContainsFunc(pending, func(renewal pendingRenewal) bool { return time.Now().Before(renewal.due) }) {
+		ux.Logger.PrintToUser("Waiting for the current validations to end...")
+	}
+	failed := map[string]error{}
+	for len(pending) > 0 {
+		now := time.Now()
+		waiting := []pendingRenewal{}
+		for _, renewal := range pending {
+			if now.Before(renewal.due) {
+				waiting = append(waiting, renewal)
+				continue
+			}
+			txID, err := renewal.issue()
+			switch {
+			case err == nil:
+				ux.Logger.GreenCheckmarkToUser("Validation of %s renewed, transaction ID: %s", renewal.cloudID, txID)
+			case now.Sub(renewal.due) > renewIssueTimeout:
+				ux.Logger.RedXToUser("Failed to renew the validation of %s: %s", renewal.cloudID, err)
+				failed[renewal.cloudID] = err
+			default:
+				ux.Logger.PrintToUser("Unable to renew the validation of %s yet, retrying in %s: %s", renewal.cloudID, renewWatchInterval, err)
+				waiting = append(waiting, renewal)
+			}
+		}
+		pending = waiting
+		if len(pending) == 0 {
+			break
+		}
+		wait := renewWatchInterval
+		for _, renewal := range pending {
+			if untilDue := renewal.due.Sub(now); untilDue > 0 && untilDue < wait {
+				wait = untilDue
+			}
+		}
+		time.Sleep(wait)
+	}
+	if len(failed) > 0 {
+		cloudIDs := maps.Keys(failed)
+		slices.Sort(cloudIDs)
+		return fmt.Errorf("failed to renew the validations of node(s) %s", cloudIDs)
+	}
+	return nil
+}
+
+func printRenewals(renewals []node.Renewal) {
+	header := []string{"Cloud ID", "Node ID", "Current End", "New Start", "New End", "Stake"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	for _, renewal := range renewals {
+		table.Append([]string{
+			renewal.Current.CloudID,
+			renewal.Current.NodeID.String(),
+			renewal.Current.End.Local().Format(constants.TimeParseLayout),
+			renewal.Start.Local().Format(constants.TimeParseLayout),
+			renewal.End.Local().Format(constants.TimeParseLayout),
+			convertNanoAvaxToAvaxString(renewal.Weight),
+		})
+	}
+	table.Render()
+}
@@ -0,0 +1,259 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/node"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/MetalBlockchain/metalgo/utils/units"
+	"github.com/MetalBlockchain/metalgo/vms/platformvm"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	validationExpiryWarning time.Duration
+	exportValidatorMetrics  bool
+)
+
+func newValidateStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status [clusterName]",
+		Short: "(ALPHA Warning) Show the validations of the nodes of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node validate status command shows the current validations of the nodes of a cluster,
+on the Primary Network and on the subnets deployed to the cluster: their start and end time,
+weight, uptime, potential reward, number of delegators and the time remaining until they end.
+Validations ending within --expiry-warning are flagged. Its default is set with
+avalanche config validation --expiry-warning.
+
+On clusters with monitoring, the validations are also exported as Prometheus metrics of the
+monitoring host (avalanche_cli_validator_*). They are only refreshed when this command runs,
+and after node validate renew issues renewals, so schedule it (for example hourly with cron)
+to keep them current. The time remaining of a validation is
+avalanche_cli_validator_end_time_seconds - time(), and the ValidationEndingSoon alert fires
+when it is below the avalanche config validation --expiry-warning setting, as of the last
+time the cluster alert rules were updated.
+
+--selector only shows the nodes whose labels match it.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         validateStatus,
+	}
+	addExpiryWarningFlag(cmd)
+	cmd.Flags().BoolVar(&exportValidatorMetrics, "export-metrics", true, "export the validations as metrics of the cluster monitoring host")
+	addSelectorFlag(cmd)
+	return cmd
+}
+
+func addExpiryWarningFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&validationExpiryWarning, "expiry-warning", constants.DefaultValidationExpiryWarning, "warn about validations ending within the given duration")
+}
+
+// getValidationExpiryWarning returns --expiry-warning if given, or else the config setting
+func getValidationExpiryWarning(cmd *cobra.Command) (time.Duration, error) {
+	if cmd.Flags().Changed("expiry-warning") {
+		return validationExpiryWarning, nil
+	}
+	return app.Conf.GetValidationExpiryWarning()
+}
+
+func validateStatus(cmd *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	threshold, err := getValidationExpiryWarning(cmd)
+	if err != nil {
+		return err
+	}
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return err
+	}
+	hostIDs := utils.Filter(clusterConf.GetCloudIDs(), clusterConf.IsAvalancheGoHost)
+	validations, err := getClusterValidations(clusterName, hostIDs)
+	if err != nil {
+		return err
+	}
+	if exportValidatorMetrics && clusterConf.MonitoringInstance != "" {
+		// metrics are exported for the whole cluster, regardless of --selector
+		if err := exportClusterValidatorMetrics(clusterName, validations); err != nil {
+			ux.Logger.RedXToUser("Unable to export the validator metrics of cluster %s: %s", clusterName, err)
+		}
+	}
+	selectedIDs, err := utils.FilterWithError(hostIDs, matchesNodeSelector)
+	if err != nil {
+		return err
+	}
+	if len(selectedIDs) == 0 {
+		return fmt.Errorf("no nodes match selector %q", nodeSelector)
+	}
+	validations = utils.Filter(validations, func(v node.Validation) bool { return slices.Contains(selectedIDs, v.CloudID) })
+	printValidations(clusterName, validations, threshold)
+	notValidating := utils.Filter(selectedIDs, func(cloudID string) bool {
+		_, ok := node.FindValidation(validations, cloudID, ids.Empty)
+		return !ok
+	})
+	if len(notValidating) > 0 {
+		ux.Logger.PrintToUser("Nodes not validating the Primary Network: %s", strings.Join(notValidating, ", "))
+	}
+	printExpiringValidations(clusterName, validations, threshold)
+	return nil
+}
+
+// getClusterValidations returns the current validations of the nodes at [cloudIDs] on the
+// Primary Network and on the subnets deployed to the cluster
+func getClusterValidations(clusterName string, cloudIDs []string) ([]node.Validation, error) {
+	clusterConf, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	network := clusterConf.Network
+	nodeCloudIDs := map[ids.NodeID]string{}
+	nodeIDs := []ids.NodeID{}
+	for _, cloudID := range cloudIDs {
+		nodeID, err := getNodeID(app.GetNodeInstanceDirPath(cloudID))
+		if err != nil {
+			return nil, err
+		}
+		nodeCloudIDs[nodeID] = cloudID
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	subnetIDs := map[string]ids.ID{node.PrimaryNetworkName: ids.Empty}
+	for _, subnetName := range clusterConf.Subnets {
+		sc, err := app.LoadSidecar(subnetName)
+		if err != nil {
+			return nil, err
+		}
+		if subnetID := sc.Networks[network.Name()].SubnetID; subnetID != ids.Empty {
+			subnetIDs[subnetName] = subnetID
+		}
+	}
+	pClient := platformvm.NewClient(network.Endpoint)
+	validations := []node.Validation{}
+	for subnetName, subnetID := range subnetIDs {
+		ctx, cancel := context.WithTimeout(context.Background(), constants.APIRequestTimeout)
+		validators, err := pClient.GetCurrentValidators(ctx, subnetID, nodeIDs)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the current validators of %s: %w", subnetName, err)
+		}
+		validations = append(validations, node.NewValidations(validators, subnetName, subnetID, nodeCloudIDs)...)
+	}
+	node.SortValidations(validations)
+	return validations, nil
+}
+
+// exportClusterValidatorMetrics uploads [validations] to the monitoring host of the cluster,
+// setting up their exporter and scraping first on clusters created before it
+func exportClusterValidatorMetrics(clusterName string, validations []node.Validation) error {
+	monitoringHosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetMonitoringInventoryDir(clusterName))
+	if err != nil {
+		return err
+	}
+	if len(monitoringHosts) == 0 {
+		return fmt.Errorf("cluster %s has no monitoring host", clusterName)
+	}
+	defer disconnectHosts(monitoringHosts)
+	monitoringHost := monitoringHosts[0]
+	if !ssh.RunSSHCheckValidatorMetrics(monitoringHost) {
+		if err := ssh.RunSSHSetupValidatorMetrics(monitoringHost); err != nil {
+			return err
+		}
+		avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err := getPrometheusTargets(clusterName)
+		if err != nil {
+			return err
+		}
+		if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts, relayerPorts); err != nil {
+			return err
+		}
+		if err := updateClusterAlertRules(clusterName, monitoringHost); err != nil {
+			return err
+		}
+	}
+	return ssh.RunSSHUploadValidatorMetrics(monitoringHost, clusterName, node.ValidationMetrics(clusterName, validations))
+}
+
+// formatValidationRemaining returns the time remaining of [validation], in red if it ends
+// within [threshold]
+func formatValidationRemaining(validation node.Validation, now time.Time, threshold time.Duration) string {
+	remaining := ux.FormatDuration(validation.Remaining(now).Truncate(time.Minute))
+	if validation.ExpiresWithin(now, threshold) {
+		return logging.Red.Wrap(remaining)
+	}
+	return logging.Green.Wrap(remaining)
+}
+
+func printValidations(clusterName string, validations []node.Validation, threshold time.Duration) {
+	now := time.Now()
+	ux.Logger.PrintToUser("")
+	tit := fmt.Sprintf("VALIDATIONS OF CLUSTER: %s", logging.LightBlue.Wrap(clusterName))
+	ux.Logger.PrintToUser(tit)
+	ux.Logger.PrintToUser(strings.Repeat("=", len(removeColors(tit))))
+	ux.Logger.PrintToUser("")
+	header := []string{"Cloud ID", "Node ID", "Subnet", "Start", "End", "Weight", "Uptime", "Potential Reward", "Delegators", "Remaining"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	table.SetAutoMergeCellsByColumnIndex([]int{0, 1})
+	for _, validation := range validations {
+		uptime := constants.NotAvailableLabel
+		if validation.Uptime != nil {
+			uptime = fmt.Sprintf("%.2f%%", *validation.Uptime)
+		}
+		reward := constants.NotAvailableLabel
+		if validation.PotentialReward != nil {
+			reward = fmt.Sprintf("%.9f", float64(*validation.PotentialReward)/float64(units.Avax))
+		}
+		table.Append([]string{
+			validation.CloudID,
+			validation.NodeID.String(),
+			validation.SubnetName,
+			validation.Start.Local().Format(constants.TimeParseLayout),
+			validation.End.Local().Format(constants.TimeParseLayout),
+			strconv.FormatUint(validation.Weight, 10),
+			uptime,
+			reward,
+			strconv.FormatUint(validation.Delegators, 10),
+			formatValidationRemaining(validation, now, threshold),
+		})
+	}
+	table.Render()
+}
+
+// printExpiringValidations warns about the validations of [clusterName] ending within [threshold]
+func printExpiringValidations(clusterName string, validations []node.Validation, threshold time.Duration) {
+	now := time.Now()
+	expiring := false
+	for _, validation := range validations {
+		if validation.ExpiresWithin(now, threshold) {
+			expiring = true
+			ux.Logger.RedXToUser(
+				"Validation of %s on %s ends in %s (at %s)",
+				validation.CloudID,
+				validation.SubnetName,
+				ux.FormatDuration(validation.Remaining(now).Truncate(time.Minute)),
+				validation.End.Local().Format(constants.TimeParseLayout),
+			)
+		}
+	}
+	if expiring {
+		ux.Logger.PrintToUser("Primary Network validations can be renewed with avalanche node validate renew %s", clusterName)
+	}
+}
@@ -4,24 +4,19 @@ package nodecmd
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/ixAnkit/cryft/pkg/ansible"
 	"github.com/ixAnkit/cryft/pkg/application"
-	awsAPI "github.com/ixAnkit/cryft/pkg/cloud/aws"
-	gcpAPI "github.com/ixAnkit/cryft/pkg/cloud/gcp"
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/ssh"
 	"github.com/ixAnkit/cryft/pkg/utils"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/MetalBlockchain/metalgo/utils/logging"
-	"github.com/pingcap/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slices"
-	"golang.org/x/net/context"
 )
 
 var (
@@ -39,7 +34,10 @@ func newWhitelistCmd() *cobra.Command {
 
 	Command adds IP if --ip params provided to cloud security access rules allowing it to access all nodes in the cluster via ssh or http.
 	It also command adds SSH public key to all nodes in the cluster if --ssh params is there.
-	If no params provided it detects current user IP automaticaly and whitelists it`,
+	If no params provided it detects current user IP automaticaly and whitelists it
+
+	Granted access can be listed with node whitelist list, revoked with node whitelist revoke,
+	and overly broad firewall rules are flagged by node whitelist audit.`,
 		SilenceUsage: true,
 		Args:         cobra.MinimumNArgs(1),
 		RunE:         whitelist,
@@ -47,6 +45,12 @@ func newWhitelistCmd() *cobra.Command {
 	cmd.Flags().StringVar(&userIPAddress, "ip", "", "ip address to whitelist")
 	cmd.Flags().StringVar(&userPubKey, "ssh", "", "ssh public key to whitelist")
 	cmd.Flags().BoolVarP(&discoverIP, "current-ip", "y", false, "whitelist current host ip")
+	// node whitelist list cluster
+	cmd.AddCommand(newWhitelistListCmd())
+	// node whitelist revoke cluster
+	cmd.AddCommand(newWhitelistRevokeCmd())
+	// node whitelist audit cluster
+	cmd.AddCommand(newWhitelistAuditCmd())
 	return cmd
 }
 
@@ -140,26 +144,13 @@ func whitelist(_ *cobra.Command, args []string) error {
 			return fmt.Errorf("no nodes found in cluster %s", clusterName)
 		}
 
-		// GCP doesn't have regions  so we need to reduce it to only list of security groups
-		gcpSGFound := false
 		// whitelist IP
 		for _, cloudSecurityGroup := range cloudSecurityGroupList {
-			if cloudSecurityGroup.cloud == constants.GCPCloudService {
-				gcpSGFound = true
+			if cloudSecurityGroup.cloud == constants.DockerCloudService || cloudSecurityGroup.cloud == constants.ExistingHostsCloudService {
+				// firewalls of local docker hosts and existing hosts are not managed by the CLI
 				continue
 			}
-			ux.Logger.GreenCheckmarkToUser("Whitelisting IP %s in %s cloud region %s", userIPAddress, cloudSecurityGroup.cloud, cloudSecurityGroup.region)
-			if cloudSecurityGroup.cloud == "" || cloudSecurityGroup.cloud == constants.AWSCloudService {
-				if cloudSecurityGroup.cloud == "" || cloudSecurityGroup.cloud == constants.AWSCloudService {
-					if err := GrantAccessToIPinAWS(awsProfile, cloudSecurityGroup.region, cloudSecurityGroup.securityGroup, userIPAddress); err != nil {
-						return err
-					}
-				}
-			}
-		}
-		if gcpSGFound {
-			ux.Logger.GreenCheckmarkToUser("Whitelisting IP %s in %s cloud", userIPAddress, constants.GCPCloudService)
-			if err := GrantAccessToIPinGCP(userIPAddress); err != nil {
+			if err := grantAccessToIP(cloudSecurityGroup, userIPAddress); err != nil {
 				return err
 			}
 		}
@@ -167,63 +158,16 @@ func whitelist(_ *cobra.Command, args []string) error {
 	return nil
 }
 
-func GrantAccessToIPinAWS(awsProfile string, region string, sgName string, userIPAddress string) error {
-	ec2Svc, err := awsAPI.NewAwsCloud(awsProfile, region)
-	if err != nil {
-		return fmt.Errorf("failed to establish connection to %s cloud region %s with err: %w", constants.AWSCloudService, region, err)
-	}
-	securityGroupExists, sg, err := ec2Svc.CheckSecurityGroupExists(sgName)
-	if err != nil || !securityGroupExists {
-		return fmt.Errorf("can't find security group %s in %s cloud region %s with err: %w", sgName, constants.AWSCloudService, region, err)
-	}
-	ipInTCP := awsAPI.CheckIPInSg(&sg, userIPAddress, constants.SSHTCPPort)
-	ipInHTTP := awsAPI.CheckIPInSg(&sg, userIPAddress, constants.AvalanchegoAPIPort)
-	ipInGrafana := awsAPI.CheckIPInSg(&sg, userIPAddress, constants.AvalanchegoGrafanaPort)
-	if ipInTCP {
-		ux.Logger.RedXToUser("IP %s is already whitelisted in %s cloud region %s for ssh access. Skipping...", userIPAddress, constants.AWSCloudService, region)
-	} else {
-		if err := ec2Svc.AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", userIPAddress, constants.SSHTCPPort); err != nil {
-			return fmt.Errorf("failed to whitelist IP %s in %s cloud region %s for ssh access with err: %w", userIPAddress, constants.AWSCloudService, region, err)
-		}
-	}
-	if ipInHTTP {
-		ux.Logger.RedXToUser("IP %s is already whitelisted in %s cloud region %s for http access. Skipping...", userIPAddress, constants.AWSCloudService, region)
-	} else {
-		if err := ec2Svc.AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", userIPAddress, constants.AvalanchegoAPIPort); err != nil {
-			return fmt.Errorf("failed to whitelist IP %s in %s cloud region %s for http access with err: %w", userIPAddress, constants.AWSCloudService, region, err)
-		}
-	}
-	if ipInGrafana {
-		ux.Logger.RedXToUser("IP %s is already whitelisted in %s cloud region %s for grafana access. Skipping...", userIPAddress, constants.AWSCloudService, region)
-	} else {
-		if err := ec2Svc.AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", userIPAddress, constants.AvalanchegoGrafanaPort); err != nil {
-			return fmt.Errorf("failed to whitelist IP %s in %s cloud region %s for grafana access with err: %w", userIPAddress, constants.AWSCloudService, region, err)
-		}
-	}
-	return nil
-}
-
-func GrantAccessToIPinGCP(userIPAddress string) error {
-	prefix, err := defaultAvalancheCLIPrefix("")
+// grantAccessToIP allows [ip] ssh, http and grafana access to the hosts of [cloudSecurityGroup]
+func grantAccessToIP(cloudSecurityGroup regionSecurityGroup, ip string) error {
+	provider, err := getCloudProvider(cloudSecurityGroup.cloud)
 	if err != nil {
 		return err
 	}
-	networkName := fmt.Sprintf("%s-network", prefix)
-	gcpClient, projectName, _, err := getGCPCloudCredentials()
-	if err != nil {
-		return err
-	}
-	gcpCloud, err := gcpAPI.NewGcpCloud(gcpClient, projectName, context.Background())
-	if err != nil {
-		return err
-	}
-	ux.Logger.PrintToUser("Whitelisting IP %s in %s cloud", userIPAddress, constants.GCPCloudService)
-	if _, err = gcpCloud.SetFirewallRule(userIPAddress, fmt.Sprintf("%s-%s", networkName, strings.ReplaceAll(userIPAddress, ".", "")), networkName, []string{strconv.Itoa(constants.SSHTCPPort), strconv.Itoa(constants.AvalanchegoAPIPort), strconv.Itoa(constants.AvalanchegoGrafanaPort)}); err != nil {
-		if errors.IsAlreadyExists(err) {
-			return fmt.Errorf("IP %s is already whitelisted in %s cloud. Skipping... ", userIPAddress, constants.GCPCloudService)
-		} else {
-			return fmt.Errorf("failed to whitelist IP %s in %s cloud with err: %w", userIPAddress, constants.GCPCloudService, err)
-		}
+	ux.Logger.GreenCheckmarkToUser("Whitelisting IP %s in %s cloud region %s", ip, provider.Name(), cloudSecurityGroup.region)
+	ports := []int{constants.SSHTCPPort, constants.AvalanchegoAPIPort, constants.AvalanchegoGrafanaPort}
+	if err := provider.AddFirewallRule(cloudSecurityGroup.region, cloudSecurityGroup.securityGroup, ip, ports); err != nil {
+		return fmt.Errorf("failed to whitelist IP %s in %s cloud region %s with err: %w", ip, provider.Name(), cloudSecurityGroup.region, err)
 	}
 	return nil
 }
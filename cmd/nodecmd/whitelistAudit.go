@@ -0,0 +1,63 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+)
+
+func newWhitelistAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit [clusterName]",
+		Short: "(ALPHA Warning) Flag overly broad firewall rules of a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node whitelist audit command checks the firewall rules of the security groups of all
+regions of a cluster, and flags the rules allowing any address (0.0.0.0/0 or ::/0), or IPv4
+blocks wider than /16 and IPv6 blocks wider than /48, on the SSH, API or monitoring ports.
+The P2P and Loki ports are open to the internet by design and are not flagged.
+
+The command fails if any rule is flagged, so that it can be run periodically.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         whitelistAudit,
+	}
+	return cmd
+}
+
+func whitelistAudit(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	sgRules, err := getClusterFirewallRules(clusterName)
+	if err != nil {
+		return err
+	}
+	numFindings := 0
+	for _, sg := range sgRules {
+		location := fmt.Sprintf("%s cloud network %s", sg.provider.Name(), sg.securityGroup)
+		if sg.region != "" {
+			location = fmt.Sprintf("%s cloud region %s security group %s", sg.provider.Name(), sg.region, sg.securityGroup)
+		}
+		for _, finding := range cloud.AuditFirewallRules(sg.rules, whitelistAuditPorts) {
+			numFindings++
+			ux.Logger.RedXToUser("%s: %s", location, finding)
+			ux.Logger.PrintToUser(
+				"  Revoke it with avalanche node whitelist revoke %s --ip %s --ports %d",
+				clusterName,
+				finding.Rule.CIDR,
+				finding.Port,
+			)
+		}
+	}
+	if numFindings > 0 {
+		return fmt.Errorf("found %d overly broad firewall rule(s) in cluster %s", numFindings, clusterName)
+	}
+	ux.Logger.GreenCheckmarkToUser("No overly broad firewall rules found in cluster %s", clusterName)
+	return nil
+}
@@ -0,0 +1,267 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+// whitelistAuditPorts are the ports flagged when open to broad address blocks, by port.
+// The P2P and Loki ports are open to the internet by design
+var whitelistAuditPorts = map[int]string{
+	constants.SSHTCPPort:                    "SSH",
+	constants.AvalanchegoAPIPort:            "API",
+	constants.AvalanchegoGrafanaPort:        "Grafana",
+	constants.AvalanchegoMonitoringPort:     "Prometheus",
+	constants.AvalanchegoMachineMetricsPort: "Machine metrics",
+	constants.ValidatorMetricsPort:          "Validator metrics",
+	constants.AWMRelayerMetricsPort:         "Relayer metrics",
+}
+
+// securityGroupRules are the firewall rules of a security group of a cluster
+type securityGroupRules struct {
+	regionSecurityGroup
+	provider cloud.Provider
+	rules    []cloud.FirewallRule
+}
+
+// clusterSSHKey is an ssh key authorized on hosts of a cluster
+type clusterSSHKey struct {
+	utils.AuthorizedKey
+	cloudIDs []string
+}
+
+func newWhitelistListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [clusterName]",
+		Short: "(ALPHA Warning) List the IPs and SSH keys with access to a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node whitelist list command lists the firewall rules of the security groups of all regions
+of a cluster, and the SSH public keys authorized on its nodes. Rules allowing broad address
+blocks on SSH, API or monitoring ports are flagged as by avalanche node whitelist audit.
+
+Firewalls of local docker hosts and existing hosts are not managed by the CLI and are not listed.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         whitelistList,
+	}
+	return cmd
+}
+
+func whitelistList(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	sgRules, err := getClusterFirewallRules(clusterName)
+	if err != nil {
+		return err
+	}
+	sshKeys, err := getClusterSSHKeys(clusterName)
+	if err != nil {
+		return err
+	}
+	printClusterFirewallRules(clusterName, sgRules)
+	return printClusterSSHKeys(clusterName, sshKeys)
+}
+
+// getClusterFirewallRules returns the firewall rules of the security groups of all regions
+// of [clusterName]. All GCP regions share the same network
+func getClusterFirewallRules(clusterName string) ([]securityGroupRules, error) {
+	clusterNodes, err := getClusterNodes(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	cloudSecurityGroupList, err := getCloudSecurityGroupList(clusterNodes)
+	if err != nil {
+		return nil, err
+	}
+	providers := map[string]cloud.Provider{}
+	sgRules := []securityGroupRules{}
+	for _, sg := range cloudSecurityGroupList {
+		if sg.cloud == constants.DockerCloudService || sg.cloud == constants.ExistingHostsCloudService {
+			// firewalls of local docker hosts and existing hosts are not managed by the CLI
+			continue
+		}
+		if sg.cloud == constants.GCPCloudService {
+			sg.region = ""
+			if slices.Contains(utils.Map(sgRules, func(r securityGroupRules) regionSecurityGroup { return r.regionSecurityGroup }), sg) {
+				continue
+			}
+		}
+		provider, ok := providers[sg.cloud]
+		if !ok {
+			provider, err = getCloudProvider(sg.cloud)
+			if err != nil {
+				return nil, err
+			}
+			providers[sg.cloud] = provider
+		}
+		rules, err := provider.ListFirewallRules(sg.region, sg.securityGroup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the firewall rules of %s in %s cloud: %w", sg.securityGroup, provider.Name(), err)
+		}
+		sgRules = append(sgRules, securityGroupRules{
+			regionSecurityGroup: sg,
+			provider:            provider,
+			rules:               rules,
+		})
+	}
+	return sgRules, nil
+}
+
+// getClusterSSHKeys returns the ssh keys authorized on the hosts of [clusterName]
+func getClusterSSHKeys(clusterName string) ([]clusterSSHKey, error) {
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return nil, err
+	}
+	defer disconnectHosts(hosts)
+	wg := sync.WaitGroup{}
+	wgResults := models.NodeResults{}
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, host *models.Host) {
+			defer wg.Done()
+			content, err := ssh.RunSSHGetAuthorizedKeys(host)
+			if err != nil {
+				nodeResults.AddResult(host.GetCloudID(), nil, err)
+				return
+			}
+			nodeResults.AddResult(host.GetCloudID(), utils.ParseAuthorizedKeys(content), nil)
+		}(&wgResults, host)
+	}
+	wg.Wait()
+	if wgResults.HasErrors() {
+		return nil, fmt.Errorf("failed to get the SSH keys of node(s) %s", wgResults.GetErrorHostMap())
+	}
+	keys := map[string]*clusterSSHKey{}
+	for cloudID, result := range wgResults.GetResultMap() {
+		for _, authorizedKey := range result.([]utils.AuthorizedKey) {
+			key, ok := keys[authorizedKey.Fingerprint]
+			if !ok {
+				key = &clusterSSHKey{AuthorizedKey: authorizedKey}
+				keys[authorizedKey.Fingerprint] = key
+			}
+			key.cloudIDs = append(key.cloudIDs, cloudID)
+		}
+	}
+	sshKeys := []clusterSSHKey{}
+	for _, key := range keys {
+		sort.Strings(key.cloudIDs)
+		sshKeys = append(sshKeys, *key)
+	}
+	sort.Slice(sshKeys, func(i, j int) bool {
+		if sshKeys[i].Comment != sshKeys[j].Comment {
+			return sshKeys[i].Comment < sshKeys[j].Comment
+		}
+		return sshKeys[i].Fingerprint < sshKeys[j].Fingerprint
+	})
+	return sshKeys, nil
+}
+
+// getSSHKeyFingerprints returns the fingerprints of the ssh keys the CLI uses to connect
+// to [hosts], when they can be read
+func getSSHKeyFingerprints(hosts []*models.Host) []string {
+	fingerprints := []string{}
+	for _, host := range hosts {
+		if host.SSHPrivateKeyPath == "" {
+			continue
+		}
+		fingerprint, err := utils.GetSSHPublicKeyFingerprint(utils.ExpandHome(host.SSHPrivateKeyPath))
+		if err != nil {
+			continue
+		}
+		if !slices.Contains(fingerprints, fingerprint) {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	return fingerprints
+}
+
+func printClusterFirewallRules(clusterName string, sgRules []securityGroupRules) {
+	ux.Logger.PrintToUser("")
+	tit := fmt.Sprintf("FIREWALL RULES OF CLUSTER: %s", logging.LightBlue.Wrap(clusterName))
+	ux.Logger.PrintToUser(tit)
+	ux.Logger.PrintToUser(strings.Repeat("=", len(removeColors(tit))))
+	ux.Logger.PrintToUser("")
+	header := []string{"Cloud", "Region", "Security Group", "Firewall", "Protocol", "Source", "Ports", "Audit"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	table.SetAutoMergeCellsByColumnIndex([]int{0, 1, 2})
+	for _, sg := range sgRules {
+		for _, rule := range sg.rules {
+			audit := logging.Green.Wrap("OK")
+			if findings := cloud.AuditFirewallRules([]cloud.FirewallRule{rule}, whitelistAuditPorts); len(findings) > 0 {
+				audit = logging.Red.Wrap(strings.Join(utils.Map(findings, func(f cloud.FirewallFinding) string { return f.PortName }), ", ") + " open")
+			}
+			region := sg.region
+			if region == "" {
+				region = constants.NotAvailableLabel
+			}
+			firewall := rule.Name
+			if firewall == "" {
+				firewall = constants.NotAvailableLabel
+			}
+			table.Append([]string{
+				sg.provider.Name(),
+				region,
+				sg.securityGroup,
+				firewall,
+				rule.Protocol,
+				rule.CIDR,
+				rule.Ports(),
+				audit,
+			})
+		}
+	}
+	table.Render()
+}
+
+func printClusterSSHKeys(clusterName string, sshKeys []clusterSSHKey) error {
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return err
+	}
+	clusterKeys := getSSHKeyFingerprints(hosts)
+	ux.Logger.PrintToUser("")
+	tit := fmt.Sprintf("SSH KEYS OF CLUSTER: %s", logging.LightBlue.Wrap(clusterName))
+	ux.Logger.PrintToUser(tit)
+	ux.Logger.PrintToUser(strings.Repeat("=", len(removeColors(tit))))
+	ux.Logger.PrintToUser("")
+	header := []string{"Fingerprint", "Type", "Comment", "Nodes"}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetRowLine(true)
+	for _, key := range sshKeys {
+		comment := key.Comment
+		if slices.Contains(clusterKeys, key.Fingerprint) {
+			comment = strings.TrimSpace(comment + " " + logging.LightBlue.Wrap("(cluster key)"))
+		}
+		nodes := strings.Join(key.cloudIDs, "\n")
+		if len(key.cloudIDs) == len(hosts) {
+			nodes = "all"
+		}
+		table.Append([]string{key.Fingerprint, key.Type, comment, nodes})
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package nodecmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ixAnkit/cryft/pkg/ansible"
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ssh"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+var revokePorts []int
+
+func newWhitelistRevokeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [clusterName] [--ip <IP>] [--ssh \"<sshPubKey>\"]",
+		Short: "(ALPHA Warning) Revoke access to a cluster",
+		Long: `(ALPHA Warning) This command is currently in experimental mode.
+
+The node whitelist revoke command revokes access to a cluster granted with avalanche node whitelist.
+
+--ip removes the firewall rules allowing the given IP or CIDR block from the security groups of
+all regions of the cluster. --ports restricts it to the rules allowing any of the given ports, e.g.
+to close SSH to 0.0.0.0/0 while keeping the P2P port open. Rules are revoked in full, so the
+command refuses to revoke a rule that also allows ports not given with --ports, such as an
+all ports or port range rule, and nothing is revoked until it is narrowed in the cloud console.
+
+--ssh removes the given SSH public key from all nodes of the cluster. The key can be given as
+public key, SHA256 fingerprint or comment, as shown by avalanche node whitelist list. The keys
+the CLI uses to manage the cluster can't be revoked.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         whitelistRevoke,
+	}
+	cmd.Flags().StringVar(&userIPAddress, "ip", "", "ip address or CIDR block to revoke")
+	cmd.Flags().IntSliceVar(&revokePorts, "ports", []int{}, "only revoke the rules allowing any of the given ports")
+	cmd.Flags().StringVar(&userPubKey, "ssh", "", "ssh public key, fingerprint or comment to revoke")
+	return cmd
+}
+
+func whitelistRevoke(_ *cobra.Command, args []string) error {
+	clusterName := args[0]
+	if err := checkCluster(clusterName); err != nil {
+		return err
+	}
+	if userIPAddress == "" && userPubKey == "" {
+		return fmt.Errorf("at least one of --ip or --ssh must be given")
+	}
+	if userIPAddress == "" && len(revokePorts) > 0 {
+		return fmt.Errorf("--ports can only be used with --ip")
+	}
+	if userPubKey != "" {
+		if err := revokeSSHPubKey(clusterName, userPubKey); err != nil {
+			return err
+		}
+		if userIPAddress == "" {
+			return nil
+		}
+		ux.Logger.PrintLineSeparator()
+	}
+	return revokeIP(clusterName, userIPAddress, revokePorts)
+}
+
+// revokeIP removes the rules allowing [ip] on any of [ports], or on any port if empty, from
+// the security groups of all regions of [clusterName]
+func revokeIP(clusterName string, ip string, ports []int) error {
+	cidr, err := cloud.NormalizeCIDR(ip)
+	if err != nil {
+		return err
+	}
+	sgRules, err := getClusterFirewallRules(clusterName)
+	if err != nil {
+		return err
+	}
+	revokedRules := make([][]cloud.FirewallRule, len(sgRules))
+	wideRules := []string{}
+	for i, sg := range sgRules {
+		revokedRules[i] = utils.Filter(sg.rules, func(rule cloud.FirewallRule) bool {
+			if !rule.MatchesCIDR(cidr) {
+				return false
+			}
+			return len(ports) == 0 || slices.ContainsFunc(ports, rule.AllowsPort)
+		})
+		for _, rule := range revokedRules[i] {
+			if len(ports) > 0 && !rule.AllowsOnlyPorts(ports) {
+				wideRules = append(wideRules, fmt.Sprintf("%s %s ports %s in %s", rule.CIDR, rule.Protocol, rule.Ports(), firewallLocation(sg.provider, sg.region)))
+			}
+		}
+	}
+	if len(wideRules) > 0 {
+		return fmt.Errorf(
+			"refusing to revoke rules that also allow ports not given with --ports: %s. Revoke them without --ports, or narrow them in the cloud console first",
+			strings.Join(wideRules, "; "),
+		)
+	}
+	revoked := false
+	for i, sg := range sgRules {
+		rules := revokedRules[i]
+		if len(rules) == 0 {
+			continue
+		}
+		revoked = true
+		location := firewallLocation(sg.provider, sg.region)
+		ux.Logger.GreenCheckmarkToUser(
+			"Revoking IP %s on ports %s in %s",
+			logging.LightBlue.Wrap(cidr),
+			strings.Join(utils.Map(rules, func(rule cloud.FirewallRule) string { return rule.Ports() }), ", "),
+			location,
+		)
+		if err := sg.provider.RevokeFirewallRules(sg.region, sg.securityGroup, rules); err != nil {
+			return fmt.Errorf("failed to revoke IP %s in %s: %w", cidr, location, err)
+		}
+	}
+	if !revoked {
+		return fmt.Errorf("IP %s is not whitelisted in cluster %s", cidr, clusterName)
+	}
+	return nil
+}
+
+// firewallLocation returns where the security group of [provider] in [region] is, as shown
+// to the user
+func firewallLocation(provider cloud.Provider, region string) string {
+	if region == "" {
+		return fmt.Sprintf("%s cloud", provider.Name())
+	}
+	return fmt.Sprintf("%s cloud region %s", provider.Name(), region)
+}
+
+// revokeSSHPubKey removes [pubkey], given as public key, fingerprint or comment, from
+// all nodes of [clusterName]
+func revokeSSHPubKey(clusterName string, pubkey string) error {
+	hosts, err := ansible.GetInventoryFromAnsibleInventoryFile(app.GetAnsibleInventoryDirPath(clusterName))
+	if err != nil {
+		return err
+	}
+	defer disconnectHosts(hosts)
+	clusterKeys := getSSHKeyFingerprints(hosts)
+	ux.Logger.PrintToUser("Revoking SSH public key on all nodes in cluster: %s", logging.LightBlue.Wrap(clusterName))
+	wg := sync.WaitGroup{}
+	wgResults := models.NodeResults{}
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(nodeResults *models.NodeResults, host *models.Host) {
+			defer wg.Done()
+			content, err := ssh.RunSSHGetAuthorizedKeys(host)
+			if err != nil {
+				nodeResults.AddResult(host.NodeID, nil, err)
+				return
+			}
+			content, removed := utils.RemoveAuthorizedKeys(content, pubkey)
+			if len(removed) == 0 {
+				nodeResults.AddResult(host.NodeID, false, nil)
+				return
+			}
+			for _, key := range removed {
+				if slices.Contains(clusterKeys, key.Fingerprint) {
+					nodeResults.AddResult(host.NodeID, nil, fmt.Errorf("refusing to revoke SSH key %s used to manage the cluster", key.Fingerprint))
+					return
+				}
+			}
+			if len(utils.ParseAuthorizedKeys(content)) == 0 {
+				nodeResults.AddResult(host.NodeID, nil, fmt.Errorf("refusing to revoke the last SSH key of the node"))
+				return
+			}
+			if err := ssh.RunSSHSetAuthorizedKeys(host, content); err != nil {
+				nodeResults.AddResult(host.NodeID, nil, err)
+				return
+			}
+			nodeResults.AddResult(host.NodeID, true, nil)
+			ux.Logger.GreenCheckmarkToUser(utils.ScriptLog(host.NodeID, "Revoked SSH public key %s", removed[0].Fingerprint))
+		}(&wgResults, host)
+	}
+	wg.Wait()
+	if wgResults.HasErrors() {
+		ux.Logger.RedXToUser("Failed to revoke SSH public key for node(s) %s", wgResults.GetErrorHostMap())
+		return fmt.Errorf("failed to revoke SSH public key for node(s) %s", wgResults.GetErrorHostMap())
+	}
+	for _, result := range wgResults.GetResultMap() {
+		if result.(bool) {
+			return nil
+		}
+	}
+	return fmt.Errorf("SSH key %s is not whitelisted in cluster %s", pubkey, clusterName)
+}
@@ -74,6 +74,8 @@ The node wiz command creates a devnet and deploys, sync and validate a subnet in
 	cmd.Flags().BoolVar(&useStaticIP, "use-static-ip", true, "attach static Public IP on cloud servers")
 	cmd.Flags().BoolVar(&useAWS, "aws", false, "create node/s in AWS cloud")
 	cmd.Flags().BoolVar(&useGCP, "gcp", false, "create node/s in GCP cloud")
+	cmd.Flags().BoolVar(&useDocker, "docker", false, "create node/s as local docker containers")
+	cmd.Flags().StringVar(&existingHostsFile, "existing-hosts", "", "adopt the servers listed in the given hosts yaml file instead of creating cloud instances")
 	cmd.Flags().StringSliceVar(&cmdLineRegion, "region", []string{}, "create node/s in given region(s). Use comma to separate multiple regions")
 	cmd.Flags().BoolVar(&authorizeAccess, "authorize-access", false, "authorize CLI to create cloud resources")
 	cmd.Flags().IntSliceVar(&numValidatorsNodes, "num-validators", []int{}, "number of nodes to create per region(s). Use comma to separate multiple numbers for each region in the same order as --region flag")
@@ -332,6 +334,19 @@ func wiz(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if awmRelayerHost != nil {
+		ux.Logger.PrintToUser("")
+		ux.Logger.PrintToUser(logging.Green.Wrap("Setting up AWM Relayer Service"))
+		ux.Logger.PrintToUser("")
+		clusterConfig, err := app.GetClusterConfig(clusterName)
+		if err != nil {
+			return err
+		}
+		if err := updateClusterRelayer(clusterName, awmRelayerHost, clusterConfig.Subnets); err != nil {
+			return err
+		}
+	}
+
 	ux.Logger.PrintToUser("")
 	if clusterAlreadyExists {
 		ux.Logger.PrintToUser(logging.Green.Wrap("Devnet %s is now validating subnet %s"), clusterName, subnetName)
@@ -408,13 +423,18 @@ func setUpSubnetLogging(clusterName, subnetName string) error {
 			ux.Logger.RedXToUser("Node %s is ERROR with error: %s", node.NodeID, wgResults.GetErrorHostMap()[node.NodeID])
 		}
 	}
-	avalancheGoPorts, machinePorts, ltPorts, err := getPrometheusTargets(clusterName)
+	avalancheGoPorts, machinePorts, ltPorts, relayerPorts, err := getPrometheusTargets(clusterName)
 	if err != nil {
 		return err
 	}
 	monitoringHost := monitoringHosts[0]
 	spinner := spinSession.SpinToUser(utils.ScriptLog(monitoringHost.NodeID, "Update Monitoring Targets"))
-	if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts); err != nil {
+	if err := ssh.RunSSHUpdatePrometheusConfig(monitoringHost, avalancheGoPorts, machinePorts, ltPorts, relayerPorts); err != nil {
+		ux.SpinFailWithError(spinner, "", err)
+		return err
+	}
+	// the deployed subnet chain is now watched by the alert rules
+	if err := updateClusterAlertRules(clusterName, monitoringHost); err != nil {
 		ux.SpinFailWithError(spinner, "", err)
 		return err
 	}
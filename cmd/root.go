@@ -23,6 +23,7 @@ import (
 	"github.com/ixAnkit/cryft/cmd/keycmd"
 	"github.com/ixAnkit/cryft/cmd/networkcmd"
 	"github.com/ixAnkit/cryft/cmd/subnetcmd"
+	"github.com/ixAnkit/cryft/cmd/teleportercmd"
 	"github.com/ixAnkit/cryft/cmd/transactioncmd"
 	"github.com/ixAnkit/cryft/cmd/updatecmd"
 	"github.com/ixAnkit/cryft/internal/migrations"
@@ -88,6 +89,9 @@ in with metal subnet create myNewSubnet.`,
 	// add node command
 	rootCmd.AddCommand(nodecmd.NewCmd(app))
 
+	// add teleporter command
+	rootCmd.AddCommand(teleportercmd.NewCmd(app))
+
 	return rootCmd
 }
 
@@ -3,8 +3,11 @@
 package subnetcmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,8 +18,11 @@ import (
 	"github.com/ixAnkit/cryft/pkg/metrics"
 
 	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/ixAnkit/cryft/pkg/vm"
+	"github.com/MetalBlockchain/subnet-evm/core"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
 	"golang.org/x/mod/semver"
 )
@@ -48,6 +54,8 @@ var (
 		"illegal name character: only letters, no special characters allowed")
 	errMutuallyExlusiveVersionOptions = errors.New("version flags --latest,--pre-release,vm-version are mutually exclusive")
 	errMutuallyVMConfigOptions        = errors.New("specifying --genesis flag disables SubnetEVM config flags --evm-chain-id,--evm-token,--evm-defaults")
+	errTeleporterWithoutWarp          = errors.New("teleporter requires warp support. do not use --warp=false together with --teleporter")
+	errTeleporterNonEVM               = errors.New("teleporter is only supported on Subnet-EVM based vms")
 )
 
 // avalanche subnet create
@@ -250,8 +258,28 @@ func createSubnetConfig(cmd *cobra.Command, args []string) error {
 
 	if isSubnetEVMGenesis := jsonIsSubnetEVMGenesis(genesisBytes); isSubnetEVMGenesis {
 		if evmDefaults {
+			teleporterReady = true
 			runRelayer = true
 		}
+		if teleporterReady {
+			if !useWarp {
+				return errTeleporterWithoutWarp
+			}
+			teleporterInfo, err := teleporter.GetInfo(app)
+			if err != nil {
+				return err
+			}
+			genesisBytes, err = addPrefundedAddressToGenesis(genesisBytes, teleporterInfo.FundedAddress, teleporterInfo.FundedBalance)
+			if err != nil {
+				return err
+			}
+			sc.TeleporterReady = true
+			sc.TeleporterKey = constants.TeleporterKeyName
+			sc.TeleporterVersion = teleporterInfo.Version
+			sc.RunRelayer = runRelayer
+		}
+	} else if teleporterReady {
+		return errTeleporterNonEVM
 	}
 
 	if err = app.WriteGenesisFile(subnetName, genesisBytes); err != nil {
@@ -313,3 +341,29 @@ func checkInvalidSubnetNames(name string) error {
 
 	return nil
 }
+
+// addPrefundedAddressToGenesis adds [balance] to the genesis allocation of [address]
+func addPrefundedAddressToGenesis(genesisBytes []byte, address string, balance *big.Int) ([]byte, error) {
+	genesis, err := app.LoadEvmGenesisFromJSON(genesisBytes)
+	if err != nil {
+		return nil, err
+	}
+	if genesis.Alloc == nil {
+		genesis.Alloc = core.GenesisAlloc{}
+	}
+	account := genesis.Alloc[common.HexToAddress(address)]
+	if account.Balance == nil {
+		account.Balance = new(big.Int)
+	}
+	account.Balance = new(big.Int).Add(account.Balance, balance)
+	genesis.Alloc[common.HexToAddress(address)] = account
+	jsonBytes, err := genesis.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, jsonBytes, "", "    "); err != nil {
+		return nil, err
+	}
+	return prettyJSON.Bytes(), nil
+}
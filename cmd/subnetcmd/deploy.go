@@ -84,6 +84,7 @@ so you can take your locally tested Subnet and deploy it on Fuji or Mainnet.`,
 		Args:              cobra.ExactArgs(1),
 	}
 	networkoptions.AddNetworkFlagsToCmd(cmd, &globalNetworkFlags, true, deploySupportedNetworkOptions)
+	networkoptions.AddLocalInstanceFlagToCmd(cmd, &globalNetworkFlags)
 	cmd.Flags().StringVar(&userProvidedAvagoVersion, "avalanchego-version", "latest", "use this version of avalanchego (ex: v1.17.12)")
 	cmd.Flags().StringVarP(&keyName, "key", "k", "", "select the key to use [fuji/devnet deploy only]")
 	cmd.Flags().BoolVarP(&sameControlKey, "same-control-key", "s", false, "use the fee-paying key as control key")
@@ -370,7 +371,31 @@ func deploySubnet(cmd *cobra.Command, args []string) error {
 		flags := make(map[string]string)
 		flags[constants.Network] = network.Name()
 		metrics.HandleTracking(cmd, app, flags)
-		return app.UpdateSidecarNetworks(
+		// the deploy is recorded before teleporter is set up, so a teleporter failure
+		// does not lose track of the new blockchain
+		if err := app.UpdateSidecarNetworks(
+			&sidecar,
+			network,
+			deployInfo.SubnetID,
+			ids.Empty,
+			deployInfo.BlockchainID,
+			"",
+			"",
+		); err != nil {
+			return err
+		}
+		if !sidecar.TeleporterReady || skipLocalTeleporter {
+			return nil
+		}
+		deployInfo.TeleporterMessengerAddress, deployInfo.TeleporterRegistryAddress, err = subnet.DeployLocalTeleporter(
+			app,
+			sidecar,
+			deployInfo.BlockchainID,
+		)
+		if err != nil {
+			return err
+		}
+		if err := app.UpdateSidecarNetworks(
 			&sidecar,
 			network,
 			deployInfo.SubnetID,
@@ -378,7 +403,11 @@ func deploySubnet(cmd *cobra.Command, args []string) error {
 			deployInfo.BlockchainID,
 			deployInfo.TeleporterMessengerAddress,
 			deployInfo.TeleporterRegistryAddress,
-		)
+		); err != nil {
+			return err
+		}
+		// relayer config is regenerated so the new blockchain gets relayed
+		return subnet.RefreshLocalRelayer(app, sidecar.RunRelayer)
 	}
 
 	// from here on we are assuming a public deploy
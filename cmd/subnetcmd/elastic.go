@@ -83,7 +83,7 @@ mechanics will work.`,
 }
 
 func checkIfSubnetIsElasticOnLocal(sc models.Sidecar) bool {
-	if _, ok := sc.ElasticSubnet[models.Local.String()]; ok {
+	if _, ok := sc.ElasticSubnet[models.NewLocalNetwork().Name()]; ok {
 		return true
 	}
 	return false
@@ -181,7 +181,7 @@ func transformElasticSubnet(cmd *cobra.Command, args []string) error {
 
 	subnetID := sc.Networks[network.Name()].SubnetID
 	if os.Getenv(constants.SimulatePublicNetwork) != "" {
-		subnetID = sc.Networks[models.Local.String()].SubnetID
+		subnetID = sc.Networks[models.NewLocalNetwork().Name()].SubnetID
 	}
 	if subnetID == ids.Empty {
 		return errNoSubnetID
@@ -390,7 +390,7 @@ func transformElasticSubnetLocal(sc models.Sidecar, subnetName string, tokenName
 		return fmt.Errorf("%s is already an elastic subnet", subnetName)
 	}
 	var err error
-	subnetID := sc.Networks[models.Local.String()].SubnetID
+	subnetID := sc.Networks[models.NewLocalNetwork().Name()].SubnetID
 	if subnetID == ids.Empty {
 		return errNoSubnetID
 	}
@@ -450,7 +450,7 @@ func transformElasticSubnetLocal(sc models.Sidecar, subnetName string, tokenName
 
 	PrintTransformResults(subnetName, txID, subnetID, tokenName, tokenSymbol, assetID)
 	flags := make(map[string]string)
-	flags[constants.Network] = models.Local.String()
+	flags[constants.Network] = models.NewLocalNetwork().Name()
 	metrics.HandleTracking(cmd, app, flags)
 	return nil
 }
@@ -496,7 +496,7 @@ func getTokenSymbol() (string, error) {
 }
 
 func checkAllLocalNodesAreCurrentValidators(subnetID ids.ID) error {
-	api := constants.LocalAPIEndpoint
+	api := models.NewLocalNetwork().Endpoint
 	pClient := platformvm.NewClient(api)
 
 	ctx := context.Background()
@@ -571,7 +571,7 @@ func handleRemoveAndAddValidators(sc models.Sidecar, subnetID ids.ID, validator
 		return err
 	}
 	ux.Logger.PrintToUser(fmt.Sprintf("Validator %s removed", validator.String()))
-	assetID := sc.ElasticSubnet[models.Local.String()].AssetID
+	assetID := sc.ElasticSubnet[models.NewLocalNetwork().Name()].AssetID
 	txID, err := subnet.IssueAddPermissionlessValidatorTx(keyChain, subnetID, validator, stakedAmount, assetID, uint64(startTime.Unix()), uint64(endTime.Unix()))
 	if err != nil {
 		return err
@@ -358,7 +358,7 @@ func handleValidatorJoinElasticSubnet(sc models.Sidecar, network models.Network,
 
 	subnetID := sc.Networks[network.Name()].SubnetID
 	if os.Getenv(constants.SimulatePublicNetwork) != "" {
-		subnetID = sc.Networks[models.Local.String()].SubnetID
+		subnetID = sc.Networks[models.NewLocalNetwork().Name()].SubnetID
 	}
 	if subnetID == ids.Empty {
 		return errNoSubnetID
@@ -451,10 +451,10 @@ func handleValidatorJoinElasticSubnetLocal(sc models.Sidecar, network models.Net
 	if !checkIfSubnetIsElasticOnLocal(sc) {
 		return fmt.Errorf("%s is not an elastic subnet", subnetName)
 	}
-	assetID := sc.ElasticSubnet[models.Local.String()].AssetID
+	assetID := sc.ElasticSubnet[models.NewLocalNetwork().Name()].AssetID
 	testKey := genesis.EWOQKey
 	keyChain := secp256k1fx.NewKeychain(testKey)
-	subnetID := sc.Networks[models.Local.String()].SubnetID
+	subnetID := sc.Networks[models.NewLocalNetwork().Name()].SubnetID
 	txID, err := subnet.IssueAddPermissionlessValidatorTx(keyChain, subnetID, nodeID, stakedTokenAmount, assetID, uint64(start.Unix()), uint64(endTime.Unix()))
 	if err != nil {
 		return err
@@ -583,7 +583,7 @@ func promptStakeAmount(subnetName string, isValidator bool, network models.Netwo
 		if err != nil {
 			return 0, err
 		}
-		pClient := platformvm.NewClient(constants.LocalAPIEndpoint)
+		pClient := platformvm.NewClient(models.NewLocalNetwork().Endpoint)
 		walletBalance, err := getAssetBalance(pClient, ewoqPChainAddr, esc.AssetID)
 		if err != nil {
 			return 0, err
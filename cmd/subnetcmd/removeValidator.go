@@ -211,7 +211,7 @@ func removeFromLocal(subnetName string) error {
 		return err
 	}
 
-	subnetID := sc.Networks[models.Local.String()].SubnetID
+	subnetID := sc.Networks[models.NewLocalNetwork().Name()].SubnetID
 	if subnetID == ids.Empty {
 		return errNoSubnetID
 	}
@@ -106,7 +106,7 @@ func applyCmd(_ *cobra.Command, args []string) error {
 	switch networkToUpgrade {
 	// update a locally running network
 	case localDeployment:
-		return applyLocalNetworkUpgrade(subnetName, models.Local.String(), &sc)
+		return applyLocalNetworkUpgrade(subnetName, models.NewLocalNetwork().Name(), &sc)
 	case fujiDeployment:
 		return applyPublicNetworkUpgrade(subnetName, models.Tahoe.String(), &sc)
 	case mainnetDeployment:
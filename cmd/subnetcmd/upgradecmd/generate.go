@@ -377,7 +377,7 @@ func getCClient(apiEndpoint string, blockchainID string) (ethclient.Client, erro
 }
 
 func ensureHaveBalanceLocalNetwork(which string, addresses []common.Address, blockchainID string) error {
-	cClient, err := getCClient(constants.LocalAPIEndpoint, blockchainID)
+	cClient, err := getCClient(models.NewLocalNetwork().Endpoint, blockchainID)
 	if err != nil {
 		return err
 	}
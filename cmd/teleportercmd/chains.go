@@ -0,0 +1,107 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleportercmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/key"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/MetalBlockchain/metalgo/ids"
+)
+
+// chainInfo describes a teleporter enabled chain of a given network
+type chainInfo struct {
+	Name             string
+	RPCURL           string
+	BlockchainID     ids.ID
+	MessengerAddress string
+	// Sidecar is nil for the C-Chain
+	Sidecar *models.Sidecar
+}
+
+func isCChain(chainName string) bool {
+	switch strings.ToLower(chainName) {
+	case "c", "cchain", "c-chain":
+		return true
+	}
+	return false
+}
+
+// getChainInfo returns the endpoint and teleporter messenger of [chainName] on [network].
+// [chainName] is either a subnet name or c-chain
+func getChainInfo(network models.Network, chainName string) (chainInfo, error) {
+	if isCChain(chainName) {
+		blockchainID, err := subnet.GetChainID(network, "C")
+		if err != nil {
+			return chainInfo{}, err
+		}
+		info := chainInfo{
+			Name:         "c-chain",
+			RPCURL:       network.CChainEndpoint(),
+			BlockchainID: blockchainID,
+		}
+		switch {
+		case network.Kind == models.Local:
+			data, err := subnet.GetExtraLocalNetworkData(app)
+			if err != nil {
+				return chainInfo{}, fmt.Errorf("teleporter has not been deployed to the local network c-chain: %w", err)
+			}
+			info.MessengerAddress = data.CChainTeleporterMessengerAddress
+		case network.ClusterName != "":
+			clusterConfig, err := app.GetClusterConfig(network.ClusterName)
+			if err != nil {
+				return chainInfo{}, err
+			}
+			info.MessengerAddress = clusterConfig.ExtraNetworkData.CChainTeleporterMessengerAddress
+		}
+		if info.MessengerAddress == "" {
+			return chainInfo{}, fmt.Errorf("teleporter messenger address not found for c-chain on %s", network.Name())
+		}
+		return info, nil
+	}
+	sc, err := app.LoadSidecar(chainName)
+	if err != nil {
+		return chainInfo{}, fmt.Errorf("failed to load sidecar: %w", err)
+	}
+	networkData, ok := sc.Networks[network.Name()]
+	if !ok || networkData.BlockchainID == ids.Empty {
+		return chainInfo{}, fmt.Errorf("subnet %s has not been deployed to %s", chainName, network.Name())
+	}
+	if networkData.TeleporterMessengerAddress == "" {
+		return chainInfo{}, fmt.Errorf("teleporter messenger address not found for subnet %s on %s", chainName, network.Name())
+	}
+	return chainInfo{
+		Name:             chainName,
+		RPCURL:           network.BlockchainEndpoint(networkData.BlockchainID.String()),
+		BlockchainID:     networkData.BlockchainID,
+		MessengerAddress: networkData.TeleporterMessengerAddress,
+		Sidecar:          &sc,
+	}, nil
+}
+
+// getPrivateKey returns the hex encoded private key that is going to pay for txs on
+// a chain: the given stored key, the subnet teleporter key, or ewoq on local and devnets
+func getPrivateKey(network models.Network, keyName string, sc *models.Sidecar) (string, error) {
+	var (
+		k   *key.SoftKey
+		err error
+	)
+	switch {
+	case keyName != "":
+		k, err = key.LoadSoft(network.ID, app.GetKeyPath(keyName))
+	case sc != nil && sc.TeleporterReady && sc.TeleporterKey != "":
+		k, err = key.LoadSoft(network.ID, app.GetKeyPath(sc.TeleporterKey))
+	case network.Kind == models.Local || network.Kind == models.Devnet:
+		k, err = key.LoadEwoq(network.ID)
+	default:
+		return "", fmt.Errorf("a key to pay for the txs must be given with --key")
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(k.Raw()), nil
+}
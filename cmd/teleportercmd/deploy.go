@@ -0,0 +1,186 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleportercmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/networkoptions"
+	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deploySupportedNetworkOptions = []networkoptions.NetworkOption{
+		networkoptions.Local,
+		networkoptions.Devnet,
+		networkoptions.Tahoe,
+		networkoptions.Mainnet,
+		networkoptions.Cluster,
+	}
+	globalNetworkFlags networkoptions.NetworkFlags
+	subnetName         string
+	deployToCChain     bool
+	keyName            string
+	teleporterVersion  string
+
+	errNoChainSelected = errors.New("one of --subnet or --c-chain must be given")
+)
+
+// avalanche teleporter deploy
+func newDeployCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploys Teleporter into a given Network and Subnet",
+		Long: `The teleporter deploy command deploys the Teleporter Messenger and Registry
+contracts into the C-Chain or into a Subnet-EVM based Subnet of the given network.
+
+The messenger is deployed using its keyless deployment transaction, so it gets
+the same address on every chain. The deployer address is funded from the
+paying key if needed. The registry is deployed having the messenger as its
+initial entry.
+
+If the messenger is already present on the chain, the registry recorded for the
+chain is kept. If there is none, a new registry is deployed and recorded.`,
+		SilenceUsage: true,
+		RunE:         deploy,
+		Args:         cobra.ExactArgs(0),
+	}
+	networkoptions.AddNetworkFlagsToCmd(cmd, &globalNetworkFlags, true, deploySupportedNetworkOptions)
+	networkoptions.AddLocalInstanceFlagToCmd(cmd, &globalNetworkFlags)
+	cmd.Flags().StringVar(&subnetName, "subnet", "", "deploy teleporter into the given subnet")
+	cmd.Flags().BoolVar(&deployToCChain, "c-chain", false, "deploy teleporter into the C-Chain")
+	cmd.Flags().StringVarP(&keyName, "key", "k", "", "CLI stored key to use to pay for the deploy fees (defaults to the subnet teleporter key, or ewoq on local network and devnets)")
+	cmd.Flags().StringVar(&teleporterVersion, "version", "", "teleporter version to deploy (defaults to the subnet teleporter version, or latest)")
+	return cmd
+}
+
+func deploy(_ *cobra.Command, _ []string) error {
+	if (subnetName == "") == !deployToCChain {
+		return errNoChainSelected
+	}
+	network, err := networkoptions.GetNetworkFromCmdLineFlags(
+		app,
+		globalNetworkFlags,
+		true,
+		deploySupportedNetworkOptions,
+		subnetName,
+	)
+	if err != nil {
+		return err
+	}
+	var (
+		sc        models.Sidecar
+		rpcURL    string
+		chainName string
+	)
+	if deployToCChain {
+		chainName = "c-chain"
+		rpcURL = network.CChainEndpoint()
+	} else {
+		sc, err = app.LoadSidecar(subnetName)
+		if err != nil {
+			return fmt.Errorf("failed to load sidecar: %w", err)
+		}
+		if sc.VM != models.SubnetEvm {
+			return fmt.Errorf("teleporter can only be deployed to Subnet-EVM based subnets")
+		}
+		blockchainID := sc.Networks[network.Name()].BlockchainID
+		if blockchainID == ids.Empty {
+			return fmt.Errorf("subnet %s has not been deployed to %s", subnetName, network.Name())
+		}
+		chainName = subnetName
+		rpcURL = network.BlockchainEndpoint(blockchainID.String())
+	}
+	var scPtr *models.Sidecar
+	if !deployToCChain {
+		scPtr = &sc
+	}
+	privateKey, err := getPrivateKey(network, keyName, scPtr)
+	if err != nil {
+		return err
+	}
+	version := teleporterVersion
+	if version == "" {
+		version = sc.TeleporterVersion
+	}
+	if version == "" {
+		version, err = teleporter.GetLatestVersion(app)
+		if err != nil {
+			return err
+		}
+	}
+	recordedRegistryAddress, err := getRecordedRegistryAddress(network, sc)
+	if err != nil {
+		return err
+	}
+	td := teleporter.Deployer{}
+	alreadyDeployed, messengerAddress, registryAddress, err := td.Deploy(app, version, chainName, rpcURL, privateKey, recordedRegistryAddress)
+	if err != nil {
+		return err
+	}
+	if alreadyDeployed && registryAddress == recordedRegistryAddress {
+		return nil
+	}
+	ux.Logger.PrintToUser("")
+	ux.Logger.PrintToUser("Teleporter Messenger Address: %s", messengerAddress)
+	ux.Logger.PrintToUser("Teleporter Registry Address:  %s", registryAddress)
+	return saveAddresses(network, sc, messengerAddress, registryAddress)
+}
+
+// getRecordedRegistryAddress returns the registry address recorded for the subnet, or
+// for the C-Chain of the local network or cluster, if any
+func getRecordedRegistryAddress(network models.Network, sc models.Sidecar) (string, error) {
+	if !deployToCChain {
+		return sc.Networks[network.Name()].TeleporterRegistryAddress, nil
+	}
+	switch {
+	case network.Kind == models.Local:
+		data, err := subnet.GetExtraLocalNetworkData(app)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return data.CChainTeleporterRegistryAddress, nil
+	case network.ClusterName != "":
+		clusterConfig, err := app.GetClusterConfig(network.ClusterName)
+		if err != nil {
+			return "", err
+		}
+		return clusterConfig.ExtraNetworkData.CChainTeleporterRegistryAddress, nil
+	}
+	return "", nil
+}
+
+// saveAddresses records the deployed contract addresses on the sidecar, for subnets,
+// or on the local network or cluster data, for the C-Chain
+func saveAddresses(network models.Network, sc models.Sidecar, messengerAddress string, registryAddress string) error {
+	if !deployToCChain {
+		networkData := sc.Networks[network.Name()]
+		networkData.TeleporterMessengerAddress = messengerAddress
+		networkData.TeleporterRegistryAddress = registryAddress
+		sc.Networks[network.Name()] = networkData
+		return app.UpdateSidecar(&sc)
+	}
+	switch {
+	case network.Kind == models.Local:
+		return subnet.WriteExtraLocalNetworkData(app, messengerAddress, registryAddress)
+	case network.ClusterName != "":
+		clusterConfig, err := app.GetClusterConfig(network.ClusterName)
+		if err != nil {
+			return err
+		}
+		clusterConfig.ExtraNetworkData.CChainTeleporterMessengerAddress = messengerAddress
+		clusterConfig.ExtraNetworkData.CChainTeleporterRegistryAddress = registryAddress
+		return app.SetClusterConfig(network.ClusterName, clusterConfig)
+	}
+	return nil
+}
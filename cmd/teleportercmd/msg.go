@@ -0,0 +1,142 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleportercmd
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/networkoptions"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/units"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+const defaultMsgGasLimit = 100_000
+
+var (
+	msgSupportedNetworkOptions = []networkoptions.NetworkOption{networkoptions.Local, networkoptions.Cluster}
+	msgNetworkFlags            networkoptions.NetworkFlags
+	msgKeyName                 string
+	msgTimeout                 time.Duration
+	msgDestinationAddress      string
+	msgGasLimit                uint64
+	msgFeeTokenAddress         string
+	msgFeeAmount               uint64
+)
+
+// avalanche teleporter msg
+func newMsgCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "msg [sourceSubnetName] [destinationSubnetName] [messageContent]",
+		Short: "Verifies exchange of teleporter message between two subnets",
+		Long: `The teleporter msg command sends a Teleporter message from the source chain to the
+destination chain, using the messenger addresses stored for them, and waits for the
+message to be delivered by a relayer.
+
+Source and destination can be the name of a teleporter-ready subnet or c-chain.
+
+Once delivered, it reports the delivery latency, the fees paid on the source chain,
+and the relayer reward.`,
+		SilenceUsage: true,
+		RunE:         msg,
+		Args:         cobra.ExactArgs(3),
+	}
+	networkoptions.AddNetworkFlagsToCmd(cmd, &msgNetworkFlags, true, msgSupportedNetworkOptions)
+	networkoptions.AddLocalInstanceFlagToCmd(cmd, &msgNetworkFlags)
+	cmd.Flags().StringVarP(&msgKeyName, "key", "k", "", "CLI stored key to use to pay for the message fees (defaults to the subnet teleporter key, or ewoq on local network and devnets)")
+	cmd.Flags().DurationVar(&msgTimeout, "timeout", 2*time.Minute, "time to wait for the message to be received on the destination")
+	cmd.Flags().StringVar(&msgDestinationAddress, "destination-address", common.Address{}.Hex(), "receiver contract address on the destination chain")
+	cmd.Flags().Uint64Var(&msgGasLimit, "gas-limit", defaultMsgGasLimit, "gas limit required to execute the message on the destination")
+	cmd.Flags().StringVar(&msgFeeTokenAddress, "fee-token-address", "", "ERC20 token used to reward the relayer")
+	cmd.Flags().Uint64Var(&msgFeeAmount, "fee-amount", 0, "amount of fee tokens to reward the relayer with")
+	return cmd
+}
+
+func msg(_ *cobra.Command, args []string) error {
+	sourceName := args[0]
+	destinationName := args[1]
+	message := args[2]
+	if msgFeeAmount > 0 && !common.IsHexAddress(msgFeeTokenAddress) {
+		return fmt.Errorf("a valid --fee-token-address must be given together with --fee-amount")
+	}
+	if !common.IsHexAddress(msgDestinationAddress) {
+		return fmt.Errorf("invalid destination address %q", msgDestinationAddress)
+	}
+	network, err := networkoptions.GetNetworkFromCmdLineFlags(
+		app,
+		msgNetworkFlags,
+		true,
+		msgSupportedNetworkOptions,
+		"",
+	)
+	if err != nil {
+		return err
+	}
+	source, err := getChainInfo(network, sourceName)
+	if err != nil {
+		return err
+	}
+	destination, err := getChainInfo(network, destinationName)
+	if err != nil {
+		return err
+	}
+	privateKey, err := getPrivateKey(network, msgKeyName, source.Sidecar)
+	if err != nil {
+		return err
+	}
+	fromBlock, err := teleporter.GetBlockNumber(destination.RPCURL)
+	if err != nil {
+		return err
+	}
+
+	ux.Logger.PrintToUser("Sending message %q from %s to %s", message, source.Name, destination.Name)
+	sent, err := teleporter.SendCrossChainMessage(
+		source.RPCURL,
+		source.MessengerAddress,
+		privateKey,
+		teleporter.MessageInput{
+			DestinationBlockchainID: destination.BlockchainID,
+			DestinationAddress:      common.HexToAddress(msgDestinationAddress),
+			FeeTokenAddress:         common.HexToAddress(msgFeeTokenAddress),
+			FeeAmount:               new(big.Int).SetUint64(msgFeeAmount),
+			RequiredGasLimit:        new(big.Int).SetUint64(msgGasLimit),
+			Message:                 []byte(message),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Message %s sent on tx %s", sent.MessageID, sent.TxHash)
+
+	ux.Logger.PrintToUser("Waiting for message delivery on %s...", destination.Name)
+	received, err := teleporter.WaitForMessageReceipt(
+		destination.RPCURL,
+		destination.MessengerAddress,
+		sent.MessageID,
+		fromBlock,
+		msgTimeout,
+	)
+	if err != nil {
+		return err
+	}
+	ux.Logger.GreenCheckmarkToUser("Message successfully delivered on tx %s", received.TxHash)
+	ux.Logger.PrintToUser("Delivery latency: %s", received.ReceivedAt.Sub(sent.SentAt).Round(time.Millisecond))
+	ux.Logger.PrintToUser("Fees paid:        %s (in %s native token)", formatWei(sent.GasFeesPaid), source.Name)
+	ux.Logger.PrintToUser("Deliverer:        %s", received.Deliverer.Hex())
+	if sent.FeeAmount.Sign() > 0 {
+		ux.Logger.PrintToUser("Relayer reward:   %s of token %s, redeemable by %s", sent.FeeAmount, sent.FeeToken.Hex(), received.RewardRedeemer.Hex())
+	} else {
+		ux.Logger.PrintToUser("Relayer reward:   none")
+	}
+	return nil
+}
+
+// formatWei returns [amount] expressed in units of 10^18
+func formatWei(amount *big.Int) string {
+	nAvax := new(big.Int).Div(amount, big.NewInt(int64(units.Avax)))
+	return fmt.Sprintf("%.9f", float64(nAvax.Uint64())/float64(units.Avax))
+}
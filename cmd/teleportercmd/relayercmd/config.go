@@ -0,0 +1,250 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayercmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ixAnkit/cryft/pkg/key"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/spf13/cobra"
+)
+
+type configFlags struct {
+	subnetName       string
+	useCChain        bool
+	messengerAddress string
+	rewardAddress    string
+	keyName          string
+}
+
+var (
+	cfgFlags configFlags
+
+	errMutuallyExclusiveChainFlags = errors.New("exactly one of --subnet or --c-chain must be given")
+)
+
+// localChain describes a blockchain of the local network the relayer can be configured for
+type localChain struct {
+	subnetID         string
+	blockchainID     string
+	rpcEndpoint      string
+	wsEndpoint       string
+	messengerAddress string
+}
+
+// avalanche teleporter relayer config
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the AWM relayer config of the local network",
+		Long: `The relayer config command suite allows to regenerate the local relayer config,
+or to manually add source and destination blockchains to it.
+
+Changes are picked up on the next relayer start.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := cmd.Help()
+			if err != nil {
+				fmt.Println(err)
+			}
+		},
+		Args: cobra.ExactArgs(0),
+	}
+	// relayer config generate
+	cmd.AddCommand(newConfigGenerateCmd())
+	// relayer config add-source
+	cmd.AddCommand(newConfigAddSourceCmd())
+	// relayer config add-destination
+	cmd.AddCommand(newConfigAddDestinationCmd())
+	return cmd
+}
+
+func addChainFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&cfgFlags.subnetName, "subnet", "", "locally deployed subnet to configure")
+	cmd.Flags().BoolVar(&cfgFlags.useCChain, "c-chain", false, "configure the local network C-Chain")
+}
+
+// avalanche teleporter relayer config generate
+func newConfigGenerateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "generate",
+		Short: "Regenerates the relayer config for the local network",
+		Long: `The relayer config generate command adds the local network C-Chain and every
+teleporter-ready Subnet deployed locally to the relayer config, both as source
+and as destination. The relayer key is funded on all of them.`,
+		SilenceUsage: true,
+		RunE:         configGenerate,
+		Args:         cobra.ExactArgs(0),
+	}
+}
+
+func configGenerate(_ *cobra.Command, _ []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
+	config, err := subnet.UpdateLocalRelayerConfig(app)
+	if err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Relayer config written to %s with %d sources and %d destinations",
+		app.GetAWMRelayerConfigPath(), len(config.SourceBlockchains), len(config.DestinationBlockchains))
+	return nil
+}
+
+// avalanche teleporter relayer config add-source
+func newConfigAddSourceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-source",
+		Short: "Adds a source blockchain to the relayer config",
+		Long: `The relayer config add-source command makes the relayer listen for teleporter
+messages sent from the given local blockchain.`,
+		SilenceUsage: true,
+		RunE:         configAddSource,
+		Args:         cobra.ExactArgs(0),
+	}
+	addChainFlags(cmd)
+	cmd.Flags().StringVar(&cfgFlags.messengerAddress, "messenger-address", "", "teleporter messenger address to listen to (defaults to the deployed one)")
+	cmd.Flags().StringVar(&cfgFlags.rewardAddress, "reward-address", "", "address to receive relayer rewards (defaults to the relayer key address)")
+	return cmd
+}
+
+func configAddSource(_ *cobra.Command, _ []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
+	chain, err := getLocalChain(cfgFlags.subnetName, cfgFlags.useCChain)
+	if err != nil {
+		return err
+	}
+	messengerAddress := cfgFlags.messengerAddress
+	if messengerAddress == "" {
+		messengerAddress = chain.messengerAddress
+	}
+	if messengerAddress == "" {
+		return fmt.Errorf("teleporter messenger address not found for blockchain %s. set it with --messenger-address", chain.blockchainID)
+	}
+	rewardAddress := cfgFlags.rewardAddress
+	if rewardAddress == "" {
+		rewardAddress, _, err = teleporter.GetRelayerKeyInfo(app)
+		if err != nil {
+			return err
+		}
+	}
+	config, err := loadOrCreateConfig()
+	if err != nil {
+		return err
+	}
+	config.AddSource(chain.subnetID, chain.blockchainID, chain.rpcEndpoint, chain.wsEndpoint, messengerAddress, rewardAddress)
+	if err := config.Save(app.GetAWMRelayerConfigPath()); err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Source blockchain %s added to relayer config", chain.blockchainID)
+	return nil
+}
+
+// avalanche teleporter relayer config add-destination
+func newConfigAddDestinationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-destination",
+		Short: "Adds a destination blockchain to the relayer config",
+		Long: `The relayer config add-destination command makes the relayer deliver teleporter
+messages into the given local blockchain, paying for the txs with the given key.`,
+		SilenceUsage: true,
+		RunE:         configAddDestination,
+		Args:         cobra.ExactArgs(0),
+	}
+	addChainFlags(cmd)
+	cmd.Flags().StringVarP(&cfgFlags.keyName, "key", "k", "", "stored key the relayer pays deliveries with (defaults to the relayer key)")
+	return cmd
+}
+
+func configAddDestination(_ *cobra.Command, _ []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
+	chain, err := getLocalChain(cfgFlags.subnetName, cfgFlags.useCChain)
+	if err != nil {
+		return err
+	}
+	var privateKey string
+	if cfgFlags.keyName != "" {
+		k, err := key.LoadSoft(models.NewLocalNetwork().ID, app.GetKeyPath(cfgFlags.keyName))
+		if err != nil {
+			return err
+		}
+		privateKey = hex.EncodeToString(k.Raw())
+	} else {
+		_, privateKey, err = teleporter.GetRelayerKeyInfo(app)
+		if err != nil {
+			return err
+		}
+	}
+	config, err := loadOrCreateConfig()
+	if err != nil {
+		return err
+	}
+	config.AddDestination(chain.subnetID, chain.blockchainID, chain.rpcEndpoint, privateKey)
+	if err := config.Save(app.GetAWMRelayerConfigPath()); err != nil {
+		return err
+	}
+	ux.Logger.PrintToUser("Destination blockchain %s added to relayer config", chain.blockchainID)
+	return nil
+}
+
+func loadOrCreateConfig() (*teleporter.RelayerConfig, error) {
+	config, err := teleporter.LoadRelayerConfig(app.GetAWMRelayerConfigPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return subnet.UpdateLocalRelayerConfig(app)
+	}
+	return config, err
+}
+
+// getLocalChain returns the endpoints and messenger address of either the local C-Chain
+// or the locally deployed blockchain of [subnetName]
+func getLocalChain(subnetName string, useCChain bool) (localChain, error) {
+	if (subnetName == "") == !useCChain {
+		return localChain{}, errMutuallyExclusiveChainFlags
+	}
+	network := models.NewLocalNetwork()
+	if useCChain {
+		blockchainID, err := subnet.GetChainID(network, "C")
+		if err != nil {
+			return localChain{}, err
+		}
+		chain := localChain{
+			subnetID:     ids.Empty.String(),
+			blockchainID: blockchainID.String(),
+			rpcEndpoint:  network.CChainEndpoint(),
+			wsEndpoint:   network.CChainWSEndpoint(),
+		}
+		if data, err := subnet.GetExtraLocalNetworkData(app); err == nil {
+			chain.messengerAddress = data.CChainTeleporterMessengerAddress
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return localChain{}, err
+		}
+		return chain, nil
+	}
+	sc, err := app.LoadSidecar(subnetName)
+	if err != nil {
+		return localChain{}, fmt.Errorf("failed to load sidecar: %w", err)
+	}
+	networkData, ok := sc.Networks[network.Name()]
+	if !ok || networkData.BlockchainID == ids.Empty {
+		return localChain{}, fmt.Errorf("subnet %s has not been deployed to %s", subnetName, network.Name())
+	}
+	blockchainID := networkData.BlockchainID.String()
+	return localChain{
+		subnetID:         networkData.SubnetID.String(),
+		blockchainID:     blockchainID,
+		rpcEndpoint:      network.BlockchainEndpoint(blockchainID),
+		wsEndpoint:       network.BlockchainWSEndpoint(blockchainID),
+		messengerAddress: networkData.TeleporterMessengerAddress,
+	}, nil
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayercmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var lastLines int
+
+// avalanche teleporter relayer logs
+func newLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "logs",
+		Short:        "Prints the logs of the AWM relayer of the local network",
+		Long:         `The relayer logs command prints the output of the AWM relayer of the local network.`,
+		SilenceUsage: true,
+		RunE:         logs,
+		Args:         cobra.ExactArgs(0),
+	}
+	cmd.Flags().IntVar(&lastLines, "last", 0, "only print the given number of last lines")
+	return cmd
+}
+
+func logs(_ *cobra.Command, _ []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
+	logPath := app.GetAWMRelayerLogPath()
+	bs, err := os.ReadFile(logPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no relayer logs found at %s. has the relayer been started?", logPath)
+		}
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(string(bs), "\n"), "\n")
+	if lastLines > 0 && lastLines < len(lines) {
+		lines = lines[len(lines)-lastLines:]
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayercmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ixAnkit/cryft/pkg/binutils"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
+	"github.com/spf13/cobra"
+)
+
+// avalanche teleporter relayer prepareService
+func newPrepareServiceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "prepareService",
+		Short:        "Installs the AWM relayer as a systemd service on a cloud node",
+		Long:         `The relayer prepareService command is run on cloud nodes to download the AWM relayer and write its systemd unit.`,
+		Hidden:       true,
+		SilenceUsage: true,
+		RunE:         prepareService,
+		Args:         cobra.ExactArgs(0),
+	}
+	cmd.Flags().StringVar(&version, "version", "latest", "version of the AWM relayer to use")
+	return cmd
+}
+
+func prepareService(_ *cobra.Command, _ []string) error {
+	_, binPath, err := binutils.SetupAWMRelayer(app, version)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(app.GetAWMRelayerServiceStorageDir(""), constants.DefaultPerms755); err != nil {
+		return err
+	}
+	return teleporter.CreateRelayerServiceFile(
+		filepath.Join(app.GetAWMRelayerServiceDir(""), constants.AWMRelayerServiceFilename),
+		binPath,
+		app.GetAWMRelayerServiceConfigPath(""),
+	)
+}
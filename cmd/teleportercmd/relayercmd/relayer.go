@@ -0,0 +1,60 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayercmd
+
+import (
+	"fmt"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+var (
+	app                  *application.Avalanche
+	localNetworkInstance string
+)
+
+// avalanche teleporter relayer
+func NewCmd(injectedApp *application.Avalanche) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relayer",
+		Short: "Manage the AWM relayer of the local network",
+		Long: `The relayer command suite provides a collection of tools for managing the
+AWM relayer that delivers Teleporter messages between the chains of the local network.
+
+The relayer binary is downloaded on first use. Its config is generated from the
+local network C-Chain and every teleporter-ready Subnet deployed locally.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := cmd.Help()
+			if err != nil {
+				fmt.Println(err)
+			}
+		},
+		Args: cobra.ExactArgs(0),
+	}
+	app = injectedApp
+	cmd.PersistentFlags().StringVar(
+		&localNetworkInstance,
+		constants.LocalNetworkInstanceFlag,
+		constants.DefaultLocalNetworkInstance,
+		"local network instance to operate on",
+	)
+	// relayer start
+	cmd.AddCommand(newStartCmd())
+	// relayer stop
+	cmd.AddCommand(newStopCmd())
+	// relayer status
+	cmd.AddCommand(newStatusCmd())
+	// relayer logs
+	cmd.AddCommand(newLogsCmd())
+	// relayer config
+	cmd.AddCommand(newConfigCmd())
+	// relayer prepareService
+	cmd.AddCommand(newPrepareServiceCmd())
+	return cmd
+}
+
+func selectLocalNetworkInstance() error {
+	return app.SetLocalNetworkInstance(localNetworkInstance)
+}
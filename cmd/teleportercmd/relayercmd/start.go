@@ -0,0 +1,46 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayercmd
+
+import (
+	"errors"
+
+	"github.com/ixAnkit/cryft/pkg/subnet"
+	"github.com/spf13/cobra"
+)
+
+var (
+	version string
+
+	errLocalNetworkNotRunning = errors.New("local network is not running. start it with 'network start' or deploy a subnet")
+)
+
+// avalanche teleporter relayer start
+func newStartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Starts the AWM relayer on the local network",
+		Long: `The relayer start command downloads the AWM relayer binary if needed, and runs it in
+background for the local network.
+
+If there is no relayer config yet, one is generated that relays messages between the
+C-Chain and all teleporter-ready Subnets deployed locally.`,
+		SilenceUsage: true,
+		RunE:         start,
+		Args:         cobra.ExactArgs(0),
+	}
+	cmd.Flags().StringVar(&version, "version", "latest", "version of the AWM relayer to use")
+	return cmd
+}
+
+func start(_ *cobra.Command, _ []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
+	if isRunning, err := subnet.IsLocalNetworkRunning(); err != nil {
+		return err
+	} else if !isRunning {
+		return errLocalNetworkNotRunning
+	}
+	return subnet.StartLocalRelayer(app, version)
+}
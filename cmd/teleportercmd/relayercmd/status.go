@@ -0,0 +1,79 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayercmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// avalanche teleporter relayer status
+func newStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Prints the status of the AWM relayer of the local network",
+		Long: `The relayer status command prints whether the AWM relayer of the local network
+is running, together with the source and destination blockchains it is configured for.`,
+		SilenceUsage: true,
+		RunE:         status,
+		Args:         cobra.ExactArgs(0),
+	}
+}
+
+func status(_ *cobra.Command, _ []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
+	info, isRunning, err := teleporter.GetRelayerRunInfo(app.GetAWMRelayerRunPath())
+	if err != nil {
+		return err
+	}
+	if isRunning {
+		ux.Logger.PrintToUser("AWM Relayer is running, pid: %d, version: %s", info.Pid, info.Version)
+		ux.Logger.PrintToUser("Logs: %s", info.LogPath)
+	} else {
+		ux.Logger.PrintToUser("AWM Relayer is not running")
+	}
+	configPath := app.GetAWMRelayerConfigPath()
+	config, err := teleporter.LoadRelayerConfig(configPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			ux.Logger.PrintToUser("No relayer config found")
+			return nil
+		}
+		return err
+	}
+	ux.Logger.PrintToUser("Config: %s", configPath)
+	ux.Logger.PrintToUser("Metrics port: %d", config.MetricsPort)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Blockchain ID", "Subnet ID", "Source", "Destination"})
+	table.SetRowLine(true)
+	isDestination := map[string]bool{}
+	for _, destination := range config.DestinationBlockchains {
+		isDestination[destination.BlockchainID] = true
+	}
+	for _, source := range config.SourceBlockchains {
+		table.Append([]string{source.BlockchainID, source.SubnetID, constants.YesLabel, yesNo(isDestination[source.BlockchainID])})
+		delete(isDestination, source.BlockchainID)
+	}
+	for _, destination := range config.DestinationBlockchains {
+		if isDestination[destination.BlockchainID] {
+			table.Append([]string{destination.BlockchainID, destination.SubnetID, constants.NoLabel, constants.YesLabel})
+		}
+	}
+	table.Render()
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return constants.YesLabel
+	}
+	return constants.NoLabel
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayercmd
+
+import (
+	"github.com/ixAnkit/cryft/pkg/teleporter"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/spf13/cobra"
+)
+
+// avalanche teleporter relayer stop
+func newStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "stop",
+		Short:        "Stops the AWM relayer of the local network",
+		Long:         `The relayer stop command stops the AWM relayer running for the local network.`,
+		SilenceUsage: true,
+		RunE:         stop,
+		Args:         cobra.ExactArgs(0),
+	}
+}
+
+func stop(_ *cobra.Command, _ []string) error {
+	if err := selectLocalNetworkInstance(); err != nil {
+		return err
+	}
+	_, isRunning, err := teleporter.GetRelayerRunInfo(app.GetAWMRelayerRunPath())
+	if err != nil {
+		return err
+	}
+	if err := teleporter.StopRelayer(app.GetAWMRelayerRunPath()); err != nil {
+		return err
+	}
+	if isRunning {
+		ux.Logger.PrintToUser("AWM Relayer stopped")
+	} else {
+		ux.Logger.PrintToUser("AWM Relayer is not running")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleportercmd
+
+import (
+	"fmt"
+
+	"github.com/ixAnkit/cryft/cmd/teleportercmd/relayercmd"
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/spf13/cobra"
+)
+
+var app *application.Avalanche
+
+// avalanche teleporter
+func NewCmd(injectedApp *application.Avalanche) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "teleporter",
+		Short: "Interact with teleporter-compatible Subnets",
+		Long: `The teleporter command suite provides a collection of tools for deploying
+and interacting with the Teleporter cross-chain messaging contracts.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := cmd.Help()
+			if err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+	app = injectedApp
+	// teleporter deploy
+	cmd.AddCommand(newDeployCmd())
+	// teleporter msg
+	cmd.AddCommand(newMsgCmd())
+	// teleporter relayer
+	cmd.AddCommand(relayercmd.NewCmd(app))
+	return cmd
+}
@@ -54,6 +54,45 @@ func CreateAnsibleHostInventory(inventoryDirPath, certFilePath, cloudService str
 	return nil
 }
 
+// AddHostsToInventory adds the given hosts, with their own ssh user and key, to the inventory file.
+// Records of hosts already in the inventory are replaced, so adding the same hosts again
+// does not duplicate them
+func AddHostsToInventory(inventoryDirPath string, hosts []*models.Host) error {
+	if err := os.MkdirAll(inventoryDirPath, os.ModePerm); err != nil {
+		return err
+	}
+	inventoryHostsFilePath := filepath.Join(inventoryDirPath, constants.AnsibleHostInventoryFileName)
+	content, err := os.ReadFile(inventoryHostsFilePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	nodeIDs := map[string]bool{}
+	for _, host := range hosts {
+		nodeIDs[host.NodeID] = true
+	}
+	records := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		// host alias is first element in each line of host inventory file
+		if line == "" || nodeIDs[strings.Split(line, " ")[0]] {
+			continue
+		}
+		records = append(records, line+"\n")
+	}
+	for _, host := range hosts {
+		records = append(records, host.GetAnsibleInventoryRecord()+"\n")
+	}
+	return os.WriteFile(inventoryHostsFilePath, []byte(strings.Join(records, "")), constants.WriteReadReadPerms)
+}
+
+// WriteInventory replaces the content of the inventory file with the given hosts
+func WriteInventory(inventoryDirPath string, hosts []*models.Host) error {
+	inventoryHostsFilePath := filepath.Join(inventoryDirPath, constants.AnsibleHostInventoryFileName)
+	if err := os.Remove(inventoryHostsFilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return AddHostsToInventory(inventoryDirPath, hosts)
+}
+
 func writeToInventoryFile(inventoryFile *os.File, ansibleInstanceID, publicIP, certFilePath string) error {
 	inventoryContent := ansibleInstanceID
 	inventoryContent += " ansible_host="
@@ -49,7 +49,7 @@ func (app *Avalanche) GetRunFile() string {
 }
 
 func (app *Avalanche) GetSnapshotsDir() string {
-	return filepath.Join(app.baseDir, constants.SnapshotsDirName)
+	return filepath.Join(app.GetLocalNetworkInstanceDir(), constants.SnapshotsDirName)
 }
 
 func (app *Avalanche) GetBaseDir() string {
@@ -69,7 +69,7 @@ func (app *Avalanche) GetReposDir() string {
 }
 
 func (app *Avalanche) GetRunDir() string {
-	return filepath.Join(app.baseDir, constants.RunDir)
+	return filepath.Join(app.GetLocalNetworkInstanceDir(), constants.RunDir)
 }
 
 func (app *Avalanche) GetServicesDir(baseDir string) string {
@@ -84,7 +84,7 @@ func (app *Avalanche) GetCustomVMDir() string {
 }
 
 func (app *Avalanche) GetPluginsDir() string {
-	return filepath.Join(app.baseDir, constants.PluginDir)
+	return filepath.Join(app.GetLocalNetworkInstanceDir(), constants.PluginDir)
 }
 
 // Remove all plugins from plugin dir
@@ -198,10 +198,20 @@ func (app *Avalanche) GetNodeInstanceDirPath(nodeName string) string {
 	return filepath.Join(app.GetNodesDir(), nodeName)
 }
 
+// GetArchivedNodesDir returns the dir where the local files of nodes removed from
+// their clusters are archived
+func (app *Avalanche) GetArchivedNodesDir() string {
+	return filepath.Join(app.baseDir, constants.ArchivedNodesDir)
+}
+
 func (app *Avalanche) GetNodeInstanceAvaGoConfigDirPath(nodeName string) string {
 	return filepath.Join(app.GetAnsibleDir(), nodeName)
 }
 
+func (app *Avalanche) GetNodeBackupsDir(clusterName string) string {
+	return filepath.Join(app.GetNodesDir(), constants.NodeBackupsDir, clusterName)
+}
+
 func (app *Avalanche) GetAnsibleDir() string {
 	return filepath.Join(app.GetNodesDir(), constants.AnsibleDir)
 }
@@ -214,10 +224,35 @@ func (app *Avalanche) GetMonitoringInventoryDir(clusterName string) string {
 	return filepath.Join(app.GetAnsibleInventoryDirPath(clusterName), constants.MonitoringDir)
 }
 
+// GetClusterAlertRulesPath returns the path of the user overrides of the cluster alert rules
+func (app *Avalanche) GetClusterAlertRulesPath(clusterName string) string {
+	return filepath.Join(app.GetAnsibleInventoryDirPath(clusterName), constants.AlertRulesFileName)
+}
+
+func (app *Avalanche) GetClusterAlertReceiversPath(clusterName string) string {
+	return filepath.Join(app.GetAnsibleInventoryDirPath(clusterName), constants.AlertReceiversFileName)
+}
+
 func (app *Avalanche) GetLoadTestInventoryDir(clusterName string) string {
 	return filepath.Join(app.GetAnsibleInventoryDirPath(clusterName), constants.LoadTestDir)
 }
 
+// GetLoadTestRunsDir returns the dir of the load test run records of [clusterName], which
+// outlive the clusters and load test instances
+func (app *Avalanche) GetLoadTestRunsDir(clusterName string) string {
+	return filepath.Join(app.GetNodesDir(), constants.LoadTestRunsDir, clusterName)
+}
+
+func (app *Avalanche) GetLoadTestRunPath(clusterName, loadTestName string) string {
+	return filepath.Join(app.GetLoadTestRunsDir(clusterName), loadTestName+constants.JSONSuffix)
+}
+
+// GetLegacyLoadTestRunPath returns the path of a load test run record saved before the
+// records were kept by cluster
+func (app *Avalanche) GetLegacyLoadTestRunPath(loadTestName string) string {
+	return filepath.Join(app.GetNodesDir(), constants.LoadTestRunsDir, loadTestName+constants.JSONSuffix)
+}
+
 func (app *Avalanche) CreateAnsibleDir() error {
 	ansibleDir := app.GetAnsibleDir()
 	if _, err := os.Stat(ansibleDir); os.IsNotExist(err) {
@@ -244,6 +279,12 @@ func (app *Avalanche) GetClustersConfigPath() string {
 	return filepath.Join(app.GetNodesDir(), constants.ClustersConfigFileName)
 }
 
+// GetPriceTablePath returns the path of the user provided cloud price table, that
+// overrides the one shipped with the CLI
+func (app *Avalanche) GetPriceTablePath() string {
+	return filepath.Join(app.baseDir, constants.PriceTableFileName)
+}
+
 func (app *Avalanche) GetNodeBLSSecretKeyPath(instanceID string) string {
 	return filepath.Join(app.GetNodeInstanceDirPath(instanceID), constants.BLSKeyFileName)
 }
@@ -283,3 +283,43 @@ func newTestApp(t *testing.T) *Avalanche {
 		Log:     logging.NoLog{},
 	}
 }
+
+func TestLocalNetworkInstances(t *testing.T) {
+	require := require.New(t)
+	ap := newTestApp(t)
+	defer models.SetCurrentLocalNetworkInstance(models.DefaultLocalNetworkInstance)
+
+	instance, err := ap.GetLocalNetworkInstance("")
+	require.NoError(err)
+	require.True(instance.IsDefault())
+
+	_, err = ap.GetLocalNetworkInstance("../escape")
+	require.Error(err)
+
+	first, err := ap.GetLocalNetworkInstance("first")
+	require.NoError(err)
+	require.Equal(1, first.Index)
+	second, err := ap.GetLocalNetworkInstance("second")
+	require.NoError(err)
+	require.Equal(2, second.Index)
+	// registered instances keep their index
+	first, err = ap.GetLocalNetworkInstance("first")
+	require.NoError(err)
+	require.Equal(1, first.Index)
+
+	// freed indices are reused
+	require.NoError(ap.RemoveLocalNetworkInstance("first"))
+	third, err := ap.GetLocalNetworkInstance("third")
+	require.NoError(err)
+	require.Equal(1, third.Index)
+
+	instances, err := ap.GetLocalNetworkInstances()
+	require.NoError(err)
+	require.Equal([]models.LocalNetworkInstance{models.DefaultLocalNetworkInstance, third, second}, instances)
+
+	require.NoError(ap.SetLocalNetworkInstance("second"))
+	require.Equal(filepath.Join(ap.GetBaseDir(), constants.LocalNetworkInstancesDir, "second", constants.RunDir), ap.GetRunDir())
+	require.DirExists(ap.GetSnapshotsDir())
+	require.NoError(ap.SetLocalNetworkInstance(""))
+	require.Equal(filepath.Join(ap.GetBaseDir(), constants.RunDir), ap.GetRunDir())
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+)
+
+var localNetworkInstanceNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// localNetworkInstances is the on disk registry of named local network instances,
+// mapping each instance name to the index used to compute its port range
+type localNetworkInstances struct {
+	Instances map[string]int
+}
+
+func (app *Avalanche) getLocalNetworkInstancesPath() string {
+	return filepath.Join(app.baseDir, constants.LocalNetworkInstancesDir, constants.LocalNetworkInstancesFileName)
+}
+
+// GetLocalNetworkInstanceDir returns the base dir for run, snapshots and plugins
+// data of the currently selected local network instance
+func (app *Avalanche) GetLocalNetworkInstanceDir() string {
+	instance := models.CurrentLocalNetworkInstance()
+	if instance.IsDefault() {
+		return app.baseDir
+	}
+	return filepath.Join(app.baseDir, constants.LocalNetworkInstancesDir, instance.Name)
+}
+
+func (app *Avalanche) loadLocalNetworkInstances() (localNetworkInstances, error) {
+	instances := localNetworkInstances{Instances: map[string]int{}}
+	bs, err := os.ReadFile(app.getLocalNetworkInstancesPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return instances, nil
+		}
+		return instances, err
+	}
+	if err := json.Unmarshal(bs, &instances); err != nil {
+		return instances, fmt.Errorf("failed unmarshalling local network instances file: %w", err)
+	}
+	if instances.Instances == nil {
+		instances.Instances = map[string]int{}
+	}
+	return instances, nil
+}
+
+func (app *Avalanche) writeLocalNetworkInstances(instances localNetworkInstances) error {
+	bs, err := json.MarshalIndent(instances, "", "    ")
+	if err != nil {
+		return err
+	}
+	return app.writeFile(app.getLocalNetworkInstancesPath(), bs)
+}
+
+// GetLocalNetworkInstances returns the default instance plus all named instances
+// ever selected on this machine, ordered by index
+func (app *Avalanche) GetLocalNetworkInstances() ([]models.LocalNetworkInstance, error) {
+	instances, err := app.loadLocalNetworkInstances()
+	if err != nil {
+		return nil, err
+	}
+	result := []models.LocalNetworkInstance{models.DefaultLocalNetworkInstance}
+	for name, index := range instances.Instances {
+		result = append(result, models.LocalNetworkInstance{Name: name, Index: index})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Index < result[j].Index })
+	return result, nil
+}
+
+// GetLocalNetworkInstance returns the named instance, registering it with the
+// first free port range if it was not used before
+func (app *Avalanche) GetLocalNetworkInstance(name string) (models.LocalNetworkInstance, error) {
+	if name == "" || name == constants.DefaultLocalNetworkInstance {
+		return models.DefaultLocalNetworkInstance, nil
+	}
+	if !localNetworkInstanceNameRegex.MatchString(name) {
+		return models.LocalNetworkInstance{}, fmt.Errorf("invalid local network instance name %q: only letters, digits, '-' and '_' are allowed", name)
+	}
+	instances, err := app.loadLocalNetworkInstances()
+	if err != nil {
+		return models.LocalNetworkInstance{}, err
+	}
+	if index, ok := instances.Instances[name]; ok {
+		return models.LocalNetworkInstance{Name: name, Index: index}, nil
+	}
+	usedIndices := map[int]bool{}
+	for _, index := range instances.Instances {
+		usedIndices[index] = true
+	}
+	index := 1
+	for usedIndices[index] {
+		index++
+	}
+	if index >= constants.MaxLocalNetworkInstances {
+		return models.LocalNetworkInstance{}, fmt.Errorf("maximum number of local network instances (%d) reached", constants.MaxLocalNetworkInstances)
+	}
+	instances.Instances[name] = index
+	if err := app.writeLocalNetworkInstances(instances); err != nil {
+		return models.LocalNetworkInstance{}, err
+	}
+	return models.LocalNetworkInstance{Name: name, Index: index}, nil
+}
+
+// RemoveLocalNetworkInstance unregisters a named instance, releasing its port range
+func (app *Avalanche) RemoveLocalNetworkInstance(name string) error {
+	instances, err := app.loadLocalNetworkInstances()
+	if err != nil {
+		return err
+	}
+	if _, ok := instances.Instances[name]; !ok {
+		return nil
+	}
+	delete(instances.Instances, name)
+	return app.writeLocalNetworkInstances(instances)
+}
+
+// SetLocalNetworkInstance selects the local network instance to be used by all
+// local network operations of this process, creating its dirs if needed
+func (app *Avalanche) SetLocalNetworkInstance(name string) error {
+	instance, err := app.GetLocalNetworkInstance(name)
+	if err != nil {
+		return err
+	}
+	models.SetCurrentLocalNetworkInstance(instance)
+	for _, dir := range []string{app.GetRunDir(), app.GetSnapshotsDir(), app.GetPluginsDir()} {
+		if err := os.MkdirAll(dir, constants.DefaultPerms755); err != nil {
+			return fmt.Errorf("failed creating local network instance dir %s: %w", dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package binutils
+
+import (
+	"path/filepath"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+)
+
+func SetupAWMRelayer(app *application.Avalanche, awmRelayerVersion string) (string, string, error) {
+	if awmRelayerVersion == "latest" {
+		var err error
+		awmRelayerVersion, err = app.Downloader.GetLatestReleaseVersion(GetGithubLatestReleaseURL(
+			constants.AvaLabsOrg,
+			constants.AWMRelayerRepoName,
+		))
+		if err != nil {
+			return "", "", err
+		}
+	}
+	// Check if already installed
+	binDir := app.GetAWMRelayerBinDir()
+	subDir := filepath.Join(binDir, awmRelayerBinPrefix+awmRelayerVersion)
+
+	installer := NewInstaller()
+	downloader := NewAWMRelayerDownloader()
+	version, relayerDir, err := InstallBinary(
+		app,
+		awmRelayerVersion,
+		binDir,
+		subDir,
+		awmRelayerBinPrefix,
+		constants.AvaLabsOrg,
+		constants.AWMRelayerRepoName,
+		downloader,
+		installer,
+	)
+	return version, filepath.Join(relayerDir, constants.AWMRelayerBin), err
+}
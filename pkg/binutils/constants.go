@@ -6,12 +6,10 @@ import "time"
 
 const (
 	gRPCClientLogLevel = "error"
-	gRPCServerPort     = ":8097"
-	gRPCGatewayPort    = ":8098"
-	gRPCServerEndpoint = "localhost" + gRPCServerPort
 	gRPCDialTimeout    = 10 * time.Second
 
 	avalanchegoBinPrefix = "metalgo-"
 	subnetEVMBinPrefix   = "subnet-evm-"
+	awmRelayerBinPrefix  = "awm-relayer-"
 	maxCopy              = 2147483648 // 2 GB
 )
@@ -25,11 +25,13 @@ type GithubDownloader interface {
 type (
 	subnetEVMDownloader   struct{}
 	avalancheGoDownloader struct{}
+	awmRelayerDownloader  struct{}
 )
 
 var (
 	_ GithubDownloader = (*subnetEVMDownloader)(nil)
 	_ GithubDownloader = (*avalancheGoDownloader)(nil)
+	_ GithubDownloader = (*awmRelayerDownloader)(nil)
 )
 
 func GetGithubLatestReleaseURL(org, repo string) string {
@@ -122,3 +124,33 @@ func (subnetEVMDownloader) GetDownloadURL(version string, installer Installer) (
 
 	return subnetEVMURL, ext, nil
 }
+
+func NewAWMRelayerDownloader() GithubDownloader {
+	return &awmRelayerDownloader{}
+}
+
+func (awmRelayerDownloader) GetDownloadURL(version string, installer Installer) (string, string, error) {
+	// NOTE: if any of the underlying URLs change (github changes, release file names, etc.) this fails
+	goarch, goos := installer.GetArch()
+
+	var awmRelayerURL string
+	ext := tarExtension
+
+	switch goos {
+	case linux, darwin:
+		awmRelayerURL = fmt.Sprintf(
+			"https://github.com/%s/%s/releases/download/%s/%s_%s_%s_%s.tar.gz",
+			constants.AvaLabsOrg,
+			constants.AWMRelayerRepoName,
+			version,
+			constants.AWMRelayerRepoName,
+			version[1:], // as with subnet-evm, the v is omitted in the file name
+			goos,
+			goarch,
+		)
+	default:
+		return "", "", fmt.Errorf("OS not supported: %s", goos)
+	}
+
+	return awmRelayerURL, ext, nil
+}
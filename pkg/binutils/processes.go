@@ -16,6 +16,7 @@ import (
 
 	"github.com/ixAnkit/cryft/pkg/application"
 	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/utils"
 	"github.com/ixAnkit/cryft/pkg/ux"
 	"github.com/MetalBlockchain/metal-network-runner/client"
@@ -89,7 +90,7 @@ func NewGRPCClient(opts ...GRPCClientOpOption) (client.Client, error) {
 		return nil, err
 	}
 	client, err := client.New(client.Config{
-		Endpoint:    gRPCServerEndpoint,
+		Endpoint:    models.CurrentLocalNetworkInstance().GRPCServerEndpoint(),
 		DialTimeout: op.dialTimeout,
 	}, log)
 	if errors.Is(err, context.DeadlineExceeded) {
@@ -116,6 +117,7 @@ func NewGRPCClient(opts ...GRPCClientOpOption) (client.Client, error) {
 }
 
 // NewGRPCClient hides away the details (params) of creating a gRPC server
+// ports are taken from the currently selected local network instance
 func NewGRPCServer(snapshotsDir string) (server.Server, error) {
 	logFactory := logging.NewFactory(logging.Config{
 		DisplayLevel: logging.Info,
@@ -125,9 +127,10 @@ func NewGRPCServer(snapshotsDir string) (server.Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	instance := models.CurrentLocalNetworkInstance()
 	return server.New(server.Config{
-		Port:                gRPCServerPort,
-		GwPort:              gRPCGatewayPort,
+		Port:                fmt.Sprintf(":%d", instance.GRPCServerPort()),
+		GwPort:              fmt.Sprintf(":%d", instance.GRPCGatewayPort()),
 		DialTimeout:         gRPCDialTimeout,
 		SnapshotsDir:        snapshotsDir,
 		RedirectNodesOutput: false,
@@ -199,11 +202,14 @@ func GetServerPID(app *application.Avalanche) (int, error) {
 }
 
 // StartServerProcess starts the gRPC server as a reentrant process of this binary
-// it just executes `avalanche-cli backend start`
+// it just executes `avalanche-cli backend start`, selecting the current local network instance
 func StartServerProcess(app *application.Avalanche) error {
 	thisBin := reexec.Self()
 
 	args := []string{constants.BackendCmd}
+	if instance := models.CurrentLocalNetworkInstance(); !instance.IsDefault() {
+		args = append(args, "--"+constants.LocalNetworkInstanceFlag, instance.Name)
+	}
 	cmd := exec.Command(thisBin, args...)
 
 	outputDirPrefix := path.Join(app.GetRunDir(), "server")
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ixAnkit/cryft/pkg/cloud"
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/utils"
@@ -27,9 +28,22 @@ import (
 var (
 	ErrNoInstanceState         = errors.New("unable to get instance state")
 	ErrNoAddressFound          = errors.New("unable to get public IP address info on AWS")
-	ErrNodeNotFoundToBeRunning = errors.New("node not found to be running")
+	ErrNodeNotFoundToBeRunning = cloud.ErrNodeNotFoundToBeRunning
 )
 
+// ExpiresAtTag is the instance tag holding the RFC3339 time after which the
+// instance is considered expired by node destroy --expired
+const ExpiresAtTag = "Expires-At"
+
+// InstanceLifecycle sets how EC2 instances are purchased and when they expire
+type InstanceLifecycle struct {
+	Spot bool
+	// SpotMaxPrice is the max hourly price for spot instances. Defaults to the on-demand price
+	SpotMaxPrice string
+	// ExpiresAt is tagged into the instances if set
+	ExpiresAt time.Time
+}
+
 type AwsCloud struct {
 	ec2Client *ec2.Client
 	ctx       context.Context
@@ -145,43 +159,39 @@ func (c *AwsCloud) AddSecurityGroupRule(groupID, direction, protocol, ip string,
 
 // DeleteSecurityGroupRule removes a rule from the given security group
 func (c *AwsCloud) DeleteSecurityGroupRule(groupID, direction, protocol, ip string, port int32) error {
+	return c.DeleteSecurityGroupRuleRange(groupID, direction, protocol, ip, port, port)
+}
+
+// DeleteSecurityGroupRuleRange removes a rule on ports [fromPort] to [toPort] from the given
+// security group. Ports are ignored for the all traffic protocol "-1"
+func (c *AwsCloud) DeleteSecurityGroupRuleRange(groupID, direction, protocol, ip string, fromPort, toPort int32) error {
 	if !strings.Contains(ip, "/") {
 		ip = fmt.Sprintf("%s/32", ip) // add netmask /32 if missing
 	}
+	permission := types.IpPermission{
+		IpProtocol: aws.String(protocol),
+	}
+	if protocol != "-1" {
+		permission.FromPort = aws.Int32(fromPort)
+		permission.ToPort = aws.Int32(toPort)
+	}
+	if strings.Contains(ip, ":") {
+		permission.Ipv6Ranges = []types.Ipv6Range{{CidrIpv6: aws.String(ip)}}
+	} else {
+		permission.IpRanges = []types.IpRange{{CidrIp: aws.String(ip)}}
+	}
 	switch direction {
 	case "ingress":
 		if _, err := c.ec2Client.RevokeSecurityGroupIngress(c.ctx, &ec2.RevokeSecurityGroupIngressInput{
-			GroupId: aws.String(groupID),
-			IpPermissions: []types.IpPermission{
-				{
-					IpProtocol: aws.String(protocol),
-					FromPort:   aws.Int32(port),
-					ToPort:     aws.Int32(port),
-					IpRanges: []types.IpRange{
-						{
-							CidrIp: aws.String(ip),
-						},
-					},
-				},
-			},
+			GroupId:       aws.String(groupID),
+			IpPermissions: []types.IpPermission{permission},
 		}); err != nil {
 			return err
 		}
 	case "egress":
 		if _, err := c.ec2Client.RevokeSecurityGroupEgress(c.ctx, &ec2.RevokeSecurityGroupEgressInput{
-			GroupId: aws.String(groupID),
-			IpPermissions: []types.IpPermission{
-				{
-					IpProtocol: aws.String(protocol),
-					FromPort:   aws.Int32(port),
-					ToPort:     aws.Int32(port),
-					IpRanges: []types.IpRange{
-						{
-							CidrIp: aws.String(ip),
-						},
-					},
-				},
-			},
+			GroupId:       aws.String(groupID),
+			IpPermissions: []types.IpPermission{permission},
 		}); err != nil {
 			return err
 		}
@@ -192,7 +202,7 @@ func (c *AwsCloud) DeleteSecurityGroupRule(groupID, direction, protocol, ip stri
 }
 
 // CreateEC2Instances creates EC2 instances
-func (c *AwsCloud) CreateEC2Instances(prefix string, count int, amiID, instanceType, keyName, securityGroupID string, forMonitoring bool, iops, throughput int, volumeType types.VolumeType, volumeSize int) ([]string, error) {
+func (c *AwsCloud) CreateEC2Instances(prefix string, count int, amiID, instanceType, keyName, securityGroupID string, forMonitoring bool, iops, throughput int, volumeType types.VolumeType, volumeSize int, lifecycle InstanceLifecycle) ([]string, error) {
 	var diskVolumeSize int32
 	if forMonitoring {
 		diskVolumeSize = constants.MonitoringCloudServerStorageSize
@@ -211,13 +221,45 @@ func (c *AwsCloud) CreateEC2Instances(prefix string, count int, amiID, instanceT
 		ebsValue.Iops = aws.Int32(int32(iops))
 	}
 
+	tags := []types.Tag{
+		{
+			Key:   aws.String("Name"),
+			Value: aws.String(prefix),
+		},
+		{
+			Key:   aws.String("Managed-By"),
+			Value: aws.String("avalanche-cli"),
+		},
+	}
+	if !lifecycle.ExpiresAt.IsZero() {
+		tags = append(tags, types.Tag{
+			Key:   aws.String(ExpiresAtTag),
+			Value: aws.String(lifecycle.ExpiresAt.UTC().Format(time.RFC3339)),
+		})
+	}
+	var marketOptions *types.InstanceMarketOptionsRequest
+	if lifecycle.Spot {
+		// one-time spot instances are terminated when interrupted
+		spotOptions := &types.SpotMarketOptions{
+			SpotInstanceType:             types.SpotInstanceTypeOneTime,
+			InstanceInterruptionBehavior: types.InstanceInterruptionBehaviorTerminate,
+		}
+		if lifecycle.SpotMaxPrice != "" {
+			spotOptions.MaxPrice = aws.String(lifecycle.SpotMaxPrice)
+		}
+		marketOptions = &types.InstanceMarketOptionsRequest{
+			MarketType:  types.MarketTypeSpot,
+			SpotOptions: spotOptions,
+		}
+	}
 	runResult, err := c.ec2Client.RunInstances(c.ctx, &ec2.RunInstancesInput{
-		ImageId:          aws.String(amiID),
-		InstanceType:     types.InstanceType(instanceType),
-		KeyName:          aws.String(keyName),
-		SecurityGroupIds: []string{securityGroupID},
-		MinCount:         aws.Int32(int32(count)),
-		MaxCount:         aws.Int32(int32(count)),
+		ImageId:               aws.String(amiID),
+		InstanceMarketOptions: marketOptions,
+		InstanceType:          types.InstanceType(instanceType),
+		KeyName:               aws.String(keyName),
+		SecurityGroupIds:      []string{securityGroupID},
+		MinCount:              aws.Int32(int32(count)),
+		MaxCount:              aws.Int32(int32(count)),
 		BlockDeviceMappings: []types.BlockDeviceMapping{
 			{
 				DeviceName: aws.String("/dev/sda1"), // ubuntu ami disk name
@@ -227,16 +269,7 @@ func (c *AwsCloud) CreateEC2Instances(prefix string, count int, amiID, instanceT
 		TagSpecifications: []types.TagSpecification{
 			{
 				ResourceType: types.ResourceTypeInstance,
-				Tags: []types.Tag{
-					{
-						Key:   aws.String("Name"),
-						Value: aws.String(prefix),
-					},
-					{
-						Key:   aws.String("Managed-By"),
-						Value: aws.String("avalanche-cli"),
-					},
-				},
+				Tags:         tags,
 			},
 		},
 	})
@@ -321,6 +354,18 @@ func (c *AwsCloud) GetInstancePublicIPs(nodeIDs []string) (map[string]string, er
 
 // checkInstanceIsRunning checks that EC2 instance nodeID is running in EC2
 func (c *AwsCloud) checkInstanceIsRunning(nodeID string) (bool, error) {
+	instanceStatus, err := c.getInstanceState(nodeID)
+	if err != nil {
+		return false, err
+	}
+	if instanceStatus == constants.AWSCloudServerRunningState {
+		return true, nil
+	}
+	return false, nil
+}
+
+// getInstanceState returns the state of EC2 instance nodeID
+func (c *AwsCloud) getInstanceState(nodeID string) (types.InstanceStateName, error) {
 	instanceInput := &ec2.DescribeInstancesInput{
 		InstanceIds: []string{
 			*aws.String(nodeID),
@@ -328,21 +373,17 @@ func (c *AwsCloud) checkInstanceIsRunning(nodeID string) (bool, error) {
 	}
 	nodeStatus, err := c.ec2Client.DescribeInstances(c.ctx, instanceInput)
 	if err != nil {
-		return false, err
+		return "", err
 	}
 	reservation := nodeStatus.Reservations
 	if len(reservation) == 0 {
-		return false, ErrNoInstanceState
+		return "", ErrNoInstanceState
 	}
 	instances := reservation[0].Instances
-	if len(instances) == 0 {
-		return false, ErrNoInstanceState
-	}
-	instanceStatus := instances[0].State.Name
-	if instanceStatus == constants.AWSCloudServerRunningState {
-		return true, nil
+	if len(instances) == 0 || instances[0].State == nil {
+		return "", ErrNoInstanceState
 	}
-	return false, nil
+	return instances[0].State.Name, nil
 }
 
 // DestroyAWSNode terminates an EC2 instance with the given ID.
@@ -371,20 +412,12 @@ func (c *AwsCloud) DestroyInstance(instanceID, publicIP string, releasePublicIP
 		if publicIP == "" {
 			ux.Logger.RedXToUser("Unable to remove public IP for instance %s: undefined", instanceID)
 		} else {
-			describeAddressInput := &ec2.DescribeAddressesInput{
-				Filters: []types.Filter{
-					{Name: aws.String("public-ip"), Values: []string{publicIP}},
-				},
-			}
-			addressOutput, err := c.ec2Client.DescribeAddresses(c.ctx, describeAddressInput)
+			allocationID, err := c.GetEIPAllocationID(publicIP)
 			if err != nil {
 				return err
 			}
-			if len(addressOutput.Addresses) == 0 {
-				return ErrNoAddressFound
-			}
 			releaseAddressInput := &ec2.ReleaseAddressInput{
-				AllocationId: aws.String(*addressOutput.Addresses[0].AllocationId),
+				AllocationId: aws.String(allocationID),
 			}
 			if _, err = c.ec2Client.ReleaseAddress(c.ctx, releaseAddressInput); err != nil {
 				return err
@@ -422,6 +455,40 @@ func (c *AwsCloud) CreateEIP(prefix string) (string, string, error) {
 	}
 }
 
+// GetEIPAllocationID returns the allocation ID of the Elastic IP address [publicIP]
+func (c *AwsCloud) GetEIPAllocationID(publicIP string) (string, error) {
+	addressOutput, err := c.ec2Client.DescribeAddresses(c.ctx, &ec2.DescribeAddressesInput{
+		Filters: []types.Filter{
+			{Name: aws.String("public-ip"), Values: []string{publicIP}},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(addressOutput.Addresses) == 0 {
+		return "", ErrNoAddressFound
+	}
+	return *addressOutput.Addresses[0].AllocationId, nil
+}
+
+// GetInstanceExpiry returns the time at which the EC2 instance expires, as tagged on
+// its creation. Returns the zero time for instances without expiry
+func (c *AwsCloud) GetInstanceExpiry(instanceID string) (time.Time, error) {
+	output, err := c.ec2Client.DescribeTags(c.ctx, &ec2.DescribeTagsInput{
+		Filters: []types.Filter{
+			{Name: aws.String("resource-id"), Values: []string{instanceID}},
+			{Name: aws.String("key"), Values: []string{ExpiresAtTag}},
+		},
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(output.Tags) == 0 || output.Tags[0].Value == nil {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, *output.Tags[0].Value)
+}
+
 // AssociateEIP associates an Elastic IP address with an EC2 instance.
 func (c *AwsCloud) AssociateEIP(instanceID, allocationID string) error {
 	if _, err := c.ec2Client.AssociateAddress(c.ctx, &ec2.AssociateAddressInput{
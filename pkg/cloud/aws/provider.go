@@ -0,0 +1,309 @@
+// Copyright (C) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Provider implements cloud.Provider on top of per region AwsCloud clients.
+// Security groups are referenced by name
+type Provider struct {
+	awsProfile string
+	lock       sync.Mutex
+	clouds     map[string]*AwsCloud
+}
+
+var _ cloud.Provider = (*Provider)(nil)
+
+// NewProvider creates an AWS cloud provider using the given AWS profile
+func NewProvider(awsProfile string) *Provider {
+	return &Provider{
+		awsProfile: awsProfile,
+		clouds:     map[string]*AwsCloud{},
+	}
+}
+
+// Cloud returns the AwsCloud client for the region, creating it if needed
+func (p *Provider) Cloud(region string) (*AwsCloud, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if c, ok := p.clouds[region]; ok {
+		return c, nil
+	}
+	c, err := NewAwsCloud(p.awsProfile, region)
+	if err != nil {
+		return nil, err
+	}
+	p.clouds[region] = c
+	return c, nil
+}
+
+// Name returns the AWS cloud service name
+func (*Provider) Name() string {
+	return constants.AWSCloudService
+}
+
+// CreateInstances creates EC2 instances in the given region
+func (p *Provider) CreateInstances(region string, count int, spec cloud.InstanceSpec) ([]string, error) {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return nil, err
+	}
+	sg, err := c.securityGroup(spec.SecurityGroup)
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateEC2Instances(
+		spec.Prefix,
+		count,
+		spec.ImageID,
+		spec.InstanceType,
+		spec.KeyPairName,
+		*sg.GroupId,
+		spec.ForMonitoring,
+		spec.IOPS,
+		spec.Throughput,
+		types.VolumeType(spec.VolumeType),
+		spec.VolumeSize,
+		InstanceLifecycle{
+			Spot:         spec.Spot,
+			SpotMaxPrice: spec.SpotMaxPrice,
+			ExpiresAt:    spec.ExpiresAt,
+		},
+	)
+}
+
+// WaitForInstances waits for the EC2 instances to be running
+func (p *Provider) WaitForInstances(region string, instanceIDs []string) error {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return err
+	}
+	return c.WaitForEC2Instances(instanceIDs, types.InstanceStateNameRunning)
+}
+
+// IsInstanceRunning checks if the EC2 instance exists and is running
+func (p *Provider) IsInstanceRunning(region, instanceID string) (bool, error) {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return false, err
+	}
+	isRunning, err := c.checkInstanceIsRunning(instanceID)
+	if err != nil && (errors.Is(err, ErrNoInstanceState) || strings.Contains(err.Error(), "InvalidInstanceID.NotFound")) {
+		return false, nil
+	}
+	return isRunning, err
+}
+
+// InstanceExists checks if the EC2 instance exists and is not terminated
+func (p *Provider) InstanceExists(region, instanceID string) (bool, error) {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return false, err
+	}
+	state, err := c.getInstanceState(instanceID)
+	if err != nil {
+		if errors.Is(err, ErrNoInstanceState) || strings.Contains(err.Error(), "InvalidInstanceID.NotFound") {
+			return false, nil
+		}
+		return false, err
+	}
+	return state != types.InstanceStateNameTerminated && state != types.InstanceStateNameShuttingDown, nil
+}
+
+// GetInstancePublicIPs returns the public IPs of the given EC2 instances
+func (p *Provider) GetInstancePublicIPs(region string, instanceIDs []string) (map[string]string, error) {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetInstancePublicIPs(instanceIDs)
+}
+
+// DestroyNode terminates the EC2 instance of the node
+func (p *Provider) DestroyNode(nodeConfig models.NodeConfig, clusterName string) error {
+	c, err := p.Cloud(nodeConfig.Region)
+	if err != nil {
+		return err
+	}
+	return c.DestroyAWSNode(nodeConfig, clusterName)
+}
+
+// AddFirewallRule adds ingress rules for [ip] on [ports] not yet present in the security group
+func (p *Provider) AddFirewallRule(region, securityGroup, ip string, ports []int) error {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return err
+	}
+	sg, err := c.securityGroup(securityGroup)
+	if err != nil {
+		return err
+	}
+	for _, port := range ports {
+		if CheckIPInSg(&sg, ip, int32(port)) {
+			continue
+		}
+		if err := c.AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", ip, int32(port)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveFirewallRule revokes ingress rules for [ip] on [ports] present in the security group
+func (p *Provider) RemoveFirewallRule(region, securityGroup, ip string, ports []int) error {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return err
+	}
+	exists, sg, err := c.CheckSecurityGroupExists(securityGroup)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	for _, port := range ports {
+		if !CheckIPInSg(&sg, ip, int32(port)) {
+			continue
+		}
+		if err := c.DeleteSecurityGroupRule(*sg.GroupId, "ingress", "tcp", ip, int32(port)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListFirewallRules returns the ingress rules of the security group, one per source range
+func (p *Provider) ListFirewallRules(region, securityGroup string) ([]cloud.FirewallRule, error) {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return nil, err
+	}
+	sg, err := c.securityGroup(securityGroup)
+	if err != nil {
+		return nil, err
+	}
+	rules := []cloud.FirewallRule{}
+	for _, permission := range sg.IpPermissions {
+		rule := cloud.FirewallRule{
+			Protocol: aws.ToString(permission.IpProtocol),
+			FromPort: 0,
+			ToPort:   65535,
+		}
+		if rule.Protocol == "-1" {
+			rule.Protocol = cloud.AllProtocols
+		} else if permission.FromPort != nil && permission.ToPort != nil {
+			rule.FromPort = int(*permission.FromPort)
+			rule.ToPort = int(*permission.ToPort)
+		}
+		for _, ipRange := range permission.IpRanges {
+			rule.CIDR = aws.ToString(ipRange.CidrIp)
+			rules = append(rules, rule)
+		}
+		for _, ipRange := range permission.Ipv6Ranges {
+			rule.CIDR = aws.ToString(ipRange.CidrIpv6)
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// RevokeFirewallRules deletes the given ingress rules from the security group
+func (p *Provider) RevokeFirewallRules(region, securityGroup string, rules []cloud.FirewallRule) error {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return err
+	}
+	sg, err := c.securityGroup(securityGroup)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		protocol := rule.Protocol
+		if protocol == cloud.AllProtocols {
+			protocol = "-1"
+		}
+		if err := c.DeleteSecurityGroupRuleRange(*sg.GroupId, "ingress", protocol, rule.CIDR, int32(rule.FromPort), int32(rule.ToPort)); err != nil {
+			return fmt.Errorf("failed to revoke access of %s on ports %s: %w", rule.CIDR, rule.Ports(), err)
+		}
+	}
+	return nil
+}
+
+// KeyPairExists checks if the key pair exists in the region
+func (p *Provider) KeyPairExists(region, keyPairName string) (bool, error) {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return false, err
+	}
+	return c.CheckKeyPairExists(keyPairName)
+}
+
+// CreateKeyPair creates the key pair in the region and downloads its private key
+func (p *Provider) CreateKeyPair(region, keyPairName, privateKeyPath string) error {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return err
+	}
+	return c.CreateAndDownloadKeyPair(keyPairName, privateKeyPath)
+}
+
+// IsInstanceTypeSupported checks if the instance type is available in the region
+func (p *Provider) IsInstanceTypeSupported(region, instanceType string) (bool, error) {
+	c, err := p.Cloud(region)
+	if err != nil {
+		return false, err
+	}
+	return c.IsInstanceTypeSupported(instanceType)
+}
+
+// ResizeRootVolume grows the root EBS volume of the node
+func (p *Provider) ResizeRootVolume(nodeConfig models.NodeConfig, sizeGB int) error {
+	if sizeGB > math.MaxInt32 {
+		return fmt.Errorf("disk size exceeds maximum supported value")
+	}
+	c, err := p.Cloud(nodeConfig.Region)
+	if err != nil {
+		return err
+	}
+	rootVolume, err := c.GetRootVolumeID(nodeConfig.NodeID)
+	if err != nil {
+		return err
+	}
+	return c.ResizeVolume(rootVolume, int32(sizeGB))
+}
+
+// ChangeInstanceType changes the instance type of the node
+func (p *Provider) ChangeInstanceType(nodeConfig models.NodeConfig, instanceType string) error {
+	c, err := p.Cloud(nodeConfig.Region)
+	if err != nil {
+		return err
+	}
+	return c.ChangeInstanceType(nodeConfig.NodeID, instanceType)
+}
+
+// securityGroup returns the security group with the given name
+func (c *AwsCloud) securityGroup(sgName string) (types.SecurityGroup, error) {
+	exists, sg, err := c.CheckSecurityGroupExists(sgName)
+	if err != nil {
+		return types.SecurityGroup{}, err
+	}
+	if !exists {
+		return types.SecurityGroup{}, fmt.Errorf("security group %s not found", sgName)
+	}
+	return sg, nil
+}
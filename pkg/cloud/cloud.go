@@ -0,0 +1,79 @@
+// Copyright (C) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cloud
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/models"
+)
+
+var (
+	ErrNodeNotFoundToBeRunning = errors.New("node not found to be running")
+	ErrNotSupported            = errors.New("operation not supported by cloud provider")
+)
+
+// InstanceSpec describes the instances to be created by a Provider
+type InstanceSpec struct {
+	Prefix        string
+	ImageID       string
+	InstanceType  string
+	KeyPairName   string
+	SecurityGroup string
+	SSHPublicKey  string
+	VolumeSize    int
+	VolumeType    string
+	IOPS          int
+	Throughput    int
+	ForMonitoring bool
+	// Spot requests spot instances, with an optional max hourly price [AWS only]
+	Spot         bool
+	SpotMaxPrice string
+	// ExpiresAt is tagged into the instances if set [AWS only]
+	ExpiresAt time.Time
+}
+
+// Provider is the set of operations node commands need from a cloud service
+// in order to manage the lifecycle of cluster hosts.
+//
+// region is the provider specific location of the instances (AWS region,
+// GCP zone, ignored by docker) and securityGroup is the provider specific
+// access control resource (AWS security group name, GCP network name).
+type Provider interface {
+	// Name returns the cloud service name stored in node configs
+	Name() string
+	// CreateInstances creates [count] instances and returns their IDs
+	CreateInstances(region string, count int, spec InstanceSpec) ([]string, error)
+	// WaitForInstances blocks until all given instances are running
+	WaitForInstances(region string, instanceIDs []string) error
+	// IsInstanceRunning checks if the instance exists and is running
+	IsInstanceRunning(region, instanceID string) (bool, error)
+	// InstanceExists checks if the instance exists, running or stopped. Returns false only
+	// when the cloud reports the instance as not found or terminated
+	InstanceExists(region, instanceID string) (bool, error)
+	// GetInstancePublicIPs returns a map from instance ID to public IP
+	GetInstancePublicIPs(region string, instanceIDs []string) (map[string]string, error)
+	// DestroyNode terminates the node instance and releases its static IP if any.
+	// Returns ErrNodeNotFoundToBeRunning if the instance is already gone
+	DestroyNode(nodeConfig models.NodeConfig, clusterName string) error
+	// AddFirewallRule allows TCP ingress from [ip] on the given ports
+	AddFirewallRule(region, securityGroup, ip string, ports []int) error
+	// RemoveFirewallRule revokes TCP ingress from [ip] on the given ports
+	RemoveFirewallRule(region, securityGroup, ip string, ports []int) error
+	// ListFirewallRules returns the ingress rules of the security group
+	ListFirewallRules(region, securityGroup string) ([]FirewallRule, error)
+	// RevokeFirewallRules revokes ingress rules returned by ListFirewallRules
+	RevokeFirewallRules(region, securityGroup string, rules []FirewallRule) error
+	// KeyPairExists checks if the key pair is registered in the cloud
+	KeyPairExists(region, keyPairName string) (bool, error)
+	// CreateKeyPair creates a key pair and saves its private key at [privateKeyPath]
+	CreateKeyPair(region, keyPairName, privateKeyPath string) error
+	// IsInstanceTypeSupported checks if the instance type is available in the region
+	IsInstanceTypeSupported(region, instanceType string) (bool, error)
+	// ResizeRootVolume grows the root volume of the node to [sizeGB]
+	ResizeRootVolume(nodeConfig models.NodeConfig, sizeGB int) error
+	// ChangeInstanceType changes the instance type of the node
+	ChangeInstanceType(nodeConfig models.NodeConfig, instanceType string) error
+}
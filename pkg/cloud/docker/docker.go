@@ -0,0 +1,289 @@
+// Copyright (C) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+)
+
+const containerNamePrefix = "ubuntu_container"
+
+var containerNameRegex = regexp.MustCompile(`container_name: ` + containerNamePrefix + `(\d+)`)
+
+// Provider implements cloud.Provider with local docker containers managed
+// by a docker compose file. Container k (0 based) is reachable at
+// <E2ENetworkPrefix>.<k+2> and its instance ID is docker<k+1>-<random suffix>.
+// Indexes of destroyed containers are not reused while the compose file exists.
+// Regions and security groups are ignored
+type Provider struct {
+	composeFile string
+	lock        sync.Mutex
+}
+
+var _ cloud.Provider = (*Provider)(nil)
+
+// NewProvider creates a docker provider backed by the given compose file
+func NewProvider(composeFile string) *Provider {
+	return &Provider{composeFile: composeFile}
+}
+
+// Available checks if docker and docker-compose are installed
+func Available() bool {
+	return utils.E2EDocker()
+}
+
+// Name returns the docker cloud service name
+func (*Provider) Name() string {
+	return constants.DockerCloudService
+}
+
+// CreateInstances adds [count] containers to the compose file and starts them
+func (p *Provider) CreateInstances(_ string, count int, spec cloud.InstanceSpec) ([]string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	indexes, err := p.containerIndexes()
+	if err != nil {
+		return nil, err
+	}
+	next := 0
+	for _, index := range indexes {
+		next = max(next, index+1)
+	}
+	ubuntuVersion := spec.ImageID
+	if ubuntuVersion == "" || ubuntuVersion == constants.DockerCloudService {
+		ubuntuVersion = constants.DockerUbuntuVersion
+	}
+	instanceIDs := make([]string, count)
+	for i := range instanceIDs {
+		indexes = append(indexes, next+i)
+		instanceIDs[i] = fmt.Sprintf("docker%d-%s", next+i+1, utils.RandomString(5))
+	}
+	content, err := utils.GenDockerComposeFileForContainers(indexes, ubuntuVersion, constants.E2ENetworkPrefix, strings.TrimSuffix(spec.SSHPublicKey, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(p.composeFile, []byte(content), constants.WriteReadUserOnlyPerms); err != nil {
+		return nil, err
+	}
+	if err := utils.StartDockerCompose(p.composeFile); err != nil {
+		return nil, err
+	}
+	return instanceIDs, nil
+}
+
+// WaitForInstances is a no-op as compose starts containers synchronously
+func (*Provider) WaitForInstances(string, []string) error {
+	return nil
+}
+
+// GetInstancePublicIPs returns the compose network IPs of the given containers
+func (*Provider) GetInstancePublicIPs(_ string, instanceIDs []string) (map[string]string, error) {
+	ips := map[string]string{}
+	for _, instanceID := range instanceIDs {
+		index, err := InstanceIndex(instanceID)
+		if err != nil {
+			return nil, err
+		}
+		ips[instanceID] = InstanceIP(index)
+	}
+	return ips, nil
+}
+
+// IsInstanceRunning checks if the container of the instance is running
+func (*Provider) IsInstanceRunning(_ string, instanceID string) (bool, error) {
+	index, err := InstanceIndex(instanceID)
+	if err != nil {
+		return false, err
+	}
+	out, err := exec.Command("docker", "inspect", "-f", "{{.State.Running}}", ContainerName(index)).Output()
+	if err != nil {
+		// docker inspect fails for unknown containers
+		return false, nil
+	}
+	return strings.TrimSpace(string(out)) == "true", nil
+}
+
+// InstanceExists checks if the container of the instance exists, running or stopped
+func (*Provider) InstanceExists(_ string, instanceID string) (bool, error) {
+	index, err := InstanceIndex(instanceID)
+	if err != nil {
+		return false, err
+	}
+	// docker inspect fails for unknown containers
+	return exec.Command("docker", "inspect", ContainerName(index)).Run() == nil, nil
+}
+
+// DestroyNode removes the container of the node and its service from the compose file
+func (p *Provider) DestroyNode(nodeConfig models.NodeConfig, clusterName string) error {
+	index, err := InstanceIndex(nodeConfig.NodeID)
+	if err != nil {
+		return err
+	}
+	containerName := ContainerName(index)
+	if isRunning, _ := p.IsInstanceRunning("", nodeConfig.NodeID); !isRunning {
+		return fmt.Errorf("%w: instance %s, cluster %s", cloud.ErrNodeNotFoundToBeRunning, nodeConfig.NodeID, clusterName)
+	}
+	ux.Logger.PrintToUser(fmt.Sprintf("Removing node container %s in cluster %s...", nodeConfig.NodeID, clusterName))
+	if out, err := exec.Command("docker", "rm", "-f", containerName).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove container %s: %s: %w", containerName, strings.TrimSpace(string(out)), err)
+	}
+	return p.removeService(index - 1)
+}
+
+// removeService removes the service of the container with 0 based index from the
+// compose file. The compose project is stopped and the file removed if it was the last one
+func (p *Provider) removeService(index int) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !utils.FileExists(p.composeFile) {
+		return nil
+	}
+	content, err := os.ReadFile(p.composeFile)
+	if err != nil {
+		return err
+	}
+	updated := removeComposeService(string(content), index)
+	if len(composeIndexes(updated)) == 0 {
+		if err := utils.StopDockerCompose(p.composeFile); err != nil {
+			return err
+		}
+		return os.Remove(p.composeFile)
+	}
+	return os.WriteFile(p.composeFile, []byte(updated), constants.WriteReadUserOnlyPerms)
+}
+
+// Cleanup stops the compose project and removes the compose file
+func (p *Provider) Cleanup() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !utils.FileExists(p.composeFile) {
+		return nil
+	}
+	if err := utils.StopDockerCompose(p.composeFile); err != nil {
+		return err
+	}
+	return os.Remove(p.composeFile)
+}
+
+// AddFirewallRule is a no-op as containers are reachable from the local host
+func (*Provider) AddFirewallRule(string, string, string, []int) error {
+	return nil
+}
+
+// RemoveFirewallRule is a no-op as containers are reachable from the local host
+func (*Provider) RemoveFirewallRule(string, string, string, []int) error {
+	return nil
+}
+
+// ListFirewallRules is not supported as containers have no firewall
+func (*Provider) ListFirewallRules(string, string) ([]cloud.FirewallRule, error) {
+	return nil, fmt.Errorf("%w: list firewall rules on %s", cloud.ErrNotSupported, constants.DockerCloudService)
+}
+
+// RevokeFirewallRules is not supported as containers have no firewall
+func (*Provider) RevokeFirewallRules(string, string, []cloud.FirewallRule) error {
+	return fmt.Errorf("%w: revoke firewall rules on %s", cloud.ErrNotSupported, constants.DockerCloudService)
+}
+
+// KeyPairExists always succeeds as the ssh public key is injected by the compose file
+func (*Provider) KeyPairExists(string, string) (bool, error) {
+	return true, nil
+}
+
+// CreateKeyPair generates a local ssh key pair
+func (*Provider) CreateKeyPair(_, _, privateKeyPath string) error {
+	return cloud.GenerateSSHKeyPair(privateKeyPath)
+}
+
+// IsInstanceTypeSupported only accepts the docker instance type
+func (*Provider) IsInstanceTypeSupported(_, instanceType string) (bool, error) {
+	return instanceType == constants.DockerCloudService, nil
+}
+
+// ResizeRootVolume is not supported on docker
+func (*Provider) ResizeRootVolume(models.NodeConfig, int) error {
+	return fmt.Errorf("%w: resize disk on %s", cloud.ErrNotSupported, constants.DockerCloudService)
+}
+
+// ChangeInstanceType is not supported on docker
+func (*Provider) ChangeInstanceType(models.NodeConfig, string) error {
+	return fmt.Errorf("%w: change instance type on %s", cloud.ErrNotSupported, constants.DockerCloudService)
+}
+
+// containerIndexes returns the 0 based indexes of the containers declared in the compose file
+func (p *Provider) containerIndexes() ([]int, error) {
+	if !utils.FileExists(p.composeFile) {
+		return nil, nil
+	}
+	content, err := os.ReadFile(p.composeFile)
+	if err != nil {
+		return nil, err
+	}
+	return composeIndexes(string(content)), nil
+}
+
+// composeIndexes returns the 0 based indexes of the containers declared in compose file content
+func composeIndexes(content string) []int {
+	indexes := []int{}
+	for _, match := range containerNameRegex.FindAllStringSubmatch(content, -1) {
+		if index, err := strconv.Atoi(match[1]); err == nil {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// removeComposeService removes the service block of the container with 0 based index
+// from compose file content. Service properties are indented deeper than service names
+func removeComposeService(content string, index int) string {
+	serviceLine := fmt.Sprintf("  ubuntu%d:", index)
+	lines := strings.Split(content, "\n")
+	kept := []string{}
+	for i := 0; i < len(lines); i++ {
+		if lines[i] != serviceLine {
+			kept = append(kept, lines[i])
+			continue
+		}
+		for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "    ") {
+			i++
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// InstanceIndex returns the 1 based index k of instance ID docker<k>-<suffix>
+func InstanceIndex(instanceID string) (int, error) {
+	indexStr, _, found := strings.Cut(strings.TrimPrefix(instanceID, constants.DockerCloudService), "-")
+	if !found || !strings.HasPrefix(instanceID, constants.DockerCloudService) {
+		return 0, fmt.Errorf("invalid docker instance ID %s", instanceID)
+	}
+	index, err := strconv.Atoi(indexStr)
+	if err != nil || index <= 0 {
+		return 0, errors.Join(fmt.Errorf("invalid docker instance ID %s", instanceID), err)
+	}
+	return index, nil
+}
+
+// InstanceIP returns the compose network IP of the instance with 1 based index k
+func InstanceIP(index int) string {
+	return fmt.Sprintf("%s.%d", constants.E2ENetworkPrefix, index+1)
+}
+
+// ContainerName returns the compose container name of the instance with 1 based index k
+func ContainerName(index int) string {
+	return fmt.Sprintf("%s%d", containerNamePrefix, index-1)
+}
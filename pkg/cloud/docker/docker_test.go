@@ -0,0 +1,68 @@
+// Copyright (C) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceIndex(t *testing.T) {
+	require := require.New(t)
+	index, err := InstanceIndex("docker3-abcde")
+	require.NoError(err)
+	require.Equal(3, index)
+	require.Equal("172.18.0.4", InstanceIP(index))
+	require.Equal("ubuntu_container2", ContainerName(index))
+	for _, id := range []string{"i-0123456789", "docker-abcde", "docker0-abcde", "dockerx-abcde"} {
+		_, err := InstanceIndex(id)
+		require.Error(err, id)
+	}
+}
+
+func TestGetInstancePublicIPsMatchesCompose(t *testing.T) {
+	require := require.New(t)
+	p := NewProvider("")
+	ids := utils.GenerateDockerHostIDs(3)
+	ips, err := p.GetInstancePublicIPs("", ids)
+	require.NoError(err)
+	expected := utils.GenerateDockerHostIPs(3)
+	for i, id := range ids {
+		require.Equal(expected[i], ips[id])
+	}
+}
+
+func TestContainerIndexes(t *testing.T) {
+	require := require.New(t)
+	composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+	p := NewProvider(composeFile)
+	indexes, err := p.containerIndexes()
+	require.NoError(err)
+	require.Empty(indexes)
+	content, err := utils.GenDockerComposeFile(4, "focal", "172.18.0", "ssh-ed25519 AAAA")
+	require.NoError(err)
+	require.NoError(os.WriteFile(composeFile, []byte(content), 0o600))
+	indexes, err = p.containerIndexes()
+	require.NoError(err)
+	require.Equal([]int{0, 1, 2, 3}, indexes)
+}
+
+func TestRemoveComposeService(t *testing.T) {
+	require := require.New(t)
+	content, err := utils.GenDockerComposeFileForContainers([]int{0, 2, 3}, "focal", "172.18.0", "ssh-ed25519 AAAA")
+	require.NoError(err)
+	require.Contains(content, "ipv4_address: 172.18.0.4")
+	updated := removeComposeService(content, 2)
+	require.Equal([]int{0, 3}, composeIndexes(updated))
+	require.NotContains(updated, "ubuntu2:")
+	require.NotContains(updated, "172.18.0.4")
+	require.Contains(updated, "ipv4_address: 172.18.0.5")
+	require.Contains(updated, "networks:\n  e2e:\n    ipam:")
+	require.Equal(content, removeComposeService(content, 1))
+	require.Empty(composeIndexes(removeComposeService(removeComposeService(updated, 0), 3)))
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package existing
+
+import (
+	"fmt"
+
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/ux"
+)
+
+// Provider implements cloud.Provider for servers not managed by the CLI.
+// Hosts can only be adopted into or detached from a cluster
+type Provider struct{}
+
+var _ cloud.Provider = (*Provider)(nil)
+
+// NewProvider creates a provider for existing hosts
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Name returns the existing hosts cloud service name
+func (*Provider) Name() string {
+	return constants.ExistingHostsCloudService
+}
+
+// CreateInstances is not supported, existing hosts are adopted from a hosts file
+func (*Provider) CreateInstances(string, int, cloud.InstanceSpec) ([]string, error) {
+	return nil, notSupported("create instances")
+}
+
+// WaitForInstances is a no-op as existing hosts are expected to be running
+func (*Provider) WaitForInstances(string, []string) error {
+	return nil
+}
+
+// IsInstanceRunning is not supported, existing hosts are only checked over ssh
+func (*Provider) IsInstanceRunning(string, string) (bool, error) {
+	return false, notSupported("check instance state")
+}
+
+// InstanceExists is not supported, existing hosts are only checked over ssh
+func (*Provider) InstanceExists(string, string) (bool, error) {
+	return false, notSupported("check instance existence")
+}
+
+// GetInstancePublicIPs is not supported, existing hosts have static IPs
+func (*Provider) GetInstancePublicIPs(string, []string) (map[string]string, error) {
+	return nil, notSupported("get public IPs")
+}
+
+// DestroyNode only detaches the host, the server itself is left untouched
+func (*Provider) DestroyNode(nodeConfig models.NodeConfig, clusterName string) error {
+	ux.Logger.PrintToUser(fmt.Sprintf("Detaching existing host %s from cluster %s...", nodeConfig.NodeID, clusterName))
+	return nil
+}
+
+// AddFirewallRule is a no-op, firewalls of existing hosts are managed by their owners
+func (*Provider) AddFirewallRule(string, string, string, []int) error {
+	return nil
+}
+
+// RemoveFirewallRule is a no-op, firewalls of existing hosts are managed by their owners
+func (*Provider) RemoveFirewallRule(string, string, string, []int) error {
+	return nil
+}
+
+// ListFirewallRules is not supported, firewalls of existing hosts are managed by their owners
+func (*Provider) ListFirewallRules(string, string) ([]cloud.FirewallRule, error) {
+	return nil, notSupported("list firewall rules")
+}
+
+// RevokeFirewallRules is not supported, firewalls of existing hosts are managed by their owners
+func (*Provider) RevokeFirewallRules(string, string, []cloud.FirewallRule) error {
+	return notSupported("revoke firewall rules")
+}
+
+// KeyPairExists always succeeds as existing hosts come with their own ssh access
+func (*Provider) KeyPairExists(string, string) (bool, error) {
+	return true, nil
+}
+
+// CreateKeyPair is not supported, existing hosts come with their own ssh access
+func (*Provider) CreateKeyPair(string, string, string) error {
+	return notSupported("create key pair")
+}
+
+// IsInstanceTypeSupported always fails as existing hosts have no instance type
+func (*Provider) IsInstanceTypeSupported(string, string) (bool, error) {
+	return false, nil
+}
+
+// ResizeRootVolume is not supported on existing hosts
+func (*Provider) ResizeRootVolume(models.NodeConfig, int) error {
+	return notSupported("resize disk")
+}
+
+// ChangeInstanceType is not supported on existing hosts
+func (*Provider) ChangeInstanceType(models.NodeConfig, string) error {
+	return notSupported("change instance type")
+}
+
+func notSupported(op string) error {
+	return fmt.Errorf("%w: %s on %s", cloud.ErrNotSupported, op, constants.ExistingHostsCloudService)
+}
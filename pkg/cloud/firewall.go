@@ -0,0 +1,155 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cloud
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// AllProtocols is the protocol of firewall rules allowing any traffic
+const AllProtocols = "all"
+
+const (
+	// rules allowing wider IPv4/IPv6 blocks than these prefixes are flagged as broad
+	minIPv4AuditPrefix = 16
+	minIPv6AuditPrefix = 48
+)
+
+// FirewallRule is an ingress rule of a cloud firewall allowing traffic from the CIDR
+// block [CIDR] on ports [FromPort] to [ToPort]
+type FirewallRule struct {
+	// Name is the cloud name of the resource holding the rule, if any (GCP firewall name)
+	Name     string
+	Protocol string
+	CIDR     string
+	FromPort int
+	ToPort   int
+}
+
+// AllowsPort tells if the rule allows TCP traffic on [port]
+func (r FirewallRule) AllowsPort(port int) bool {
+	if r.Protocol != "tcp" && r.Protocol != AllProtocols {
+		return false
+	}
+	return r.FromPort <= port && port <= r.ToPort
+}
+
+// AllowsOnlyPorts tells if every port the rule allows is one of the TCP ports [ports], so
+// revoking it closes no other port
+func (r FirewallRule) AllowsOnlyPorts(ports []int) bool {
+	if r.Protocol != "tcp" {
+		return false
+	}
+	for port := r.FromPort; port <= r.ToPort; port++ {
+		if !slices.Contains(ports, port) {
+			return false
+		}
+	}
+	return true
+}
+
+// Ports returns the ports of the rule as shown to the user
+func (r FirewallRule) Ports() string {
+	switch {
+	case r.FromPort == 0 && r.ToPort == 65535:
+		return "all"
+	case r.FromPort == r.ToPort:
+		return fmt.Sprint(r.FromPort)
+	default:
+		return fmt.Sprintf("%d-%d", r.FromPort, r.ToPort)
+	}
+}
+
+// MatchesCIDR tells if the rule allows exactly the IP or CIDR block [cidr]
+func (r FirewallRule) MatchesCIDR(cidr string) bool {
+	a, err := NormalizeCIDR(r.CIDR)
+	if err != nil {
+		return false
+	}
+	b, err := NormalizeCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	return a == b
+}
+
+// NormalizeCIDR returns [ip] as a CIDR block, adding the host netmask to single IPs
+func NormalizeCIDR(ip string) (string, error) {
+	if !strings.Contains(ip, "/") {
+		parsedIP := net.ParseIP(ip)
+		if parsedIP == nil {
+			return "", fmt.Errorf("invalid IP address: %s", ip)
+		}
+		if parsedIP.To4() != nil {
+			return parsedIP.String() + "/32", nil
+		}
+		return parsedIP.String() + "/128", nil
+	}
+	_, ipNet, err := net.ParseCIDR(ip)
+	if err != nil {
+		return "", fmt.Errorf("invalid CIDR block: %s", ip)
+	}
+	return ipNet.String(), nil
+}
+
+// FirewallFinding is an overly broad rule found by AuditFirewallRules
+type FirewallFinding struct {
+	Rule     FirewallRule
+	Port     int
+	PortName string
+	// Public is set when the rule allows any address, and not only a broad block
+	Public bool
+}
+
+// String describes the finding to the user
+func (f FirewallFinding) String() string {
+	scope := "a broad address block"
+	if f.Public {
+		scope = "the internet"
+	}
+	return fmt.Sprintf("%s port %d is open to %s (%s)", f.PortName, f.Port, scope, f.Rule.CIDR)
+}
+
+// AuditFirewallRules returns the rules in [rules] that allow any address, or IPv4 blocks
+// wider than /16 and IPv6 blocks wider than /48, on the ports in [ports], a map from
+// port to name. Findings are ordered as the rules and then by port
+func AuditFirewallRules(rules []FirewallRule, ports map[int]string) []FirewallFinding {
+	sortedPorts := make([]int, 0, len(ports))
+	for port := range ports {
+		sortedPorts = append(sortedPorts, port)
+	}
+	sort.Ints(sortedPorts)
+	findings := []FirewallFinding{}
+	for _, rule := range rules {
+		_, ipNet, err := net.ParseCIDR(rule.CIDR)
+		if err != nil {
+			continue
+		}
+		prefix, bits := ipNet.Mask.Size()
+		minPrefix := minIPv4AuditPrefix
+		if bits == net.IPv6len*8 {
+			minPrefix = minIPv6AuditPrefix
+		}
+		if prefix >= minPrefix {
+			continue
+		}
+		for _, port := range sortedPorts {
+			if !rule.AllowsPort(port) {
+				continue
+			}
+			findings = append(findings, FirewallFinding{
+				Rule:     rule,
+				Port:     port,
+				PortName: ports[port],
+				Public:   prefix == 0,
+			})
+		}
+	}
+	return findings
+}
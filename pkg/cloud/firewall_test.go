@@ -0,0 +1,73 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirewallRule(t *testing.T) {
+	require := require.New(t)
+	rule := FirewallRule{Protocol: "tcp", CIDR: "1.2.3.4/32", FromPort: 9650, ToPort: 9660}
+	require.True(rule.AllowsPort(9650))
+	require.True(rule.AllowsPort(9660))
+	require.False(rule.AllowsPort(22))
+	require.Equal("9650-9660", rule.Ports())
+	require.False(rule.AllowsOnlyPorts([]int{9650}))
+	require.True(FirewallRule{Protocol: "tcp", FromPort: 9650, ToPort: 9651}.AllowsOnlyPorts([]int{22, 9650, 9651}))
+	require.True(rule.MatchesCIDR("1.2.3.4"))
+	require.True(rule.MatchesCIDR("1.2.3.4/32"))
+	require.False(rule.MatchesCIDR("1.2.3.0/24"))
+	require.False(rule.MatchesCIDR("11.2.3.4"))
+
+	udp := FirewallRule{Protocol: "udp", CIDR: "0.0.0.0/0", FromPort: 22, ToPort: 22}
+	require.False(udp.AllowsPort(22))
+	all := FirewallRule{Protocol: AllProtocols, CIDR: "0.0.0.0/0", FromPort: 0, ToPort: 65535}
+	require.True(all.AllowsPort(22))
+	require.Equal("all", all.Ports())
+	require.False(all.AllowsOnlyPorts([]int{22}))
+	require.False(udp.AllowsOnlyPorts([]int{22}))
+	require.Equal("22", FirewallRule{FromPort: 22, ToPort: 22}.Ports())
+}
+
+func TestNormalizeCIDR(t *testing.T) {
+	require := require.New(t)
+	cidr, err := NormalizeCIDR("1.2.3.4")
+	require.NoError(err)
+	require.Equal("1.2.3.4/32", cidr)
+	cidr, err = NormalizeCIDR("1.2.3.4/16")
+	require.NoError(err)
+	require.Equal("1.2.0.0/16", cidr)
+	cidr, err = NormalizeCIDR("2001:db8::1")
+	require.NoError(err)
+	require.Equal("2001:db8::1/128", cidr)
+	_, err = NormalizeCIDR("1.2.3")
+	require.Error(err)
+	_, err = NormalizeCIDR("1.2.3.4/33")
+	require.Error(err)
+}
+
+func TestAuditFirewallRules(t *testing.T) {
+	require := require.New(t)
+	ports := map[int]string{22: "SSH", 9650: "API"}
+	rules := []FirewallRule{
+		{Protocol: "tcp", CIDR: "1.2.3.4/32", FromPort: 22, ToPort: 22},
+		{Protocol: "tcp", CIDR: "0.0.0.0/0", FromPort: 9651, ToPort: 9651},
+		{Protocol: "tcp", CIDR: "0.0.0.0/0", FromPort: 22, ToPort: 22},
+		{Protocol: "tcp", CIDR: "10.0.0.0/8", FromPort: 9650, ToPort: 9650},
+		{Protocol: "tcp", CIDR: "10.1.0.0/16", FromPort: 9650, ToPort: 9650},
+		{Protocol: AllProtocols, CIDR: "::/0", FromPort: 0, ToPort: 65535},
+	}
+	findings := AuditFirewallRules(rules, ports)
+	require.Len(findings, 4)
+	require.Equal(FirewallFinding{Rule: rules[2], Port: 22, PortName: "SSH", Public: true}, findings[0])
+	require.Equal(FirewallFinding{Rule: rules[3], Port: 9650, PortName: "API", Public: false}, findings[1])
+	require.Equal(22, findings[2].Port)
+	require.Equal(9650, findings[3].Port)
+	require.True(findings[3].Public)
+	require.Equal("SSH port 22 is open to the internet (0.0.0.0/0)", findings[0].String())
+	require.Equal("API port 9650 is open to a broad address block (10.0.0.0/8)", findings[1].String())
+}
@@ -5,7 +5,6 @@ package gcp
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -18,6 +17,7 @@ import (
 
 	"google.golang.org/api/compute/v1"
 
+	"github.com/ixAnkit/cryft/pkg/cloud"
 	"github.com/ixAnkit/cryft/pkg/constants"
 	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/utils"
@@ -31,7 +31,7 @@ const (
 	gcpRegionAPI  = "https://www.googleapis.com/compute/v1/projects/%s/regions/%s"
 )
 
-var ErrNodeNotFoundToBeRunning = errors.New("node not found to be running")
+var ErrNodeNotFoundToBeRunning = cloud.ErrNodeNotFoundToBeRunning
 
 type GcpCloud struct {
 	gcpClient *compute.Service
@@ -461,6 +461,50 @@ func (c *GcpCloud) AddFirewall(publicIP, networkName, projectName, firewallName
 	return nil
 }
 
+// DeleteFirewall removes firewall firewallName from the GCP project
+func (c *GcpCloud) DeleteFirewall(firewallName string) error {
+	deleteOp, err := c.gcpClient.Firewalls.Delete(c.projectID, firewallName).Do()
+	if err != nil {
+		return fmt.Errorf("error deleting firewall rule %s: %w", firewallName, err)
+	}
+	return c.waitForOperation(deleteOp)
+}
+
+// ListFirewalls returns the enabled ingress firewalls of network networkName, from all
+// the pages of the firewall list of the GCP project
+func (c *GcpCloud) ListFirewalls(networkName string) ([]*compute.Firewall, error) {
+	firewalls := []*compute.Firewall{}
+	if err := c.gcpClient.Firewalls.List(c.projectID).Pages(c.ctx, func(firewallList *compute.FirewallList) error {
+		for _, firewall := range firewallList.Items {
+			if !strings.HasSuffix(firewall.Network, "/networks/"+networkName) || firewall.Disabled {
+				continue
+			}
+			if firewall.Direction != "" && firewall.Direction != "INGRESS" {
+				continue
+			}
+			firewalls = append(firewalls, firewall)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return firewalls, nil
+}
+
+// GetFirewall returns firewall firewallName of the GCP project
+func (c *GcpCloud) GetFirewall(firewallName string) (*compute.Firewall, error) {
+	return c.gcpClient.Firewalls.Get(c.projectID, firewallName).Do()
+}
+
+// UpdateFirewall replaces the firewall of the GCP project with the same name by [firewall]
+func (c *GcpCloud) UpdateFirewall(firewall *compute.Firewall) error {
+	updateOp, err := c.gcpClient.Firewalls.Update(c.projectID, firewall.Name, firewall).Do()
+	if err != nil {
+		return fmt.Errorf("error updating firewall rule %s: %w", firewall.Name, err)
+	}
+	return c.waitForOperation(updateOp)
+}
+
 // ListRegions returns a list of regions for the GcpCloud instance.
 func (c *GcpCloud) ListRegions() []string {
 	regionListCall := c.gcpClient.Regions.List(c.projectID)
@@ -0,0 +1,324 @@
+// Copyright (C) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gcp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/cloud"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// Provider implements cloud.Provider on top of GcpCloud.
+// Regions are GCP zones and security groups are GCP network names
+type Provider struct {
+	gcpCloud *GcpCloud
+}
+
+var _ cloud.Provider = (*Provider)(nil)
+
+// NewProvider creates a GCP cloud provider
+func NewProvider(gcpCloud *GcpCloud) *Provider {
+	return &Provider{gcpCloud: gcpCloud}
+}
+
+// Name returns the GCP cloud service name
+func (*Provider) Name() string {
+	return constants.GCPCloudService
+}
+
+// CreateInstances creates GCP instances in the given zone
+func (p *Provider) CreateInstances(zone string, count int, spec cloud.InstanceSpec) ([]string, error) {
+	instances, err := p.gcpCloud.SetupInstances(
+		spec.Prefix,
+		zone,
+		spec.SecurityGroup,
+		spec.SSHPublicKey,
+		spec.ImageID,
+		spec.Prefix,
+		spec.InstanceType,
+		nil,
+		count,
+		spec.ForMonitoring,
+	)
+	if err != nil {
+		return nil, err
+	}
+	instanceIDs := make([]string, len(instances))
+	for i, instance := range instances {
+		instanceIDs[i] = instance.Name
+	}
+	return instanceIDs, nil
+}
+
+// WaitForInstances is a no-op as GCP instance creation already waits for the instances to be running
+func (*Provider) WaitForInstances(string, []string) error {
+	return nil
+}
+
+// IsInstanceRunning checks if the GCP instance exists and is running
+func (p *Provider) IsInstanceRunning(zone, instanceID string) (bool, error) {
+	instance, err := p.gcpCloud.gcpClient.Instances.Get(p.gcpCloud.projectID, zone, instanceID).Do()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return instance.Status == "RUNNING", nil
+}
+
+// InstanceExists checks if the GCP instance exists. Stopped GCP instances have the
+// TERMINATED status and still exist
+func (p *Provider) InstanceExists(zone, instanceID string) (bool, error) {
+	if _, err := p.gcpCloud.gcpClient.Instances.Get(p.gcpCloud.projectID, zone, instanceID).Do(); err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetInstancePublicIPs returns the public IPs of the given instances
+func (p *Provider) GetInstancePublicIPs(zone string, instanceIDs []string) (map[string]string, error) {
+	return p.gcpCloud.GetInstancePublicIPs(zone, instanceIDs)
+}
+
+// DestroyNode terminates the GCP instance of the node
+func (p *Provider) DestroyNode(nodeConfig models.NodeConfig, clusterName string) error {
+	return p.gcpCloud.DestroyGCPNode(nodeConfig, clusterName)
+}
+
+// AddFirewallRule creates a firewall rule in the given network allowing [ip] on [ports]
+func (p *Provider) AddFirewallRule(_, networkName, ip string, ports []int) error {
+	strPorts := make([]string, len(ports))
+	for i, port := range ports {
+		strPorts[i] = strconv.Itoa(port)
+	}
+	return p.gcpCloud.AddFirewall(ip, networkName, p.gcpCloud.projectID, firewallName(networkName, ip), strPorts, false)
+}
+
+// RemoveFirewallRule removes [ports] from the firewall created for [ip] by AddFirewallRule,
+// deleting the firewall once it has no ports left. Ports not allowed individually by the
+// firewall are skipped
+func (p *Provider) RemoveFirewallRule(region, networkName, ip string, ports []int) error {
+	name := firewallName(networkName, ip)
+	exists, err := p.gcpCloud.CheckFirewallExists(name, false)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	rules := make([]cloud.FirewallRule, len(ports))
+	for i, port := range ports {
+		rules[i] = cloud.FirewallRule{Name: name, Protocol: "tcp", FromPort: port, ToPort: port, CIDR: ip}
+	}
+	return p.RevokeFirewallRules(region, networkName, rules)
+}
+
+// KeyPairExists always succeeds as GCP receives the ssh public key on instance creation
+func (*Provider) KeyPairExists(string, string) (bool, error) {
+	return true, nil
+}
+
+// CreateKeyPair generates a local ssh key pair to be injected on instance creation
+func (*Provider) CreateKeyPair(_, _, privateKeyPath string) error {
+	return cloud.GenerateSSHKeyPair(privateKeyPath)
+}
+
+// IsInstanceTypeSupported checks if the machine type is available in the zone
+func (p *Provider) IsInstanceTypeSupported(zone, instanceType string) (bool, error) {
+	return p.gcpCloud.IsInstanceTypeSupported(instanceType, zone)
+}
+
+// ResizeRootVolume grows the boot disk of the node
+func (p *Provider) ResizeRootVolume(nodeConfig models.NodeConfig, sizeGB int) error {
+	if sizeGB > math.MaxInt32 {
+		return fmt.Errorf("disk size exceeds maximum supported value")
+	}
+	rootVolume, err := p.gcpCloud.GetRootVolumeID(nodeConfig.NodeID, nodeConfig.Region)
+	if err != nil {
+		return err
+	}
+	return p.gcpCloud.ResizeVolume(rootVolume, nodeConfig.Region, int64(sizeGB))
+}
+
+// ChangeInstanceType changes the machine type of the node
+func (p *Provider) ChangeInstanceType(nodeConfig models.NodeConfig, instanceType string) error {
+	return p.gcpCloud.ChangeInstanceType(nodeConfig.NodeID, nodeConfig.Region, instanceType)
+}
+
+// ListFirewallRules returns the ingress rules of the firewalls of the network, one per
+// source range and allowed port range
+func (p *Provider) ListFirewallRules(_, networkName string) ([]cloud.FirewallRule, error) {
+	firewalls, err := p.gcpCloud.ListFirewalls(networkName)
+	if err != nil {
+		return nil, err
+	}
+	rules := []cloud.FirewallRule{}
+	for _, firewall := range firewalls {
+		for _, allowed := range firewall.Allowed {
+			portRules, err := allowedRules(firewall.Name, allowed)
+			if err != nil {
+				return nil, err
+			}
+			for _, rule := range portRules {
+				for _, sourceRange := range firewall.SourceRanges {
+					rule.CIDR = sourceRange
+					rules = append(rules, rule)
+				}
+			}
+		}
+	}
+	return rules, nil
+}
+
+// RevokeFirewallRules removes the given rules from their firewalls. Source ranges left with
+// no allowed ports are removed from the firewall, and firewalls left with no source ranges
+// are deleted. Revoking part of the ports of a source range is only supported on firewalls
+// with a single source range
+func (p *Provider) RevokeFirewallRules(_, _ string, rules []cloud.FirewallRule) error {
+	revokedByFirewall := map[string][]cloud.FirewallRule{}
+	for _, rule := range rules {
+		revokedByFirewall[rule.Name] = append(revokedByFirewall[rule.Name], rule)
+	}
+	for name, revoked := range revokedByFirewall {
+		firewall, err := p.gcpCloud.GetFirewall(name)
+		if err != nil {
+			return err
+		}
+		allowedKeys := map[string]bool{}
+		for _, allowed := range firewall.Allowed {
+			portRules, err := allowedRules(name, allowed)
+			if err != nil {
+				return err
+			}
+			for _, rule := range portRules {
+				allowedKeys[ruleKey(rule)] = true
+			}
+		}
+		revokedKeys := map[string]map[string]bool{}
+		for _, rule := range revoked {
+			cidr, err := cloud.NormalizeCIDR(rule.CIDR)
+			if err != nil {
+				return err
+			}
+			if revokedKeys[cidr] == nil {
+				revokedKeys[cidr] = map[string]bool{}
+			}
+			revokedKeys[cidr][ruleKey(rule)] = true
+		}
+		sourceRanges := []string{}
+		var partialKeys map[string]bool
+		for _, sourceRange := range firewall.SourceRanges {
+			cidr, err := cloud.NormalizeCIDR(sourceRange)
+			if err != nil {
+				return err
+			}
+			keys := revokedKeys[cidr]
+			if len(keys) > 0 && revokesAll(keys, allowedKeys) {
+				continue
+			}
+			if len(keys) > 0 {
+				partialKeys = keys
+			}
+			sourceRanges = append(sourceRanges, sourceRange)
+		}
+		if len(sourceRanges) == 0 {
+			if err := p.gcpCloud.DeleteFirewall(name); err != nil {
+				return err
+			}
+			continue
+		}
+		if partialKeys != nil {
+			if len(sourceRanges) > 1 {
+				return fmt.Errorf("firewall %s allows other source ranges on the same ports, remove the ports of a single source range in the %s console", name, constants.GCPCloudService)
+			}
+			allowedList := []*compute.FirewallAllowed{}
+			for _, allowed := range firewall.Allowed {
+				portRules, err := allowedRules(name, allowed)
+				if err != nil {
+					return err
+				}
+				if len(allowed.Ports) == 0 {
+					if !partialKeys[ruleKey(portRules[0])] {
+						allowedList = append(allowedList, allowed)
+					}
+					continue
+				}
+				ports := []string{}
+				for i, rule := range portRules {
+					if !partialKeys[ruleKey(rule)] {
+						ports = append(ports, allowed.Ports[i])
+					}
+				}
+				if len(ports) > 0 {
+					allowedList = append(allowedList, &compute.FirewallAllowed{IPProtocol: allowed.IPProtocol, Ports: ports})
+				}
+			}
+			firewall.Allowed = allowedList
+		}
+		firewall.SourceRanges = sourceRanges
+		if err := p.gcpCloud.UpdateFirewall(firewall); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowedRules returns the rules of firewall [name] for [allowed], one per port range, without
+// source range. Allowed entries without ports allow all of them
+func allowedRules(name string, allowed *compute.FirewallAllowed) ([]cloud.FirewallRule, error) {
+	if len(allowed.Ports) == 0 {
+		return []cloud.FirewallRule{{Name: name, Protocol: allowed.IPProtocol, FromPort: 0, ToPort: 65535}}, nil
+	}
+	rules := []cloud.FirewallRule{}
+	for _, port := range allowed.Ports {
+		rule := cloud.FirewallRule{Name: name, Protocol: allowed.IPProtocol}
+		from, to, isRange := strings.Cut(port, "-")
+		var err error
+		if rule.FromPort, err = strconv.Atoi(from); err != nil {
+			return nil, fmt.Errorf("invalid port %q in firewall %s", port, name)
+		}
+		rule.ToPort = rule.FromPort
+		if isRange {
+			if rule.ToPort, err = strconv.Atoi(to); err != nil {
+				return nil, fmt.Errorf("invalid port %q in firewall %s", port, name)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// revokesAll tells if [revokedKeys] include all of [allowedKeys]
+func revokesAll(revokedKeys map[string]bool, allowedKeys map[string]bool) bool {
+	for key := range allowedKeys {
+		if !revokedKeys[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleKey identifies the protocol and ports of [rule] within its firewall
+func ruleKey(rule cloud.FirewallRule) string {
+	return rule.Protocol + "/" + rule.Ports()
+}
+
+func firewallName(networkName, ip string) string {
+	return fmt.Sprintf("%s-%s", networkName, strings.ReplaceAll(ip, ".", ""))
+}
@@ -0,0 +1,38 @@
+// Copyright (C) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cloud
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// GenerateSSHKeyPair creates an ed25519 ssh key pair, saving the private key at
+// [privateKeyPath] and the authorized_keys formatted public key at [privateKeyPath].pub
+func GenerateSSHKeyPair(privateKeyPath string) error {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	privPEM, err := ssh.MarshalPrivateKey(privKey, "")
+	if err != nil {
+		return err
+	}
+	sshPubKey, err := ssh.NewPublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(privateKeyPath), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(privateKeyPath, pem.EncodeToMemory(privPEM), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(privateKeyPath+".pub", ssh.MarshalAuthorizedKey(sshPubKey), 0o600)
+}
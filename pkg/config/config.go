@@ -5,9 +5,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/ixAnkit/cryft/pkg/utils"
 	"github.com/MetalBlockchain/metalgo/utils/logging"
 
@@ -72,6 +75,46 @@ func (*Config) GetConfigStringValue(key string) string {
 	return viper.GetString(key)
 }
 
+func (*Config) GetConfigIntValue(key string) int {
+	return viper.GetInt(key)
+}
+
+// GetSSHSettings returns the SSH settings saved in the config, with the defaults for
+// the ones not set
+func (c *Config) GetSSHSettings() (models.SSHSettings, error) {
+	settings := models.DefaultSSHSettings()
+	if c.ConfigValueIsSet(constants.ConfigSSHMaxParallelKey) {
+		settings.MaxParallel = c.GetConfigIntValue(constants.ConfigSSHMaxParallelKey)
+	}
+	if c.ConfigValueIsSet(constants.ConfigSSHRetriesKey) {
+		settings.Retries = c.GetConfigIntValue(constants.ConfigSSHRetriesKey)
+	}
+	if c.ConfigValueIsSet(constants.ConfigSSHKeepAliveKey) {
+		keepAlive, err := time.ParseDuration(c.GetConfigStringValue(constants.ConfigSSHKeepAliveKey))
+		if err != nil {
+			return settings, fmt.Errorf("invalid %s config value: %w", constants.ConfigSSHKeepAliveKey, err)
+		}
+		settings.KeepAlive = keepAlive
+	}
+	if c.ConfigValueIsSet(constants.ConfigSSHConnectionReuseKey) {
+		settings.Reuse = c.GetConfigBoolValue(constants.ConfigSSHConnectionReuseKey)
+	}
+	return settings, nil
+}
+
+// GetValidationExpiryWarning returns how long before the end of a validation node commands
+// warn about it
+func (c *Config) GetValidationExpiryWarning() (time.Duration, error) {
+	if !c.ConfigValueIsSet(constants.ConfigValidationExpiryWarnKey) {
+		return constants.DefaultValidationExpiryWarning, nil
+	}
+	threshold, err := time.ParseDuration(c.GetConfigStringValue(constants.ConfigValidationExpiryWarnKey))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s config value: %w", constants.ConfigValidationExpiryWarnKey, err)
+	}
+	return threshold, nil
+}
+
 func (*Config) LoadNodeConfig() (string, error) {
 	globalConfigs := viper.GetStringMap(constants.ConfigNodeConfigKey)
 	if len(globalConfigs) == 0 {
@@ -37,6 +37,9 @@ const (
 	GetAWSNodeIP                 = "get-aws-node-ip"
 	ClustersConfigFileName       = "cluster_config.json"
 	ClustersConfigVersion        = "1"
+	PriceTableFileName           = "prices.json"
+	AlertRulesFileName           = "alert_rules.yml"
+	AlertReceiversFileName       = "alert_receivers.json"
 	StakerCertFileName           = "staker.crt"
 	StakerKeyFileName            = "staker.key"
 	BLSKeyFileName               = "signer.key"
@@ -66,6 +69,7 @@ const (
 	AWSVolumeTypeIO2            = "io2"
 	AWSGP3DefaultIOPS           = 3000
 	AWSGP3DefaultThroughput     = 125
+	GCPVolumeTypeStandard       = "pd-standard"
 	SimulatePublicNetwork       = "SIMULATE_PUBLIC_NETWORK"
 
 	TahoeAPIEndpoint   = "https://tahoe.metalblockchain.org"
@@ -121,6 +125,19 @@ const (
 	ExtraLocalNetworkDataFilename     = "extra-local-network-data.json"
 	ExtraLocalNetworkDataSnapshotsDir = "extra-local-network-data"
 
+	// local network instances other than the default one live under this dir,
+	// each one with its own run dir, snapshots dir and plugins dir
+	LocalNetworkInstancesDir      = "local-networks"
+	LocalNetworkInstancesFileName = "instances.json"
+	DefaultLocalNetworkInstance   = "default"
+	// every instance is given a port range starting at its index times this stride.
+	// gRPC ports (8097, 8098) and node ports (9650-9659) never overlap modulo 100
+	LocalNetworkInstancePortStride = 100
+	MaxLocalNetworkInstances       = 50
+	LocalNetworkGRPCServerPort     = 8097
+	LocalNetworkGRPCGatewayPort    = 8098
+	LocalNetworkNumNodePorts       = 10
+
 	CliInstallationURL         = "https://raw.githubusercontent.com/MetalBlockchain/metal-cli/main/scripts/install.sh"
 	ExpectedCliInstallErr      = "resource temporarily unavailable"
 	EIPLimitErr                = "AddressLimitExceeded"
@@ -130,6 +147,7 @@ const (
 	KeyDir                     = "key"
 	KeySuffix                  = ".pk"
 	YAMLSuffix                 = ".yml"
+	JSONSuffix                 = ".json"
 	CustomGrafanaDashboardJSON = "custom.json"
 	Enable                     = "enable"
 
@@ -148,6 +166,7 @@ const (
 	StakingMinimumLeadTime                       = 25 * time.Second
 	PrimaryNetworkValidatingStartLeadTimeNodeCmd = 20 * time.Second
 	PrimaryNetworkValidatingStartLeadTime        = 1 * time.Minute
+	DefaultValidationExpiryWarning               = 7 * 24 * time.Hour
 	AWSCloudServerRunningState                   = "running"
 	AvalancheCLISuffix                           = "-metal-cli"
 	AWSDefaultCredential                         = "default"
@@ -190,17 +209,28 @@ const (
 	ConfigMetricsEnabledKey       = "MetricsEnabled"
 	ConfigAuthorizeCloudAccessKey = "AuthorizeCloudAccess"
 	ConfigSingleNodeEnabledKey    = "SingleNodeEnabled"
+	ConfigSSHMaxParallelKey       = "SSHMaxParallel"
+	ConfigSSHRetriesKey           = "SSHRetries"
+	ConfigSSHKeepAliveKey         = "SSHKeepAlive"
+	ConfigSSHConnectionReuseKey   = "SSHConnectionReuse"
+	ConfigValidationExpiryWarnKey = "ValidationExpiryWarning"
 	OldConfigFileName             = ".metal-cli.json"
 	OldMetricsConfigFileName      = ".metal-cli/config"
 	DefaultConfigFileName         = ".metal-cli/config.json"
 	DefaultNodeType               = "default"
 	AWSCloudService               = "Amazon Web Services"
 	GCPCloudService               = "Google Cloud Platform"
+	DockerCloudService            = "docker"
+	ExistingHostsCloudService     = "Existing Hosts"
+	ExistingHostsDefaultRegion    = "default"
+	DockerComposeFileName         = "docker-compose.yml"
+	DockerUbuntuVersion           = "focal"
 	AWSDefaultInstanceType        = "c5.2xlarge"
 	GCPDefaultInstanceType        = "e2-standard-8"
 	AnsibleSSHUser                = "ubuntu"
 	AWSNodeAnsiblePrefix          = "aws_node"
 	GCPNodeAnsiblePrefix          = "gcp_node"
+	ExistingHostAnsiblePrefix     = "existing_host"
 	CustomVMDir                   = "vms"
 	ClusterYAMLFileName           = "clusterInfo.yaml"
 	GCPStaticIPPrefix             = "static-ip"
@@ -208,6 +238,7 @@ const (
 	AvalancheGoRepoName           = "metalgo"
 	SubnetEVMRepoName             = "subnet-evm"
 	CliRepoName                   = "metal-cli"
+	CliRepoURL                    = "https://github.com/ixAnkit/cryft"
 	TeleporterRepoName            = "teleporter"
 	AWMRelayerRepoName            = "awm-relayer"
 	SubnetEVMReleaseURL           = "https://github.com/MetalBlockchain/subnet-evm/releases/download/%s/%s"
@@ -215,13 +246,19 @@ const (
 	CloudNodeConfigBasePath       = "/home/ubuntu/.metalgo/"
 	CloudNodeSubnetEvmBinaryPath  = "/home/ubuntu/.metalgo/plugins/%s"
 	CloudNodeStakingPath          = "/home/ubuntu/.metalgo/staking/"
+	CloudNodeDBDir                = "db"
+	CloudNodeStakingDir           = "staking"
 	CloudNodeConfigPath           = "/home/ubuntu/.metalgo/configs/"
 	CloudNodePrometheusConfigPath = "/etc/prometheus/prometheus.yml"
 	CloudNodeCLIConfigBasePath    = "/home/ubuntu/.metal-cli/"
+	CloudNodeProvisionPath        = "/home/ubuntu/.metal-cli/provision/"
 	AvalanchegoMonitoringPort     = 9090
 	AvalanchegoMachineMetricsPort = 9100
+	ValidatorMetricsPort          = 9101
+	CloudNodeValidatorMetricsPath = "/var/lib/metal-cli/validator-metrics/"
 	MonitoringDir                 = "monitoring"
 	LoadTestDir                   = "loadtest"
+	LoadTestRunsDir               = "loadtest-runs"
 	DashboardsDir                 = "dashboards"
 	NodeConfigJSONFile            = "node.json"
 	IPAddressSuffix               = "/32"
@@ -234,13 +271,19 @@ const (
 	AWMRelayerStorageDir          = "awm-relayer-storage"
 	AWMRelayerLogFilename         = "awm-relayer.log"
 	AWMRelayerRunFilename         = "awm-relayer-process.json"
+	AWMRelayerServiceFilename     = "awm-relayer.service"
 
 	AWMRelayerSnapshotConfsDir = "relayer-confs"
 
 	TeleporterKeyName = "cli-teleporter-deployer"
 	AWMRelayerKeyName = "cli-awm-relayer"
 
+	// DefaultTeleporterVersion is the teleporter release new teleporter ready subnets are set to
+	DefaultTeleporterVersion = "v1.0.0"
+
 	AWMRelayerMetricsPort = 9091
+	// AWMRelayerStopTimeout is how long a stopped local relayer is waited for to exit
+	AWMRelayerStopTimeout = 10 * time.Second
 
 	SubnetEVMBin = "subnet-evm"
 
@@ -253,10 +296,14 @@ const (
 
 	// #nosec G101
 	GithubAPITokenEnvVarName = "METAL_CLI_GITHUB_TOKEN"
+	// #nosec G101
+	SnapshotPassphraseEnvVarName = "METAL_CLI_SNAPSHOT_PASSPHRASE"
 
 	ReposDir                   = "repos"
 	SubnetDir                  = "subnets"
 	NodesDir                   = "nodes"
+	NodeBackupsDir             = "backups"
+	ArchivedNodesDir           = "archived-nodes"
 	VMDir                      = "vms"
 	ChainConfigDir             = "chains"
 	AVMKeyName                 = "avm"
@@ -296,6 +343,7 @@ const (
 	Network                      = "network"
 	MultiSig                     = "multi-sig"
 	SkipUpdateFlag               = "skip-update-check"
+	LocalNetworkInstanceFlag     = "instance"
 	LastFileName                 = ".last_actions.json"
 	APIRole                      = "API"
 	ValidatorRole                = "Validator"
@@ -313,7 +361,7 @@ const (
 	// E2E
 	E2ENetworkPrefix        = "172.18.0"
 	E2EClusterName          = "e2e"
-	E2EDocker               = "docker"
+	E2EDocker               = DockerCloudService
 	E2EDockerComposeFile    = "/tmp/avalanche-cli-docker-compose.yml"
 	E2EDebugAvalanchegoPath = "E2E_AVALANCHEGO_PATH"
 	GitExtension            = ".git"
@@ -0,0 +1,239 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package cost
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+)
+
+// HoursPerMonth is the number of hours cloud providers bill for a month
+const HoursPerMonth = 730
+
+//go:embed prices.json
+var defaultPriceTable []byte
+
+// cloudKeys maps cloud service names to their key in the price table
+var cloudKeys = map[string]string{
+	constants.AWSCloudService: "aws",
+	constants.GCPCloudService: "gcp",
+}
+
+// VolumePrice is the monthly price of a volume type. IOPS and throughput over the
+// free amounts are charged per IOPS-month and per MiB/s-month
+type VolumePrice struct {
+	GBMonth         float64 `json:"gbMonth"`
+	IOPSMonth       float64 `json:"iopsMonth,omitempty"`
+	FreeIOPS        int     `json:"freeIops,omitempty"`
+	ThroughputMonth float64 `json:"throughputMonth,omitempty"`
+	FreeThroughput  int     `json:"freeThroughput,omitempty"`
+}
+
+// CloudPrices holds the on-demand prices of a cloud in its base region, together with
+// the price multiplier of each region relative to it
+type CloudPrices struct {
+	BaseRegion        string                 `json:"baseRegion"`
+	Instances         map[string]float64     `json:"instances"`
+	Regions           map[string]float64     `json:"regions"`
+	Volumes           map[string]VolumePrice `json:"volumes"`
+	DefaultVolumeType string                 `json:"defaultVolumeType"`
+	StaticIPHour      float64                `json:"staticIPHour"`
+	// SpotFactor is the average spot price as a fraction of the on-demand price
+	SpotFactor float64 `json:"spotFactor"`
+}
+
+type PriceTable struct {
+	Updated  string                 `json:"updated"`
+	Currency string                 `json:"currency"`
+	Clouds   map[string]CloudPrices `json:"clouds"`
+}
+
+// LoadPriceTable loads the price table at [path] if it exists, falling back to the
+// one shipped with the CLI
+func LoadPriceTable(path string) (*PriceTable, error) {
+	tableBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ParsePriceTable(defaultPriceTable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	table, err := ParsePriceTable(tableBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price table %s: %w", path, err)
+	}
+	return table, nil
+}
+
+func ParsePriceTable(tableBytes []byte) (*PriceTable, error) {
+	table := &PriceTable{}
+	if err := json.Unmarshal(tableBytes, table); err != nil {
+		return nil, err
+	}
+	if len(table.Clouds) == 0 {
+		return nil, fmt.Errorf("no cloud prices found")
+	}
+	return table, nil
+}
+
+// Resource describes [Count] identical cloud instances to be priced
+type Resource struct {
+	// Cloud is the cloud service name, as stored in node configs
+	Cloud        string
+	Region       string
+	Role         string
+	Count        int
+	InstanceType string
+	Spot         bool
+	// VolumeType defaults to the cloud default volume type
+	VolumeType string
+	VolumeSize int
+	IOPS       int
+	Throughput int
+	StaticIP   bool
+}
+
+// Item is the hourly price of each instance of a resource
+type Item struct {
+	Resource
+	InstanceHourly float64
+	VolumeHourly   float64
+	StaticIPHourly float64
+}
+
+func (i Item) Hourly() float64 {
+	return float64(i.Count) * (i.InstanceHourly + i.VolumeHourly + i.StaticIPHourly)
+}
+
+func (i Item) Monthly() float64 {
+	return i.Hourly() * HoursPerMonth
+}
+
+type Estimate struct {
+	Currency string
+	Items    []Item
+	// Warnings lists the resources that could not be fully priced
+	Warnings []string
+}
+
+func (e *Estimate) Hourly() float64 {
+	total := 0.0
+	for _, item := range e.Items {
+		total += item.Hourly()
+	}
+	return total
+}
+
+func (e *Estimate) Monthly() float64 {
+	return e.Hourly() * HoursPerMonth
+}
+
+// Regions returns the sorted regions of the estimate items
+func (e *Estimate) Regions() []string {
+	regions := []string{}
+	for _, item := range e.Items {
+		if !contains(regions, item.Region) {
+			regions = append(regions, item.Region)
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// RegionHourly returns the hourly cost of the items in [region]
+func (e *Estimate) RegionHourly(region string) float64 {
+	total := 0.0
+	for _, item := range e.Items {
+		if item.Region == region {
+			total += item.Hourly()
+		}
+	}
+	return total
+}
+
+// Estimate prices the given resources. Identical resources are merged into a single item
+func (t *PriceTable) Estimate(resources []Resource) *Estimate {
+	estimate := &Estimate{Currency: t.Currency}
+	for _, resource := range resources {
+		merged := false
+		for i := range estimate.Items {
+			item := &estimate.Items[i]
+			key := item.Resource
+			key.Count = resource.Count
+			if key == resource {
+				item.Count += resource.Count
+				merged = true
+				break
+			}
+		}
+		if merged {
+			continue
+		}
+		item, warnings := t.price(resource)
+		estimate.Items = append(estimate.Items, item)
+		for _, warning := range warnings {
+			if !contains(estimate.Warnings, warning) {
+				estimate.Warnings = append(estimate.Warnings, warning)
+			}
+		}
+	}
+	return estimate
+}
+
+func (t *PriceTable) price(resource Resource) (Item, []string) {
+	item := Item{Resource: resource}
+	warnings := []string{}
+	prices, ok := t.Clouds[cloudKeys[resource.Cloud]]
+	if !ok {
+		return item, append(warnings, fmt.Sprintf("no prices for %s instances", resource.Cloud))
+	}
+	regionFactor, ok := prices.Regions[resource.Region]
+	if !ok {
+		// GCP nodes are located by zone
+		regionFactor, ok = prices.Regions[resource.Region[:max(strings.LastIndex(resource.Region, "-"), 0)]]
+	}
+	if !ok {
+		regionFactor = 1
+		warnings = append(warnings, fmt.Sprintf("no prices for region %s, using %s prices", resource.Region, prices.BaseRegion))
+	}
+	if instanceHourly, ok := prices.Instances[resource.InstanceType]; ok {
+		item.InstanceHourly = instanceHourly * regionFactor
+		if resource.Spot && prices.SpotFactor > 0 {
+			item.InstanceHourly *= prices.SpotFactor
+		}
+	} else {
+		warnings = append(warnings, fmt.Sprintf("no price for instance type %s, it is not included", resource.InstanceType))
+	}
+	volumeType := resource.VolumeType
+	if volumeType == "" {
+		volumeType = prices.DefaultVolumeType
+	}
+	if volumePrice, ok := prices.Volumes[volumeType]; ok {
+		volumeMonthly := float64(resource.VolumeSize) * volumePrice.GBMonth
+		volumeMonthly += float64(max(resource.IOPS-volumePrice.FreeIOPS, 0)) * volumePrice.IOPSMonth
+		volumeMonthly += float64(max(resource.Throughput-volumePrice.FreeThroughput, 0)) * volumePrice.ThroughputMonth
+		item.VolumeHourly = volumeMonthly * regionFactor / HoursPerMonth
+	} else {
+		warnings = append(warnings, fmt.Sprintf("no price for volume type %s, it is not included", volumeType))
+	}
+	if resource.StaticIP {
+		item.StaticIPHourly = prices.StaticIPHour
+	}
+	return item, warnings
+}
+
+func contains(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/stretchr/testify/require"
+)
+
+const testPriceTable = `{
+  "updated": "2024-01-01",
+  "currency": "USD",
+  "clouds": {
+    "aws": {
+      "baseRegion": "us-east-1",
+      "instances": {"c5.2xlarge": 0.34},
+      "regions": {"us-east-1": 1.0, "eu-west-1": 1.5},
+      "volumes": {"gp3": {"gbMonth": 0.08, "iopsMonth": 0.005, "freeIops": 3000, "throughputMonth": 0.04, "freeThroughput": 125}},
+      "defaultVolumeType": "gp3",
+      "staticIPHour": 0.005,
+      "spotFactor": 0.5
+    },
+    "gcp": {
+      "baseRegion": "us-east1",
+      "instances": {"e2-standard-8": 0.27},
+      "regions": {"us-east1": 1.0},
+      "volumes": {"pd-standard": {"gbMonth": 0.04}},
+      "defaultVolumeType": "pd-standard",
+      "staticIPHour": 0.005,
+      "spotFactor": 0.3
+    }
+  }
+}`
+
+func TestLoadPriceTable(t *testing.T) {
+	require := require.New(t)
+	table, err := LoadPriceTable(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(err)
+	require.Contains(table.Clouds, "aws")
+	require.Contains(table.Clouds, "gcp")
+
+	path := filepath.Join(t.TempDir(), "prices.json")
+	require.NoError(os.WriteFile(path, []byte(testPriceTable), 0o600))
+	table, err = LoadPriceTable(path)
+	require.NoError(err)
+	require.Equal("2024-01-01", table.Updated)
+
+	require.NoError(os.WriteFile(path, []byte("{}"), 0o600))
+	_, err = LoadPriceTable(path)
+	require.Error(err)
+}
+
+func TestEstimate(t *testing.T) {
+	require := require.New(t)
+	table, err := ParsePriceTable([]byte(testPriceTable))
+	require.NoError(err)
+	node := Resource{
+		Cloud:        constants.AWSCloudService,
+		Region:       "us-east-1",
+		Role:         constants.ValidatorRole,
+		Count:        1,
+		InstanceType: "c5.2xlarge",
+		VolumeType:   "gp3",
+		VolumeSize:   1000,
+		IOPS:         4000,
+		Throughput:   125,
+		StaticIP:     true,
+	}
+	euNode := node
+	euNode.Region = "eu-west-1"
+	estimate := table.Estimate([]Resource{node, node, euNode})
+	require.Len(estimate.Items, 2)
+	require.Empty(estimate.Warnings)
+	require.Equal(2, estimate.Items[0].Count)
+	require.InDelta(0.34, estimate.Items[0].InstanceHourly, 1e-9)
+	// 1000GB plus 1000 iops over the free tier
+	require.InDelta((80.0+5.0)/HoursPerMonth, estimate.Items[0].VolumeHourly, 1e-9)
+	require.InDelta(0.005, estimate.Items[0].StaticIPHourly, 1e-9)
+	require.InDelta(0.51, estimate.Items[1].InstanceHourly, 1e-9)
+	require.Equal([]string{"eu-west-1", "us-east-1"}, estimate.Regions())
+	require.InDelta(estimate.RegionHourly("eu-west-1")+estimate.RegionHourly("us-east-1"), estimate.Hourly(), 1e-9)
+	require.InDelta(estimate.Hourly()*HoursPerMonth, estimate.Monthly(), 1e-9)
+
+	spotNode := node
+	spotNode.Spot = true
+	estimate = table.Estimate([]Resource{spotNode})
+	require.InDelta(0.17, estimate.Items[0].InstanceHourly, 1e-9)
+}
+
+func TestEstimateGCPZone(t *testing.T) {
+	require := require.New(t)
+	table, err := ParsePriceTable([]byte(testPriceTable))
+	require.NoError(err)
+	estimate := table.Estimate([]Resource{{
+		Cloud:        constants.GCPCloudService,
+		Region:       "us-east1-b",
+		Count:        1,
+		InstanceType: "e2-standard-8",
+		VolumeSize:   1000,
+	}})
+	require.Empty(estimate.Warnings)
+	require.InDelta(0.27, estimate.Items[0].InstanceHourly, 1e-9)
+	require.InDelta(40.0/HoursPerMonth, estimate.Items[0].VolumeHourly, 1e-9)
+}
+
+func TestEstimateWarnings(t *testing.T) {
+	require := require.New(t)
+	table, err := ParsePriceTable([]byte(testPriceTable))
+	require.NoError(err)
+	estimate := table.Estimate([]Resource{{
+		Cloud:        constants.AWSCloudService,
+		Region:       "mars-north-1",
+		Count:        1,
+		InstanceType: "x99.huge",
+		VolumeSize:   100,
+	}})
+	require.Len(estimate.Warnings, 2)
+	require.Zero(estimate.Items[0].InstanceHourly)
+	require.InDelta(8.0/HoursPerMonth, estimate.Items[0].VolumeHourly, 1e-9)
+}
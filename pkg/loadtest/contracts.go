@@ -0,0 +1,151 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package loadtest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/MetalBlockchain/subnet-evm/core/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	erc20TransferSelector  = 0xa9059cbb // transfer(address,uint256)
+	erc20BalanceOfSelector = 0x70a08231 // balanceOf(address)
+	// initCodeSuffixLen is the length of the code appended to a constructor to
+	// return the runtime code
+	initCodeSuffixLen = 13
+)
+
+var (
+	erc20TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	// tokenSupply is minted to the deployer of the load test token
+	tokenSupply = new(big.Int).Lsh(big.NewInt(1), 128)
+	// counterIncrementCallData calls increment() on the load test counter, which
+	// increments its counter on any call
+	counterIncrementCallData = crypto.Keccak256([]byte("increment()"))[:4]
+)
+
+// assembler builds EVM bytecode, resolving the jump labels
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	jumps  map[int]string
+}
+
+func newAssembler() *assembler {
+	return &assembler{
+		labels: map[string]int{},
+		jumps:  map[int]string{},
+	}
+}
+
+func (a *assembler) op(ops ...vm.OpCode) *assembler {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+	return a
+}
+
+// push pushes [value] with the smallest PUSHn that fits it
+func (a *assembler) push(value []byte) *assembler {
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(value)-1))
+	a.code = append(a.code, value...)
+	return a
+}
+
+func (a *assembler) pushUint(value uint64) *assembler {
+	return a.push(new(big.Int).SetUint64(value).Bytes())
+}
+
+// pushLabel pushes the offset of [label] as a 2 bytes value
+func (a *assembler) pushLabel(label string) *assembler {
+	a.code = append(a.code, byte(vm.PUSH2))
+	a.jumps[len(a.code)] = label
+	a.code = append(a.code, 0, 0)
+	return a
+}
+
+func (a *assembler) label(label string) *assembler {
+	a.labels[label] = len(a.code)
+	return a.op(vm.JUMPDEST)
+}
+
+func (a *assembler) bytes() ([]byte, error) {
+	for pos, label := range a.jumps {
+		offset, ok := a.labels[label]
+		if !ok {
+			return nil, fmt.Errorf("undefined label %s", label)
+		}
+		binary.BigEndian.PutUint16(a.code[pos:], uint16(offset))
+	}
+	return a.code, nil
+}
+
+// deployCode returns the contract creation code that runs [constructor] and returns
+// [runtime] as the contract code
+func deployCode(constructor []byte, runtime []byte) []byte {
+	code := append([]byte{}, constructor...)
+	code = append(code, byte(vm.PUSH2))
+	code = binary.BigEndian.AppendUint16(code, uint16(len(runtime)))
+	code = append(code, byte(vm.DUP1), byte(vm.PUSH2))
+	code = binary.BigEndian.AppendUint16(code, uint16(len(constructor)+initCodeSuffixLen))
+	code = append(code, byte(vm.PUSH1), 0, byte(vm.CODECOPY), byte(vm.PUSH1), 0, byte(vm.RETURN))
+	return append(code, runtime...)
+}
+
+// tokenDeployCode returns the creation code of a minimal ERC-20 token, that mints
+// [tokenSupply] to its deployer and implements transfer and balanceOf. Balances are
+// stored at the slot given by the holder address
+func tokenDeployCode() ([]byte, error) {
+	constructor := newAssembler().push(tokenSupply.Bytes()).op(vm.CALLER, vm.SSTORE).code
+	runtime := newAssembler().
+		// selector
+		pushUint(0).op(vm.CALLDATALOAD).pushUint(0xe0).op(vm.SHR).
+		op(vm.DUP1).pushUint(erc20TransferSelector).op(vm.EQ).pushLabel("transfer").op(vm.JUMPI).
+		pushUint(erc20BalanceOfSelector).op(vm.EQ).pushLabel("balanceOf").op(vm.JUMPI).
+		pushUint(0).op(vm.DUP1, vm.REVERT).
+		// balanceOf(owner)
+		label("balanceOf").
+		pushUint(4).op(vm.CALLDATALOAD, vm.SLOAD).pushUint(0).op(vm.MSTORE).
+		pushUint(32).pushUint(0).op(vm.RETURN).
+		// transfer(to, amount)
+		label("transfer").op(vm.POP).
+		pushUint(36).op(vm.CALLDATALOAD).
+		op(vm.CALLER, vm.SLOAD).
+		op(vm.DUP2, vm.DUP2, vm.LT).pushLabel("fail").op(vm.JUMPI).
+		op(vm.DUP2, vm.DUP2, vm.SUB, vm.SWAP1, vm.POP, vm.CALLER, vm.SSTORE).
+		pushUint(4).op(vm.CALLDATALOAD, vm.DUP1, vm.SLOAD, vm.DUP3, vm.ADD, vm.SWAP1, vm.SSTORE).
+		pushUint(0).op(vm.MSTORE).
+		pushUint(4).op(vm.CALLDATALOAD, vm.CALLER).push(erc20TransferTopic.Bytes()).
+		pushUint(32).pushUint(0).op(vm.LOG3).
+		pushUint(1).pushUint(0).op(vm.MSTORE).
+		pushUint(32).pushUint(0).op(vm.RETURN).
+		label("fail").
+		pushUint(0).op(vm.DUP1, vm.REVERT)
+	runtimeCode, err := runtime.bytes()
+	if err != nil {
+		return nil, err
+	}
+	return deployCode(constructor, runtimeCode), nil
+}
+
+// counterDeployCode returns the creation code of a contract that increments a
+// storage counter on each call
+func counterDeployCode() []byte {
+	runtime := newAssembler().
+		pushUint(0).op(vm.SLOAD).pushUint(1).op(vm.ADD).pushUint(0).op(vm.SSTORE, vm.STOP)
+	return deployCode(nil, runtime.code)
+}
+
+func erc20TransferCallData(to common.Address, amount *big.Int) []byte {
+	data := binary.BigEndian.AppendUint32(nil, erc20TransferSelector)
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	return append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+}
@@ -0,0 +1,568 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package loadtest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/evm"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/subnet-evm/core/types"
+	"github.com/MetalBlockchain/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/exp/slices"
+)
+
+type TxType string
+
+const (
+	NativeTransfer TxType = "native"
+	ERC20Transfer  TxType = "erc20"
+	ContractCall   TxType = "contract"
+
+	DefaultWorkers        = 10
+	DefaultTPS            = 100
+	DefaultDuration       = time.Minute
+	DefaultConfirmTimeout = 30 * time.Second
+
+	erc20TransferGas  uint64 = 100_000
+	contractCallGas   uint64 = 60_000
+	contractDeployGas uint64 = 500_000
+	// workers are funded for this many times the initial gas fee cap, so that they can
+	// keep paying for their transactions while the base fee rises under load
+	fundingFeeFactor = 4
+	issuePeriod      = 10 * time.Millisecond
+	blockPollPeriod  = 100 * time.Millisecond
+	feeRefreshPeriod = 5 * time.Second
+	progressPeriod   = 5 * time.Second
+)
+
+var TxTypes = []TxType{NativeTransfer, ERC20Transfer, ContractCall}
+
+// Config sets how a load test is run
+type Config struct {
+	// Endpoints are the RPC URLs of the chain. Workers are spread over them
+	Endpoints []string
+	// FundingKey is the hex encoded private key that funds the workers
+	FundingKey string
+	TxType     TxType
+	Workers    int
+	TPS        int
+	Duration   time.Duration
+	// ConfirmTimeout is how long to wait for the issued transactions to be accepted
+	// once the issuing ends
+	ConfirmTimeout time.Duration
+}
+
+// Latency holds the confirmation latency percentiles, in milliseconds
+type Latency struct {
+	P50 float64 `json:"p50Ms"`
+	P90 float64 `json:"p90Ms"`
+	P99 float64 `json:"p99Ms"`
+	Max float64 `json:"maxMs"`
+}
+
+// Result holds the measurements of a load test
+type Result struct {
+	TxType    TxType    `json:"txType"`
+	Endpoints []string  `json:"endpoints"`
+	Workers   int       `json:"workers"`
+	TargetTPS int       `json:"targetTPS"`
+	Start     time.Time `json:"start"`
+	// Duration is the time from the first issued transaction to the last accepted one,
+	// in seconds
+	Duration float64 `json:"durationSeconds"`
+	Sent     int     `json:"sent"`
+	Accepted int     `json:"accepted"`
+	// Failed counts the transactions rejected by the endpoints
+	Failed int `json:"failed"`
+	// Unconfirmed counts the sent transactions not accepted before the confirm timeout
+	Unconfirmed int     `json:"unconfirmed"`
+	AcceptedTPS float64 `json:"acceptedTPS"`
+	Latency     Latency `json:"latency"`
+}
+
+// Validate checks the load generator settings, defaulting the confirm timeout
+func (c *Config) Validate() error {
+	if !slices.Contains(TxTypes, c.TxType) {
+		return fmt.Errorf("invalid tx type %q. valid types are %v", c.TxType, TxTypes)
+	}
+	if c.Workers <= 0 {
+		return fmt.Errorf("number of workers must be positive")
+	}
+	if c.TPS <= 0 {
+		return fmt.Errorf("target TPS must be positive")
+	}
+	if c.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if c.ConfirmTimeout <= 0 {
+		c.ConfirmTimeout = DefaultConfirmTimeout
+	}
+	return nil
+}
+
+func (c *Config) gasLimit() uint64 {
+	switch c.TxType {
+	case ERC20Transfer:
+		return erc20TransferGas
+	case ContractCall:
+		return contractCallGas
+	default:
+		return evm.NativeTransferGas
+	}
+}
+
+// txsPerWorker is the number of transactions each worker is funded for
+func (c *Config) txsPerWorker() uint64 {
+	return uint64(math.Ceil(float64(c.TPS)*c.Duration.Seconds()/float64(c.Workers))) + 1
+}
+
+// txsDue returns the number of transactions to be issued [elapsed] time after the
+// start of a load test at [tps] transactions per second
+func txsDue(tps int, elapsed time.Duration) int {
+	return int(float64(tps) * elapsed.Seconds())
+}
+
+// percentile returns the [p] percentile of the sorted [values], using the nearest
+// rank method
+func percentile(values []time.Duration, p float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	rank = min(max(rank, 1), len(values))
+	return values[rank-1]
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func latencyPercentiles(latencies []time.Duration) Latency {
+	sorted := slices.Clone(latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return Latency{
+		P50: toMilliseconds(percentile(sorted, 50)),
+		P90: toMilliseconds(percentile(sorted, 90)),
+		P99: toMilliseconds(percentile(sorted, 99)),
+		Max: toMilliseconds(percentile(sorted, 100)),
+	}
+}
+
+// WriteResult saves [result] as JSON at [path]
+func WriteResult(path string, result *Result) error {
+	resultBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, resultBytes, constants.WriteReadReadPerms)
+}
+
+// fees holds the fee caps used by the workers, refreshed while the load test runs
+type fees struct {
+	lock      sync.RWMutex
+	gasFeeCap *big.Int
+	gasTipCap *big.Int
+}
+
+func (f *fees) get() (*big.Int, *big.Int) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.gasFeeCap, f.gasTipCap
+}
+
+func (f *fees) refresh(client ethclient.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.APIRequestTimeout)
+	defer cancel()
+	baseFee, err := client.EstimateBaseFee(ctx)
+	if err != nil {
+		return
+	}
+	gasTipCap, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return
+	}
+	gasFeeCap := baseFee.Mul(baseFee, big.NewInt(evm.BaseFeeFactor))
+	gasFeeCap.Add(gasFeeCap, big.NewInt(evm.MaxPriorityFeePerGas))
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.gasFeeCap = gasFeeCap
+	f.gasTipCap = gasTipCap
+}
+
+// tracker records when the transactions are sent and when they are seen in an accepted
+// block
+type tracker struct {
+	lock        sync.Mutex
+	pending     map[common.Hash]time.Time
+	latencies   []time.Duration
+	sent        int
+	failed      int
+	firstSent   time.Time
+	lastConfirm time.Time
+}
+
+func (t *tracker) add(txHash common.Hash, sentAt time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.firstSent.IsZero() {
+		t.firstSent = sentAt
+	}
+	t.pending[txHash] = sentAt
+	t.sent++
+}
+
+func (t *tracker) fail(txHash common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.pending, txHash)
+	t.sent--
+	t.failed++
+}
+
+func (t *tracker) confirm(txHash common.Hash, at time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	sentAt, ok := t.pending[txHash]
+	if !ok {
+		return
+	}
+	delete(t.pending, txHash)
+	t.latencies = append(t.latencies, at.Sub(sentAt))
+	t.lastConfirm = at
+}
+
+func (t *tracker) stats() (int, int, int, int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.sent, len(t.latencies), t.failed, len(t.pending)
+}
+
+// watchBlocks confirms the tracked transactions included in the blocks accepted after
+// [lastBlock], until [done] is closed and either there are no pending transactions
+// or [confirmTimeout] has passed
+func (t *tracker) watchBlocks(client ethclient.Client, lastBlock uint64, done <-chan struct{}, confirmTimeout time.Duration) {
+	ticker := time.NewTicker(blockPollPeriod)
+	defer ticker.Stop()
+	var doneAt time.Time
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), constants.APIRequestTimeout)
+		head, err := client.BlockNumber(ctx)
+		cancel()
+		for err == nil && lastBlock < head {
+			ctx, cancel := context.WithTimeout(context.Background(), constants.APIRequestTimeout)
+			var block *types.Block
+			block, err = client.BlockByNumber(ctx, new(big.Int).SetUint64(lastBlock+1))
+			cancel()
+			if err != nil {
+				break
+			}
+			now := time.Now()
+			for _, tx := range block.Transactions() {
+				t.confirm(tx.Hash(), now)
+			}
+			lastBlock++
+		}
+		if doneAt.IsZero() {
+			select {
+			case <-done:
+				doneAt = time.Now()
+			default:
+			}
+		}
+		if !doneAt.IsZero() {
+			if _, _, _, pending := t.stats(); pending == 0 || time.Since(doneAt) > confirmTimeout {
+				return
+			}
+		}
+	}
+}
+
+type worker struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	client  ethclient.Client
+	nonce   uint64
+}
+
+// loadTest holds the state shared by the workers of a run
+type loadTest struct {
+	config   Config
+	chainID  *big.Int
+	signer   types.Signer
+	fees     *fees
+	tracker  *tracker
+	workers  []*worker
+	contract common.Address
+}
+
+// txData returns the recipient, value and data of the next transaction of worker [i]
+func (lt *loadTest) txData(i int) (common.Address, *big.Int, []byte) {
+	next := lt.workers[(i+1)%len(lt.workers)].address
+	switch lt.config.TxType {
+	case ERC20Transfer:
+		return lt.contract, common.Big0, erc20TransferCallData(next, common.Big1)
+	case ContractCall:
+		return lt.contract, common.Big0, counterIncrementCallData
+	default:
+		return next, common.Big1, nil
+	}
+}
+
+// issue sends the transactions of worker [i], one per job
+func (lt *loadTest) issue(i int, jobs <-chan struct{}) {
+	w := lt.workers[i]
+	for range jobs {
+		to, value, data := lt.txData(i)
+		gasFeeCap, gasTipCap := lt.fees.get()
+		tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   lt.chainID,
+			Nonce:     w.nonce,
+			To:        &to,
+			Gas:       lt.config.gasLimit(),
+			GasFeeCap: gasFeeCap,
+			GasTipCap: gasTipCap,
+			Value:     value,
+			Data:      data,
+		}), lt.signer, w.key)
+		if err != nil {
+			continue
+		}
+		// tracked before sending, so that it can't be accepted before being tracked
+		lt.tracker.add(tx.Hash(), time.Now())
+		ctx, cancel := context.WithTimeout(context.Background(), constants.APIRequestTimeout)
+		err = w.client.SendTransaction(ctx, tx)
+		cancel()
+		if err != nil {
+			// the nonce is reused by the next transaction of the worker
+			lt.tracker.fail(tx.Hash())
+			continue
+		}
+		w.nonce++
+	}
+}
+
+// sendFromFunder signs and sends a transaction from the funding key, returning it
+func (lt *loadTest) sendFromFunder(client ethclient.Client, funder *ecdsa.PrivateKey, nonce uint64, to *common.Address, gas uint64, value *big.Int, data []byte) (*types.Transaction, error) {
+	gasFeeCap, gasTipCap := lt.fees.get()
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   lt.chainID,
+		Nonce:     nonce,
+		To:        to,
+		Gas:       gas,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Value:     value,
+		Data:      data,
+	}), lt.signer, funder)
+	if err != nil {
+		return nil, err
+	}
+	return tx, evm.SendTransaction(client, tx)
+}
+
+func waitForTransactions(client ethclient.Client, txs []*types.Transaction) error {
+	for _, tx := range txs {
+		if _, success, err := evm.WaitForTransaction(client, tx); err != nil {
+			return err
+		} else if !success {
+			return fmt.Errorf("transaction %s failed", tx.Hash())
+		}
+	}
+	return nil
+}
+
+// setup deploys the contract used by the transaction type, if any, and funds the
+// workers from the funding key
+func (lt *loadTest) setup(client ethclient.Client, funder *ecdsa.PrivateKey) error {
+	funderAddress := crypto.PubkeyToAddress(funder.PublicKey)
+	nonce, err := evm.NonceAt(client, funderAddress.Hex())
+	if err != nil {
+		return err
+	}
+	var deployCode []byte
+	switch lt.config.TxType {
+	case ERC20Transfer:
+		if deployCode, err = tokenDeployCode(); err != nil {
+			return err
+		}
+	case ContractCall:
+		deployCode = counterDeployCode()
+	}
+	if deployCode != nil {
+		ux.Logger.PrintToUser("Deploying load test contract")
+		tx, err := lt.sendFromFunder(client, funder, nonce, nil, contractDeployGas, common.Big0, deployCode)
+		if err != nil {
+			return err
+		}
+		nonce++
+		receipt, success, err := evm.WaitForTransaction(client, tx)
+		if err != nil {
+			return err
+		}
+		if !success {
+			return fmt.Errorf("failure deploying load test contract")
+		}
+		lt.contract = receipt.ContractAddress
+	}
+	ux.Logger.PrintToUser("Funding %d workers", len(lt.workers))
+	txsPerWorker := lt.config.txsPerWorker()
+	gasFeeCap, _ := lt.fees.get()
+	amount := new(big.Int).Mul(gasFeeCap, new(big.Int).SetUint64(lt.config.gasLimit()*txsPerWorker*fundingFeeFactor))
+	amount.Add(amount, new(big.Int).SetUint64(txsPerWorker))
+	txs := []*types.Transaction{}
+	for _, w := range lt.workers {
+		tx, err := lt.sendFromFunder(client, funder, nonce, &w.address, evm.NativeTransferGas, amount, nil)
+		if err != nil {
+			return err
+		}
+		nonce++
+		txs = append(txs, tx)
+		if lt.config.TxType == ERC20Transfer {
+			tx, err := lt.sendFromFunder(client, funder, nonce, &lt.contract, erc20TransferGas, common.Big0, erc20TransferCallData(w.address, new(big.Int).SetUint64(txsPerWorker)))
+			if err != nil {
+				return err
+			}
+			nonce++
+			txs = append(txs, tx)
+		}
+	}
+	return waitForTransactions(client, txs)
+}
+
+// Run funds [config.Workers] accounts from the funding key and makes them issue
+// transactions of the given type at the target TPS against the endpoints, for the given
+// duration. It returns the accepted TPS and confirmation latencies
+func Run(config Config) (*Result, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("no RPC endpoints given")
+	}
+	clients := []ethclient.Client{}
+	for _, endpoint := range config.Endpoints {
+		client, err := evm.GetClient(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		defer client.Close()
+		clients = append(clients, client)
+	}
+	funder, err := crypto.HexToECDSA(config.FundingKey)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := evm.GetChainID(clients[0])
+	if err != nil {
+		return nil, err
+	}
+	lt := &loadTest{
+		config:  config,
+		chainID: chainID,
+		signer:  types.LatestSignerForChainID(chainID),
+		fees:    &fees{},
+		tracker: &tracker{pending: map[common.Hash]time.Time{}},
+	}
+	gasFeeCap, gasTipCap, _, err := evm.CalculateTxParams(clients[0], crypto.PubkeyToAddress(funder.PublicKey).Hex())
+	if err != nil {
+		return nil, err
+	}
+	lt.fees.gasFeeCap, lt.fees.gasTipCap = gasFeeCap, gasTipCap
+	for i := 0; i < config.Workers; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		lt.workers = append(lt.workers, &worker{
+			key:     key,
+			address: crypto.PubkeyToAddress(key.PublicKey),
+			client:  clients[i%len(clients)],
+		})
+	}
+	if err := lt.setup(clients[0], funder); err != nil {
+		return nil, fmt.Errorf("failure setting up load test: %w", err)
+	}
+	lastBlock, err := clients[0].BlockNumber(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	ux.Logger.PrintToUser("Sending %s transactions at %d TPS for %s", config.TxType, config.TPS, config.Duration)
+	done := make(chan struct{})
+	watchDone := make(chan struct{})
+	go func() {
+		lt.tracker.watchBlocks(clients[0], lastBlock, done, config.ConfirmTimeout)
+		close(watchDone)
+	}()
+	jobs := make(chan struct{}, config.Workers)
+	wg := sync.WaitGroup{}
+	for i := range lt.workers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lt.issue(i, jobs)
+		}(i)
+	}
+	start := time.Now()
+	issued := 0
+	issueTicker := time.NewTicker(issuePeriod)
+	lastRefresh, lastProgress := start, start
+	for now := range issueTicker.C {
+		elapsed := now.Sub(start)
+		if elapsed >= config.Duration {
+			break
+		}
+		for due := txsDue(config.TPS, elapsed); issued < due; issued++ {
+			jobs <- struct{}{}
+		}
+		if now.Sub(lastRefresh) >= feeRefreshPeriod {
+			go lt.fees.refresh(clients[0])
+			lastRefresh = now
+		}
+		if now.Sub(lastProgress) >= progressPeriod {
+			sent, accepted, failed, pending := lt.tracker.stats()
+			ux.Logger.PrintToUser("%s: sent %d, accepted %d, failed %d, pending %d", elapsed.Round(time.Second), sent, accepted, failed, pending)
+			lastProgress = now
+		}
+	}
+	issueTicker.Stop()
+	close(jobs)
+	wg.Wait()
+	close(done)
+	ux.Logger.PrintToUser("Waiting for the pending transactions to be accepted")
+	<-watchDone
+
+	sent, accepted, failed, pending := lt.tracker.stats()
+	result := &Result{
+		TxType:      config.TxType,
+		Endpoints:   config.Endpoints,
+		Workers:     config.Workers,
+		TargetTPS:   config.TPS,
+		Start:       start,
+		Sent:        sent,
+		Accepted:    accepted,
+		Failed:      failed,
+		Unconfirmed: pending,
+		Latency:     latencyPercentiles(lt.tracker.latencies),
+	}
+	if accepted > 0 {
+		result.Duration = lt.tracker.lastConfirm.Sub(lt.tracker.firstSent).Seconds()
+		if result.Duration > 0 {
+			result.AcceptedTPS = float64(accepted) / result.Duration
+		}
+	}
+	return result, nil
+}
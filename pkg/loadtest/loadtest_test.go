@@ -0,0 +1,134 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package loadtest
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/MetalBlockchain/subnet-evm/core/rawdb"
+	"github.com/MetalBlockchain/subnet-evm/core/state"
+	"github.com/MetalBlockchain/subnet-evm/core/types"
+	"github.com/MetalBlockchain/subnet-evm/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func balanceOfCallData(owner common.Address) []byte {
+	data := binary.BigEndian.AppendUint32(nil, erc20BalanceOfSelector)
+	return append(data, common.LeftPadBytes(owner.Bytes(), 32)...)
+}
+
+func newRuntimeConfig(t *testing.T, origin common.Address) *runtime.Config {
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	return &runtime.Config{Origin: origin, State: statedb, GasLimit: contractDeployGas}
+}
+
+func TestTokenContract(t *testing.T) {
+	require := require.New(t)
+	deployer := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	receiver := common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+	deployCode, err := tokenDeployCode()
+	require.NoError(err)
+	cfg := newRuntimeConfig(t, deployer)
+	_, token, _, err := runtime.Create(deployCode, cfg)
+	require.NoError(err)
+
+	cfg.GasLimit = erc20TransferGas
+	ret, _, err := runtime.Call(token, balanceOfCallData(deployer), cfg)
+	require.NoError(err)
+	require.Equal(tokenSupply, new(big.Int).SetBytes(ret))
+
+	ret, leftOverGas, err := runtime.Call(token, erc20TransferCallData(receiver, big.NewInt(5)), cfg)
+	require.NoError(err)
+	require.Equal(common.Big1, new(big.Int).SetBytes(ret))
+	// the intrinsic gas of the transaction is not accounted by the runtime
+	require.Less(erc20TransferGas-leftOverGas, erc20TransferGas-30_000)
+	logs := cfg.State.Logs()
+	require.Len(logs, 1)
+	require.Equal([]common.Hash{erc20TransferTopic, common.BytesToHash(deployer.Bytes()), common.BytesToHash(receiver.Bytes())}, logs[0].Topics)
+
+	ret, _, err = runtime.Call(token, balanceOfCallData(receiver), cfg)
+	require.NoError(err)
+	require.Equal(big.NewInt(5), new(big.Int).SetBytes(ret))
+	ret, _, err = runtime.Call(token, balanceOfCallData(deployer), cfg)
+	require.NoError(err)
+	require.Equal(new(big.Int).Sub(tokenSupply, big.NewInt(5)), new(big.Int).SetBytes(ret))
+
+	cfg.Origin = receiver
+	_, _, err = runtime.Call(token, erc20TransferCallData(deployer, big.NewInt(6)), cfg)
+	require.Error(err)
+	_, _, err = runtime.Call(token, counterIncrementCallData, cfg)
+	require.Error(err)
+	_, _, err = runtime.Call(token, erc20TransferCallData(deployer, big.NewInt(5)), cfg)
+	require.NoError(err)
+	ret, _, err = runtime.Call(token, balanceOfCallData(receiver), cfg)
+	require.NoError(err)
+	require.Zero(new(big.Int).SetBytes(ret).Sign())
+}
+
+func TestCounterContract(t *testing.T) {
+	require := require.New(t)
+	cfg := newRuntimeConfig(t, common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"))
+	_, counter, _, err := runtime.Create(counterDeployCode(), cfg)
+	require.NoError(err)
+	cfg.GasLimit = contractCallGas
+	for i := 0; i < 3; i++ {
+		_, _, err = runtime.Call(counter, counterIncrementCallData, cfg)
+		require.NoError(err)
+	}
+	require.Equal(common.BigToHash(big.NewInt(3)), cfg.State.GetState(counter, common.Hash{}))
+}
+
+func TestTxsDue(t *testing.T) {
+	require := require.New(t)
+	require.Equal(0, txsDue(100, 0))
+	require.Equal(0, txsDue(100, 9*time.Millisecond))
+	require.Equal(1, txsDue(100, 10*time.Millisecond))
+	require.Equal(150, txsDue(100, 1500*time.Millisecond))
+	require.Equal(2500, txsDue(5000, 500*time.Millisecond))
+}
+
+func TestLatencyPercentiles(t *testing.T) {
+	require := require.New(t)
+	require.Equal(Latency{}, latencyPercentiles(nil))
+
+	latencies := []time.Duration{}
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	require.Equal(Latency{P50: 50, P90: 90, P99: 99, Max: 100}, latencyPercentiles(latencies))
+	// the given latencies are not reordered
+	require.Equal(100*time.Millisecond, latencies[0])
+
+	require.Equal(Latency{P50: 1500, P90: 1500, P99: 1500, Max: 1500}, latencyPercentiles([]time.Duration{1500 * time.Millisecond}))
+}
+
+func TestConfigValidate(t *testing.T) {
+	require := require.New(t)
+	config := Config{
+		Endpoints: []string{"http://127.0.0.1:9650/ext/bc/C/rpc"},
+		TxType:    ERC20Transfer,
+		Workers:   10,
+		TPS:       100,
+		Duration:  time.Minute,
+	}
+	require.NoError(config.Validate())
+	require.Equal(DefaultConfirmTimeout, config.ConfirmTimeout)
+	require.Equal(uint64(601), config.txsPerWorker())
+	require.Equal(erc20TransferGas, config.gasLimit())
+
+	invalid := config
+	invalid.TxType = "swap"
+	require.ErrorContains(invalid.Validate(), "invalid tx type")
+	invalid = config
+	invalid.Workers = 0
+	require.Error(invalid.Validate())
+	invalid = config
+	invalid.TPS = 0
+	require.Error(invalid.Validate())
+}
@@ -0,0 +1,448 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package loadtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/olekukonko/tablewriter"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	TextFormat     = "text"
+	MarkdownFormat = "markdown"
+	JSONFormat     = "json"
+)
+
+var ReportFormats = []string{TextFormat, MarkdownFormat, JSONFormat}
+
+// ChainMetrics holds the chain activity during a load test, as seen by its validators
+type ChainMetrics struct {
+	TPS float64 `json:"tps"`
+	// BlockTime is the average time between accepted blocks, in seconds
+	BlockTime    float64 `json:"blockTimeSeconds"`
+	GasUsed      float64 `json:"gasUsed"`
+	GasPerSecond float64 `json:"gasPerSecond"`
+}
+
+// NodeMetrics holds the resource usage of a validator during a load test
+type NodeMetrics struct {
+	CloudID string `json:"cloudID"`
+	IP      string `json:"ip"`
+	// CPU is the average CPU usage, in percent
+	CPU float64 `json:"cpuPercent"`
+	// Memory is the average memory usage, in percent
+	Memory float64 `json:"memoryPercent"`
+	// Disk is the root disk usage at the end of the load test, in percent
+	Disk float64 `json:"diskPercent"`
+	// DiskWrite is the average disk write throughput, in bytes per second
+	DiskWrite float64 `json:"diskWriteBytesPerSecond"`
+}
+
+// Record holds a load test and the metrics collected for its time window
+type Record struct {
+	Name               string        `json:"name"`
+	ClusterName        string        `json:"clusterName"`
+	SubnetName         string        `json:"subnetName"`
+	BlockchainID       string        `json:"blockchainID"`
+	AvalancheGoVersion string        `json:"avalancheGoVersion,omitempty"`
+	SubnetEVMVersion   string        `json:"subnetEVMVersion,omitempty"`
+	Start              time.Time     `json:"start"`
+	End                *time.Time    `json:"end,omitempty"`
+	Chain              *ChainMetrics `json:"chain,omitempty"`
+	Validators         []NodeMetrics `json:"validators,omitempty"`
+	// Generator is the result of the built-in load generator, if it was used
+	Generator *Result `json:"generator,omitempty"`
+}
+
+// Finished tells if the load test was stopped
+func (r *Record) Finished() bool {
+	return r.End != nil
+}
+
+func LoadRecord(path string) (*Record, error) {
+	runBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no load test record found at %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	run := &Record{}
+	if err := json.Unmarshal(runBytes, run); err != nil {
+		return nil, fmt.Errorf("invalid load test record %s: %w", path, err)
+	}
+	return run, nil
+}
+
+func SaveRecord(path string, run *Record) error {
+	runBytes, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, runBytes, constants.WriteReadReadPerms)
+}
+
+func LoadResult(path string) (*Result, error) {
+	resultBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	if err := json.Unmarshal(resultBytes, result); err != nil {
+		return nil, fmt.Errorf("invalid load test result %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// MetricQuery is a Prometheus query evaluated at the end of a load test window
+type MetricQuery struct {
+	Name  string
+	Query string
+}
+
+const (
+	TPSMetric          = "tps"
+	BlocksMetric       = "blocks"
+	GasUsedMetric      = "gasUsed"
+	CPUMetric          = "cpu"
+	MemoryMetric       = "memory"
+	DiskMetric         = "disk"
+	DiskWriteMetric    = "diskWrite"
+	machineMetricsJob  = `job="avalanchego-machine"`
+	avalancheGoMetrics = `job="avalanchego"`
+)
+
+// MetricQueries returns the Prometheus queries collecting the metrics of a load test
+// on [blockchainID] lasting [window]. Chain metrics are given per avalanchego instance
+// and resource metrics per machine instance
+func MetricQueries(blockchainID string, window time.Duration) []MetricQuery {
+	w := fmt.Sprintf("%ds", max(int(window.Seconds()), 1))
+	chainPrefix := "avalanche_" + blockchainID
+	return []MetricQuery{
+		{TPSMetric, fmt.Sprintf(`rate(%s_vm_chain_txs_accepted{%s}[%s])`, chainPrefix, avalancheGoMetrics, w)},
+		{BlocksMetric, fmt.Sprintf(`increase(%s_blks_accepted_count{%s}[%s])`, chainPrefix, avalancheGoMetrics, w)},
+		{GasUsedMetric, fmt.Sprintf(`increase(%s_vm_chain_block_gas_used_accepted{%s}[%s])`, chainPrefix, avalancheGoMetrics, w)},
+		{CPUMetric, fmt.Sprintf(`100 * (1 - avg by (instance) (rate(node_cpu_seconds_total{%s,mode="idle"}[%s])))`, machineMetricsJob, w)},
+		{MemoryMetric, fmt.Sprintf(`100 * avg_over_time((1 - node_memory_MemAvailable_bytes{%s} / node_memory_MemTotal_bytes{%s})[%s:])`, machineMetricsJob, machineMetricsJob, w)},
+		{DiskMetric, fmt.Sprintf(`100 * (1 - node_filesystem_avail_bytes{%s,mountpoint="/"} / node_filesystem_size_bytes{%s,mountpoint="/"})`, machineMetricsJob, machineMetricsJob)},
+		{DiskWriteMetric, fmt.Sprintf(`sum by (instance) (rate(node_disk_written_bytes_total{%s}[%s]))`, machineMetricsJob, w)},
+	}
+}
+
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// ParsePrometheusVector returns the values of a Prometheus instant query response,
+// mapped by the IP of their instance
+func ParsePrometheusVector(response []byte) (map[string]float64, error) {
+	resp := prometheusResponse{}
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return nil, fmt.Errorf("invalid prometheus response: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", resp.Error)
+	}
+	if resp.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("unexpected prometheus result type %s", resp.Data.ResultType)
+	}
+	values := map[string]float64{}
+	for _, sample := range resp.Data.Result {
+		if len(sample.Value) != 2 {
+			return nil, fmt.Errorf("invalid prometheus sample %v", sample.Value)
+		}
+		valueStr, ok := sample.Value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid prometheus sample %v", sample.Value)
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		ip, _, _ := strings.Cut(sample.Metric["instance"], ":")
+		values[ip] = value
+	}
+	return values, nil
+}
+
+// NewChainMetrics summarizes the chain metrics seen by each validator over [window]
+// into the chain activity, using the most advanced validator
+func NewChainMetrics(values map[string]map[string]float64, window time.Duration) *ChainMetrics {
+	maxValue := func(metric string) float64 {
+		value := 0.0
+		for _, v := range values[metric] {
+			value = max(value, v)
+		}
+		return value
+	}
+	metrics := &ChainMetrics{
+		TPS:     maxValue(TPSMetric),
+		GasUsed: maxValue(GasUsedMetric),
+	}
+	if blocks := maxValue(BlocksMetric); blocks > 0 {
+		metrics.BlockTime = window.Seconds() / blocks
+	}
+	if window > 0 {
+		metrics.GasPerSecond = metrics.GasUsed / window.Seconds()
+	}
+	return metrics
+}
+
+// averageNodeMetrics returns the average resource usage of the validators, and the
+// disk usage of the fullest one
+func averageNodeMetrics(nodes []NodeMetrics) NodeMetrics {
+	avg := NodeMetrics{}
+	if len(nodes) == 0 {
+		return avg
+	}
+	for _, node := range nodes {
+		avg.CPU += node.CPU
+		avg.Memory += node.Memory
+		avg.DiskWrite += node.DiskWrite
+		avg.Disk = max(avg.Disk, node.Disk)
+	}
+	n := float64(len(nodes))
+	avg.CPU /= n
+	avg.Memory /= n
+	avg.DiskWrite /= n
+	return avg
+}
+
+// reportRow is a metric of a run, with an optional comparison value
+type reportRow struct {
+	Metric string
+	Unit   string
+	Value  float64
+	// LowerIsBetter tells if a decrease of the metric is an improvement
+	LowerIsBetter bool
+}
+
+func runRows(run *Record) []reportRow {
+	rows := []reportRow{}
+	if run.Generator != nil {
+		rows = append(rows,
+			reportRow{"Generator accepted TPS", "tx/s", run.Generator.AcceptedTPS, false},
+			reportRow{"Generator failed txs", "", float64(run.Generator.Failed + run.Generator.Unconfirmed), true},
+			reportRow{"Confirmation latency p50", "ms", run.Generator.Latency.P50, true},
+			reportRow{"Confirmation latency p90", "ms", run.Generator.Latency.P90, true},
+			reportRow{"Confirmation latency p99", "ms", run.Generator.Latency.P99, true},
+		)
+	}
+	if run.Chain != nil {
+		rows = append(rows,
+			reportRow{"Chain TPS", "tx/s", run.Chain.TPS, false},
+			reportRow{"Block time", "s", run.Chain.BlockTime, true},
+			reportRow{"Gas used", "gas", run.Chain.GasUsed, false},
+			reportRow{"Gas per second", "gas/s", run.Chain.GasPerSecond, false},
+		)
+	}
+	if len(run.Validators) > 0 {
+		avg := averageNodeMetrics(run.Validators)
+		rows = append(rows,
+			reportRow{"Validator avg CPU", "%", avg.CPU, true},
+			reportRow{"Validator avg memory", "%", avg.Memory, true},
+			reportRow{"Validator max disk", "%", avg.Disk, true},
+			reportRow{"Validator avg disk write", "B/s", avg.DiskWrite, true},
+		)
+	}
+	return rows
+}
+
+func formatValue(value float64, unit string) string {
+	s := strconv.FormatFloat(value, 'f', 2, 64)
+	if unit != "" {
+		s += " " + unit
+	}
+	return s
+}
+
+// formatDelta returns the relative change from [a] to [b], flagged as a regression when
+// it goes the wrong way by more than [regressionThreshold] percent
+func formatDelta(a, b float64, lowerIsBetter bool, regressionThreshold float64) string {
+	if a == 0 {
+		if b == 0 {
+			return "0.00%"
+		}
+		return "n/a"
+	}
+	delta := 100 * (b - a) / math.Abs(a)
+	s := fmt.Sprintf("%+.2f%%", delta)
+	if (lowerIsBetter && delta > regressionThreshold) || (!lowerIsBetter && delta < -regressionThreshold) {
+		s += " (regression)"
+	}
+	return s
+}
+
+func runWindow(run *Record) string {
+	end := "running"
+	if run.Finished() {
+		end = run.End.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%s - %s", run.Start.UTC().Format(time.RFC3339), end)
+}
+
+func renderTable(buf *bytes.Buffer, format string, header []string, rows [][]string) {
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(header)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	if format == MarkdownFormat {
+		table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+		table.SetCenterSeparator("|")
+	}
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+func heading(buf *bytes.Buffer, format string, title string) {
+	if format == MarkdownFormat {
+		fmt.Fprintf(buf, "## %s\n\n", title)
+	} else {
+		fmt.Fprintf(buf, "%s\n\n", title)
+	}
+}
+
+func checkFormat(format string) error {
+	if !slices.Contains(ReportFormats, format) {
+		return fmt.Errorf("invalid format %q. valid formats are %v", format, ReportFormats)
+	}
+	return nil
+}
+
+// FormatReport renders [run] as text, markdown or JSON
+func FormatReport(run *Record, format string) (string, error) {
+	if err := checkFormat(format); err != nil {
+		return "", err
+	}
+	if format == JSONFormat {
+		runBytes, err := json.MarshalIndent(run, "", "  ")
+		return string(runBytes) + "\n", err
+	}
+	buf := &bytes.Buffer{}
+	heading(buf, format, fmt.Sprintf("Load test %s", run.Name))
+	renderTable(buf, format, []string{"Cluster", "Subnet", "AvalancheGo", "Subnet-EVM", "Window"}, [][]string{
+		{run.ClusterName, run.SubnetName, run.AvalancheGoVersion, run.SubnetEVMVersion, runWindow(run)},
+	})
+	buf.WriteString("\n")
+	rows := [][]string{}
+	for _, row := range runRows(run) {
+		rows = append(rows, []string{row.Metric, formatValue(row.Value, row.Unit)})
+	}
+	if len(rows) == 0 {
+		buf.WriteString("No metrics were collected for this load test\n")
+		return buf.String(), nil
+	}
+	renderTable(buf, format, []string{"Metric", "Value"}, rows)
+	if len(run.Validators) > 0 {
+		buf.WriteString("\n")
+		nodeRows := [][]string{}
+		validators := slices.Clone(run.Validators)
+		sort.Slice(validators, func(i, j int) bool { return validators[i].CloudID < validators[j].CloudID })
+		for _, node := range validators {
+			nodeRows = append(nodeRows, []string{
+				node.CloudID,
+				node.IP,
+				formatValue(node.CPU, "%"),
+				formatValue(node.Memory, "%"),
+				formatValue(node.Disk, "%"),
+				formatValue(node.DiskWrite, "B/s"),
+			})
+		}
+		renderTable(buf, format, []string{"Validator", "IP", "CPU", "Memory", "Disk", "Disk Write"}, nodeRows)
+	}
+	return buf.String(), nil
+}
+
+// metricComparison is a metric of two runs
+type metricComparison struct {
+	Metric string  `json:"metric"`
+	Unit   string  `json:"unit,omitempty"`
+	A      float64 `json:"a"`
+	B      float64 `json:"b"`
+	Delta  string  `json:"delta"`
+}
+
+type comparison struct {
+	A       *Record            `json:"a"`
+	B       *Record            `json:"b"`
+	Metrics []metricComparison `json:"metrics"`
+}
+
+func compareRuns(a, b *Record, regressionThreshold float64) []metricComparison {
+	bRows := map[string]reportRow{}
+	for _, row := range runRows(b) {
+		bRows[row.Metric] = row
+	}
+	metrics := []metricComparison{}
+	for _, aRow := range runRows(a) {
+		bRow, ok := bRows[aRow.Metric]
+		if !ok {
+			continue
+		}
+		metrics = append(metrics, metricComparison{
+			Metric: aRow.Metric,
+			Unit:   aRow.Unit,
+			A:      aRow.Value,
+			B:      bRow.Value,
+			Delta:  formatDelta(aRow.Value, bRow.Value, aRow.LowerIsBetter, regressionThreshold),
+		})
+	}
+	return metrics
+}
+
+// FormatComparison renders the metrics of [b] against the ones of [a] as text,
+// markdown or JSON. Changes for the worse of more than [regressionThreshold] percent
+// are flagged as regressions
+func FormatComparison(a, b *Record, format string, regressionThreshold float64) (string, error) {
+	if err := checkFormat(format); err != nil {
+		return "", err
+	}
+	metrics := compareRuns(a, b, regressionThreshold)
+	if format == JSONFormat {
+		comparisonBytes, err := json.MarshalIndent(comparison{A: a, B: b, Metrics: metrics}, "", "  ")
+		return string(comparisonBytes) + "\n", err
+	}
+	buf := &bytes.Buffer{}
+	heading(buf, format, fmt.Sprintf("Load test %s vs %s", a.Name, b.Name))
+	renderTable(buf, format, []string{"", a.Name, b.Name}, [][]string{
+		{"Cluster", a.ClusterName, b.ClusterName},
+		{"Subnet", a.SubnetName, b.SubnetName},
+		{"AvalancheGo", a.AvalancheGoVersion, b.AvalancheGoVersion},
+		{"Subnet-EVM", a.SubnetEVMVersion, b.SubnetEVMVersion},
+		{"Window", runWindow(a), runWindow(b)},
+	})
+	buf.WriteString("\n")
+	if len(metrics) == 0 {
+		buf.WriteString("The load tests have no metrics in common\n")
+		return buf.String(), nil
+	}
+	rows := [][]string{}
+	for _, metric := range metrics {
+		rows = append(rows, []string{metric.Metric, formatValue(metric.A, metric.Unit), formatValue(metric.B, metric.Unit), metric.Delta})
+	}
+	renderTable(buf, format, []string{"Metric", a.Name, b.Name, "Change"}, rows)
+	return buf.String(), nil
+}
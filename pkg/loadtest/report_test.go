@@ -0,0 +1,175 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package loadtest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testRecord(name string, tps, blockTime, cpu float64) *Record {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+	return &Record{
+		Name:               name,
+		ClusterName:        "cluster",
+		SubnetName:         "subnet",
+		BlockchainID:       "2Ab",
+		AvalancheGoVersion: "v1.11.3",
+		SubnetEVMVersion:   "v0.6.3",
+		Start:              start,
+		End:                &end,
+		Chain:              &ChainMetrics{TPS: tps, BlockTime: blockTime, GasUsed: 1e9, GasPerSecond: 1e9 / 600},
+		Validators: []NodeMetrics{
+			{CloudID: "i-2", IP: "10.0.0.2", CPU: cpu, Memory: 40, Disk: 30, DiskWrite: 1000},
+			{CloudID: "i-1", IP: "10.0.0.1", CPU: cpu, Memory: 60, Disk: 50, DiskWrite: 3000},
+		},
+	}
+}
+
+func TestParsePrometheusVector(t *testing.T) {
+	require := require.New(t)
+	values, err := ParsePrometheusVector([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"instance":"10.0.0.1:9650","job":"avalanchego"},"value":[1714557600,"125.5"]},
+		{"metric":{"instance":"10.0.0.2:9650","job":"avalanchego"},"value":[1714557600,"NaN"]}
+	]}}`))
+	require.NoError(err)
+	require.Equal(map[string]float64{"10.0.0.1": 125.5}, values)
+
+	_, err = ParsePrometheusVector([]byte(`{"status":"error","error":"parse error"}`))
+	require.ErrorContains(err, "parse error")
+	_, err = ParsePrometheusVector([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	require.Error(err)
+	_, err = ParsePrometheusVector([]byte(`not json`))
+	require.Error(err)
+}
+
+func TestMetricQueries(t *testing.T) {
+	require := require.New(t)
+	queries := MetricQueries("2Ab", 10*time.Minute)
+	require.Len(queries, 7)
+	for _, query := range queries {
+		// queries are single quoted when sent over ssh
+		require.NotContains(query.Query, "'")
+	}
+	require.Equal(TPSMetric, queries[0].Name)
+	require.Equal(`rate(avalanche_2Ab_vm_chain_txs_accepted{job="avalanchego"}[600s])`, queries[0].Query)
+}
+
+func TestNewChainMetrics(t *testing.T) {
+	require := require.New(t)
+	metrics := NewChainMetrics(map[string]map[string]float64{
+		TPSMetric:     {"10.0.0.1": 100, "10.0.0.2": 98},
+		BlocksMetric:  {"10.0.0.1": 300, "10.0.0.2": 299},
+		GasUsedMetric: {"10.0.0.1": 6e8},
+	}, 10*time.Minute)
+	require.Equal(&ChainMetrics{TPS: 100, BlockTime: 2, GasUsed: 6e8, GasPerSecond: 1e6}, metrics)
+	require.Equal(&ChainMetrics{}, NewChainMetrics(nil, 0))
+}
+
+func TestFormatDelta(t *testing.T) {
+	require := require.New(t)
+	require.Equal("+10.00%", formatDelta(100, 110, false, 5))
+	require.Equal("-10.00% (regression)", formatDelta(100, 90, false, 5))
+	require.Equal("+10.00% (regression)", formatDelta(2, 2.2, true, 5))
+	require.Equal("-4.00%", formatDelta(100, 96, false, 5))
+	require.Equal("0.00%", formatDelta(0, 0, true, 5))
+	require.Equal("n/a", formatDelta(0, 1, true, 5))
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	require := require.New(t)
+	path := filepath.Join(t.TempDir(), "lt.json")
+	record := testRecord("lt", 100, 2, 50)
+	record.Generator = &Result{TxType: ERC20Transfer, AcceptedTPS: 99.5, Latency: Latency{P50: 1200}}
+	require.NoError(SaveRecord(path, record))
+	loaded, err := LoadRecord(path)
+	require.NoError(err)
+	require.Equal(record, loaded)
+	require.True(loaded.Finished())
+
+	record.End = nil
+	require.NoError(SaveRecord(path, record))
+	recordBytes, err := os.ReadFile(path)
+	require.NoError(err)
+	require.NotContains(string(recordBytes), `"end"`)
+	loaded, err = LoadRecord(path)
+	require.NoError(err)
+	require.False(loaded.Finished())
+
+	_, err = LoadRecord(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(err, "no load test record")
+}
+
+func TestFormatReport(t *testing.T) {
+	require := require.New(t)
+	record := testRecord("lt", 100, 2, 50)
+
+	report, err := FormatReport(record, TextFormat)
+	require.NoError(err)
+	require.Contains(report, "Load test lt")
+	require.Contains(report, "v1.11.3")
+	require.Contains(report, "Chain TPS")
+	require.Contains(report, "100.00 tx/s")
+	// the fullest disk and the average of the other metrics
+	require.Contains(report, "Validator max disk")
+	require.Contains(report, "50.00 %")
+	require.Contains(report, "2000.00 B/s")
+	require.Less(strings.Index(report, "i-1"), strings.Index(report, "i-2"))
+
+	report, err = FormatReport(record, MarkdownFormat)
+	require.NoError(err)
+	require.True(strings.HasPrefix(report, "## Load test lt"))
+	require.Contains(report, "|")
+
+	report, err = FormatReport(record, JSONFormat)
+	require.NoError(err)
+	decoded := &Record{}
+	require.NoError(json.Unmarshal([]byte(report), decoded))
+	require.Equal(record.Chain, decoded.Chain)
+
+	record.End = nil
+	record.Chain = nil
+	record.Validators = nil
+	report, err = FormatReport(record, TextFormat)
+	require.NoError(err)
+	require.Contains(report, "running")
+	require.Contains(report, "No metrics")
+
+	_, err = FormatReport(record, "html")
+	require.ErrorContains(err, "invalid format")
+}
+
+func TestFormatComparison(t *testing.T) {
+	require := require.New(t)
+	a := testRecord("before", 100, 2, 50)
+	b := testRecord("after", 80, 2, 51)
+	b.AvalancheGoVersion = "v1.11.4"
+
+	report, err := FormatComparison(a, b, TextFormat, 5)
+	require.NoError(err)
+	require.Contains(report, "Load test before vs after")
+	require.Contains(report, "v1.11.4")
+	require.Contains(report, "-20.00% (regression)")
+	require.Contains(report, "+2.00%")
+	require.NotContains(report, "+2.00% (regression)")
+
+	report, err = FormatComparison(a, b, JSONFormat, 5)
+	require.NoError(err)
+	decoded := comparison{}
+	require.NoError(json.Unmarshal([]byte(report), &decoded))
+	require.Equal("Chain TPS", decoded.Metrics[0].Metric)
+	require.Equal(100.0, decoded.Metrics[0].A)
+	require.Equal(80.0, decoded.Metrics[0].B)
+
+	// generator metrics are only compared when both runs used it
+	a.Generator = &Result{AcceptedTPS: 100}
+	metrics := compareRuns(a, b, 5)
+	require.Equal("Chain TPS", metrics[0].Metric)
+}
@@ -8,7 +8,7 @@ import (
 	"errors"
 	"strings"
 
-	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
 	"github.com/MetalBlockchain/metalgo/api/info"
 )
 
@@ -24,7 +24,7 @@ func NewStatusChecker() StatusChecker {
 
 func (networkStatusChecker) GetCurrentNetworkVersion() (string, int, bool, error) {
 	ctx := context.Background()
-	infoClient := info.NewClient(constants.LocalAPIEndpoint)
+	infoClient := info.NewClient(models.NewLocalNetwork().Endpoint)
 	versionResponse, err := infoClient.GetNodeVersion(ctx)
 	if err != nil {
 		// not actually an error, network just not running
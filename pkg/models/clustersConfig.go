@@ -39,7 +39,7 @@ type ClustersConfig struct {
 // GetAPINodes returns a filtered list of API nodes based on the ClusterConfig and given hosts.
 func (cc *ClusterConfig) GetAPIHosts(hosts []*Host) []*Host {
 	return utils.Filter(hosts, func(h *Host) bool {
-		return slices.Contains(cc.APINodes, h.NodeID)
+		return slices.Contains(cc.APINodes, h.GetCloudID())
 	})
 }
 
@@ -66,6 +66,29 @@ func (cc *ClusterConfig) GetCloudIDs() []string {
 	return r
 }
 
+// RemoveHost removes the host from the node, API node and monitoring lists of the cluster
+func (cc *ClusterConfig) RemoveHost(hostCloudID string) {
+	cc.Nodes = utils.Filter(cc.Nodes, func(s string) bool { return s != hostCloudID })
+	cc.APINodes = utils.Filter(cc.APINodes, func(s string) bool { return s != hostCloudID })
+	if cc.MonitoringInstance == hostCloudID {
+		cc.MonitoringInstance = ""
+	}
+}
+
+// ReplaceHost puts [newCloudID] in place of [oldCloudID] in the node, API node and
+// monitoring lists of the cluster
+func (cc *ClusterConfig) ReplaceHost(oldCloudID, newCloudID string) {
+	replace := func(s string) string {
+		if s == oldCloudID {
+			return newCloudID
+		}
+		return s
+	}
+	cc.Nodes = utils.Map(cc.Nodes, replace)
+	cc.APINodes = utils.Map(cc.APINodes, replace)
+	cc.MonitoringInstance = replace(cc.MonitoringInstance)
+}
+
 func (cc *ClusterConfig) GetHostRoles(nodeConf NodeConfig) []string {
 	roles := []string{}
 	if cc.IsAvalancheGoHost(nodeConf.NodeID) {
@@ -0,0 +1,41 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterConfigRemoveHost(t *testing.T) {
+	cc := ClusterConfig{
+		Nodes:              []string{"i-1", "i-2", "i-3"},
+		APINodes:           []string{"i-3"},
+		MonitoringInstance: "i-4",
+	}
+	cc.RemoveHost("i-3")
+	require.Equal(t, []string{"i-1", "i-2"}, cc.Nodes)
+	require.Empty(t, cc.APINodes)
+	require.Equal(t, "i-4", cc.MonitoringInstance)
+
+	cc.RemoveHost("i-4")
+	require.Equal(t, []string{"i-1", "i-2"}, cc.Nodes)
+	require.Empty(t, cc.MonitoringInstance)
+}
+
+func TestClusterConfigReplaceHost(t *testing.T) {
+	cc := ClusterConfig{
+		Nodes:              []string{"i-1", "i-2"},
+		APINodes:           []string{"i-2"},
+		MonitoringInstance: "i-3",
+	}
+	cc.ReplaceHost("i-2", "i-5")
+	require.Equal(t, []string{"i-1", "i-5"}, cc.Nodes)
+	require.Equal(t, []string{"i-5"}, cc.APINodes)
+	require.Equal(t, "i-3", cc.MonitoringInstance)
+
+	cc.ReplaceHost("i-1", "i-6")
+	require.Equal(t, []string{"i-6", "i-5"}, cc.Nodes)
+	require.Equal(t, []string{"i-5"}, cc.APINodes)
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+var existingHostNameRegex = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// ExistingHost describes a server not created by the CLI that is adopted into a cluster
+type ExistingHost struct {
+	// Name identifies the host in the cluster. Defaults to the IP with dots replaced by dashes
+	Name string `yaml:"name,omitempty"`
+	IP   string `yaml:"ip"`
+	// User is the ssh user. Defaults to ubuntu
+	User string `yaml:"user,omitempty"`
+	// SSHKey is the path to the ssh private key. Mutually exclusive with SSHAgentIdentity
+	SSHKey string `yaml:"ssh-key,omitempty"`
+	// SSHAgentIdentity is an identity loaded in the ssh agent. Mutually exclusive with SSHKey
+	SSHAgentIdentity string `yaml:"ssh-agent-identity,omitempty"`
+	// Region is a free form location label. Defaults to "default"
+	Region string `yaml:"region,omitempty"`
+	// API marks the host as an API (non staking) node. Only valid for Devnet
+	API bool `yaml:"api,omitempty"`
+	// Monitoring marks the host as the cluster monitoring host
+	Monitoring bool `yaml:"monitoring,omitempty"`
+}
+
+type existingHostsFile struct {
+	Hosts []ExistingHost `yaml:"hosts"`
+}
+
+// LoadExistingHosts reads and validates a hosts file of the form
+//
+//	hosts:
+//	  - ip: 10.0.0.5
+//	    user: ubuntu
+//	    ssh-key: ~/.ssh/id_ed25519
+//	    region: dc1
+func LoadExistingHosts(filePath string) ([]ExistingHost, error) {
+	bs, err := os.ReadFile(utils.ExpandHome(filePath))
+	if err != nil {
+		return nil, err
+	}
+	var hostsFile existingHostsFile
+	if err := yaml.Unmarshal(bs, &hostsFile); err != nil {
+		return nil, fmt.Errorf("invalid hosts file %s: %w", filePath, err)
+	}
+	if len(hostsFile.Hosts) == 0 {
+		return nil, fmt.Errorf("no hosts found in %s", filePath)
+	}
+	names := map[string]bool{}
+	ips := map[string]bool{}
+	numMonitoring := 0
+	for i := range hostsFile.Hosts {
+		host := &hostsFile.Hosts[i]
+		if !utils.IsValidIP(host.IP) {
+			return nil, fmt.Errorf("invalid IP %q for host #%d", host.IP, i+1)
+		}
+		if host.Name == "" {
+			host.Name = strings.ReplaceAll(strings.ReplaceAll(host.IP, ".", "-"), ":", "-")
+		}
+		if !existingHostNameRegex.MatchString(host.Name) {
+			return nil, fmt.Errorf("invalid name %q for host %s: only letters, digits and dashes are allowed", host.Name, host.IP)
+		}
+		if host.User == "" {
+			host.User = constants.AnsibleSSHUser
+		}
+		if host.Region == "" {
+			host.Region = constants.ExistingHostsDefaultRegion
+		}
+		switch {
+		case host.SSHKey != "" && host.SSHAgentIdentity != "":
+			return nil, fmt.Errorf("host %s: ssh-key and ssh-agent-identity are mutually exclusive", host.Name)
+		case host.SSHKey == "" && host.SSHAgentIdentity == "":
+			return nil, fmt.Errorf("host %s: either ssh-key or ssh-agent-identity must be provided", host.Name)
+		case host.SSHKey != "":
+			host.SSHKey = utils.ExpandHome(host.SSHKey)
+			if !utils.FileExists(host.SSHKey) {
+				return nil, fmt.Errorf("host %s: ssh key %s not found", host.Name, host.SSHKey)
+			}
+		}
+		if host.Monitoring && host.API {
+			return nil, fmt.Errorf("host %s: monitoring host can't be an API node", host.Name)
+		}
+		if names[host.Name] {
+			return nil, fmt.Errorf("duplicated host name %s", host.Name)
+		}
+		if ips[host.IP] {
+			return nil, fmt.Errorf("duplicated host IP %s", host.IP)
+		}
+		names[host.Name] = true
+		ips[host.IP] = true
+		if host.Monitoring {
+			numMonitoring++
+		}
+	}
+	if numMonitoring > 1 {
+		return nil, errors.New("only one monitoring host is allowed")
+	}
+	return hostsFile.Hosts, nil
+}
+
+// UsesSSHAgent checks if the host is accessed with an ssh agent identity
+func (eh ExistingHost) UsesSSHAgent() bool {
+	return eh.SSHAgentIdentity != ""
+}
+
+// ToHost returns the Host used to access the existing host
+func (eh ExistingHost) ToHost() (*Host, error) {
+	ansibleID, err := HostCloudIDToAnsibleID(constants.ExistingHostsCloudService, eh.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &Host{
+		NodeID:            ansibleID,
+		IP:                eh.IP,
+		SSHUser:           eh.User,
+		SSHPrivateKeyPath: eh.SSHKey,
+		SSHCommonArgs:     constants.AnsibleSSHUseAgentParams,
+	}, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHostsFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "hosts.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadExistingHosts(t *testing.T) {
+	require := require.New(t)
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(os.WriteFile(keyPath, []byte("key"), 0o600))
+	hosts, err := LoadExistingHosts(writeHostsFile(t, `
+hosts:
+  - ip: 10.0.0.5
+    ssh-key: `+keyPath+`
+  - name: db-2
+    ip: 10.0.0.6
+    user: admin
+    ssh-agent-identity: me@example.com
+    region: dc2
+    monitoring: true
+`))
+	require.NoError(err)
+	require.Len(hosts, 2)
+	require.Equal("10-0-0-5", hosts[0].Name)
+	require.Equal(constants.AnsibleSSHUser, hosts[0].User)
+	require.Equal(constants.ExistingHostsDefaultRegion, hosts[0].Region)
+	require.False(hosts[0].UsesSSHAgent())
+	require.True(hosts[1].UsesSSHAgent())
+
+	host, err := hosts[1].ToHost()
+	require.NoError(err)
+	require.Equal("existing_host_db-2", host.NodeID)
+	require.Equal("db-2", host.GetCloudID())
+	require.Equal("admin", host.SSHUser)
+	require.Empty(host.SSHPrivateKeyPath)
+	cloudService, cloudID, err := HostAnsibleIDToCloudID(host.NodeID)
+	require.NoError(err)
+	require.Equal(constants.ExistingHostsCloudService, cloudService)
+	require.Equal("db-2", cloudID)
+}
+
+func TestLoadExistingHostsErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty":        "hosts: []",
+		"invalid ip":   "hosts:\n  - ip: nope\n    ssh-agent-identity: me",
+		"no auth":      "hosts:\n  - ip: 10.0.0.5",
+		"both auth":    "hosts:\n  - ip: 10.0.0.5\n    ssh-key: /k\n    ssh-agent-identity: me",
+		"missing key":  "hosts:\n  - ip: 10.0.0.5\n    ssh-key: /does/not/exist",
+		"bad name":     "hosts:\n  - ip: 10.0.0.5\n    name: a_b\n    ssh-agent-identity: me",
+		"duplicate ip": "hosts:\n  - ip: 10.0.0.5\n    ssh-agent-identity: me\n  - ip: 10.0.0.5\n    name: other\n    ssh-agent-identity: me",
+		"two monitors": "hosts:\n  - ip: 10.0.0.5\n    monitoring: true\n    ssh-agent-identity: me\n  - ip: 10.0.0.6\n    monitoring: true\n    ssh-agent-identity: me",
+	} {
+		_, err := LoadExistingHosts(writeHostsFile(t, content))
+		require.Error(t, err, name)
+	}
+}
@@ -25,7 +25,6 @@ import (
 const (
 	maxResponseSize      = 102400          // 100KB should be enough to read the avalanchego response
 	sshConnectionTimeout = 3 * time.Second // usually takes less than 2
-	sshConnectionRetries = 5
 )
 
 type Host struct {
@@ -75,33 +74,40 @@ func (h *Host) GetCloudID() string {
 	return cloudID
 }
 
-// Connect starts a new SSH connection with the provided private key.
+// Connect starts a new SSH connection with the provided private key, or reuses
+// a pooled one to the same host. A pooled connection evicted since the host got it
+// is replaced.
 func (h *Host) Connect(port uint) error {
 	if port == 0 {
 		port = constants.SSHTCPPort
 	}
-	if h.Connection != nil {
+	if h.Connected() {
 		return nil
 	}
+	h.Connection = nil
 	var err error
-	for i := 0; h.Connection == nil && i < sshConnectionRetries; i++ {
-		h.Connection, err = NewHostConnection(h, port)
-	}
+	h.Connection, err = sshPool.connect(h, port)
 	if err != nil {
 		return fmt.Errorf("failed to connect to host %s: %w", h.IP, err)
 	}
 	return nil
 }
 
+// Connected tells if the host has a connection, which is not the case once its pooled
+// connection is evicted.
 func (h *Host) Connected() bool {
-	return h.Connection != nil
+	return h.Connection != nil && !sshPool.stale(h.Connection)
 }
 
+// Disconnect releases the host connection, which is kept open for reuse if pooled.
 func (h *Host) Disconnect() error {
-	if h.Connection == nil {
+	if !h.Connected() {
+		// an evicted pooled connection is already closed
+		h.Connection = nil
 		return nil
 	}
-	err := h.Connection.Close()
+	err := sshPool.release(h.Connection)
+	h.Connection = nil
 	return err
 }
 
@@ -112,6 +118,7 @@ func (h *Host) Upload(localFile string, remoteFile string, timeout time.Duration
 			return err
 		}
 	}
+	defer sshPool.acquire()()
 	_, err := utils.TimedFunction(
 		func() (interface{}, error) {
 			return nil, h.Connection.Upload(localFile, remoteFile)
@@ -135,6 +142,7 @@ func (h *Host) Download(remoteFile string, localFile string, timeout time.Durati
 	if err := os.MkdirAll(filepath.Dir(localFile), os.ModePerm); err != nil {
 		return err
 	}
+	defer sshPool.acquire()()
 	_, err := utils.TimedFunction(
 		func() (interface{}, error) {
 			return nil, h.Connection.Download(remoteFile, localFile)
@@ -155,6 +163,7 @@ func (h *Host) MkdirAll(remoteDir string, timeout time.Duration) error {
 			return err
 		}
 	}
+	defer sshPool.acquire()()
 	_, err := utils.TimedFunction(
 		func() (interface{}, error) {
 			return nil, h.UntimedMkdirAll(remoteDir)
@@ -191,6 +200,7 @@ func (h *Host) Command(script string, env []string, timeout time.Duration) ([]by
 			return nil, err
 		}
 	}
+	defer sshPool.acquire()()
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	cmd, err := h.Connection.CommandContext(ctx, "", script)
@@ -210,6 +220,7 @@ func (h *Host) Forward(httpRequest string, timeout time.Duration) ([]byte, error
 			return nil, err
 		}
 	}
+	defer sshPool.acquire()()
 	retI, err := utils.TimedFunction(
 		func() (interface{}, error) {
 			return h.UntimedForward(httpRequest)
@@ -285,6 +296,8 @@ func HostCloudIDToAnsibleID(cloudService string, hostCloudID string) (string, er
 		return fmt.Sprintf("%s_%s", constants.AWSNodeAnsiblePrefix, hostCloudID), nil
 	case constants.E2EDocker:
 		return fmt.Sprintf("%s_%s", constants.E2EDocker, hostCloudID), nil
+	case constants.ExistingHostsCloudService:
+		return fmt.Sprintf("%s_%s", constants.ExistingHostAnsiblePrefix, hostCloudID), nil
 	}
 	return "", fmt.Errorf("unknown cloud service %s", cloudService)
 }
@@ -299,6 +312,9 @@ func HostAnsibleIDToCloudID(hostAnsibleID string) (string, string, error) {
 	case strings.HasPrefix(hostAnsibleID, constants.GCPNodeAnsiblePrefix):
 		cloudService = constants.GCPCloudService
 		cloudIDPrefix = strings.TrimPrefix(hostAnsibleID, constants.GCPNodeAnsiblePrefix+"_")
+	case strings.HasPrefix(hostAnsibleID, constants.ExistingHostAnsiblePrefix):
+		cloudService = constants.ExistingHostsCloudService
+		cloudIDPrefix = strings.TrimPrefix(hostAnsibleID, constants.ExistingHostAnsiblePrefix+"_")
 	case strings.HasPrefix(hostAnsibleID, constants.E2EDocker):
 		cloudService = constants.E2EDocker
 		cloudIDPrefix = strings.TrimPrefix(hostAnsibleID, constants.E2EDocker+"_")
@@ -308,6 +324,11 @@ func HostAnsibleIDToCloudID(hostAnsibleID string) (string, string, error) {
 	return cloudService, cloudIDPrefix, nil
 }
 
+// IsDocker checks if the host is a local docker container
+func (h *Host) IsDocker() bool {
+	return strings.HasPrefix(h.NodeID, constants.DockerCloudService+"_")
+}
+
 // WaitForSSHPort waits for the SSH port to become available on the host.
 func (h *Host) WaitForSSHPort(port uint, timeout time.Duration) error {
 	if port == 0 {
@@ -363,6 +384,7 @@ func (h *Host) StreamSSHCommand(command string, env []string, timeout time.Durat
 		}
 	}
 
+	defer sshPool.acquire()()
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
@@ -414,6 +436,41 @@ func (h *Host) StreamSSHCommand(command string, env []string, timeout time.Durat
 	return nil
 }
 
+// PipeSSHCommand runs an SSH command on the host feeding [stdin] to it, if given, and
+// writing its output to [stdout]. Meant to move large streams, such as database snapshots,
+// without storing them on the host.
+func (h *Host) PipeSSHCommand(command string, stdin io.Reader, stdout io.Writer, timeout time.Duration) error {
+	if !h.Connected() {
+		if err := h.Connect(0); err != nil {
+			return err
+		}
+	}
+	defer sshPool.acquire()()
+	session, err := h.Connection.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	var stderr bytes.Buffer
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = &stderr
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		// unblocks session.Run on timeout
+		_ = session.Close()
+	}()
+	if err := session.Run(command); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return fmt.Errorf("failed to run command %s on host %s: %w: %s", command, h.IP, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
 func consumeOutput(ctx context.Context, output io.Reader) error {
 	scanner := bufio.NewScanner(output)
 	for scanner.Scan() {
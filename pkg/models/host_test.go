@@ -207,6 +207,19 @@ func hostRunTest(t *testing.T) {
 		t.Fatal(err)
 	}
 	assert.FileExists("/tmp/test/streamtest")
+	// pooled connection is reused by other hosts with the same address
+	connection := host.Connection
+	assert.NoError(host.Disconnect())
+	assert.False(host.Connected())
+	sameHost := *host
+	assert.NoError(sameHost.Connect(sshPort))
+	assert.Same(connection, sameHost.Connection)
+	CloseSSHConnections()
+	// hosts holding an evicted connection connect again
+	assert.False(sameHost.Connected())
+	assert.NoError(sameHost.Connect(sshPort))
+	assert.NotSame(connection, sameHost.Connection)
+	assert.NoError(sameHost.Disconnect())
 
 	// bad connection
 	if err := brokenHost.Connect(sshPort); err == nil {
@@ -268,3 +281,13 @@ func stopSSHServer() {
 		close(stopChan) // Close the stop channel to signal stopping the server goroutine
 	}
 }
+
+func TestHostIsDocker(t *testing.T) {
+	require := require.New(t)
+	dockerID, err := HostCloudIDToAnsibleID(constants.DockerCloudService, "docker1-abcde")
+	require.NoError(err)
+	require.True((&Host{NodeID: dockerID}).IsDocker())
+	awsID, err := HostCloudIDToAnsibleID(constants.AWSCloudService, "i-0123456789")
+	require.NoError(err)
+	require.False((&Host{NodeID: awsID}).IsDocker())
+}
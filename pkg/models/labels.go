@@ -0,0 +1,110 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var labelKeyRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_./-]*[A-Za-z0-9])?$`)
+
+type labelOperator int
+
+const (
+	labelEquals labelOperator = iota
+	labelNotEquals
+	labelExists
+	labelNotExists
+)
+
+type labelRequirement struct {
+	key      string
+	operator labelOperator
+	value    string
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.operator {
+	case labelEquals:
+		return ok && value == r.value
+	case labelNotEquals:
+		return !ok || value != r.value
+	case labelExists:
+		return ok
+	default:
+		return !ok
+	}
+}
+
+// LabelSelector selects nodes by their labels. All of its requirements must hold
+type LabelSelector []labelRequirement
+
+// ParseLabelSelector parses a comma separated list of requirements, each of them one of
+// key=value, key!=value, key (the label is set) or !key (the label is not set)
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	labelSelector := LabelSelector{}
+	if strings.TrimSpace(selector) == "" {
+		return labelSelector, nil
+	}
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		requirement := labelRequirement{}
+		switch {
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			requirement = labelRequirement{key: parts[0], operator: labelNotEquals, value: parts[1]}
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(strings.Replace(term, "==", "=", 1), "=", 2)
+			requirement = labelRequirement{key: parts[0], operator: labelEquals, value: parts[1]}
+		case strings.HasPrefix(term, "!"):
+			requirement = labelRequirement{key: strings.TrimPrefix(term, "!"), operator: labelNotExists}
+		default:
+			requirement = labelRequirement{key: term, operator: labelExists}
+		}
+		requirement.key = strings.TrimSpace(requirement.key)
+		requirement.value = strings.TrimSpace(requirement.value)
+		if !labelKeyRegex.MatchString(requirement.key) {
+			return nil, fmt.Errorf("invalid selector %q: invalid label key %q", selector, requirement.key)
+		}
+		labelSelector = append(labelSelector, requirement)
+	}
+	return labelSelector, nil
+}
+
+// Matches tells if the given labels satisfy all the selector requirements
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabelChanges parses label arguments of the form key=value, setting the label,
+// or key-, removing it
+func ParseLabelChanges(args []string) (map[string]string, []string, error) {
+	set := map[string]string{}
+	remove := []string{}
+	for _, arg := range args {
+		key, value, isSet := strings.Cut(arg, "=")
+		if !isSet {
+			if !strings.HasSuffix(arg, "-") {
+				return nil, nil, fmt.Errorf("invalid label %q, expected key=value or key-", arg)
+			}
+			key = strings.TrimSuffix(arg, "-")
+		}
+		if !labelKeyRegex.MatchString(key) {
+			return nil, nil, fmt.Errorf("invalid label key %q", key)
+		}
+		if isSet {
+			set[key] = value
+		} else {
+			remove = append(remove, key)
+		}
+	}
+	return set, remove, nil
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelSelector(t *testing.T) {
+	require := require.New(t)
+	labels := map[string]string{"tier": "canary", "region": "eu-west-1"}
+
+	selector, err := ParseLabelSelector("")
+	require.NoError(err)
+	require.True(selector.Matches(labels))
+	require.True(selector.Matches(nil))
+
+	selector, err = ParseLabelSelector("tier=canary, region!=us-east-1")
+	require.NoError(err)
+	require.True(selector.Matches(labels))
+	require.False(selector.Matches(map[string]string{"tier": "canary", "region": "us-east-1"}))
+	require.False(selector.Matches(nil))
+
+	selector, err = ParseLabelSelector("tier==canary,team")
+	require.NoError(err)
+	require.False(selector.Matches(labels))
+
+	selector, err = ParseLabelSelector("!team,region")
+	require.NoError(err)
+	require.True(selector.Matches(labels))
+
+	_, err = ParseLabelSelector("tier=canary,")
+	require.Error(err)
+	_, err = ParseLabelSelector("bad key=x")
+	require.Error(err)
+}
+
+func TestParseLabelChanges(t *testing.T) {
+	require := require.New(t)
+	set, remove, err := ParseLabelChanges([]string{"tier=canary", "team=", "region-"})
+	require.NoError(err)
+	require.Equal(map[string]string{"tier": "canary", "team": ""}, set)
+	require.Equal([]string{"region"}, remove)
+
+	_, _, err = ParseLabelChanges([]string{"tier"})
+	require.Error(err)
+	_, _, err = ParseLabelChanges([]string{"-=x"})
+	require.Error(err)
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package models
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+)
+
+// LocalNetworkInstance identifies one of the local networks that can run side by side
+// on the same machine. The default instance (index 0) uses the historical ports and dirs.
+type LocalNetworkInstance struct {
+	Name  string
+	Index int
+}
+
+var (
+	DefaultLocalNetworkInstance = LocalNetworkInstance{Name: constants.DefaultLocalNetworkInstance}
+
+	currentLocalNetworkInstanceLock sync.RWMutex
+	currentLocalNetworkInstance     = DefaultLocalNetworkInstance
+)
+
+// SetCurrentLocalNetworkInstance selects the instance all local network operations
+// of this process are going to work on
+func SetCurrentLocalNetworkInstance(instance LocalNetworkInstance) {
+	currentLocalNetworkInstanceLock.Lock()
+	defer currentLocalNetworkInstanceLock.Unlock()
+	currentLocalNetworkInstance = instance
+}
+
+// CurrentLocalNetworkInstance returns the instance selected for this process
+func CurrentLocalNetworkInstance() LocalNetworkInstance {
+	currentLocalNetworkInstanceLock.RLock()
+	defer currentLocalNetworkInstanceLock.RUnlock()
+	return currentLocalNetworkInstance
+}
+
+func (i LocalNetworkInstance) IsDefault() bool {
+	return i.Index == 0
+}
+
+// PortOffset is the amount added to every default local network port for this instance
+func (i LocalNetworkInstance) PortOffset() int {
+	return i.Index * constants.LocalNetworkInstancePortStride
+}
+
+func (i LocalNetworkInstance) GRPCServerPort() int {
+	return constants.LocalNetworkGRPCServerPort + i.PortOffset()
+}
+
+func (i LocalNetworkInstance) GRPCGatewayPort() int {
+	return constants.LocalNetworkGRPCGatewayPort + i.PortOffset()
+}
+
+func (i LocalNetworkInstance) GRPCServerEndpoint() string {
+	return fmt.Sprintf("localhost:%d", i.GRPCServerPort())
+}
+
+// APIPort is the API port of the first node of the instance
+func (i LocalNetworkInstance) APIPort() int {
+	return constants.AvalanchegoAPIPort + i.PortOffset()
+}
+
+// NodePortRange returns the first and last ports used by the instance nodes
+func (i LocalNetworkInstance) NodePortRange() (int, int) {
+	return i.APIPort(), i.APIPort() + constants.LocalNetworkNumNodePorts - 1
+}
+
+func (i LocalNetworkInstance) Endpoint() string {
+	return fmt.Sprintf("http://127.0.0.1:%d", i.APIPort())
+}
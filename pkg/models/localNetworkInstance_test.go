@@ -0,0 +1,37 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalNetworkInstancePorts(t *testing.T) {
+	require := require.New(t)
+	require.Equal(constants.LocalAPIEndpoint, DefaultLocalNetworkInstance.Endpoint())
+	require.Equal("localhost:8097", DefaultLocalNetworkInstance.GRPCServerEndpoint())
+
+	instance := LocalNetworkInstance{Name: "feature", Index: 2}
+	require.Equal(8297, instance.GRPCServerPort())
+	require.Equal(8298, instance.GRPCGatewayPort())
+	require.Equal("http://127.0.0.1:9850", instance.Endpoint())
+	firstPort, lastPort := instance.NodePortRange()
+	require.Equal(9850, firstPort)
+	require.Equal(9859, lastPort)
+}
+
+func TestLocalNetworkNameForInstance(t *testing.T) {
+	require := require.New(t)
+	defer SetCurrentLocalNetworkInstance(DefaultLocalNetworkInstance)
+
+	require.Equal(Local.String(), NewLocalNetwork().Name())
+
+	SetCurrentLocalNetworkInstance(LocalNetworkInstance{Name: "feature", Index: 1})
+	network := NewLocalNetwork()
+	require.Equal(Local.String()+" feature", network.Name())
+	require.Equal("http://127.0.0.1:9750", network.Endpoint)
+}
@@ -37,10 +37,11 @@ func (nk NetworkKind) String() string {
 }
 
 type Network struct {
-	Kind        NetworkKind
-	ID          uint32
-	Endpoint    string
-	ClusterName string
+	Kind          NetworkKind
+	ID            uint32
+	Endpoint      string
+	ClusterName   string
+	LocalInstance string `json:",omitempty"`
 }
 
 var UndefinedNetwork = Network{}
@@ -54,8 +55,18 @@ func NewNetwork(kind NetworkKind, id uint32, endpoint string, clusterName string
 	}
 }
 
+// NewLocalNetwork returns the local network for the currently selected local network instance
 func NewLocalNetwork() Network {
-	return NewNetwork(Local, constants.LocalNetworkID, constants.LocalAPIEndpoint, "")
+	return NewLocalInstanceNetwork(CurrentLocalNetworkInstance())
+}
+
+// NewLocalInstanceNetwork returns the local network of the given local network instance
+func NewLocalInstanceNetwork(instance LocalNetworkInstance) Network {
+	network := NewNetwork(Local, constants.LocalNetworkID, instance.Endpoint(), "")
+	if !instance.IsDefault() {
+		network.LocalInstance = instance.Name
+	}
+	return network
 }
 
 func NewDevnetNetwork(endpoint string, id uint32) Network {
@@ -100,6 +111,9 @@ func (n Network) Name() string {
 	if n.Kind == Devnet {
 		name += " " + n.Endpoint
 	}
+	if n.Kind == Local && n.LocalInstance != "" {
+		name += " " + n.LocalInstance
+	}
 	return name
 }
 
@@ -153,8 +167,10 @@ func (n Network) GenesisParams() *genesis.Params {
 func (n *Network) HandlePublicNetworkSimulation() {
 	// used in E2E to simulate public network execution paths on a local network
 	if os.Getenv(constants.SimulatePublicNetwork) != "" {
+		localNetwork := NewLocalNetwork()
 		n.Kind = Local
-		n.ID = constants.LocalNetworkID
-		n.Endpoint = constants.LocalAPIEndpoint
+		n.ID = localNetwork.ID
+		n.Endpoint = localNetwork.Endpoint
+		n.LocalInstance = localNetwork.LocalInstance
 	}
 }
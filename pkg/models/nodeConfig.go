@@ -3,16 +3,29 @@
 package models
 
 type NodeConfig struct {
-	NodeID        string // instance id on cloud server
-	Region        string // region where cloud server instance is deployed
-	AMI           string // image id for cloud server dependent on its os (e.g. ubuntu )and region deployed (e.g. us-east-1)
-	KeyPair       string // key pair name used on cloud server
-	CertPath      string // where the cert is stored in user's local machine ssh directory
-	SecurityGroup string // security group used on cloud server
-	ElasticIP     string // public IP address of the cloud server
-	CloudService  string // which cloud service node is hosted on (AWS / GCP)
-	UseStaticIP   bool   // node has a static IP association
-	IsMonitor     bool   // node has a monitoring dashboard
-	IsAWMRelayer  bool   // node has an AWM relayer service
-	IsLoadTest    bool   // node is used to host load test
+	NodeID           string // instance id on cloud server
+	Region           string // region where cloud server instance is deployed
+	AMI              string // image id for cloud server dependent on its os (e.g. ubuntu )and region deployed (e.g. us-east-1)
+	KeyPair          string // key pair name used on cloud server
+	CertPath         string // where the cert is stored in user's local machine ssh directory
+	SecurityGroup    string // security group used on cloud server
+	ElasticIP        string // public IP address of the cloud server
+	CloudService     string // which cloud service node is hosted on (AWS / GCP)
+	UseStaticIP      bool   // node has a static IP association
+	IsMonitor        bool   // node has a monitoring dashboard
+	IsAWMRelayer     bool   // node has an AWM relayer service
+	IsLoadTest       bool   // node is used to host load test
+	IsSpot           bool   // node runs on an AWS spot instance that may be interrupted
+	SpotMaxPrice     string // max hourly price in USD of the spot instance, empty for the on-demand price
+	InstanceType     string // cloud instance type, if known
+	VolumeType       string // cloud volume type, if known
+	VolumeSize       int    // cloud volume size in GB, if known
+	VolumeIOPS       int    // provisioned volume iops, if known
+	VolumeThroughput int    // provisioned volume throughput in MiB/s, if known
+	// AvalancheGoVersion is the avalanchego version the node was provisioned with
+	AvalancheGoVersion string
+	// ProvisionedSteps lists the provisioning steps completed on the node
+	ProvisionedSteps []string
+	// Labels are the user key=value labels of the node, used to select it with --selector
+	Labels map[string]string
 }
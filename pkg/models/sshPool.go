@@ -0,0 +1,287 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/melbahja/goph"
+)
+
+const (
+	DefaultSSHMaxParallel  = 10
+	DefaultSSHRetries      = 5
+	DefaultSSHRetryBackoff = 500 * time.Millisecond
+	DefaultSSHKeepAlive    = 30 * time.Second
+	maxSSHRetryBackoff     = 10 * time.Second
+	sshKeepAliveRequest    = "keepalive@openssh.com"
+)
+
+// SSHSettings configures how hosts are connected to
+type SSHSettings struct {
+	// MaxParallel bounds the concurrent connection attempts and remote operations
+	// over all hosts
+	MaxParallel int
+	// Retries is the number of connection attempts made on transient dial errors
+	Retries int
+	// RetryBackoff is the wait before the first retry, doubled on each following one
+	RetryBackoff time.Duration
+	// KeepAlive is the interval between keepalive requests on pooled connections.
+	// Zero disables them
+	KeepAlive time.Duration
+	// Reuse keeps connections open on Disconnect, so that later steps of the same
+	// command connecting to the host reuse them
+	Reuse bool
+}
+
+func DefaultSSHSettings() SSHSettings {
+	return SSHSettings{
+		MaxParallel:  DefaultSSHMaxParallel,
+		Retries:      DefaultSSHRetries,
+		RetryBackoff: DefaultSSHRetryBackoff,
+		KeepAlive:    DefaultSSHKeepAlive,
+		Reuse:        true,
+	}
+}
+
+type pooledConnection struct {
+	client *goph.Client
+	stop   chan struct{}
+}
+
+// sshConnectionPool holds the open host connections, keyed by user, address,
+// port and key, and limits the parallel work done over them
+type sshConnectionPool struct {
+	lock        sync.Mutex
+	settings    SSHSettings
+	connections map[string]*pooledConnection
+	dialSlots   chan struct{}
+	opSlots     chan struct{}
+}
+
+var sshPool = newSSHConnectionPool(DefaultSSHSettings())
+
+func newSSHConnectionPool(settings SSHSettings) *sshConnectionPool {
+	settings.MaxParallel = max(settings.MaxParallel, 1)
+	settings.Retries = max(settings.Retries, 1)
+	return &sshConnectionPool{
+		settings:    settings,
+		connections: map[string]*pooledConnection{},
+		dialSlots:   make(chan struct{}, settings.MaxParallel),
+		opSlots:     make(chan struct{}, settings.MaxParallel),
+	}
+}
+
+// SetSSHSettings replaces the SSH settings, closing the connections opened so far
+func SetSSHSettings(settings SSHSettings) {
+	CloseSSHConnections()
+	sshPool = newSSHConnectionPool(settings)
+}
+
+func GetSSHSettings() SSHSettings {
+	return sshPool.settings
+}
+
+// CloseSSHConnections closes all the pooled host connections
+func CloseSSHConnections() {
+	sshPool.closeAll()
+}
+
+func sshPoolKey(h *Host, port uint) string {
+	return fmt.Sprintf("%s@%s:%d/%s", h.SSHUser, h.IP, port, h.SSHPrivateKeyPath)
+}
+
+// connect returns a pooled connection to the host if there is a live one, or
+// dials a new one
+func (p *sshConnectionPool) connect(h *Host, port uint) (*goph.Client, error) {
+	key := sshPoolKey(h, port)
+	if p.settings.Reuse {
+		if client := p.get(key); client != nil {
+			return client, nil
+		}
+	}
+	client, err := p.dial(h, port)
+	if err != nil {
+		return nil, err
+	}
+	if p.settings.Reuse {
+		client = p.put(key, client)
+	}
+	return client, nil
+}
+
+// release closes [client] unless it is pooled
+func (p *sshConnectionPool) release(client *goph.Client) error {
+	if p.settings.Reuse {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		for _, conn := range p.connections {
+			if conn.client == client {
+				return nil
+			}
+		}
+	}
+	return client.Close()
+}
+
+func (p *sshConnectionPool) get(key string) *goph.Client {
+	p.lock.Lock()
+	conn, ok := p.connections[key]
+	p.lock.Unlock()
+	if !ok {
+		return nil
+	}
+	// the host may have been rebooted since the connection was opened
+	if err := sendKeepAlive(conn.client, sshConnectionTimeout); err != nil {
+		p.evict(key, conn)
+		return nil
+	}
+	return conn.client
+}
+
+// stale tells if [client] was pooled and has since been evicted or closed, so that
+// hosts holding it need to connect again
+func (p *sshConnectionPool) stale(client *goph.Client) bool {
+	if !p.settings.Reuse {
+		return false
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, conn := range p.connections {
+		if conn.client == client {
+			return false
+		}
+	}
+	return true
+}
+
+// sendKeepAlive probes [client], failing if the host doesn't reply within [timeout], as
+// requests on a dead connection only fail once the TCP connection times out
+func sendKeepAlive(client *goph.Client, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest(sshKeepAliveRequest, true, nil)
+		errCh <- err
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("ssh keepalive timed out after %s", timeout)
+	}
+}
+
+// put pools [client] for [key], returning the already pooled client instead if
+// another caller connected to the same host meanwhile
+func (p *sshConnectionPool) put(key string, client *goph.Client) *goph.Client {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if conn, ok := p.connections[key]; ok {
+		_ = client.Close()
+		return conn.client
+	}
+	conn := &pooledConnection{client: client, stop: make(chan struct{})}
+	p.connections[key] = conn
+	if p.settings.KeepAlive > 0 {
+		go p.keepAlive(key, conn)
+	}
+	return client
+}
+
+func (p *sshConnectionPool) keepAlive(key string, conn *pooledConnection) {
+	ticker := time.NewTicker(p.settings.KeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.stop:
+			return
+		case <-ticker.C:
+			if err := sendKeepAlive(conn.client, sshConnectionTimeout); err != nil {
+				p.evict(key, conn)
+				return
+			}
+		}
+	}
+}
+
+func (p *sshConnectionPool) evict(key string, conn *pooledConnection) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.connections[key] != conn {
+		return
+	}
+	delete(p.connections, key)
+	close(conn.stop)
+	_ = conn.client.Close()
+}
+
+func (p *sshConnectionPool) closeAll() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for key, conn := range p.connections {
+		delete(p.connections, key)
+		close(conn.stop)
+		_ = conn.client.Close()
+	}
+}
+
+// dial connects to the host, retrying with exponential backoff on transient errors
+func (p *sshConnectionPool) dial(h *Host, port uint) (*goph.Client, error) {
+	p.dialSlots <- struct{}{}
+	defer func() { <-p.dialSlots }()
+	backoff := p.settings.RetryBackoff
+	var err error
+	for i := 0; i < p.settings.Retries; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff = min(2*backoff, maxSSHRetryBackoff)
+		}
+		var client *goph.Client
+		client, err = NewHostConnection(h, port)
+		if err == nil {
+			return client, nil
+		}
+		if !isTransientSSHError(err) {
+			break
+		}
+	}
+	return nil, err
+}
+
+// acquire waits for a free slot to run a remote operation. The returned function
+// releases it
+func (p *sshConnectionPool) acquire() func() {
+	p.opSlots <- struct{}{}
+	return func() { <-p.opSlots }
+}
+
+// isTransientSSHError tells if a connection error may go away on retry, such as
+// timeouts, refused connections while sshd starts, or connections dropped by sshd
+// due to its MaxStartups limit
+func isTransientSSHError(err error) bool {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &netErr) && netErr.Timeout(),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EHOSTUNREACH),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+	// the ssh handshake does not wrap the underlying errors
+	msg := err.Error()
+	return strings.Contains(msg, "handshake failed: EOF") ||
+		strings.Contains(msg, "connection reset by peer") ||
+		strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "i/o timeout")
+}
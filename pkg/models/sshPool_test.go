@@ -0,0 +1,126 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/melbahja/goph"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestIsTransientSSHError(t *testing.T) {
+	require := require.New(t)
+	require.False(isTransientSSHError(nil))
+	require.True(isTransientSSHError(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}))
+	require.True(isTransientSSHError(fmt.Errorf("dial: %w", syscall.ECONNRESET)))
+	require.True(isTransientSSHError(errors.New("ssh: handshake failed: EOF")))
+	require.False(isTransientSSHError(errors.New("ssh: handshake failed: ssh: unable to authenticate")))
+}
+
+func TestSSHPoolLimits(t *testing.T) {
+	require := require.New(t)
+	pool := newSSHConnectionPool(SSHSettings{MaxParallel: 2})
+	require.Equal(1, pool.settings.Retries)
+	release1 := pool.acquire()
+	release2 := pool.acquire()
+	acquired := make(chan struct{})
+	go func() {
+		defer pool.acquire()()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired more slots than allowed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	release1()
+	<-acquired
+	release2()
+}
+
+func TestSSHPoolDialRetries(t *testing.T) {
+	require := require.New(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	port := uint(listener.Addr().(*net.TCPAddr).Port)
+	require.NoError(listener.Close())
+	keyPath := filepath.Join(t.TempDir(), "key")
+	require.NoError(os.WriteFile(keyPath, privateBytes, 0o600))
+	pool := newSSHConnectionPool(SSHSettings{MaxParallel: 1, Retries: 3, RetryBackoff: 50 * time.Millisecond})
+	// refused connections are retried with backoff
+	start := time.Now()
+	_, err = pool.dial(&Host{IP: "127.0.0.1", SSHUser: "ubuntu", SSHPrivateKeyPath: keyPath}, port)
+	require.ErrorIs(err, syscall.ECONNREFUSED)
+	require.GreaterOrEqual(time.Since(start), 150*time.Millisecond)
+	// a missing key is not
+	start = time.Now()
+	_, err = pool.dial(&Host{IP: "127.0.0.1", SSHUser: "ubuntu", SSHPrivateKeyPath: "/nonexistent"}, port)
+	require.Error(err)
+	require.Less(time.Since(start), 50*time.Millisecond)
+}
+
+// newUnresponsiveSSHClient returns a client connected to an ssh server that never
+// replies to requests, as a host that stopped responding
+func newUnresponsiveSSHClient(t *testing.T) *goph.Client {
+	require := require.New(t)
+	signer, err := ssh.ParsePrivateKey(privateBytes)
+	require.NoError(err)
+	serverConfig := &ssh.ServerConfig{NoClientAuth: true}
+	serverConfig.AddHostKey(signer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	t.Cleanup(func() { _ = listener.Close() })
+	serverConns := make(chan net.Conn, 1)
+	go func() {
+		serverConn, err := listener.Accept()
+		if err != nil {
+			close(serverConns)
+			return
+		}
+		serverConns <- serverConn
+		// requests are left unread
+		_, _, _, _ = ssh.NewServerConn(serverConn, serverConfig)
+	}()
+	client, err := ssh.Dial("tcp", listener.Addr().String(), &ssh.ClientConfig{
+		User: "ubuntu",
+		// #nosec G106
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	require.NoError(err)
+	t.Cleanup(func() {
+		_ = client.Close()
+		if serverConn, ok := <-serverConns; ok {
+			_ = serverConn.Close()
+		}
+	})
+	return &goph.Client{Client: client}
+}
+
+func TestSSHPoolKeepAliveTimeout(t *testing.T) {
+	require := require.New(t)
+	client := newUnresponsiveSSHClient(t)
+	start := time.Now()
+	require.ErrorContains(sendKeepAlive(client, 100*time.Millisecond), "timed out")
+	require.Less(time.Since(start), time.Second)
+}
+
+func TestSSHPoolStaleConnection(t *testing.T) {
+	require := require.New(t)
+	pool := newSSHConnectionPool(SSHSettings{Reuse: true})
+	client := newUnresponsiveSSHClient(t)
+	require.Same(client, pool.put("key", client))
+	require.False(pool.stale(client))
+	pool.evict("key", pool.connections["key"])
+	require.True(pool.stale(client))
+	// connections not pooled are owned by their host
+	require.False(newSSHConnectionPool(SSHSettings{}).stale(client))
+}
@@ -0,0 +1,332 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	alertRulesGroup       = "avalanche-cli"
+	alertReceiverName     = "cluster"
+	criticalSeverity      = "critical"
+	warningSeverity       = "warning"
+	maxBlocksBehind       = 50
+	minDiskFreeRatio      = 0.1
+	minStakeConnectedRate = 0.8
+)
+
+// AlertRule is a Prometheus alerting rule
+type AlertRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr,omitempty"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	// Disabled removes a curated rule when set in a user override
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// AlertChain is a subnet chain watched by the alert rules
+type AlertChain struct {
+	Name         string
+	BlockchainID string
+}
+
+type alertRuleOverrides struct {
+	Rules []AlertRule `yaml:"rules"`
+}
+
+type alertRuleGroup struct {
+	Name  string      `yaml:"name"`
+	Rules []AlertRule `yaml:"rules"`
+}
+
+type alertRuleFile struct {
+	Groups []alertRuleGroup `yaml:"groups"`
+}
+
+// CuratedAlertRules returns the default alert rules for the avalanchego nodes of a
+// cluster, including a falling behind rule for each of the given subnet chains, and
+// a rule for the validations ending within [validationExpiryWarning]
+func CuratedAlertRules(chains []AlertChain, validationExpiryWarning time.Duration) []AlertRule {
+	rules := []AlertRule{
+		{
+			Alert:       "NodeDown",
+			Expr:        `up{job="avalanchego"} == 0`,
+			For:         "5m",
+			Labels:      map[string]string{"severity": criticalSeverity},
+			Annotations: map[string]string{"summary": "Node {{ $labels.instance }} is not reachable"},
+		},
+		{
+			Alert:       "NodeUnhealthy",
+			Expr:        `avalanche_health_checks_failing > 0`,
+			For:         "5m",
+			Labels:      map[string]string{"severity": criticalSeverity},
+			Annotations: map[string]string{"summary": "Node {{ $labels.instance }} is failing {{ $value }} health checks"},
+		},
+		{
+			Alert:       "NodeBootstrapStalled",
+			Expr:        `increase(avalanche_P_bs_fetched[15m]) == 0 and on(instance) avalanche_health_checks_failing > 0`,
+			For:         "15m",
+			Labels:      map[string]string{"severity": criticalSeverity},
+			Annotations: map[string]string{"summary": "Node {{ $labels.instance }} stopped bootstrapping"},
+		},
+		{
+			Alert:       "NodeNoPeers",
+			Expr:        `avalanche_network_peers == 0`,
+			For:         "5m",
+			Labels:      map[string]string{"severity": criticalSeverity},
+			Annotations: map[string]string{"summary": "Node {{ $labels.instance }} has no peers"},
+		},
+		{
+			Alert:       "NodeLowStakeConnected",
+			Expr:        fmt.Sprintf(`avalanche_P_percent_connected < %g`, minStakeConnectedRate),
+			For:         "10m",
+			Labels:      map[string]string{"severity": warningSeverity},
+			Annotations: map[string]string{"summary": "Node {{ $labels.instance }} is connected to {{ $value | humanizePercentage }} of the stake"},
+		},
+		{
+			Alert: "NodeLowDisk",
+			Expr: fmt.Sprintf(
+				`node_filesystem_avail_bytes{job="avalanchego-machine",mountpoint="/"} / node_filesystem_size_bytes{job="avalanchego-machine",mountpoint="/"} < %g`,
+				minDiskFreeRatio,
+			),
+			For:         "10m",
+			Labels:      map[string]string{"severity": warningSeverity},
+			Annotations: map[string]string{"summary": "Host {{ $labels.instance }} has {{ $value | humanizePercentage }} of its disk free"},
+		},
+		{
+			Alert:       "ValidationEndingSoon",
+			Expr:        fmt.Sprintf(`avalanche_cli_validator_end_time_seconds - time() < %d`, int(validationExpiryWarning.Seconds())),
+			Labels:      map[string]string{"severity": warningSeverity},
+			Annotations: map[string]string{"summary": "Validation of {{ $labels.cloud_id }} on {{ $labels.subnet }} ends in {{ $value | humanizeDuration }}"},
+		},
+	}
+	for _, chain := range chains {
+		metric := fmt.Sprintf("avalanche_%s_last_accepted_height", chain.BlockchainID)
+		rules = append(rules, AlertRule{
+			Alert:       fmt.Sprintf("ChainBehind_%s", chain.Name),
+			Expr:        fmt.Sprintf(`scalar(max(%s)) - %s > %d`, metric, metric, maxBlocksBehind),
+			For:         "10m",
+			Labels:      map[string]string{"severity": warningSeverity, "subnet": chain.Name},
+			Annotations: map[string]string{"summary": fmt.Sprintf("Node {{ $labels.instance }} is {{ $value }} blocks behind on %s", chain.Name)},
+		})
+	}
+	return rules
+}
+
+// LoadAlertRuleOverrides loads the user alert rule overrides at [path], if the file exists
+func LoadAlertRuleOverrides(path string) ([]AlertRule, error) {
+	overridesBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	overrides := alertRuleOverrides{}
+	if err := yaml.Unmarshal(overridesBytes, &overrides); err != nil {
+		return nil, fmt.Errorf("invalid alert rules file %s: %w", path, err)
+	}
+	return overrides.Rules, nil
+}
+
+// ApplyAlertRuleOverrides applies the user overrides to the given rules. An override
+// changes the fields it sets on the rule of the same name, removes it if disabled,
+// and adds a new rule otherwise
+func ApplyAlertRuleOverrides(rules []AlertRule, overrides []AlertRule) ([]AlertRule, error) {
+	for _, override := range overrides {
+		if override.Alert == "" {
+			return nil, fmt.Errorf("alert rule override without alert name")
+		}
+		index := -1
+		for i := range rules {
+			if rules[i].Alert == override.Alert {
+				index = i
+				break
+			}
+		}
+		switch {
+		case index == -1 && override.Disabled:
+			continue
+		case index == -1:
+			if override.Expr == "" {
+				return nil, fmt.Errorf("new alert rule %s has no expr", override.Alert)
+			}
+			rules = append(rules, override)
+		case override.Disabled:
+			rules = append(rules[:index], rules[index+1:]...)
+		default:
+			rule := &rules[index]
+			if override.Expr != "" {
+				rule.Expr = override.Expr
+			}
+			if override.For != "" {
+				rule.For = override.For
+			}
+			rule.Labels = mergeAlertRuleMaps(rule.Labels, override.Labels)
+			rule.Annotations = mergeAlertRuleMaps(rule.Annotations, override.Annotations)
+		}
+	}
+	return rules, nil
+}
+
+func mergeAlertRuleMaps(base map[string]string, override map[string]string) map[string]string {
+	merged := map[string]string{}
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// WriteAlertRules writes the given rules as a Prometheus rule file
+func WriteAlertRules(filePath string, rules []AlertRule) error {
+	config, err := yaml.Marshal(alertRuleFile{
+		Groups: []alertRuleGroup{{Name: alertRulesGroup, Rules: rules}},
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, config, constants.WriteReadReadPerms)
+}
+
+// SMTPConfig is the mail server used to send email alerts
+type SMTPConfig struct {
+	Smarthost string `json:"smarthost,omitempty"`
+	From      string `json:"from,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+}
+
+// AlertReceivers are the destinations of the cluster alerts
+type AlertReceivers struct {
+	Webhooks      []string   `json:"webhooks,omitempty"`
+	SlackWebhooks []string   `json:"slackWebhooks,omitempty"`
+	Emails        []string   `json:"emails,omitempty"`
+	SMTP          SMTPConfig `json:"smtp,omitempty"`
+}
+
+func (r AlertReceivers) Validate() error {
+	if len(r.Emails) > 0 && (r.SMTP.Smarthost == "" || r.SMTP.From == "") {
+		return fmt.Errorf("email receivers require the smtp host and from address")
+	}
+	return nil
+}
+
+// LoadAlertReceivers loads the alert receivers saved at [path], if the file exists
+func LoadAlertReceivers(path string) (AlertReceivers, error) {
+	receivers := AlertReceivers{}
+	receiversBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return receivers, nil
+	}
+	if err != nil {
+		return receivers, err
+	}
+	err = json.Unmarshal(receiversBytes, &receivers)
+	return receivers, err
+}
+
+func SaveAlertReceivers(path string, receivers AlertReceivers) error {
+	receiversBytes, err := json.MarshalIndent(receivers, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the file may hold smtp credentials
+	return os.WriteFile(path, receiversBytes, constants.WriteReadUserOnlyPerms)
+}
+
+type alertmanagerWebhook struct {
+	URL          string `yaml:"url"`
+	SendResolved bool   `yaml:"send_resolved"`
+}
+
+type alertmanagerSlack struct {
+	APIURL       string `yaml:"api_url"`
+	SendResolved bool   `yaml:"send_resolved"`
+	Title        string `yaml:"title"`
+	Text         string `yaml:"text"`
+}
+
+type alertmanagerEmail struct {
+	To           string `yaml:"to"`
+	SendResolved bool   `yaml:"send_resolved"`
+}
+
+type alertmanagerReceiver struct {
+	Name           string                `yaml:"name"`
+	WebhookConfigs []alertmanagerWebhook `yaml:"webhook_configs,omitempty"`
+	SlackConfigs   []alertmanagerSlack   `yaml:"slack_configs,omitempty"`
+	EmailConfigs   []alertmanagerEmail   `yaml:"email_configs,omitempty"`
+}
+
+type alertmanagerRoute struct {
+	Receiver       string   `yaml:"receiver"`
+	GroupBy        []string `yaml:"group_by"`
+	GroupWait      string   `yaml:"group_wait"`
+	GroupInterval  string   `yaml:"group_interval"`
+	RepeatInterval string   `yaml:"repeat_interval"`
+}
+
+type alertmanagerConfig struct {
+	Global    map[string]string      `yaml:"global,omitempty"`
+	Route     alertmanagerRoute      `yaml:"route"`
+	Receivers []alertmanagerReceiver `yaml:"receivers"`
+}
+
+// WriteAlertmanagerConfig writes an Alertmanager config sending all alerts to the
+// given receivers
+func WriteAlertmanagerConfig(filePath string, receivers AlertReceivers) error {
+	receiver := alertmanagerReceiver{Name: alertReceiverName}
+	for _, url := range receivers.Webhooks {
+		receiver.WebhookConfigs = append(receiver.WebhookConfigs, alertmanagerWebhook{URL: url, SendResolved: true})
+	}
+	for _, url := range receivers.SlackWebhooks {
+		receiver.SlackConfigs = append(receiver.SlackConfigs, alertmanagerSlack{
+			APIURL:       url,
+			SendResolved: true,
+			Title:        `[{{ .Status | toUpper }}] {{ .CommonLabels.alertname }}`,
+			Text:         `{{ range .Alerts }}{{ .Annotations.summary }}{{ "\n" }}{{ end }}`,
+		})
+	}
+	for _, to := range receivers.Emails {
+		receiver.EmailConfigs = append(receiver.EmailConfigs, alertmanagerEmail{To: to, SendResolved: true})
+	}
+	config := alertmanagerConfig{
+		Route: alertmanagerRoute{
+			Receiver:       alertReceiverName,
+			GroupBy:        []string{"alertname", "instance"},
+			GroupWait:      "30s",
+			GroupInterval:  "5m",
+			RepeatInterval: "4h",
+		},
+		Receivers: []alertmanagerReceiver{receiver},
+	}
+	if len(receivers.Emails) > 0 {
+		config.Global = map[string]string{
+			"smtp_smarthost": receivers.SMTP.Smarthost,
+			"smtp_from":      receivers.SMTP.From,
+		}
+		if receivers.SMTP.Username != "" {
+			config.Global["smtp_auth_username"] = receivers.SMTP.Username
+			config.Global["smtp_auth_password"] = receivers.SMTP.Password
+		}
+	}
+	configBytes, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, configBytes, constants.WriteReadUserOnlyPerms)
+}
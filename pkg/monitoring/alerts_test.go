@@ -0,0 +1,128 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package monitoring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func findAlertRule(rules []AlertRule, name string) *AlertRule {
+	for i := range rules {
+		if rules[i].Alert == name {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func TestCuratedAlertRules(t *testing.T) {
+	require := require.New(t)
+	rules := CuratedAlertRules(nil, constants.DefaultValidationExpiryWarning)
+	require.NotNil(findAlertRule(rules, "NodeDown"))
+	require.NotNil(findAlertRule(rules, "NodeLowDisk"))
+	rule := findAlertRule(rules, "ValidationEndingSoon")
+	require.NotNil(rule)
+	require.Equal("avalanche_cli_validator_end_time_seconds - time() < 604800", rule.Expr)
+	require.Nil(findAlertRule(rules, "ChainBehind_mySubnet"))
+
+	rules = CuratedAlertRules([]AlertChain{{Name: "mySubnet", BlockchainID: "2Xyz"}}, 48*time.Hour)
+	rule = findAlertRule(rules, "ChainBehind_mySubnet")
+	require.NotNil(rule)
+	require.Contains(rule.Expr, "avalanche_2Xyz_last_accepted_height")
+	require.Equal("mySubnet", rule.Labels["subnet"])
+	rule = findAlertRule(rules, "ValidationEndingSoon")
+	require.NotNil(rule)
+	require.Equal("avalanche_cli_validator_end_time_seconds - time() < 172800", rule.Expr)
+}
+
+func TestApplyAlertRuleOverrides(t *testing.T) {
+	require := require.New(t)
+	overridesPath := filepath.Join(t.TempDir(), "alert_rules.yml")
+	overrides, err := LoadAlertRuleOverrides(overridesPath)
+	require.NoError(err)
+	require.Nil(overrides)
+
+	require.NoError(os.WriteFile(overridesPath, []byte(`rules:
+  - alert: NodeLowDisk
+    for: 30m
+    labels:
+      team: infra
+  - alert: NodeNoPeers
+    disabled: true
+  - alert: HighCPU
+    expr: cpu > 90
+`), 0o600))
+	overrides, err = LoadAlertRuleOverrides(overridesPath)
+	require.NoError(err)
+	require.Len(overrides, 3)
+
+	rules, err := ApplyAlertRuleOverrides(CuratedAlertRules(nil, constants.DefaultValidationExpiryWarning), overrides)
+	require.NoError(err)
+	lowDisk := findAlertRule(rules, "NodeLowDisk")
+	require.NotNil(lowDisk)
+	require.Equal("30m", lowDisk.For)
+	require.Equal("infra", lowDisk.Labels["team"])
+	require.Equal(warningSeverity, lowDisk.Labels["severity"])
+	require.Nil(findAlertRule(rules, "NodeNoPeers"))
+	require.NotNil(findAlertRule(rules, "HighCPU"))
+
+	_, err = ApplyAlertRuleOverrides(CuratedAlertRules(nil, constants.DefaultValidationExpiryWarning), []AlertRule{{Alert: "NoExpr"}})
+	require.Error(err)
+}
+
+func TestWriteAlertRules(t *testing.T) {
+	require := require.New(t)
+	rulesPath := filepath.Join(t.TempDir(), "rules.yml")
+	require.NoError(WriteAlertRules(rulesPath, CuratedAlertRules(nil, constants.DefaultValidationExpiryWarning)))
+	rulesBytes, err := os.ReadFile(rulesPath)
+	require.NoError(err)
+	ruleFile := alertRuleFile{}
+	require.NoError(yaml.Unmarshal(rulesBytes, &ruleFile))
+	require.Len(ruleFile.Groups, 1)
+	require.Len(ruleFile.Groups[0].Rules, len(CuratedAlertRules(nil, constants.DefaultValidationExpiryWarning)))
+	require.NotContains(string(rulesBytes), "disabled")
+}
+
+func TestAlertReceivers(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	receiversPath := filepath.Join(dir, "alert_receivers.json")
+	receivers, err := LoadAlertReceivers(receiversPath)
+	require.NoError(err)
+	require.Empty(receivers.Webhooks)
+
+	receivers = AlertReceivers{
+		Webhooks:      []string{"https://example.com/hook"},
+		SlackWebhooks: []string{"https://hooks.slack.com/services/x"},
+		Emails:        []string{"ops@example.com"},
+	}
+	require.Error(receivers.Validate())
+	receivers.SMTP = SMTPConfig{Smarthost: "smtp.example.com:587", From: "alerts@example.com"}
+	require.NoError(receivers.Validate())
+	require.NoError(SaveAlertReceivers(receiversPath, receivers))
+	loaded, err := LoadAlertReceivers(receiversPath)
+	require.NoError(err)
+	require.Equal(receivers, loaded)
+
+	configPath := filepath.Join(dir, "alertmanager.yml")
+	require.NoError(WriteAlertmanagerConfig(configPath, receivers))
+	configBytes, err := os.ReadFile(configPath)
+	require.NoError(err)
+	config := alertmanagerConfig{}
+	require.NoError(yaml.Unmarshal(configBytes, &config))
+	require.Equal(alertReceiverName, config.Route.Receiver)
+	require.Len(config.Receivers, 1)
+	require.Len(config.Receivers[0].WebhookConfigs, 1)
+	require.Len(config.Receivers[0].SlackConfigs, 1)
+	require.Len(config.Receivers[0].EmailConfigs, 1)
+	require.Equal("smtp.example.com:587", config.Global["smtp_smarthost"])
+	require.NotContains(config.Global, "smtp_auth_username")
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -17,14 +18,16 @@ import (
 )
 
 type configInputs struct {
-	AvalancheGoPorts string
-	MachinePorts     string
-	LoadTestPorts    string
-	IP               string
-	Port             string
-	Host             string
-	NodeID           string
-	ChainID          string
+	AvalancheGoPorts     string
+	MachinePorts         string
+	LoadTestPorts        string
+	RelayerPorts         string
+	ValidatorMetricsPort string
+	IP                   string
+	Port                 string
+	Host                 string
+	NodeID               string
+	ChainID              string
 }
 
 //go:embed dashboards/*
@@ -77,11 +80,13 @@ func GenerateConfig(configPath string, configDesc string, templateVars configInp
 	return config.String(), nil
 }
 
-func WritePrometheusConfig(filePath string, avalancheGoPorts []string, machinePorts []string, loadTestPorts []string) error {
+func WritePrometheusConfig(filePath string, avalancheGoPorts []string, machinePorts []string, loadTestPorts []string, relayerPorts []string) error {
 	config, err := GenerateConfig("configs/prometheus.yml", "Prometheus Config", configInputs{
-		AvalancheGoPorts: strings.Join(utils.AddSingleQuotes(avalancheGoPorts), ","),
-		MachinePorts:     strings.Join(utils.AddSingleQuotes(machinePorts), ","),
-		LoadTestPorts:    strings.Join(utils.AddSingleQuotes(loadTestPorts), ","),
+		AvalancheGoPorts:     strings.Join(utils.AddSingleQuotes(avalancheGoPorts), ","),
+		MachinePorts:         strings.Join(utils.AddSingleQuotes(machinePorts), ","),
+		LoadTestPorts:        strings.Join(utils.AddSingleQuotes(loadTestPorts), ","),
+		RelayerPorts:         strings.Join(utils.AddSingleQuotes(relayerPorts), ","),
+		ValidatorMetricsPort: strconv.Itoa(constants.ValidatorMetricsPort),
 	})
 	if err != nil {
 		return err
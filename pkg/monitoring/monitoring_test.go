@@ -0,0 +1,44 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package monitoring
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWritePrometheusConfig(t *testing.T) {
+	require := require.New(t)
+	configPath := filepath.Join(t.TempDir(), constants.NodePrometheusConfigFileName)
+	require.NoError(WritePrometheusConfig(configPath, []string{"10.0.0.1:9650"}, []string{"10.0.0.1:9100"}, nil, nil))
+	configBytes, err := os.ReadFile(configPath)
+	require.NoError(err)
+	config := struct {
+		ScrapeConfigs []struct {
+			JobName       string `yaml:"job_name"`
+			StaticConfigs []struct {
+				Targets []string `yaml:"targets"`
+			} `yaml:"static_configs"`
+		} `yaml:"scrape_configs"`
+	}{}
+	require.NoError(yaml.Unmarshal(configBytes, &config))
+	targets := map[string][]string{}
+	for _, scrapeConfig := range config.ScrapeConfigs {
+		for _, staticConfig := range scrapeConfig.StaticConfigs {
+			targets[scrapeConfig.JobName] = append(targets[scrapeConfig.JobName], staticConfig.Targets...)
+		}
+	}
+	require.Equal(map[string][]string{
+		"prometheus":               {"localhost:9090"},
+		"avalanchego":              {"10.0.0.1:9650"},
+		"avalanchego-machine":      {"10.0.0.1:9100"},
+		"avalanche-cli-validators": {fmt.Sprintf("localhost:%d", constants.ValidatorMetricsPort)},
+	}, targets)
+}
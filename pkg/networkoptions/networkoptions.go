@@ -62,12 +62,13 @@ func networkOptionFromString(s string) NetworkOption {
 }
 
 type NetworkFlags struct {
-	UseLocal    bool
-	UseDevnet   bool
-	UseTahoe    bool
-	UseMainnet  bool
-	Endpoint    string
-	ClusterName string
+	UseLocal      bool
+	UseDevnet     bool
+	UseTahoe      bool
+	UseMainnet    bool
+	Endpoint      string
+	ClusterName   string
+	LocalInstance string
 }
 
 func AddNetworkFlagsToCmd(cmd *cobra.Command, networkFlags *NetworkFlags, alwaysAddEndpoint bool, supportedNetworkOptions []NetworkOption) {
@@ -93,13 +94,29 @@ func AddNetworkFlagsToCmd(cmd *cobra.Command, networkFlags *NetworkFlags, always
 	}
 }
 
+// AddLocalInstanceFlagToCmd adds the flag to select the local network instance
+// used when operating on --local
+func AddLocalInstanceFlagToCmd(cmd *cobra.Command, networkFlags *NetworkFlags) {
+	cmd.Flags().StringVar(
+		&networkFlags.LocalInstance,
+		constants.LocalNetworkInstanceFlag,
+		"",
+		"local network instance to operate on (only with --local)",
+	)
+}
+
 func GetNetworkFromSidecarNetworkName(
 	app *application.Avalanche,
 	networkName string,
 ) (models.Network, error) {
 	switch {
 	case strings.HasPrefix(networkName, Local.String()):
-		return models.NewLocalNetwork(), nil
+		// local networks of named instances are saved as 'Local Network instanceName'
+		instance, err := app.GetLocalNetworkInstance(strings.TrimSpace(strings.TrimPrefix(networkName, Local.String())))
+		if err != nil {
+			return models.UndefinedNetwork, err
+		}
+		return models.NewLocalInstanceNetwork(instance), nil
 	case strings.HasPrefix(networkName, Cluster.String()):
 		parts := strings.Split(networkName, " ")
 		if len(parts) != 2 {
@@ -238,6 +255,9 @@ func GetNetworkFromCmdLineFlags(
 		}
 		return models.UndefinedNetwork, errMsg
 	}
+	if networkFlags.LocalInstance != "" && networkOption != Undefined && networkOption != Local {
+		return models.UndefinedNetwork, fmt.Errorf("--%s can only be used with --local", constants.LocalNetworkInstanceFlag)
+	}
 	// mutual exclusion
 	if !flags.EnsureMutuallyExclusive([]bool{networkFlags.UseLocal, networkFlags.UseDevnet, networkFlags.UseTahoe, networkFlags.UseMainnet, networkFlags.ClusterName != ""}) {
 		return models.UndefinedNetwork, fmt.Errorf("network flags %s are mutually exclusive", supportedNetworksFlags)
@@ -307,6 +327,9 @@ func GetNetworkFromCmdLineFlags(
 	network := models.UndefinedNetwork
 	switch networkOption {
 	case Local:
+		if err := app.SetLocalNetworkInstance(networkFlags.LocalInstance); err != nil {
+			return models.UndefinedNetwork, err
+		}
 		network = models.NewLocalNetwork()
 	case Devnet:
 		networkID := uint32(0)
@@ -0,0 +1,41 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package networkoptions
+
+import (
+	"testing"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNetworkFromSidecarNetworkName(t *testing.T) {
+	require := require.New(t)
+	app := application.New()
+	app.Setup(t.TempDir(), logging.NoLog{}, nil, nil, nil)
+	feature, err := app.GetLocalNetworkInstance("feature")
+	require.NoError(err)
+	// the selected instance must not change the one resolved from the name
+	defer models.SetCurrentLocalNetworkInstance(models.DefaultLocalNetworkInstance)
+	models.SetCurrentLocalNetworkInstance(feature)
+
+	network, err := GetNetworkFromSidecarNetworkName(app, "Local Network")
+	require.NoError(err)
+	require.Equal(models.Local, network.Kind)
+	require.Equal(constants.LocalAPIEndpoint, network.Endpoint)
+	require.Equal("Local Network", network.Name())
+
+	models.SetCurrentLocalNetworkInstance(models.DefaultLocalNetworkInstance)
+	network, err = GetNetworkFromSidecarNetworkName(app, "Local Network feature")
+	require.NoError(err)
+	require.Equal(feature.Endpoint(), network.Endpoint)
+	require.Equal("Local Network feature", network.Name())
+
+	_, err = GetNetworkFromSidecarNetworkName(app, "Local Network bad name")
+	require.Error(err)
+	_, err = GetNetworkFromSidecarNetworkName(app, "Unknown")
+	require.ErrorContains(err, "unsupported network name")
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/MetalBlockchain/metalgo/config"
+)
+
+// ParseConfigPatch builds a node config patch from a list of key=value pairs.
+// Values are parsed as JSON when possible (true, 10, null, [...]), and taken as
+// plain strings otherwise
+func ParseConfigPatch(keyValues []string) (map[string]interface{}, error) {
+	patch := map[string]interface{}{}
+	for _, keyValue := range keyValues {
+		key, value, found := strings.Cut(keyValue, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid config setting %q, expected key=value", keyValue)
+		}
+		var parsedValue interface{}
+		if err := json.Unmarshal([]byte(value), &parsedValue); err != nil {
+			parsedValue = value
+		}
+		patch[key] = parsedValue
+	}
+	return patch, nil
+}
+
+// MergeConfigPatch applies [patch] to [nodeConfig] following JSON merge patch
+// semantics: null values remove keys and objects are merged recursively
+func MergeConfigPatch(nodeConfig map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for key, value := range nodeConfig {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		switch patchValue := value.(type) {
+		case nil:
+			delete(merged, key)
+		case map[string]interface{}:
+			currentValue, _ := merged[key].(map[string]interface{})
+			merged[key] = MergeConfigPatch(currentValue, patchValue)
+		default:
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// ValidateConfigPatch checks that all keys of [patch] are known avalanchego flags
+// and that their values can be parsed as the flag type
+func ValidateConfigPatch(patch map[string]interface{}) error {
+	fs := config.BuildFlagSet()
+	for key, value := range patch {
+		if fs.Lookup(key) == nil {
+			return fmt.Errorf("unknown avalanchego flag %q", key)
+		}
+		var flagValue string
+		switch v := value.(type) {
+		case nil, map[string]interface{}:
+			continue
+		case []interface{}:
+			flagValue = strings.Join(utils.Map(v, formatConfigValue), ",")
+		default:
+			flagValue = formatConfigValue(v)
+		}
+		if err := fs.Set(key, flagValue); err != nil {
+			return fmt.Errorf("invalid value %v for avalanchego flag %q: %w", value, key, err)
+		}
+	}
+	return nil
+}
+
+// formatConfigValue returns a JSON config value as a flag value. Numbers are formatted
+// without exponent, as integer flags can't parse 1e+06
+func formatConfigValue(value interface{}) string {
+	if v, ok := value.(float64); ok {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
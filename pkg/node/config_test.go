@@ -0,0 +1,64 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigPatch(t *testing.T) {
+	require := require.New(t)
+	patch, err := ParseConfigPatch([]string{"log-level=debug", "api-admin-enabled=true", "http-port=9650", "index-enabled=null"})
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"log-level":         "debug",
+		"api-admin-enabled": true,
+		"http-port":         float64(9650),
+		"index-enabled":     nil,
+	}, patch)
+
+	_, err = ParseConfigPatch([]string{"log-level"})
+	require.Error(err)
+	_, err = ParseConfigPatch([]string{"=debug"})
+	require.Error(err)
+}
+
+func TestMergeConfigPatch(t *testing.T) {
+	nodeConfig := map[string]interface{}{
+		"log-level":     "info",
+		"index-enabled": true,
+		"chain-configs": map[string]interface{}{"C": "a", "X": "b"},
+	}
+	merged := MergeConfigPatch(nodeConfig, map[string]interface{}{
+		"log-level":         "debug",
+		"index-enabled":     nil,
+		"api-admin-enabled": true,
+		"chain-configs":     map[string]interface{}{"X": nil, "P": "c"},
+	})
+	require.Equal(t, map[string]interface{}{
+		"log-level":         "debug",
+		"api-admin-enabled": true,
+		"chain-configs":     map[string]interface{}{"C": "a", "P": "c"},
+	}, merged)
+	// original config is left untouched
+	require.Equal(t, "info", nodeConfig["log-level"])
+}
+
+func TestValidateConfigPatch(t *testing.T) {
+	require := require.New(t)
+	require.NoError(ValidateConfigPatch(map[string]interface{}{
+		"log-level":                  "debug",
+		"api-admin-enabled":          true,
+		"http-port":                  float64(9650),
+		"fd-limit":                   float64(1000000),
+		"state-sync-ids":             "",
+		"http-allowed-hosts":         []interface{}{"localhost", "example.com"},
+		"consensus-shutdown-timeout": "10s",
+		"index-enabled":              nil,
+	}))
+	require.ErrorContains(ValidateConfigPatch(map[string]interface{}{"not-a-flag": true}), "unknown avalanchego flag")
+	require.ErrorContains(ValidateConfigPatch(map[string]interface{}{"api-admin-enabled": "maybe"}), "invalid value")
+	require.ErrorContains(ValidateConfigPatch(map[string]interface{}{"http-port": "port"}), "invalid value")
+}
@@ -0,0 +1,233 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package node
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/MetalBlockchain/metalgo/vms/platformvm"
+)
+
+// PrimaryNetworkName names the Primary Network among the subnets a node validates
+const PrimaryNetworkName = "Primary Network"
+
+const validationMetricPrefix = "avalanche_cli_validator_"
+
+// Validation is the current validation of a cluster node on the Primary Network or
+// on a subnet
+type Validation struct {
+	CloudID    string
+	NodeID     ids.NodeID
+	SubnetName string
+	SubnetID   ids.ID
+	Start      time.Time
+	End        time.Time
+	Weight     uint64
+	// Uptime is the uptime percent of the node as seen by the queried API node, when known
+	Uptime *float32
+	// PotentialReward is the reward paid when the validation ends, for permissionless
+	// validations
+	PotentialReward *uint64
+	Delegators      uint64
+}
+
+// NewValidations returns the validations in [validators] of the nodes in [cloudIDs],
+// a map from node ID to the cloud ID of the host running it
+func NewValidations(
+	validators []platformvm.ClientPermissionlessValidator,
+	subnetName string,
+	subnetID ids.ID,
+	cloudIDs map[ids.NodeID]string,
+) []Validation {
+	validations := []Validation{}
+	for _, validator := range validators {
+		cloudID, ok := cloudIDs[validator.NodeID]
+		if !ok {
+			continue
+		}
+		validation := Validation{
+			CloudID:         cloudID,
+			NodeID:          validator.NodeID,
+			SubnetName:      subnetName,
+			SubnetID:        subnetID,
+			Start:           time.Unix(int64(validator.StartTime), 0).UTC(),
+			End:             time.Unix(int64(validator.EndTime), 0).UTC(),
+			Weight:          validator.Weight,
+			Uptime:          validator.Uptime,
+			PotentialReward: validator.PotentialReward,
+			Delegators:      uint64(len(validator.Delegators)),
+		}
+		if validator.DelegatorCount != nil {
+			validation.Delegators = *validator.DelegatorCount
+		}
+		validations = append(validations, validation)
+	}
+	return validations
+}
+
+// Remaining returns the time left at [now] until the validation ends
+func (v Validation) Remaining(now time.Time) time.Duration {
+	if !now.Before(v.End) {
+		return 0
+	}
+	return v.End.Sub(now)
+}
+
+// ExpiresWithin tells if the validation ends within [threshold] from [now]
+func (v Validation) ExpiresWithin(now time.Time, threshold time.Duration) bool {
+	return v.Remaining(now) <= threshold
+}
+
+// SortValidations orders [validations] by cloud ID, with the Primary Network validation
+// of each node first and then its subnets by name
+func SortValidations(validations []Validation) {
+	sort.SliceStable(validations, func(i, j int) bool {
+		a, b := validations[i], validations[j]
+		if a.CloudID != b.CloudID {
+			return a.CloudID < b.CloudID
+		}
+		if (a.SubnetID == ids.Empty) != (b.SubnetID == ids.Empty) {
+			return a.SubnetID == ids.Empty
+		}
+		return a.SubnetName < b.SubnetName
+	})
+}
+
+// FindValidation returns the validation of the node at [cloudID] on [subnetID], if any
+func FindValidation(validations []Validation, cloudID string, subnetID ids.ID) (Validation, bool) {
+	for _, validation := range validations {
+		if validation.CloudID == cloudID && validation.SubnetID == subnetID {
+			return validation, true
+		}
+	}
+	return Validation{}, false
+}
+
+type validationMetric struct {
+	name  string
+	help  string
+	value func(Validation) (float64, bool)
+}
+
+var validationMetrics = []validationMetric{
+	{
+		name:  "start_time_seconds",
+		help:  "Unix time the validation started",
+		value: func(v Validation) (float64, bool) { return float64(v.Start.Unix()), true },
+	},
+	{
+		name:  "end_time_seconds",
+		help:  "Unix time the validation ends",
+		value: func(v Validation) (float64, bool) { return float64(v.End.Unix()), true },
+	},
+	{
+		name:  "weight",
+		help:  "Weight of the validator",
+		value: func(v Validation) (float64, bool) { return float64(v.Weight), true },
+	},
+	{
+		name: "uptime_percent",
+		help: "Uptime percent of the validator",
+		value: func(v Validation) (float64, bool) {
+			if v.Uptime == nil {
+				return 0, false
+			}
+			return float64(*v.Uptime), true
+		},
+	},
+	{
+		name: "potential_reward",
+		help: "Reward paid when the validation ends, in nAVAX",
+		value: func(v Validation) (float64, bool) {
+			if v.PotentialReward == nil {
+				return 0, false
+			}
+			return float64(*v.PotentialReward), true
+		},
+	},
+	{
+		name:  "delegators",
+		help:  "Number of delegators of the validator",
+		value: func(v Validation) (float64, bool) { return float64(v.Delegators), true },
+	},
+}
+
+// ValidationMetrics returns [validations] of the nodes of [clusterName] as gauges in the
+// Prometheus text exposition format. The time remaining is end_time_seconds - time()
+func ValidationMetrics(clusterName string, validations []Validation) string {
+	var sb strings.Builder
+	for _, metric := range validationMetrics {
+		name := validationMetricPrefix + metric.name
+		_, _ = sb.WriteString(fmt.Sprintf("# HELP %s %s\n", name, metric.help))
+		_, _ = sb.WriteString(fmt.Sprintf("# TYPE %s gauge\n", name))
+		for _, validation := range validations {
+			value, ok := metric.value(validation)
+			if !ok {
+				continue
+			}
+			_, _ = sb.WriteString(fmt.Sprintf(
+				"%s{cluster=%q,cloud_id=%q,node_id=%q,subnet=%q,subnet_id=%q} %s\n",
+				name,
+				clusterName,
+				validation.CloudID,
+				validation.NodeID.String(),
+				validation.SubnetName,
+				validation.SubnetID.String(),
+				strconv.FormatFloat(value, 'f', -1, 64),
+			))
+		}
+	}
+	return sb.String()
+}
+
+// Renewal is a new Primary Network validation of a node, continuing its current one
+type Renewal struct {
+	Current Validation
+	Start   time.Time
+	End     time.Time
+	Weight  uint64
+}
+
+// Due tells if the current validation has ended at [now], so that the renewal can be issued
+func (r Renewal) Due(now time.Time) bool {
+	return !now.Before(r.Current.End)
+}
+
+// NewRenewals returns the renewals of the Primary Network validations in [validations]
+// ending within [window] from [now]. The new validations start [leadTime] after the current
+// ones end and last [period] staking [weight], or as the current ones when zero
+func NewRenewals(
+	validations []Validation,
+	now time.Time,
+	window time.Duration,
+	leadTime time.Duration,
+	period time.Duration,
+	weight uint64,
+) []Renewal {
+	renewals := []Renewal{}
+	for _, validation := range validations {
+		if validation.SubnetID != ids.Empty || !validation.ExpiresWithin(now, window) {
+			continue
+		}
+		renewal := Renewal{
+			Current: validation,
+			Start:   validation.End.Add(leadTime),
+			Weight:  weight,
+		}
+		renewalPeriod := period
+		if renewalPeriod == 0 {
+			renewalPeriod = validation.End.Sub(validation.Start)
+		}
+		renewal.End = renewal.Start.Add(renewalPeriod)
+		if renewal.Weight == 0 {
+			renewal.Weight = validation.Weight
+		}
+		renewals = append(renewals, renewal)
+	}
+	return renewals
+}
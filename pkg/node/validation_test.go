@@ -0,0 +1,146 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package node
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/MetalBlockchain/metalgo/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewValidations(t *testing.T) {
+	require := require.New(t)
+	nodeID := ids.GenerateTestNodeID()
+	uptime := float32(99.5)
+	reward := uint64(1_000_000)
+	delegators := uint64(3)
+	validators := []platformvm.ClientPermissionlessValidator{
+		{
+			ClientStaker:    platformvm.ClientStaker{NodeID: nodeID, StartTime: 1714557600, EndTime: 1715767200, Weight: 2000},
+			Uptime:          &uptime,
+			PotentialReward: &reward,
+			DelegatorCount:  &delegators,
+		},
+		// not a node of the cluster
+		{ClientStaker: platformvm.ClientStaker{NodeID: ids.GenerateTestNodeID()}},
+	}
+	validations := NewValidations(validators, PrimaryNetworkName, ids.Empty, map[ids.NodeID]string{nodeID: "i-1"})
+	require.Equal([]Validation{{
+		CloudID:         "i-1",
+		NodeID:          nodeID,
+		SubnetName:      PrimaryNetworkName,
+		Start:           time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		End:             time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC),
+		Weight:          2000,
+		Uptime:          &uptime,
+		PotentialReward: &reward,
+		Delegators:      3,
+	}}, validations)
+
+	// delegators are counted when the API does not return their number
+	validators = []platformvm.ClientPermissionlessValidator{{
+		ClientStaker: platformvm.ClientStaker{NodeID: nodeID},
+		Delegators:   []platformvm.ClientDelegator{{}, {}},
+	}}
+	validations = NewValidations(validators, "mySubnet", ids.GenerateTestID(), map[ids.NodeID]string{nodeID: "i-1"})
+	require.Equal(uint64(2), validations[0].Delegators)
+}
+
+func TestValidationRemaining(t *testing.T) {
+	require := require.New(t)
+	now := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+	validation := Validation{End: time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)}
+	require.Equal(5*24*time.Hour, validation.Remaining(now))
+	require.True(validation.ExpiresWithin(now, 7*24*time.Hour))
+	require.True(validation.ExpiresWithin(now, 5*24*time.Hour))
+	require.False(validation.ExpiresWithin(now, 4*24*time.Hour))
+
+	ended := Validation{End: now.Add(-time.Hour)}
+	require.Zero(ended.Remaining(now))
+	require.True(ended.ExpiresWithin(now, 0))
+}
+
+func TestSortAndFindValidations(t *testing.T) {
+	require := require.New(t)
+	subnetID := ids.GenerateTestID()
+	validations := []Validation{
+		{CloudID: "i-2", SubnetName: PrimaryNetworkName},
+		{CloudID: "i-1", SubnetName: "zSubnet", SubnetID: ids.GenerateTestID()},
+		{CloudID: "i-1", SubnetName: "aSubnet", SubnetID: subnetID},
+		{CloudID: "i-1", SubnetName: PrimaryNetworkName},
+	}
+	SortValidations(validations)
+	require.Equal(
+		[]string{"i-1/" + PrimaryNetworkName, "i-1/aSubnet", "i-1/zSubnet", "i-2/" + PrimaryNetworkName},
+		[]string{
+			validations[0].CloudID + "/" + validations[0].SubnetName,
+			validations[1].CloudID + "/" + validations[1].SubnetName,
+			validations[2].CloudID + "/" + validations[2].SubnetName,
+			validations[3].CloudID + "/" + validations[3].SubnetName,
+		},
+	)
+
+	validation, ok := FindValidation(validations, "i-1", subnetID)
+	require.True(ok)
+	require.Equal("aSubnet", validation.SubnetName)
+	_, ok = FindValidation(validations, "i-2", subnetID)
+	require.False(ok)
+}
+
+func TestValidationMetrics(t *testing.T) {
+	require := require.New(t)
+	nodeID := ids.GenerateTestNodeID()
+	uptime := float32(100)
+	validations := []Validation{
+		{
+			CloudID:    "i-1",
+			NodeID:     nodeID,
+			SubnetName: PrimaryNetworkName,
+			Start:      time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			End:        time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC),
+			Weight:     2_000_000_000_000,
+			Uptime:     &uptime,
+		},
+	}
+	metrics := ValidationMetrics("cluster", validations)
+	labels := `{cluster="cluster",cloud_id="i-1",node_id="` + nodeID.String() + `",subnet="Primary Network",subnet_id="` + ids.Empty.String() + `"}`
+	require.Contains(metrics, "# TYPE avalanche_cli_validator_end_time_seconds gauge\n")
+	require.Contains(metrics, "avalanche_cli_validator_end_time_seconds"+labels+" 1715767200\n")
+	require.Contains(metrics, "avalanche_cli_validator_weight"+labels+" 2000000000000\n")
+	require.Contains(metrics, "avalanche_cli_validator_uptime_percent"+labels+" 100\n")
+	require.Contains(metrics, "avalanche_cli_validator_delegators"+labels+" 0\n")
+	// unknown values are not exported
+	require.NotContains(metrics, "avalanche_cli_validator_potential_reward{")
+	require.True(strings.HasSuffix(metrics, "\n"))
+}
+
+func TestNewRenewals(t *testing.T) {
+	require := require.New(t)
+	now := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	validations := []Validation{
+		{CloudID: "i-1", SubnetName: PrimaryNetworkName, Start: start, End: now.Add(2 * 24 * time.Hour), Weight: 2000},
+		{CloudID: "i-1", SubnetName: "mySubnet", SubnetID: ids.GenerateTestID(), Start: start, End: now.Add(time.Hour), Weight: 20},
+		{CloudID: "i-2", SubnetName: PrimaryNetworkName, Start: start, End: now.Add(10 * 24 * time.Hour), Weight: 2000},
+	}
+	// only primary network validations ending within the window are renewed
+	renewals := NewRenewals(validations, now, 7*24*time.Hour, 20*time.Second, 0, 0)
+	require.Len(renewals, 1)
+	require.Equal("i-1", renewals[0].Current.CloudID)
+	require.Equal(validations[0].End.Add(20*time.Second), renewals[0].Start)
+	// same period and stake as the current validation
+	require.Equal(validations[0].End.Sub(start), renewals[0].End.Sub(renewals[0].Start))
+	require.Equal(uint64(2000), renewals[0].Weight)
+	require.False(renewals[0].Due(now))
+	require.True(renewals[0].Due(validations[0].End))
+
+	renewals = NewRenewals(validations, now, 30*24*time.Hour, 0, 48*time.Hour, 3000)
+	require.Len(renewals, 2)
+	require.Equal(validations[2].End, renewals[1].Start)
+	require.Equal(validations[2].End.Add(48*time.Hour), renewals[1].End)
+	require.Equal(uint64(3000), renewals[1].Weight)
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package snapshot
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	saltLen = 16
+	keyLen  = 32
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// encryptedMagic prefixes the files encrypted with Encrypt
+var encryptedMagic = []byte("metal-cli-snapshot-v1")
+
+// Encrypt encrypts [plaintext] with AES-256-GCM, using a key derived from [passphrase]
+// with scrypt. The salt and nonce are stored in the returned ciphertext
+func Encrypt(plaintext []byte, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("empty passphrase")
+	}
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	header := append(append(append([]byte{}, encryptedMagic...), salt...), nonce...)
+	return aead.Seal(header, nonce, plaintext, encryptedMagic), nil
+}
+
+// Decrypt decrypts [ciphertext] encrypted by Encrypt with [passphrase]
+func Decrypt(ciphertext []byte, passphrase []byte) ([]byte, error) {
+	if !bytes.HasPrefix(ciphertext, encryptedMagic) {
+		return nil, fmt.Errorf("not an encrypted snapshot file")
+	}
+	ciphertext = ciphertext[len(encryptedMagic):]
+	if len(ciphertext) < saltLen {
+		return nil, fmt.Errorf("truncated encrypted snapshot file")
+	}
+	salt, ciphertext := ciphertext[:saltLen], ciphertext[saltLen:]
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("truncated encrypted snapshot file")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, encryptedMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot file: wrong passphrase or corrupted file")
+	}
+	return plaintext, nil
+}
+
+func newAEAD(passphrase []byte, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
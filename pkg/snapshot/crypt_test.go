@@ -0,0 +1,31 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncrypt(t *testing.T) {
+	require := require.New(t)
+	plaintext := []byte("staker.key content")
+	ciphertext, err := Encrypt(plaintext, []byte("passphrase"))
+	require.NoError(err)
+	require.NotContains(string(ciphertext), string(plaintext))
+
+	got, err := Decrypt(ciphertext, []byte("passphrase"))
+	require.NoError(err)
+	require.Equal(plaintext, got)
+
+	_, err = Decrypt(ciphertext, []byte("wrong"))
+	require.ErrorContains(err, "wrong passphrase")
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = Decrypt(ciphertext, []byte("passphrase"))
+	require.Error(err)
+	_, err = Decrypt(plaintext, []byte("passphrase"))
+	require.ErrorContains(err, "not an encrypted")
+	_, err = Encrypt(plaintext, nil)
+	require.Error(err)
+}
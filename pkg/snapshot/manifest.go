@@ -0,0 +1,64 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	ArchiveExtension        = ".tar.gz"
+	ManifestExtension       = ".json"
+	StakingArchiveExtension = ".staking.tar.gz.enc"
+)
+
+// Manifest describes a node snapshot archive
+type Manifest struct {
+	Name               string    `json:"name"`
+	ClusterName        string    `json:"clusterName"`
+	Network            string    `json:"network"`
+	CloudID            string    `json:"cloudID"`
+	NodeID             string    `json:"nodeID"`
+	AvalancheGoVersion string    `json:"avalancheGoVersion"`
+	PChainHeight       uint64    `json:"pChainHeight"`
+	CreatedAt          time.Time `json:"createdAt"`
+	// Stopped tells if avalanchego was stopped while the snapshot was taken
+	Stopped bool `json:"stopped"`
+	// Contents lists the directories included in the archive
+	Contents []string `json:"contents"`
+	Archive  string   `json:"archive"`
+	Size     int64    `json:"size"`
+	SHA256   string   `json:"sha256"`
+	// StakingArchive is the passphrase encrypted archive of the staking files, if included
+	StakingArchive string `json:"stakingArchive,omitempty"`
+}
+
+// Name returns the snapshot name for the node at the given time
+func Name(clusterName string, cloudID string, t time.Time) string {
+	return fmt.Sprintf("%s-%s-%s", clusterName, cloudID, t.UTC().Format("20060102T150405Z"))
+}
+
+// ManifestName returns the name of the manifest file of the snapshot. [name] may also
+// be the manifest or archive file name itself
+func ManifestName(name string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(name, ManifestExtension), ArchiveExtension) + ManifestExtension
+}
+
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := json.NewDecoder(r).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %w", err)
+	}
+	if manifest.Archive == "" || manifest.SHA256 == "" {
+		return nil, fmt.Errorf("invalid snapshot manifest: missing archive or checksum")
+	}
+	return manifest, nil
+}
+
+func (m *Manifest) Bytes() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
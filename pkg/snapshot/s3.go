@@ -0,0 +1,266 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+const (
+	s3Service         = "s3"
+	s3DefaultRegion   = "us-east-1"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3PartSize is the size of the parts of multipart uploads. With at most s3MaxParts
+	// parts, archives up to 1.25 TiB can be uploaded
+	s3PartSize = 128 * 1024 * 1024
+	s3MaxParts = 10000
+)
+
+// S3Store keeps snapshot files in a bucket of an S3-compatible endpoint,
+// using path-style requests so it also works with MinIO
+type S3Store struct {
+	endpoint    string
+	bucket      string
+	prefix      string
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+	partSize    int
+}
+
+func NewS3Store(bucket string, prefix string, options S3Options) (*S3Store, error) {
+	region := options.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+	endpoint := options.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", endpoint, err)
+	}
+	loadOptions := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if os.Getenv("AWS_ACCESS_KEY_ID") == "" && options.Profile != "" {
+		loadOptions = append(loadOptions, config.WithSharedConfigProfile(options.Profile))
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), loadOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		bucket:      bucket,
+		prefix:      prefix,
+		region:      region,
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{},
+		partSize:    s3PartSize,
+	}, nil
+}
+
+func (s *S3Store) Location() string {
+	return s3Scheme + path.Join(s.bucket, s.prefix)
+}
+
+func (s *S3Store) objectURL(name string) string {
+	return s.endpoint + "/" + path.Join(s.bucket, s.prefix, name)
+}
+
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get S3 credentials: %w", err)
+	}
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	if err := s.signer.SignHTTP(ctx, creds, req, s3UnsignedPayload, s3Service, s.region, time.Now()); err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s %s failed with status %s: %s", req.Method, req.URL, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// Put uploads files of unknown size or larger than a part with a multipart upload,
+// as S3 rejects single uploads over 5 GB
+func (s *S3Store) Put(name string, r io.Reader, size int64) error {
+	if size < 0 || size > int64(s.partSize) {
+		return s.putMultipart(name, r)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, s.objectURL(name), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+type s3CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// putMultipart uploads [r] in parts of partSize bytes, read one at a time, so the file
+// is never fully held in memory or on disk. The upload is aborted on failure
+func (s *S3Store) putMultipart(name string, r io.Reader) error {
+	uploadID, err := s.createMultipartUpload(name)
+	if err != nil {
+		return err
+	}
+	parts := []s3CompletedPart{}
+	buf := make([]byte, s.partSize)
+	for partNumber := 1; ; partNumber++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF && partNumber > 1 {
+			break
+		}
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			s.abortMultipartUpload(name, uploadID)
+			return err
+		}
+		if partNumber > s3MaxParts {
+			s.abortMultipartUpload(name, uploadID)
+			return fmt.Errorf("%s exceeds the maximum S3 upload size of %d bytes", name, int64(s3MaxParts)*int64(s.partSize))
+		}
+		etag, err := s.uploadPart(name, uploadID, partNumber, buf[:n])
+		if err != nil {
+			s.abortMultipartUpload(name, uploadID)
+			return err
+		}
+		parts = append(parts, s3CompletedPart{PartNumber: partNumber, ETag: etag})
+		if n < len(buf) {
+			break
+		}
+	}
+	if err := s.completeMultipartUpload(name, uploadID, parts); err != nil {
+		s.abortMultipartUpload(name, uploadID)
+		return err
+	}
+	return nil
+}
+
+func (s *S3Store) multipartURL(name string, query url.Values) string {
+	return s.objectURL(name) + "?" + query.Encode()
+}
+
+func (s *S3Store) createMultipartUpload(name string) (string, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.objectURL(name)+"?uploads=", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	result := struct {
+		UploadID string `xml:"UploadId"`
+	}{}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid S3 multipart upload response: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("invalid S3 multipart upload response: missing upload ID")
+	}
+	return result.UploadID, nil
+}
+
+func (s *S3Store) uploadPart(name string, uploadID string, partNumber int, part []byte) (string, error) {
+	query := url.Values{"partNumber": {fmt.Sprint(partNumber)}, "uploadId": {uploadID}}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, s.multipartURL(name, query), bytes.NewReader(part))
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	if err := resp.Body.Close(); err != nil {
+		return "", err
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("S3 upload of part %d of %s returned no ETag", partNumber, name)
+	}
+	return etag, nil
+}
+
+func (s *S3Store) completeMultipartUpload(name string, uploadID string, parts []s3CompletedPart) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	query := url.Values{"uploadId": {uploadID}}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.multipartURL(name, query), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// S3 may report a failed completion with a 200 status and an error body
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(respBody, []byte("<Error>")) {
+		return fmt.Errorf("failed to complete S3 upload of %s: %s", name, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+func (s *S3Store) abortMultipartUpload(name string, uploadID string) {
+	query := url.Values{"uploadId": {uploadID}}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodDelete, s.multipartURL(name, query), nil)
+	if err != nil {
+		return
+	}
+	if resp, err := s.do(req); err == nil {
+		_ = resp.Body.Close()
+	}
+}
+
+func (s *S3Store) Get(name string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, s.objectURL(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
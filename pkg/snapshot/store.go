@@ -0,0 +1,91 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+)
+
+const s3Scheme = "s3://"
+
+// Store saves and retrieves snapshot files by name
+type Store interface {
+	// Location describes where the files are stored
+	Location() string
+	// Put stores [size] bytes read from [r] with the given name. [size] is -1 when
+	// unknown, and [r] is then read until EOF
+	Put(name string, r io.Reader, size int64) error
+	// Get returns a reader for the file with the given name
+	Get(name string) (io.ReadCloser, error)
+}
+
+// S3Options configures the access to an S3-compatible endpoint
+type S3Options struct {
+	// Endpoint is the base URL of the S3 API, e.g. http://localhost:9000 for MinIO.
+	// Defaults to AWS S3 for the region
+	Endpoint string
+	Region   string
+	// Profile is the AWS credentials profile, used if no credentials are set in env
+	Profile string
+}
+
+// NewStore returns a store for [location], which is either a local directory
+// or an s3://bucket[/prefix] URL
+func NewStore(location string, s3Options S3Options) (Store, error) {
+	if strings.HasPrefix(location, s3Scheme) {
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(location, s3Scheme), "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("invalid S3 location %q, expected s3://bucket[/prefix]", location)
+		}
+		return NewS3Store(bucket, strings.Trim(prefix, "/"), s3Options)
+	}
+	return &LocalStore{Dir: location}, nil
+}
+
+// SplitLocation splits a snapshot file reference into its store location and snapshot name
+func SplitLocation(ref string) (string, string) {
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ".", ref
+}
+
+// LocalStore keeps snapshot files in a local directory
+type LocalStore struct {
+	Dir string
+}
+
+func (s *LocalStore) Location() string {
+	return s.Dir
+}
+
+// Create returns a writer for the file with the given name, so large files
+// can be streamed into the store without an intermediate copy
+func (s *LocalStore) Create(name string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(s.Dir, constants.DefaultPerms755); err != nil {
+		return nil, err
+	}
+	return os.Create(filepath.Join(s.Dir, name))
+}
+
+func (s *LocalStore) Put(name string, r io.Reader, _ int64) error {
+	f, err := s.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *LocalStore) Get(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.Dir, name))
+}
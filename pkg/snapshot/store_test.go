@@ -0,0 +1,161 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	require := require.New(t)
+	store, err := NewStore(t.TempDir(), S3Options{})
+	require.NoError(err)
+	require.IsType(&LocalStore{}, store)
+	require.NoError(store.Put("a.json", strings.NewReader("content"), 7))
+	r, err := store.Get("a.json")
+	require.NoError(err)
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	require.NoError(err)
+	require.Equal("content", string(content))
+	_, err = store.Get("b.json")
+	require.Error(err)
+}
+
+func TestNamesAndLocations(t *testing.T) {
+	require := require.New(t)
+	require.Equal("c.json", ManifestName("c"))
+	require.Equal("c.json", ManifestName("c.json"))
+	require.Equal("c.json", ManifestName("c.tar.gz"))
+	location, name := SplitLocation("s3://bucket/prefix/c.json")
+	require.Equal("s3://bucket/prefix", location)
+	require.Equal("c.json", name)
+	location, name = SplitLocation("c.json")
+	require.Equal(".", location)
+	require.Equal("c.json", name)
+	_, err := NewStore("s3://", S3Options{})
+	require.Error(err)
+}
+
+// fakeS3 is a minimal path-style S3 API keeping objects and multipart uploads in memory
+type fakeS3 struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	aborted []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadID := fmt.Sprintf("upload-%d", len(f.uploads)+len(f.aborted))
+		f.uploads[uploadID] = map[int][]byte{}
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case r.Method == http.MethodPut && uploadID != "":
+		content, _ := io.ReadAll(r.Body)
+		partNumber := 0
+		_, _ = fmt.Sscan(query.Get("partNumber"), &partNumber)
+		f.uploads[uploadID][partNumber] = content
+		w.Header().Set("ETag", fmt.Sprintf("\"etag-%d\"", partNumber))
+	case r.Method == http.MethodPost && uploadID != "":
+		body, _ := io.ReadAll(r.Body)
+		content := []byte{}
+		for partNumber := 1; partNumber <= len(f.uploads[uploadID]); partNumber++ {
+			if !bytes.Contains(body, []byte(fmt.Sprintf("<PartNumber>%d</PartNumber><ETag>&#34;etag-%d&#34;</ETag>", partNumber, partNumber))) {
+				_, _ = w.Write([]byte("<Error><Code>InvalidPart</Code></Error>"))
+				return
+			}
+			content = append(content, f.uploads[uploadID][partNumber]...)
+		}
+		f.objects[r.URL.Path] = content
+		delete(f.uploads, uploadID)
+	case r.Method == http.MethodDelete && uploadID != "":
+		delete(f.uploads, uploadID)
+		f.aborted = append(f.aborted, uploadID)
+	case r.Method == http.MethodPut:
+		content, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = content
+	case r.Method == http.MethodGet:
+		content, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("NoSuchKey"))
+			return
+		}
+		_, _ = w.Write(content)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+	s3 := &fakeS3{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	store, err := NewStore("s3://snapshots/cluster", S3Options{Endpoint: server.URL})
+	require.NoError(err)
+	require.Equal("s3://snapshots/cluster", store.Location())
+	content := []byte("archive content")
+	require.NoError(store.Put("a.tar.gz", bytes.NewReader(content), int64(len(content))))
+	require.Equal(content, s3.objects["/snapshots/cluster/a.tar.gz"])
+
+	r, err := store.Get("a.tar.gz")
+	require.NoError(err)
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	require.NoError(err)
+	require.Equal(content, got)
+
+	_, err = store.Get("missing.tar.gz")
+	require.ErrorContains(err, "NoSuchKey")
+}
+
+func TestS3StoreMultipart(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+	s3 := &fakeS3{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	store, err := NewStore("s3://snapshots/cluster", S3Options{Endpoint: server.URL})
+	require.NoError(err)
+	store.(*S3Store).partSize = 4
+	for _, content := range []string{"", "abc", "abcd", "archive content"} {
+		require.NoError(store.Put("a.tar.gz", strings.NewReader(content), -1))
+		require.Equal(content, string(s3.objects["/snapshots/cluster/a.tar.gz"]))
+	}
+	// known sizes over a part are uploaded in parts too
+	require.NoError(store.Put("b.tar.gz", strings.NewReader("archive content"), 15))
+	require.Equal("archive content", string(s3.objects["/snapshots/cluster/b.tar.gz"]))
+	require.Empty(s3.uploads)
+	require.Empty(s3.aborted)
+
+	// a failing stream aborts the upload
+	r := io.MultiReader(strings.NewReader("archive"), iotest.ErrReader(errors.New("stream failed")))
+	require.ErrorContains(store.Put("c.tar.gz", r, -1), "stream failed")
+	require.NotContains(s3.objects, "/snapshots/cluster/c.tar.gz")
+	require.Empty(s3.uploads)
+	require.Len(s3.aborted, 1)
+}
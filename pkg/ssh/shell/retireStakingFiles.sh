@@ -0,0 +1,4 @@
+#!/usr/bin/env bash
+set -e
+#name:TASK [retire staking files]
+mv /home/ubuntu/.metalgo/staking /home/ubuntu/.metalgo/staking.migrated-$(date +%s)
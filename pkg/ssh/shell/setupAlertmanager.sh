@@ -0,0 +1,17 @@
+#!/usr/bin/env bash
+{{if .IsE2E }}
+#name:TASK [disable systemctl]
+sudo cp -vf /usr/bin/true /usr/local/sbin/systemctl
+{{end}}
+#name:TASK [install alertmanager]
+export DEBIAN_FRONTEND=noninteractive
+while ! sudo systemctl status prometheus-alertmanager >/dev/null 2>&1; do
+    sudo apt-get -y -o DPkg::Lock::Timeout=120 update
+    sudo apt-get -y -o DPkg::Lock::Timeout=120 install prometheus-alertmanager
+    if [ $? -ne 0 ]; then
+        echo "Failed to install Alertmanager. Retrying in 10 seconds..."
+        sleep 10
+    fi
+done
+#name:TASK [create alert rules dir]
+sudo mkdir -p /etc/prometheus/rules
@@ -0,0 +1,14 @@
+#!/usr/bin/env bash
+set -e
+export PATH=$PATH:~/go/bin:/snap/bin
+#name:TASK [update apt data and install dependencies]
+export DEBIAN_FRONTEND=noninteractive
+until sudo apt-get -y install -o DPkg::Lock::Timeout=120 git; do sleep 10 && echo "Try again"; done
+#name:TASK [build cli from source]
+cd ~
+rm -rf {{ .CliRepoDir }}
+git clone --depth 1 --single-branch -b {{ .CliBranch }} {{ .CliRepo }} {{ .CliRepoDir }}
+cd {{ .CliRepoDir }}
+./scripts/build.sh
+mkdir -p ~/bin
+cp bin/metal ~/bin/metal
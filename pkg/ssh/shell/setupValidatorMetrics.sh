@@ -0,0 +1,25 @@
+#!/usr/bin/env bash
+{{if .IsE2E }}
+#name:TASK [disable systemctl]
+sudo cp -vf /usr/bin/true /usr/local/sbin/systemctl
+{{end}}
+#name:TASK [create validator metrics dir]
+sudo mkdir -p {{ .ValidatorMetricsPath }}
+#name:TASK [install validator metrics service]
+NODE_EXPORTER=$(command -v node_exporter || echo /usr/local/bin/node_exporter)
+sudo tee /etc/systemd/system/validator-metrics.service >/dev/null <<EOT
+[Unit]
+Description=Validator metrics exporter
+After=network-online.target
+
+[Service]
+ExecStart=$NODE_EXPORTER --collector.disable-defaults --collector.textfile --collector.textfile.directory={{ .ValidatorMetricsPath }} --web.listen-address=:{{ .ValidatorMetricsPort }}
+Restart=always
+
+[Install]
+WantedBy=multi-user.target
+EOT
+#name:TASK [start validator metrics service]
+sudo systemctl daemon-reload
+sudo systemctl enable validator-metrics
+sudo systemctl restart validator-metrics
@@ -0,0 +1,6 @@
+#!/usr/bin/env bash
+#name:TASK [sync new alert rules]
+sudo mkdir -p /etc/prometheus/rules
+sudo cp -f /tmp/alert_rules.yml /etc/prometheus/rules/avalanche-cli.yml
+#name:TASK [restart prometheus service]
+sudo systemctl restart prometheus
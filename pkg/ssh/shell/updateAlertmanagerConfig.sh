@@ -0,0 +1,8 @@
+#!/usr/bin/env bash
+#name:TASK [sync new alertmanager config]
+sudo cp -f /tmp/alertmanager.yml /etc/prometheus/alertmanager.yml
+sudo chown root:prometheus /etc/prometheus/alertmanager.yml
+sudo chmod 640 /etc/prometheus/alertmanager.yml
+rm -f /tmp/alertmanager.yml
+#name:TASK [restart alertmanager service]
+sudo systemctl restart prometheus-alertmanager
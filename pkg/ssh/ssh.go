@@ -6,11 +6,13 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
@@ -22,6 +24,8 @@ import (
 	"github.com/ixAnkit/cryft/pkg/models"
 )
 
+const sshAuthorizedKeysFile = "/home/ubuntu/.ssh/authorized_keys"
+
 type scriptInputs struct {
 	AvalancheGoVersion      string
 	CLIVersion              string
@@ -30,6 +34,8 @@ type scriptInputs struct {
 	ClusterName             string
 	GoVersion               string
 	CliBranch               string
+	CliRepo                 string
+	CliRepoDir              string
 	IsDevNet                bool
 	IsE2E                   bool
 	NetworkFlag             string
@@ -46,6 +52,8 @@ type scriptInputs struct {
 	CheckoutCommit          bool
 	LoadTestResultFile      string
 	GrafanaPkg              string
+	ValidatorMetricsPath    string
+	ValidatorMetricsPort    int
 }
 
 //go:embed shell/*.sh
@@ -65,6 +73,10 @@ func RunOverSSH(
 	if err != nil {
 		return err
 	}
+	if host.IsDocker() {
+		// docker hosts run without systemd, same as E2E hosts
+		templateVars.IsE2E = true
+	}
 	var script bytes.Buffer
 	t, err := template.New(scriptDesc).Parse(string(shellScript))
 	if err != nil {
@@ -110,7 +122,7 @@ func RunSSHSetupNode(host *models.Host, configPath, avalancheGoVersion string, c
 	); err != nil {
 		return err
 	}
-	if utils.IsE2E() && utils.E2EDocker() {
+	if host.IsDocker() {
 		if err := RunOverSSH(
 			"E2E Start Avalanchego",
 			host,
@@ -131,6 +143,12 @@ func RunSSHSetupNode(host *models.Host, configPath, avalancheGoVersion string, c
 
 // RunSSHRestartNode runs script to restart avalanchego
 func RunSSHRestartNode(host *models.Host) error {
+	if host.IsDocker() {
+		if err := RunSSHStopNode(host); err != nil {
+			return err
+		}
+		return RunSSHStartNode(host)
+	}
 	return RunOverSSH(
 		"Restart Avalanchego",
 		host,
@@ -175,7 +193,7 @@ func RunSSHStopAWMRelayerService(host *models.Host) error {
 
 // RunSSHUpgradeAvalanchego runs script to upgrade avalanchego
 func RunSSHUpgradeAvalanchego(host *models.Host, avalancheGoVersion string) error {
-	if utils.IsE2E() && utils.E2EDocker() {
+	if host.IsDocker() {
 		return RunOverSSH(
 			"E2E Upgrade Avalanchego",
 			host,
@@ -195,7 +213,7 @@ func RunSSHUpgradeAvalanchego(host *models.Host, avalancheGoVersion string) erro
 
 // RunSSHStartNode runs script to start avalanchego
 func RunSSHStartNode(host *models.Host) error {
-	if utils.IsE2E() && utils.E2EDocker() {
+	if host.IsDocker() {
 		return RunOverSSH(
 			"E2E Start Avalanchego",
 			host,
@@ -215,7 +233,7 @@ func RunSSHStartNode(host *models.Host) error {
 
 // RunSSHStopNode runs script to stop avalanchego
 func RunSSHStopNode(host *models.Host) error {
-	if utils.IsE2E() && utils.E2EDocker() {
+	if host.IsDocker() {
 		return RunOverSSH(
 			"E2E Stop Avalanchego",
 			host,
@@ -352,14 +370,14 @@ func RunSSHSetupSeparateMonitoring(host *models.Host, grafanaPkg string) error {
 	)
 }
 
-func RunSSHUpdatePrometheusConfig(host *models.Host, avalancheGoPorts, machinePorts, loadTestPorts []string) error {
+func RunSSHUpdatePrometheusConfig(host *models.Host, avalancheGoPorts, machinePorts, loadTestPorts, relayerPorts []string) error {
 	const cloudNodePrometheusConfigTemp = "/tmp/prometheus.yml"
 	promConfig, err := os.CreateTemp("", "prometheus")
 	if err != nil {
 		return err
 	}
 	defer os.Remove(promConfig.Name())
-	if err := monitoring.WritePrometheusConfig(promConfig.Name(), avalancheGoPorts, machinePorts, loadTestPorts); err != nil {
+	if err := monitoring.WritePrometheusConfig(promConfig.Name(), avalancheGoPorts, machinePorts, loadTestPorts, relayerPorts); err != nil {
 		return err
 	}
 	if err := host.Upload(
@@ -378,6 +396,70 @@ func RunSSHUpdatePrometheusConfig(host *models.Host, avalancheGoPorts, machinePo
 	)
 }
 
+func RunSSHSetupAlertmanager(host *models.Host) error {
+	return RunOverSSH(
+		"Setup Alertmanager",
+		host,
+		constants.SSHLongRunningScriptTimeout,
+		"shell/setupAlertmanager.sh",
+		scriptInputs{
+			IsE2E: utils.IsE2E(),
+		},
+	)
+}
+
+func RunSSHUpdateAlertmanagerConfig(host *models.Host, receivers monitoring.AlertReceivers) error {
+	const cloudNodeAlertmanagerConfigTemp = "/tmp/alertmanager.yml"
+	alertmanagerConfig, err := os.CreateTemp("", "alertmanager")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(alertmanagerConfig.Name())
+	if err := monitoring.WriteAlertmanagerConfig(alertmanagerConfig.Name(), receivers); err != nil {
+		return err
+	}
+	if err := host.Upload(
+		alertmanagerConfig.Name(),
+		cloudNodeAlertmanagerConfigTemp,
+		constants.SSHFileOpsTimeout,
+	); err != nil {
+		return err
+	}
+	return RunOverSSH(
+		"Update Alertmanager Config",
+		host,
+		constants.SSHLongRunningScriptTimeout,
+		"shell/updateAlertmanagerConfig.sh",
+		scriptInputs{},
+	)
+}
+
+func RunSSHUpdateAlertRules(host *models.Host, rules []monitoring.AlertRule) error {
+	const cloudNodeAlertRulesTemp = "/tmp/alert_rules.yml"
+	alertRules, err := os.CreateTemp("", "alert_rules")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(alertRules.Name())
+	if err := monitoring.WriteAlertRules(alertRules.Name(), rules); err != nil {
+		return err
+	}
+	if err := host.Upload(
+		alertRules.Name(),
+		cloudNodeAlertRulesTemp,
+		constants.SSHFileOpsTimeout,
+	); err != nil {
+		return err
+	}
+	return RunOverSSH(
+		"Update Alert Rules",
+		host,
+		constants.SSHLongRunningScriptTimeout,
+		"shell/updateAlertRules.sh",
+		scriptInputs{},
+	)
+}
+
 func RunSSHUpdateLokiConfig(host *models.Host, port int) error {
 	const cloudNodeLokiConfigTemp = "/tmp/loki.yml"
 	lokiConfig, err := os.CreateTemp("", "loki")
@@ -583,6 +665,32 @@ func RunSSHUploadStakingFiles(host *models.Host, nodeInstanceDirPath string) err
 	)
 }
 
+// RunSSHDownloadStakingFiles downloads the staking files of the node into [nodeInstanceDirPath]
+func RunSSHDownloadStakingFiles(host *models.Host, nodeInstanceDirPath string) error {
+	for _, fileName := range []string{constants.StakerCertFileName, constants.StakerKeyFileName, constants.BLSKeyFileName} {
+		if err := host.Download(
+			filepath.Join(constants.CloudNodeStakingPath, fileName),
+			filepath.Join(nodeInstanceDirPath, fileName),
+			constants.SSHFileOpsTimeout,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunSSHRetireStakingFiles moves away the staking files of the node so
+// avalanchego can no longer start with its current identity
+func RunSSHRetireStakingFiles(host *models.Host) error {
+	return RunOverSSH(
+		"Retire Staking Files",
+		host,
+		constants.SSHScriptTimeout,
+		"shell/retireStakingFiles.sh",
+		scriptInputs{},
+	)
+}
+
 // RunSSHExportSubnet exports deployed Subnet from local machine to cloud server
 func RunSSHExportSubnet(host *models.Host, exportPath, cloudServerSubnetPath string) error {
 	// name: copy exported subnet VM spec to cloud server
@@ -660,13 +768,52 @@ func RunSSHRunLoadTest(host *models.Host, loadTestCommand, loadTestName string)
 	)
 }
 
+// RunSSHSetupLoadTestCLI builds the CLI from the [cliBranch] branch or tag of its repo
+// on the load test host, to run its built-in load generator
+func RunSSHSetupLoadTestCLI(host *models.Host, cliBranch string) error {
+	return RunOverSSH(
+		"Setup Load Test CLI",
+		host,
+		constants.SSHLongRunningScriptTimeout,
+		"shell/setupLoadTestCLI.sh",
+		scriptInputs{CliBranch: cliBranch, CliRepo: constants.CliRepoURL, CliRepoDir: constants.CliRepoName},
+	)
+}
+
+// GetLoadTestKeyPath returns the remote path of the private key funding the built-in
+// load generator of [loadTestName]
+func GetLoadTestKeyPath(loadTestName string) string {
+	return fmt.Sprintf("/home/ubuntu/loadtest_%s.pk", loadTestName)
+}
+
+// RunSSHUploadLoadTestKey writes the hex encoded private key funding the built-in load
+// generator to the load test host, readable only by its owner, returning its remote path
+func RunSSHUploadLoadTestKey(host *models.Host, loadTestName, privKeyHex string) (string, error) {
+	remoteKeyPath := GetLoadTestKeyPath(loadTestName)
+	// the key is piped instead of uploaded so that it is never written with wider permissions
+	writeCmd := fmt.Sprintf("rm -f %[1]s && umask 077 && cat > %[1]s", remoteKeyPath)
+	if err := host.PipeSSHCommand(writeCmd, strings.NewReader(privKeyHex), io.Discard, constants.SSHFileOpsTimeout); err != nil {
+		return "", err
+	}
+	return remoteKeyPath, nil
+}
+
+// RunSSHRemoveLoadTestKey removes the private key funding the built-in load generator of
+// [loadTestName] from the load test host
+func RunSSHRemoveLoadTestKey(host *models.Host, loadTestName string) error {
+	if output, err := host.Command(fmt.Sprintf("rm -f %s", GetLoadTestKeyPath(loadTestName)), nil, constants.SSHScriptTimeout); err != nil {
+		return fmt.Errorf("%w: %s", err, string(output))
+	}
+	return nil
+}
+
 // RunSSHSetupCLIFromSource installs any CLI branch from source
 func RunSSHSetupCLIFromSource(host *models.Host, cliBranch string) error {
 	if !constants.EnableSetupCLIFromSource {
 		return nil
 	}
 	timeout := constants.SSHLongRunningScriptTimeout
-	if utils.IsE2E() && utils.E2EDocker() {
+	if host.IsDocker() {
 		timeout = 10 * time.Minute
 	}
 	return RunOverSSH(
@@ -678,6 +825,42 @@ func RunSSHSetupCLIFromSource(host *models.Host, cliBranch string) error {
 	)
 }
 
+// RunSSHGetProvisionedSteps returns the provisioning steps marked as completed on the host
+func RunSSHGetProvisionedSteps(host *models.Host) ([]string, error) {
+	output, err := host.Command(fmt.Sprintf("ls -1 %s 2>/dev/null || true", constants.CloudNodeProvisionPath), nil, constants.SSHScriptTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// RunSSHGetActiveServices returns the systemd services in [services] that are active on the host
+func RunSSHGetActiveServices(host *models.Host, services []string) ([]string, error) {
+	output, err := host.Command(fmt.Sprintf("for s in %s; do if systemctl is-active --quiet $s; then echo $s; fi; done", strings.Join(services, " ")), nil, constants.SSHScriptTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, string(output))
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// RunSSHFileExists tells if [path] exists on the host
+func RunSSHFileExists(host *models.Host, path string) (bool, error) {
+	output, err := host.Command(fmt.Sprintf("if [ -e %s ]; then echo yes; fi", path), nil, constants.SSHScriptTimeout)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", err, string(output))
+	}
+	return strings.TrimSpace(string(output)) == "yes", nil
+}
+
+// RunSSHMarkProvisionedStep marks the provisioning [step] as completed on the host
+func RunSSHMarkProvisionedStep(host *models.Host, step string) error {
+	stepPath := filepath.Join(constants.CloudNodeProvisionPath, step)
+	if output, err := host.Command(fmt.Sprintf("mkdir -p %s && touch %s", constants.CloudNodeProvisionPath, stepPath), nil, constants.SSHScriptTimeout); err != nil {
+		return fmt.Errorf("%w: %s", err, string(output))
+	}
+	return nil
+}
+
 // RunSSHCheckAvalancheGoVersion checks node avalanchego version
 func RunSSHCheckAvalancheGoVersion(host *models.Host) ([]byte, error) {
 	// Craft and send the HTTP POST request
@@ -706,6 +889,81 @@ func RunSSHGetNodeID(host *models.Host) ([]byte, error) {
 	return PostOverSSH(host, "", requestBody)
 }
 
+// RunSSHGetPChainHeight gets the height of the last accepted P-Chain block
+func RunSSHGetPChainHeight(host *models.Host) ([]byte, error) {
+	// Craft and send the HTTP POST request
+	requestBody := "{\"jsonrpc\":\"2.0\", \"id\":1,\"method\" :\"platform.getHeight\", \"params\": {}}"
+	return PostOverSSH(host, "/ext/bc/P", requestBody)
+}
+
+// RunSSHCheckValidatorMetrics tells if the validator metrics exporter is running on the
+// monitoring host
+func RunSSHCheckValidatorMetrics(host *models.Host) bool {
+	_, err := host.Command("systemctl is-active --quiet validator-metrics", nil, constants.SSHScriptTimeout)
+	return err == nil
+}
+
+// RunSSHSetupValidatorMetrics runs a node_exporter textfile collector on the monitoring
+// host, exporting the validator metrics uploaded by RunSSHUploadValidatorMetrics
+func RunSSHSetupValidatorMetrics(host *models.Host) error {
+	return RunOverSSH(
+		"Setup Validator Metrics",
+		host,
+		constants.SSHScriptTimeout,
+		"shell/setupValidatorMetrics.sh",
+		scriptInputs{
+			IsE2E:                utils.IsE2E(),
+			ValidatorMetricsPath: constants.CloudNodeValidatorMetricsPath,
+			ValidatorMetricsPort: constants.ValidatorMetricsPort,
+		},
+	)
+}
+
+// RunSSHUploadValidatorMetrics replaces the validator metrics of [clusterName] exported
+// by the monitoring host with [metrics], in the Prometheus text format
+func RunSSHUploadValidatorMetrics(host *models.Host, clusterName, metrics string) error {
+	metricsFile, err := os.CreateTemp("", "validators")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(metricsFile.Name())
+	if _, err := metricsFile.WriteString(metrics); err != nil {
+		return err
+	}
+	if err := metricsFile.Close(); err != nil {
+		return err
+	}
+	remoteTmpPath := fmt.Sprintf("/tmp/validators_%s.prom", clusterName)
+	if err := host.Upload(metricsFile.Name(), remoteTmpPath, constants.SSHFileOpsTimeout); err != nil {
+		return err
+	}
+	// the collector only reads *.prom files, and must never read a partially written one
+	remotePath := filepath.Join(constants.CloudNodeValidatorMetricsPath, fmt.Sprintf("validators_%s.prom", clusterName))
+	if output, err := host.Command(
+		fmt.Sprintf("sudo install -m 644 %s %s.tmp && sudo mv -f %s.tmp %s && rm -f %s", remoteTmpPath, remotePath, remotePath, remotePath, remoteTmpPath),
+		nil,
+		constants.SSHScriptTimeout,
+	); err != nil {
+		return fmt.Errorf("%w: %s", err, string(output))
+	}
+	return nil
+}
+
+// RunSSHQueryPrometheus evaluates [query] at time [at] on the Prometheus of the
+// monitoring host
+func RunSSHQueryPrometheus(host *models.Host, query string, at time.Time) ([]byte, error) {
+	return host.Command(
+		fmt.Sprintf(
+			"curl -sf -G http://localhost:%d/api/v1/query --data-urlencode 'query=%s' --data-urlencode 'time=%d'",
+			constants.AvalanchegoMonitoringPort,
+			query,
+			at.Unix(),
+		),
+		nil,
+		constants.SSHScriptTimeout,
+	)
+}
+
 // SubnetSyncStatus checks if node is synced to subnet
 func RunSSHSubnetSyncStatus(host *models.Host, blockchainID string) ([]byte, error) {
 	// Craft and send the HTTP POST request
@@ -744,10 +1002,9 @@ func StreamOverSSH(
 
 // RunSSHWhitelistPubKey downloads the authorized_keys file from the specified host, appends the provided sshPubKey to it, and uploads the file back to the host.
 func RunSSHWhitelistPubKey(host *models.Host, sshPubKey string) error {
-	const sshAuthFile = "/home/ubuntu/.ssh/authorized_keys"
 	tmpName := filepath.Join(os.TempDir(), utils.RandomString(10))
 	defer os.Remove(tmpName)
-	if err := host.Download(sshAuthFile, tmpName, constants.SSHFileOpsTimeout); err != nil {
+	if err := host.Download(sshAuthorizedKeysFile, tmpName, constants.SSHFileOpsTimeout); err != nil {
 		return err
 	}
 	// write ssh public key
@@ -761,7 +1018,27 @@ func RunSSHWhitelistPubKey(host *models.Host, sshPubKey string) error {
 	if err := tmpFile.Close(); err != nil {
 		return err
 	}
-	return host.Upload(tmpFile.Name(), sshAuthFile, constants.SSHFileOpsTimeout)
+	return host.Upload(tmpFile.Name(), sshAuthorizedKeysFile, constants.SSHFileOpsTimeout)
+}
+
+// RunSSHGetAuthorizedKeys returns the authorized_keys file of the specified host
+func RunSSHGetAuthorizedKeys(host *models.Host) ([]byte, error) {
+	tmpName := filepath.Join(os.TempDir(), utils.RandomString(10))
+	defer os.Remove(tmpName)
+	if err := host.Download(sshAuthorizedKeysFile, tmpName, constants.SSHFileOpsTimeout); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(tmpName)
+}
+
+// RunSSHSetAuthorizedKeys replaces the authorized_keys file of the specified host by [content]
+func RunSSHSetAuthorizedKeys(host *models.Host, content []byte) error {
+	tmpName := filepath.Join(os.TempDir(), utils.RandomString(10))
+	defer os.Remove(tmpName)
+	if err := os.WriteFile(tmpName, content, 0o600); err != nil {
+		return err
+	}
+	return host.Upload(tmpName, sshAuthorizedKeysFile, constants.SSHFileOpsTimeout)
 }
 
 // RunSSHDownloadFile downloads specified file from the specified host
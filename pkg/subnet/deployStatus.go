@@ -36,7 +36,7 @@ func GetLocallyDeployedSubnetsFromFile(app *application.Avalanche) ([]string, er
 
 		// check if sidecar contains local deployment info in Networks map
 		// if so, add to list of deployed subnets
-		if _, ok := sc.Networks[models.Local.String()]; ok {
+		if _, ok := sc.Networks[models.NewLocalNetwork().Name()]; ok {
 			deployedSubnets = append(deployedSubnets, sc.Name)
 		}
 	}
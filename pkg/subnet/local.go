@@ -185,7 +185,7 @@ func IssueTransformSubnetTx(
 	maxSupply uint64,
 ) (ids.ID, ids.ID, error) {
 	ctx := context.Background()
-	api := constants.LocalAPIEndpoint
+	api := models.NewLocalNetwork().Endpoint
 	wallet, err := primary.MakeWallet(
 		ctx,
 		&primary.WalletConfig{
@@ -242,7 +242,7 @@ func IssueAddPermissionlessValidatorTx(
 	endTime uint64,
 ) (ids.ID, error) {
 	ctx := context.Background()
-	api := constants.LocalAPIEndpoint
+	api := models.NewLocalNetwork().Endpoint
 	wallet, err := primary.MakeWallet(
 		ctx,
 		&primary.WalletConfig{
@@ -296,7 +296,7 @@ func IssueAddPermissionlessDelegatorTx(
 	endTime uint64,
 ) (ids.ID, error) {
 	ctx := context.Background()
-	api := constants.LocalAPIEndpoint
+	api := models.NewLocalNetwork().Endpoint
 	wallet, err := primary.MakeWallet(
 		ctx,
 		&primary.WalletConfig{
@@ -347,7 +347,7 @@ func (d *LocalDeployer) StartServer() error {
 }
 
 func GetCurrentSupply(subnetID ids.ID) error {
-	api := constants.LocalAPIEndpoint
+	api := models.NewLocalNetwork().Endpoint
 	pClient := platformvm.NewClient(api)
 	ctx, cancel := utils.GetAPIContext()
 	defer cancel()
@@ -891,6 +891,11 @@ func SetDefaultSnapshot(snapshotsDir string, resetCurrentSnapshot bool, avagoVer
 		if err := binutils.InstallArchive("tar.gz", bootstrapSnapshotBytes, snapshotsDir); err != nil {
 			return false, fmt.Errorf("failed installing bootstrap snapshot: %w", err)
 		}
+		if offset := models.CurrentLocalNetworkInstance().PortOffset(); offset != 0 {
+			if err := shiftSnapshotNodePorts(defaultSnapshotPath, offset); err != nil {
+				return false, fmt.Errorf("failed setting local network instance ports on bootstrap snapshot: %w", err)
+			}
+		}
 		if err := os.WriteFile(currentBootstrapNamePath, []byte(bootstrapSnapshotArchiveName), constants.DefaultPerms755); err != nil {
 			return false, err
 		}
@@ -898,6 +903,44 @@ func SetDefaultSnapshot(snapshotsDir string, resetCurrentSnapshot bool, avagoVer
 	return resetCurrentSnapshot, nil
 }
 
+// shiftSnapshotNodePorts moves the API and staking ports of all nodes in the given
+// snapshot by [offset], so that local network instances do not collide
+func shiftSnapshotNodePorts(snapshotPath string, offset int) error {
+	networkConfigPath := filepath.Join(snapshotPath, "network.json")
+	bs, err := os.ReadFile(networkConfigPath)
+	if err != nil {
+		return err
+	}
+	var networkConfig map[string]interface{}
+	if err := json.Unmarshal(bs, &networkConfig); err != nil {
+		return err
+	}
+	nodeConfigs, ok := networkConfig["nodeConfigs"].([]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected nodeConfigs format on %s", networkConfigPath)
+	}
+	for _, nodeConfigIntf := range nodeConfigs {
+		nodeConfig, ok := nodeConfigIntf.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected node config format on %s", networkConfigPath)
+		}
+		flags, ok := nodeConfig["flags"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, portKey := range []string{config.HTTPPortKey, config.StakingPortKey} {
+			if port, ok := flags[portKey].(float64); ok {
+				flags[portKey] = int(port) + offset
+			}
+		}
+	}
+	bs, err = json.Marshal(networkConfig)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(networkConfigPath, bs, constants.WriteReadReadPerms)
+}
+
 // start the network
 func (d *LocalDeployer) startNetwork(
 	ctx context.Context,
@@ -936,6 +979,31 @@ func (d *LocalDeployer) startNetwork(
 	return nil
 }
 
+// IsLocalNetworkRunning returns true if the backend controller is up and the
+// local network has been bootstrapped
+func IsLocalNetworkRunning() (bool, error) {
+	cli, err := binutils.NewGRPCClient(
+		binutils.WithAvoidRPCVersionCheck(true),
+		binutils.WithDialTimeout(constants.FastGRPCDialTimeout),
+	)
+	if err != nil {
+		if errors.Is(err, binutils.ErrGRPCTimeout) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer cli.Close()
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	if _, err := cli.Status(ctx); err != nil {
+		if server.IsServerError(err, server.ErrNotBootstrapped) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // Returns an error if the server cannot be contacted. You may want to ignore this error.
 func GetLocallyDeployedSubnets() (map[string]struct{}, error) {
 	deployedNames := map[string]struct{}{}
@@ -962,7 +1030,7 @@ func GetLocallyDeployedSubnets() (map[string]struct{}, error) {
 
 func IssueRemoveSubnetValidatorTx(kc keychain.Keychain, subnetID ids.ID, nodeID ids.NodeID) (ids.ID, error) {
 	ctx := context.Background()
-	api := constants.LocalAPIEndpoint
+	api := models.NewLocalNetwork().Endpoint
 	wallet, err := primary.MakeWallet(
 		ctx,
 		&primary.WalletConfig{
@@ -981,7 +1049,7 @@ func IssueRemoveSubnetValidatorTx(kc keychain.Keychain, subnetID ids.ID, nodeID
 }
 
 func GetSubnetValidators(subnetID ids.ID) ([]platformvm.ClientPermissionlessValidator, error) {
-	api := constants.LocalAPIEndpoint
+	api := models.NewLocalNetwork().Endpoint
 	pClient := platformvm.NewClient(api)
 	ctx, cancel := utils.GetAPIContext()
 	defer cancel()
@@ -990,7 +1058,7 @@ func GetSubnetValidators(subnetID ids.ID) ([]platformvm.ClientPermissionlessVali
 }
 
 func CheckNodeIsInSubnetValidators(subnetID ids.ID, nodeID string) (bool, error) {
-	api := constants.LocalAPIEndpoint
+	api := models.NewLocalNetwork().Endpoint
 	pClient := platformvm.NewClient(api)
 	ctx, cancel := utils.GetAPIContext()
 	defer cancel()
@@ -379,6 +379,26 @@ func (d *PublicDeployer) AddPermissionlessValidator(
 	return txID, nil
 }
 
+// CreateAddPermissionlessValidatorTx creates and signs an add permissionless validator tx
+// for [nodeID] on the Primary Network, without issuing it, so that it can be saved and
+// committed later
+func (d *PublicDeployer) CreateAddPermissionlessValidatorTx(
+	nodeID ids.NodeID,
+	stakeAmount uint64,
+	startTime uint64,
+	endTime uint64,
+	recipientAddr ids.ShortID,
+	delegationFee uint32,
+	proofOfPossession *signer.ProofOfPossession,
+) (*txs.Tx, error) {
+	wallet, err := d.loadWallet()
+	if err != nil {
+		return nil, err
+	}
+	assetID := wallet.P().Builder().Context().AVAXAssetID
+	return d.createAddPermissionlessValidatorTX(recipientAddr, stakeAmount, ids.Empty, nodeID, assetID, startTime, endTime, wallet, delegationFee, nil, proofOfPossession)
+}
+
 func (d *PublicDeployer) AddPermissionlessDelegator(
 	subnetID ids.ID,
 	subnetAssetID ids.ID,
@@ -734,6 +754,42 @@ func (d *PublicDeployer) issueAddPermissionlessValidatorTX(
 	popBytes []byte,
 	blsProof *signer.ProofOfPossession,
 ) (ids.ID, error) {
+	tx, err := d.createAddPermissionlessValidatorTX(recipientAddr, stakeAmount, subnetID, nodeID, assetID, startTime, endTime, wallet, delegationFee, popBytes, blsProof)
+	if err != nil {
+		return ids.Empty, err
+	}
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	err = wallet.P().IssueTx(
+		tx,
+		common.WithContext(ctx),
+	)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timeout issuing/verifying tx with ID %s: %w", tx.ID(), err)
+		} else {
+			err = fmt.Errorf("error issuing tx with ID %s: %w", tx.ID(), err)
+		}
+		return ids.Empty, err
+	}
+
+	return tx.ID(), nil
+}
+
+// createAddPermissionlessValidatorTX creates and signs an add permissionless validator tx
+func (d *PublicDeployer) createAddPermissionlessValidatorTX(
+	recipientAddr ids.ShortID,
+	stakeAmount uint64,
+	subnetID ids.ID,
+	nodeID ids.NodeID,
+	assetID ids.ID,
+	startTime uint64,
+	endTime uint64,
+	wallet primary.Wallet,
+	delegationFee uint32,
+	popBytes []byte,
+	blsProof *signer.ProofOfPossession,
+) (*txs.Tx, error) {
 	options := d.getMultisigTxOptions([]ids.ShortID{})
 	owner := &secp256k1fx.OutputOwners{
 		Threshold: 1,
@@ -747,7 +803,7 @@ func (d *PublicDeployer) issueAddPermissionlessValidatorTX(
 			pop := &signer.ProofOfPossession{}
 			err := pop.UnmarshalJSON(popBytes)
 			if err != nil {
-				return ids.Empty, err
+				return nil, err
 			}
 			proofOfPossession = pop
 		} else {
@@ -778,29 +834,13 @@ func (d *PublicDeployer) issueAddPermissionlessValidatorTX(
 		options...,
 	)
 	if err != nil {
-		return ids.Empty, fmt.Errorf("error building tx: %w", err)
+		return nil, fmt.Errorf("error building tx: %w", err)
 	}
 	tx := txs.Tx{Unsigned: unsignedTx}
 	if err := wallet.P().Signer().Sign(context.Background(), &tx); err != nil {
-		return ids.Empty, fmt.Errorf("error signing tx: %w", err)
-	}
-
-	ctx, cancel := utils.GetAPIContext()
-	defer cancel()
-	err = wallet.P().IssueTx(
-		&tx,
-		common.WithContext(ctx),
-	)
-	if err != nil {
-		if ctx.Err() != nil {
-			err = fmt.Errorf("timeout issuing/verifying tx with ID %s: %w", tx.ID(), err)
-		} else {
-			err = fmt.Errorf("error issuing tx with ID %s: %w", tx.ID(), err)
-		}
-		return ids.Empty, err
+		return nil, fmt.Errorf("error signing tx: %w", err)
 	}
-
-	return tx.ID(), nil
+	return &tx, nil
 }
 
 func (d *PublicDeployer) issueAddPermissionlessDelegatorTX(
@@ -0,0 +1,315 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package subnet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/binutils"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/evm"
+	"github.com/ixAnkit/cryft/pkg/key"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/teleporter"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+)
+
+// DeployLocalTeleporter makes sure teleporter is available on the local network C-Chain,
+// and deploys it into the just created local blockchain of [chain], paying with the
+// sidecar teleporter key. Returns the blockchain messenger and registry addresses
+func DeployLocalTeleporter(
+	app *application.Avalanche,
+	sc models.Sidecar,
+	blockchainID ids.ID,
+) (string, string, error) {
+	network := models.NewLocalNetwork()
+	version := sc.TeleporterVersion
+	if version == "" {
+		var err error
+		version, err = teleporter.GetLatestVersion(app)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	ux.Logger.PrintToUser("")
+	td := teleporter.Deployer{}
+	if err := DeployLocalCChainTeleporter(app, &td, version); err != nil {
+		return "", "", err
+	}
+	k, err := key.LoadSoft(network.ID, app.GetKeyPath(sc.TeleporterKey))
+	if err != nil {
+		return "", "", fmt.Errorf("failure loading teleporter key %s: %w", sc.TeleporterKey, err)
+	}
+	privKey := hex.EncodeToString(k.Raw())
+	rpcURL := network.BlockchainEndpoint(blockchainID.String())
+	// warp messages are only delivered after the proposer vm is activated
+	if err := evm.SetupProposerVM(rpcURL, privKey); err != nil {
+		return "", "", err
+	}
+	_, messengerAddress, registryAddress, err := td.Deploy(app, version, sc.Name, rpcURL, privKey, sc.Networks[network.Name()].TeleporterRegistryAddress)
+	if err != nil {
+		return "", "", err
+	}
+	return messengerAddress, registryAddress, nil
+}
+
+// DeployLocalCChainTeleporter deploys teleporter into the local network C-Chain using
+// the ewoq key, unless its addresses were already recorded, and records them. A messenger
+// found on the C-Chain with no recorded addresses gets a new registry
+func DeployLocalCChainTeleporter(
+	app *application.Avalanche,
+	td *teleporter.Deployer,
+	version string,
+) error {
+	network := models.NewLocalNetwork()
+	if data, err := GetExtraLocalNetworkData(app); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	} else if err == nil && data.CChainTeleporterMessengerAddress != "" {
+		return nil
+	}
+	ewoq, err := key.LoadEwoq(network.ID)
+	if err != nil {
+		return err
+	}
+	_, messengerAddress, registryAddress, err := td.Deploy(
+		app,
+		version,
+		"c-chain",
+		network.CChainEndpoint(),
+		hex.EncodeToString(ewoq.Raw()),
+		"",
+	)
+	if err != nil {
+		return err
+	}
+	return WriteExtraLocalNetworkData(app, messengerAddress, registryAddress)
+}
+
+// UpdateLocalRelayerConfig makes the local relayer config, creating it if needed, include
+// the local network C-Chain and every teleporter ready subnet deployed locally, both as
+// source and as destination. The relayer key is funded on all of them
+func UpdateLocalRelayerConfig(app *application.Avalanche) (*teleporter.RelayerConfig, error) {
+	network := models.NewLocalNetwork()
+	configPath := app.GetAWMRelayerConfigPath()
+	config, err := loadOrCreateRelayerConfig(
+		network,
+		configPath,
+		app.GetAWMRelayerStorageDir(),
+		uint16(constants.AWMRelayerMetricsPort+models.CurrentLocalNetworkInstance().PortOffset()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	cChainMessengerAddress := ""
+	if data, err := GetExtraLocalNetworkData(app); err == nil {
+		cChainMessengerAddress = data.CChainTeleporterMessengerAddress
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	deployedSubnets, err := GetLocallyDeployedSubnetsFromFile(app)
+	if err != nil {
+		return nil, err
+	}
+	if err := addRelayerBlockchains(app, network, config, cChainMessengerAddress, deployedSubnets); err != nil {
+		return nil, err
+	}
+	if err := config.Save(configPath); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// UpdateClusterRelayerConfig makes the relayer config of the cluster relayer host with
+// [cloudID], creating it if needed, include the given subnets synced to [clusterName],
+// and on devnets also the C-Chain if teleporter was deployed there
+func UpdateClusterRelayerConfig(
+	app *application.Avalanche,
+	clusterName string,
+	cloudID string,
+	subnetNames []string,
+) (*teleporter.RelayerConfig, error) {
+	clusterConfig, err := app.GetClusterConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	network := clusterConfig.Network
+	configPath := app.GetAWMRelayerServiceConfigPath(app.GetNodeInstanceDirPath(cloudID))
+	config, err := loadOrCreateRelayerConfig(
+		network,
+		configPath,
+		app.GetAWMRelayerServiceStorageDir(constants.CloudNodeCLIConfigBasePath),
+		constants.AWMRelayerMetricsPort,
+	)
+	if err != nil {
+		return nil, err
+	}
+	cChainMessengerAddress := ""
+	if network.Kind == models.Devnet {
+		cChainMessengerAddress = clusterConfig.ExtraNetworkData.CChainTeleporterMessengerAddress
+	}
+	if err := addRelayerBlockchains(app, network, config, cChainMessengerAddress, subnetNames); err != nil {
+		return nil, err
+	}
+	if err := config.Save(configPath); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func loadOrCreateRelayerConfig(
+	network models.Network,
+	configPath string,
+	storageDir string,
+	metricsPort uint16,
+) (*teleporter.RelayerConfig, error) {
+	config, err := teleporter.LoadRelayerConfig(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return teleporter.NewRelayerConfig(network, storageDir, metricsPort), nil
+	}
+	return config, err
+}
+
+// addRelayerBlockchains adds the C-Chain, if [cChainMessengerAddress] is given, and the
+// teleporter ready subnets among [subnetNames] to [config]. The C-Chain is funded with ewoq,
+// so it is only available on local and devnet networks
+func addRelayerBlockchains(
+	app *application.Avalanche,
+	network models.Network,
+	config *teleporter.RelayerConfig,
+	cChainMessengerAddress string,
+	subnetNames []string,
+) error {
+	relayerAddress, relayerPrivateKey, err := teleporter.GetRelayerKeyInfo(app)
+	if err != nil {
+		return err
+	}
+	if cChainMessengerAddress != "" {
+		blockchainID, err := GetChainID(network, "C")
+		if err != nil {
+			return err
+		}
+		ewoq, err := key.LoadEwoq(network.ID)
+		if err != nil {
+			return err
+		}
+		if err := teleporter.FundRelayer(network.CChainEndpoint(), hex.EncodeToString(ewoq.Raw()), relayerAddress); err != nil {
+			return err
+		}
+		addRelayerBlockchain(
+			config,
+			ids.Empty.String(),
+			blockchainID.String(),
+			network.CChainEndpoint(),
+			network.CChainWSEndpoint(),
+			cChainMessengerAddress,
+			relayerAddress,
+			relayerPrivateKey,
+		)
+	}
+	for _, subnetName := range subnetNames {
+		sc, err := app.LoadSidecar(subnetName)
+		if err != nil {
+			return err
+		}
+		networkData := sc.Networks[network.Name()]
+		if !sc.TeleporterReady || networkData.TeleporterMessengerAddress == "" {
+			continue
+		}
+		k, err := key.LoadSoft(network.ID, app.GetKeyPath(sc.TeleporterKey))
+		if err != nil {
+			return err
+		}
+		blockchainID := networkData.BlockchainID.String()
+		if err := teleporter.FundRelayer(network.BlockchainEndpoint(blockchainID), hex.EncodeToString(k.Raw()), relayerAddress); err != nil {
+			return err
+		}
+		addRelayerBlockchain(
+			config,
+			networkData.SubnetID.String(),
+			blockchainID,
+			network.BlockchainEndpoint(blockchainID),
+			network.BlockchainWSEndpoint(blockchainID),
+			networkData.TeleporterMessengerAddress,
+			relayerAddress,
+			relayerPrivateKey,
+		)
+	}
+	return nil
+}
+
+// StartLocalRelayer runs the AWM relayer of [version] for the currently selected local
+// network instance, generating its config first if it does not exist
+func StartLocalRelayer(app *application.Avalanche, version string) error {
+	configPath := app.GetAWMRelayerConfigPath()
+	if !utils.FileExists(configPath) {
+		ux.Logger.PrintToUser("Generating relayer config for the local network...")
+		if _, err := UpdateLocalRelayerConfig(app); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(app.GetAWMRelayerStorageDir(), constants.DefaultPerms755); err != nil {
+		return err
+	}
+	installedVersion, binPath, err := binutils.SetupAWMRelayer(app, version)
+	if err != nil {
+		return err
+	}
+	_, err = teleporter.StartRelayer(
+		binPath,
+		installedVersion,
+		configPath,
+		app.GetAWMRelayerLogPath(),
+		app.GetAWMRelayerRunPath(),
+	)
+	return err
+}
+
+// RefreshLocalRelayer regenerates the local relayer config after a new deploy, and restarts
+// the relayer if it was running or if [startIfStopped] is set, so it picks up new chains
+func RefreshLocalRelayer(app *application.Avalanche, startIfStopped bool) error {
+	runInfo, isRunning, err := teleporter.GetRelayerRunInfo(app.GetAWMRelayerRunPath())
+	if err != nil {
+		return err
+	}
+	if !isRunning && !startIfStopped && !utils.FileExists(app.GetAWMRelayerConfigPath()) {
+		return nil
+	}
+	if _, err := UpdateLocalRelayerConfig(app); err != nil {
+		return err
+	}
+	if !isRunning && !startIfStopped {
+		return nil
+	}
+	version := "latest"
+	if isRunning {
+		version = runInfo.Version
+		if err := teleporter.StopRelayer(app.GetAWMRelayerRunPath()); err != nil {
+			return err
+		}
+		// the new relayer would race the old one for the same storage and metrics port
+		if err := teleporter.WaitForRelayerExit(runInfo.Pid, constants.AWMRelayerStopTimeout); err != nil {
+			return err
+		}
+	}
+	return StartLocalRelayer(app, version)
+}
+
+func addRelayerBlockchain(
+	config *teleporter.RelayerConfig,
+	subnetID string,
+	blockchainID string,
+	rpcEndpoint string,
+	wsEndpoint string,
+	messengerAddress string,
+	relayerAddress string,
+	relayerPrivateKey string,
+) {
+	config.AddSource(subnetID, blockchainID, rpcEndpoint, wsEndpoint, messengerAddress, relayerAddress)
+	config.AddDestination(subnetID, blockchainID, rpcEndpoint, relayerPrivateKey)
+}
@@ -0,0 +1,265 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleporter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/evm"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/ids"
+	"github.com/MetalBlockchain/subnet-evm/accounts/abi"
+	"github.com/MetalBlockchain/subnet-evm/accounts/abi/bind"
+	"github.com/MetalBlockchain/subnet-evm/core/types"
+	"github.com/MetalBlockchain/subnet-evm/ethclient"
+	"github.com/MetalBlockchain/subnet-evm/interfaces"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	sendCrossChainMessageMethod   = "sendCrossChainMessage"
+	sendCrossChainMessageEvent    = "SendCrossChainMessage"
+	receiveCrossChainMessageEvent = "ReceiveCrossChainMessage"
+	erc20ApproveMethod            = "approve"
+	receivePollInterval           = 1 * time.Second
+	teleporterMessageComponents   = `[{"internalType":"uint256","name":"messageNonce","type":"uint256"},{"internalType":"address","name":"originSenderAddress","type":"address"},{"internalType":"bytes32","name":"destinationBlockchainID","type":"bytes32"},{"internalType":"address","name":"destinationAddress","type":"address"},{"internalType":"uint256","name":"requiredGasLimit","type":"uint256"},{"internalType":"address[]","name":"allowedRelayerAddresses","type":"address[]"},{"components":[{"internalType":"uint256","name":"receivedMessageNonce","type":"uint256"},{"internalType":"address","name":"relayerRewardAddress","type":"address"}],"internalType":"struct TeleporterMessageReceipt[]","name":"receipts","type":"tuple[]"},{"internalType":"bytes","name":"message","type":"bytes"}]`
+	teleporterFeeInfoComponents   = `[{"internalType":"address","name":"feeTokenAddress","type":"address"},{"internalType":"uint256","name":"amount","type":"uint256"}]`
+	erc20ABI                      = `[{"inputs":[{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"amount","type":"uint256"}],"name":"approve","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`
+	messengerABIFmt               = `[` +
+		`{"inputs":[{"components":[{"internalType":"bytes32","name":"destinationBlockchainID","type":"bytes32"},{"internalType":"address","name":"destinationAddress","type":"address"},{"components":%[2]s,"internalType":"struct TeleporterFeeInfo","name":"feeInfo","type":"tuple"},{"internalType":"uint256","name":"requiredGasLimit","type":"uint256"},{"internalType":"address[]","name":"allowedRelayerAddresses","type":"address[]"},{"internalType":"bytes","name":"message","type":"bytes"}],"internalType":"struct TeleporterMessageInput","name":"messageInput","type":"tuple"}],"name":"sendCrossChainMessage","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"nonpayable","type":"function"},` +
+		`{"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"messageID","type":"bytes32"},{"indexed":true,"internalType":"bytes32","name":"destinationBlockchainID","type":"bytes32"},{"components":%[1]s,"indexed":false,"internalType":"struct TeleporterMessage","name":"message","type":"tuple"},{"components":%[2]s,"indexed":false,"internalType":"struct TeleporterFeeInfo","name":"feeInfo","type":"tuple"}],"name":"SendCrossChainMessage","type":"event"},` +
+		`{"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"messageID","type":"bytes32"},{"indexed":true,"internalType":"bytes32","name":"sourceBlockchainID","type":"bytes32"},{"indexed":true,"internalType":"address","name":"deliverer","type":"address"},{"indexed":false,"internalType":"address","name":"rewardRedeemer","type":"address"},{"components":%[1]s,"indexed":false,"internalType":"struct TeleporterMessage","name":"message","type":"tuple"}],"name":"ReceiveCrossChainMessage","type":"event"}` +
+		`]`
+)
+
+type feeInfo struct {
+	FeeTokenAddress common.Address
+	Amount          *big.Int
+}
+
+type messageInput struct {
+	DestinationBlockchainID [32]byte
+	DestinationAddress      common.Address
+	FeeInfo                 feeInfo
+	RequiredGasLimit        *big.Int
+	AllowedRelayerAddresses []common.Address
+	Message                 []byte
+}
+
+// MessageInput describes a teleporter message to be sent
+type MessageInput struct {
+	DestinationBlockchainID ids.ID
+	DestinationAddress      common.Address
+	FeeTokenAddress         common.Address
+	FeeAmount               *big.Int
+	RequiredGasLimit        *big.Int
+	Message                 []byte
+}
+
+// SentMessage describes a teleporter message accepted by the source messenger
+type SentMessage struct {
+	MessageID   common.Hash
+	TxHash      common.Hash
+	SentAt      time.Time
+	GasFeesPaid *big.Int
+	FeeToken    common.Address
+	FeeAmount   *big.Int
+}
+
+// ReceivedMessage describes the delivery of a teleporter message on the destination messenger
+type ReceivedMessage struct {
+	TxHash         common.Hash
+	ReceivedAt     time.Time
+	Deliverer      common.Address
+	RewardRedeemer common.Address
+}
+
+func getMessengerABI() (abi.ABI, error) {
+	return abi.JSON(strings.NewReader(fmt.Sprintf(messengerABIFmt, teleporterMessageComponents, teleporterFeeInfoComponents)))
+}
+
+// SendCrossChainMessage sends [input] through the messenger at [messengerAddress], paying with
+// [privateKey]. If a fee is specified, the messenger is first approved to spend it
+func SendCrossChainMessage(
+	rpcURL string,
+	messengerAddress string,
+	privateKey string,
+	input MessageInput,
+) (*SentMessage, error) {
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	feeAmount := input.FeeAmount
+	if feeAmount == nil {
+		feeAmount = big.NewInt(0)
+	}
+	gasFeesPaid := big.NewInt(0)
+	if feeAmount.Sign() > 0 {
+		fees, err := approveFee(client, privateKey, input.FeeTokenAddress, common.HexToAddress(messengerAddress), feeAmount)
+		if err != nil {
+			return nil, err
+		}
+		gasFeesPaid.Add(gasFeesPaid, fees)
+	}
+	messengerABI, err := getMessengerABI()
+	if err != nil {
+		return nil, err
+	}
+	messenger := bind.NewBoundContract(common.HexToAddress(messengerAddress), messengerABI, client, client, client)
+	txOpts, err := evm.GetTxOptsWithSigner(client, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	sentAt := time.Now()
+	tx, err := messenger.Transact(txOpts, sendCrossChainMessageMethod, messageInput{
+		DestinationBlockchainID: input.DestinationBlockchainID,
+		DestinationAddress:      input.DestinationAddress,
+		FeeInfo: feeInfo{
+			FeeTokenAddress: input.FeeTokenAddress,
+			Amount:          feeAmount,
+		},
+		RequiredGasLimit:        input.RequiredGasLimit,
+		AllowedRelayerAddresses: []common.Address{},
+		Message:                 input.Message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failure sending teleporter message: %w", err)
+	}
+	receipt, success, err := evm.WaitForTransaction(client, tx)
+	if err != nil {
+		return nil, err
+	} else if !success {
+		return nil, fmt.Errorf("failure sending teleporter message: tx %s failed", tx.Hash())
+	}
+	gasFeesPaid.Add(gasFeesPaid, txFee(receipt))
+	eventID := messengerABI.Events[sendCrossChainMessageEvent].ID
+	for _, log := range receipt.Logs {
+		if log.Address == common.HexToAddress(messengerAddress) && len(log.Topics) > 1 && log.Topics[0] == eventID {
+			return &SentMessage{
+				MessageID:   log.Topics[1],
+				TxHash:      tx.Hash(),
+				SentAt:      sentAt,
+				GasFeesPaid: gasFeesPaid,
+				FeeToken:    input.FeeTokenAddress,
+				FeeAmount:   feeAmount,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s event not found on tx %s", sendCrossChainMessageEvent, tx.Hash())
+}
+
+// WaitForMessageReceipt polls the messenger at [messengerAddress] until it emits the
+// ReceiveCrossChainMessage event for [messageID], or [timeout] expires. Failed polls
+// are retried until then
+func WaitForMessageReceipt(
+	rpcURL string,
+	messengerAddress string,
+	messageID common.Hash,
+	fromBlock uint64,
+	timeout time.Duration,
+) (*ReceivedMessage, error) {
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	messengerABI, err := getMessengerABI()
+	if err != nil {
+		return nil, err
+	}
+	messenger := bind.NewBoundContract(common.HexToAddress(messengerAddress), messengerABI, client, client, client)
+	query := interfaces.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		Addresses: []common.Address{common.HexToAddress(messengerAddress)},
+		Topics:    [][]common.Hash{{messengerABI.Events[receiveCrossChainMessageEvent].ID}, {messageID}},
+	}
+	deadline := time.Now().Add(timeout)
+	var pollErr error
+	for time.Now().Before(deadline) {
+		ctx, cancel := utils.GetAPIContext()
+		logs, err := client.FilterLogs(ctx, query)
+		cancel()
+		pollErr = err
+		if err != nil {
+			ux.Logger.Info("failed polling for teleporter message %s receipt: %s", messageID, err)
+			time.Sleep(receivePollInterval)
+			continue
+		}
+		if len(logs) > 0 {
+			log := logs[0]
+			received := ReceivedMessage{
+				TxHash:     log.TxHash,
+				ReceivedAt: time.Now(),
+			}
+			if len(log.Topics) > 3 {
+				received.Deliverer = common.BytesToAddress(log.Topics[3].Bytes())
+			}
+			event := map[string]interface{}{}
+			if err := messenger.UnpackLogIntoMap(event, receiveCrossChainMessageEvent, log); err == nil {
+				if rewardRedeemer, ok := event["rewardRedeemer"].(common.Address); ok {
+					received.RewardRedeemer = rewardRedeemer
+				}
+			}
+			return &received, nil
+		}
+		time.Sleep(receivePollInterval)
+	}
+	if pollErr != nil {
+		return nil, fmt.Errorf("teleporter message %s was not received after %s: %w", messageID, timeout, pollErr)
+	}
+	return nil, fmt.Errorf("teleporter message %s was not received after %s", messageID, timeout)
+}
+
+// GetBlockNumber returns the current block number of the EVM chain at [rpcURL]
+func GetBlockNumber(rpcURL string) (uint64, error) {
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	return client.BlockNumber(ctx)
+}
+
+func approveFee(
+	client ethclient.Client,
+	privateKey string,
+	feeTokenAddress common.Address,
+	messengerAddress common.Address,
+	amount *big.Int,
+) (*big.Int, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, err
+	}
+	token := bind.NewBoundContract(feeTokenAddress, parsedABI, client, client, client)
+	txOpts, err := evm.GetTxOptsWithSigner(client, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := token.Transact(txOpts, erc20ApproveMethod, messengerAddress, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failure approving teleporter fee: %w", err)
+	}
+	receipt, success, err := evm.WaitForTransaction(client, tx)
+	if err != nil {
+		return nil, err
+	} else if !success {
+		return nil, fmt.Errorf("failure approving teleporter fee: tx %s failed", tx.Hash())
+	}
+	return txFee(receipt), nil
+}
+
+func txFee(receipt *types.Receipt) *big.Int {
+	if receipt.EffectiveGasPrice == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// newFilterLogsServer returns a JSON-RPC server failing the first [failures] eth_getLogs
+// calls, and returning [logs] afterwards
+func newFilterLogsServer(t *testing.T, failures int32, logs []map[string]interface{}) (*httptest.Server, *atomic.Int32) {
+	calls := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID}
+		if call := calls.Add(1); call <= failures {
+			response["error"] = map[string]interface{}{"code": -32000, "message": "node is temporarily unavailable"}
+		} else {
+			response["result"] = logs
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+func TestWaitForMessageReceipt(t *testing.T) {
+	ux.NewUserLog(logging.NoLog{}, io.Discard)
+	messengerABI, err := getMessengerABI()
+	require.NoError(t, err)
+	messengerAddress := "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf"
+	messageID := common.HexToHash("0x01")
+	txHash := common.HexToHash("0x02")
+	deliverer := common.HexToAddress("0x03")
+	receiptLog := map[string]interface{}{
+		"address": messengerAddress,
+		"topics": []string{
+			messengerABI.Events[receiveCrossChainMessageEvent].ID.Hex(),
+			messageID.Hex(),
+			common.HexToHash("0x04").Hex(),
+			common.BytesToHash(deliverer.Bytes()).Hex(),
+		},
+		"data":             "0x",
+		"blockNumber":      "0x1",
+		"blockHash":        common.HexToHash("0x05").Hex(),
+		"transactionHash":  txHash.Hex(),
+		"transactionIndex": "0x0",
+		"logIndex":         "0x0",
+		"removed":          false,
+	}
+
+	t.Run("transient errors are retried", func(t *testing.T) {
+		require := require.New(t)
+		server, calls := newFilterLogsServer(t, 2, []map[string]interface{}{receiptLog})
+		received, err := WaitForMessageReceipt(server.URL, messengerAddress, messageID, 0, time.Minute)
+		require.NoError(err)
+		require.Equal(txHash, received.TxHash)
+		require.Equal(deliverer, received.Deliverer)
+		require.Equal(int32(3), calls.Load())
+	})
+
+	t.Run("last error is returned on timeout", func(t *testing.T) {
+		require := require.New(t)
+		server, calls := newFilterLogsServer(t, 1000, nil)
+		_, err := WaitForMessageReceipt(server.URL, messengerAddress, messageID, 0, 2*receivePollInterval)
+		require.ErrorContains(err, fmt.Sprintf("teleporter message %s was not received", messageID))
+		require.ErrorContains(err, "node is temporarily unavailable")
+		require.Greater(calls.Load(), int32(1))
+	})
+}
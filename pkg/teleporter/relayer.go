@@ -0,0 +1,367 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleporter
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/evm"
+	"github.com/ixAnkit/cryft/pkg/key"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/MetalBlockchain/metalgo/utils/perms"
+	"github.com/shirou/gopsutil/process"
+)
+
+const (
+	relayerServiceTemplate = `[Unit]
+Description=AWM Relayer systemd service
+StartLimitIntervalSec=0
+[Service]
+Type=simple
+User=ubuntu
+WorkingDirectory=/home/ubuntu
+ExecStart=%s --config-file %s
+Restart=on-failure
+RestartSec=1
+[Install]
+WantedBy=multi-user.target
+`
+	relayerVMName            = "evm"
+	relayerMessageFormat     = "teleporter"
+	relayerRewardAddressFlag = "reward-address"
+	relayerExitPollInterval  = 100 * time.Millisecond
+)
+
+// 10 AVAX
+var relayerRequiredBalance = new(big.Int).Mul(big.NewInt(1e18), big.NewInt(10))
+
+// RelayerConfig is the subset of the AWM relayer configuration managed by the CLI
+type RelayerConfig struct {
+	LogLevel               string                `json:"log-level"`
+	PChainAPIURL           string                `json:"p-chain-api-url"`
+	InfoAPIURL             string                `json:"info-api-url"`
+	StorageLocation        string                `json:"storage-location"`
+	ProcessMissedBlocks    bool                  `json:"process-missed-blocks"`
+	SourceBlockchains      []*RelayerSource      `json:"source-blockchains"`
+	DestinationBlockchains []*RelayerDestination `json:"destination-blockchains"`
+	MetricsPort            uint16                `json:"metrics-port"`
+}
+
+type RelayerMessageProtocolConfig struct {
+	MessageFormat string                 `json:"message-format"`
+	Settings      map[string]interface{} `json:"settings"`
+}
+
+type RelayerSource struct {
+	SubnetID         string                                  `json:"subnet-id"`
+	BlockchainID     string                                  `json:"blockchain-id"`
+	VM               string                                  `json:"vm"`
+	RPCEndpoint      string                                  `json:"rpc-endpoint"`
+	WSEndpoint       string                                  `json:"ws-endpoint"`
+	MessageContracts map[string]RelayerMessageProtocolConfig `json:"message-contracts"`
+}
+
+type RelayerDestination struct {
+	SubnetID          string `json:"subnet-id"`
+	BlockchainID      string `json:"blockchain-id"`
+	VM                string `json:"vm"`
+	RPCEndpoint       string `json:"rpc-endpoint"`
+	AccountPrivateKey string `json:"account-private-key"`
+}
+
+// NewRelayerConfig returns a relayer config for [network] without sources nor destinations
+func NewRelayerConfig(network models.Network, storageLocation string, metricsPort uint16) *RelayerConfig {
+	return &RelayerConfig{
+		LogLevel:               logging.Info.LowerString(),
+		PChainAPIURL:           network.Endpoint,
+		InfoAPIURL:             network.Endpoint,
+		StorageLocation:        storageLocation,
+		ProcessMissedBlocks:    false,
+		SourceBlockchains:      []*RelayerSource{},
+		DestinationBlockchains: []*RelayerDestination{},
+		MetricsPort:            metricsPort,
+	}
+}
+
+func LoadRelayerConfig(configPath string) (*RelayerConfig, error) {
+	bs, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	config := RelayerConfig{}
+	if err := json.Unmarshal(bs, &config); err != nil {
+		return nil, fmt.Errorf("failed unmarshalling relayer config %s: %w", configPath, err)
+	}
+	return &config, nil
+}
+
+// Save writes the config to [configPath], readable only by the user as it holds the
+// private keys of the relayer accounts
+func (c *RelayerConfig) Save(configPath string) error {
+	bs, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), constants.DefaultPerms755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(configPath, bs, constants.WriteReadUserOnlyPerms); err != nil {
+		return err
+	}
+	// configs saved before keep their permissions on write
+	return os.Chmod(configPath, constants.WriteReadUserOnlyPerms)
+}
+
+// AddSource makes the relayer listen to teleporter messages sent from the given blockchain,
+// replacing any previous source settings for it
+func (c *RelayerConfig) AddSource(
+	subnetID string,
+	blockchainID string,
+	rpcEndpoint string,
+	wsEndpoint string,
+	messengerAddress string,
+	rewardAddress string,
+) {
+	source := &RelayerSource{
+		SubnetID:     subnetID,
+		BlockchainID: blockchainID,
+		VM:           relayerVMName,
+		RPCEndpoint:  rpcEndpoint,
+		WSEndpoint:   wsEndpoint,
+		MessageContracts: map[string]RelayerMessageProtocolConfig{
+			messengerAddress: {
+				MessageFormat: relayerMessageFormat,
+				Settings: map[string]interface{}{
+					relayerRewardAddressFlag: rewardAddress,
+				},
+			},
+		},
+	}
+	for i, s := range c.SourceBlockchains {
+		if s.BlockchainID == blockchainID {
+			c.SourceBlockchains[i] = source
+			return
+		}
+	}
+	c.SourceBlockchains = append(c.SourceBlockchains, source)
+}
+
+// AddDestination makes the relayer deliver messages into the given blockchain, paying
+// with [privateKey], replacing any previous destination settings for it
+func (c *RelayerConfig) AddDestination(
+	subnetID string,
+	blockchainID string,
+	rpcEndpoint string,
+	privateKey string,
+) {
+	destination := &RelayerDestination{
+		SubnetID:          subnetID,
+		BlockchainID:      blockchainID,
+		VM:                relayerVMName,
+		RPCEndpoint:       rpcEndpoint,
+		AccountPrivateKey: privateKey,
+	}
+	for i, d := range c.DestinationBlockchains {
+		if d.BlockchainID == blockchainID {
+			c.DestinationBlockchains[i] = destination
+			return
+		}
+	}
+	c.DestinationBlockchains = append(c.DestinationBlockchains, destination)
+}
+
+// GetRelayerKeyInfo returns the address and the hex encoded private key of the relayer key,
+// creating it if it does not exist
+func GetRelayerKeyInfo(app *application.Avalanche) (string, string, error) {
+	keyPath := app.GetKeyPath(constants.AWMRelayerKeyName)
+	var (
+		k   *key.SoftKey
+		err error
+	)
+	if utils.FileExists(keyPath) {
+		k, err = key.LoadSoft(models.NewLocalNetwork().ID, keyPath)
+	} else {
+		k, err = key.NewSoft(0)
+		if err == nil {
+			err = k.Save(keyPath)
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return k.C(), hex.EncodeToString(k.Raw()), nil
+}
+
+// FundRelayer makes sure the relayer address has enough balance on the chain at [rpcURL]
+// to pay for message deliveries
+func FundRelayer(rpcURL string, prefundedPrivateKey string, relayerAddress string) error {
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	balance, err := evm.GetAddressBalance(client, relayerAddress)
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(relayerRequiredBalance) >= 0 {
+		return nil
+	}
+	toFund := new(big.Int).Sub(relayerRequiredBalance, balance)
+	return evm.FundAddress(client, prefundedPrivateKey, relayerAddress, toFund)
+}
+
+// RelayerRunInfo describes a relayer process started by the CLI, as stored on its run file
+type RelayerRunInfo struct {
+	Pid        int    `json:"pid"`
+	Version    string `json:"version"`
+	ConfigPath string `json:"configPath"`
+	LogPath    string `json:"logPath"`
+}
+
+// GetRelayerRunInfo returns the info of the relayer started with [runFilePath], if it is running
+func GetRelayerRunInfo(runFilePath string) (*RelayerRunInfo, bool, error) {
+	bs, err := os.ReadFile(runFilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	info := &RelayerRunInfo{}
+	if err := json.Unmarshal(bs, info); err != nil {
+		return nil, false, fmt.Errorf("failed unmarshalling relayer run file %s: %w", runFilePath, err)
+	}
+	running, err := process.PidExists(int32(info.Pid))
+	if err != nil {
+		return nil, false, err
+	}
+	return info, running, nil
+}
+
+// StartRelayer runs the relayer binary in background with the given config, appending its
+// output to [logFilePath] and recording the process info at [runFilePath]
+func StartRelayer(
+	binPath string,
+	version string,
+	configPath string,
+	logFilePath string,
+	runFilePath string,
+) (int, error) {
+	if _, running, err := GetRelayerRunInfo(runFilePath); err != nil {
+		return 0, err
+	} else if running {
+		return 0, fmt.Errorf("relayer is already running")
+	}
+	logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, perms.ReadWrite)
+	if err != nil {
+		return 0, err
+	}
+	defer logFile.Close()
+	cmd := exec.Command(binPath, "--config-file", configPath)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	rf := RelayerRunInfo{
+		Pid:        cmd.Process.Pid,
+		Version:    version,
+		ConfigPath: configPath,
+		LogPath:    logFilePath,
+	}
+	bs, err := json.Marshal(&rf)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(runFilePath, bs, perms.ReadWrite); err != nil {
+		return 0, fmt.Errorf("could not write relayer process info to file: %w", err)
+	}
+	ux.Logger.PrintToUser("AWM Relayer started, pid: %d, output at: %s", cmd.Process.Pid, logFilePath)
+	return cmd.Process.Pid, nil
+}
+
+// StopRelayer terminates the relayer recorded at [runFilePath], if any
+func StopRelayer(runFilePath string) error {
+	info, running, err := GetRelayerRunInfo(runFilePath)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return nil
+	}
+	if running {
+		proc, err := os.FindProcess(info.Pid)
+		if err != nil {
+			return fmt.Errorf("could not find relayer process with pid %d: %w", info.Pid, err)
+		}
+		if err := proc.Signal(os.Interrupt); err != nil {
+			return fmt.Errorf("failed stopping relayer process with pid %d: %w", info.Pid, err)
+		}
+	}
+	return os.Remove(runFilePath)
+}
+
+// WaitForRelayerExit waits up to [timeout] for the relayer process with [pid] to exit
+func WaitForRelayerExit(pid int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		exited, err := processExited(pid)
+		if err != nil {
+			return err
+		}
+		if exited {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("relayer process with pid %d did not exit after %s", pid, timeout)
+		}
+		time.Sleep(relayerExitPollInterval)
+	}
+}
+
+// processExited tells if the process with [pid] is gone. Zombies are considered exited, as
+// a relayer started by this same process is not reaped until it exits
+func processExited(pid int) (bool, error) {
+	exists, err := process.PidExists(int32(pid))
+	if err != nil || !exists {
+		return !exists, err
+	}
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return false, err
+	}
+	status, err := proc.Status()
+	if err != nil {
+		// the process may have been gone in between
+		exists, existsErr := process.PidExists(int32(pid))
+		if existsErr == nil && !exists {
+			return true, nil
+		}
+		return false, err
+	}
+	return status == "Z", nil
+}
+
+// CreateRelayerServiceFile writes a systemd unit at [servicePath] that runs the relayer
+// binary at [binPath] with the config at [configPath]
+func CreateRelayerServiceFile(servicePath string, binPath string, configPath string) error {
+	if err := os.MkdirAll(filepath.Dir(servicePath), constants.DefaultPerms755); err != nil {
+		return err
+	}
+	service := fmt.Sprintf(relayerServiceTemplate, binPath, configPath)
+	return os.WriteFile(servicePath, []byte(service), constants.WriteReadReadPerms)
+}
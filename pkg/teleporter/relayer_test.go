@@ -0,0 +1,72 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleporter
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayerConfigAddBlockchains(t *testing.T) {
+	require := require.New(t)
+	config := NewRelayerConfig(models.NewLocalNetwork(), "storage", 9091)
+	config.AddSource("subnet1", "chain1", "rpc1", "ws1", "0xmessenger", "0xreward")
+	config.AddDestination("subnet1", "chain1", "rpc1", "key1")
+	config.AddSource("subnet2", "chain2", "rpc2", "ws2", "0xmessenger", "0xreward")
+	// re-adding a blockchain replaces its previous settings
+	config.AddSource("subnet1", "chain1", "rpc1b", "ws1b", "0xmessenger", "0xreward2")
+	config.AddDestination("subnet1", "chain1", "rpc1b", "key1b")
+	require.Len(config.SourceBlockchains, 2)
+	require.Len(config.DestinationBlockchains, 1)
+	require.Equal("rpc1b", config.SourceBlockchains[0].RPCEndpoint)
+	require.Equal("0xreward2", config.SourceBlockchains[0].MessageContracts["0xmessenger"].Settings[relayerRewardAddressFlag])
+	require.Equal("key1b", config.DestinationBlockchains[0].AccountPrivateKey)
+
+	configPath := filepath.Join(t.TempDir(), "relayer", "config.json")
+	require.NoError(config.Save(configPath))
+	loaded, err := LoadRelayerConfig(configPath)
+	require.NoError(err)
+	require.Equal(config, loaded)
+
+	// the config holds private keys, so it is only readable by the user, also when
+	// replacing a config saved readable by others
+	info, err := os.Stat(configPath)
+	require.NoError(err)
+	require.Equal(os.FileMode(constants.WriteReadUserOnlyPerms), info.Mode().Perm())
+	require.NoError(os.Chmod(configPath, constants.WriteReadReadPerms))
+	require.NoError(config.Save(configPath))
+	info, err = os.Stat(configPath)
+	require.NoError(err)
+	require.Equal(os.FileMode(constants.WriteReadUserOnlyPerms), info.Mode().Perm())
+}
+
+func TestCreateRelayerServiceFile(t *testing.T) {
+	require := require.New(t)
+	servicePath := filepath.Join(t.TempDir(), "services", "awm-relayer.service")
+	require.NoError(CreateRelayerServiceFile(servicePath, "/bin/awm-relayer", "/conf/relayer.json"))
+	bs, err := os.ReadFile(servicePath)
+	require.NoError(err)
+	require.Contains(string(bs), "ExecStart=/bin/awm-relayer --config-file /conf/relayer.json\n")
+}
+
+func TestWaitForRelayerExit(t *testing.T) {
+	require := require.New(t)
+	cmd := exec.Command("sleep", "30")
+	require.NoError(cmd.Start())
+	t.Cleanup(func() { _ = cmd.Process.Kill() })
+	err := WaitForRelayerExit(cmd.Process.Pid, 200*time.Millisecond)
+	require.ErrorContains(err, "did not exit")
+
+	// the killed process is not reaped, so it is a zombie of this one
+	require.NoError(cmd.Process.Signal(os.Interrupt))
+	require.NoError(WaitForRelayerExit(cmd.Process.Pid, 5*time.Second))
+	_ = cmd.Wait()
+	require.NoError(WaitForRelayerExit(cmd.Process.Pid, time.Second))
+}
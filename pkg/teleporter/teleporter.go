@@ -0,0 +1,278 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleporter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/binutils"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/evm"
+	"github.com/ixAnkit/cryft/pkg/key"
+	"github.com/ixAnkit/cryft/pkg/models"
+	"github.com/ixAnkit/cryft/pkg/utils"
+	"github.com/ixAnkit/cryft/pkg/ux"
+	"github.com/MetalBlockchain/subnet-evm/accounts/abi"
+	"github.com/MetalBlockchain/subnet-evm/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	releaseURL                      = "https://github.com/%s/%s/releases/download/%s/%s"
+	messengerContractAddressFileFmt = "TeleporterMessenger_Contract_Address_%s.txt"
+	messengerDeployerAddressFileFmt = "TeleporterMessenger_Deployer_Address_%s.txt"
+	messengerDeployerTxFileFmt      = "TeleporterMessenger_Deployment_Transaction_%s.txt"
+	registryBytecodeFileFmt         = "TeleporterRegistry_Bytecode_%s.txt"
+
+	// registryABI only describes the constructor, which is all that is needed to deploy the registry
+	registryABI = `[{"inputs":[{"components":[{"internalType":"uint256","name":"version","type":"uint256"},{"internalType":"address","name":"protocolAddress","type":"address"}],"internalType":"struct ProtocolRegistryEntry[]","name":"initialEntries","type":"tuple[]"}],"stateMutability":"nonpayable","type":"constructor"}]`
+)
+
+var (
+	// 10 AVAX
+	messengerDeployerRequiredBalance = new(big.Int).Mul(big.NewInt(1e18), big.NewInt(10))
+	// 600 AVAX
+	PrefundedKeyBalance = new(big.Int).Mul(big.NewInt(1e18), big.NewInt(600))
+)
+
+// protocolRegistryEntry matches the ProtocolRegistryEntry struct expected by the
+// registry constructor
+type protocolRegistryEntry struct {
+	Version         *big.Int
+	ProtocolAddress common.Address
+}
+
+// Deployer deploys the teleporter messenger and registry contracts of a given release.
+// Release assets are downloaded once and cached under the teleporter bin dir.
+type Deployer struct {
+	version                  string
+	messengerContractAddress string
+	messengerDeployerAddress string
+	messengerDeployerTx      string
+	registryBytecode         string
+}
+
+// GetLatestVersion returns the latest released version of the teleporter contracts
+func GetLatestVersion(app *application.Avalanche) (string, error) {
+	return app.Downloader.GetLatestReleaseVersion(binutils.GetGithubLatestReleaseURL(
+		constants.AvaLabsOrg,
+		constants.TeleporterRepoName,
+	))
+}
+
+// GetAssets returns the messenger contract address, the messenger deployer address,
+// the messenger keyless deploy tx, and the registry bytecode for [version]
+func (t *Deployer) GetAssets(app *application.Avalanche, version string) (string, string, string, string, error) {
+	if err := t.DownloadAssets(app, version); err != nil {
+		return "", "", "", "", err
+	}
+	return t.messengerContractAddress, t.messengerDeployerAddress, t.messengerDeployerTx, t.registryBytecode, nil
+}
+
+// DownloadAssets loads the release assets for [version], downloading them into the
+// teleporter bin dir if they are not already there
+func (t *Deployer) DownloadAssets(app *application.Avalanche, version string) error {
+	if t.version == version && t.messengerContractAddress != "" {
+		return nil
+	}
+	assetsDir := filepath.Join(app.GetTeleporterBinDir(), version)
+	if err := os.MkdirAll(assetsDir, constants.DefaultPerms755); err != nil {
+		return err
+	}
+	assets := []struct {
+		fileFmt string
+		dest    *string
+	}{
+		{messengerContractAddressFileFmt, &t.messengerContractAddress},
+		{messengerDeployerAddressFileFmt, &t.messengerDeployerAddress},
+		{messengerDeployerTxFileFmt, &t.messengerDeployerTx},
+		{registryBytecodeFileFmt, &t.registryBytecode},
+	}
+	for _, asset := range assets {
+		fileName := fmt.Sprintf(asset.fileFmt, version)
+		assetPath := filepath.Join(assetsDir, fileName)
+		var (
+			bs  []byte
+			err error
+		)
+		if utils.FileExists(assetPath) {
+			bs, err = os.ReadFile(assetPath)
+			if err != nil {
+				return err
+			}
+		} else {
+			url := fmt.Sprintf(releaseURL, constants.AvaLabsOrg, constants.TeleporterRepoName, version, fileName)
+			bs, err = app.Downloader.Download(url)
+			if err != nil {
+				return fmt.Errorf("failure downloading teleporter asset %s: %w", url, err)
+			}
+			if err := os.WriteFile(assetPath, bs, constants.WriteReadReadPerms); err != nil {
+				return err
+			}
+		}
+		*asset.dest = strings.TrimSpace(string(bs))
+	}
+	t.version = version
+	return nil
+}
+
+// Deploy deploys both the messenger and the registry into the EVM chain at [rpcURL],
+// using [privateKey] to pay for fees. [registryAddress] is the registry already recorded
+// for the chain, if any. If the messenger was already deployed, the recorded registry is
+// kept, and a new one is only deployed when none was recorded. Returns whether the
+// messenger was already deployed, and both contract addresses
+func (t *Deployer) Deploy(
+	app *application.Avalanche,
+	version string,
+	chainName string,
+	rpcURL string,
+	privateKey string,
+	registryAddress string,
+) (bool, string, string, error) {
+	alreadyDeployed, messengerAddress, err := t.DeployMessenger(app, version, chainName, rpcURL, privateKey)
+	if err != nil {
+		return false, "", "", err
+	}
+	if alreadyDeployed && registryAddress != "" {
+		return true, messengerAddress, registryAddress, nil
+	}
+	registryAddress, err = t.DeployRegistry(app, version, chainName, rpcURL, privateKey)
+	if err != nil {
+		return false, "", "", err
+	}
+	return alreadyDeployed, messengerAddress, registryAddress, nil
+}
+
+// DeployMessenger funds the messenger deployer address if needed, and issues the
+// keyless messenger deploy tx. Returns true if the messenger was already deployed
+func (t *Deployer) DeployMessenger(
+	app *application.Avalanche,
+	version string,
+	chainName string,
+	rpcURL string,
+	privateKey string,
+) (bool, string, error) {
+	if err := t.DownloadAssets(app, version); err != nil {
+		return false, "", err
+	}
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return false, "", err
+	}
+	defer client.Close()
+	if alreadyDeployed, err := evm.ContractAlreadyDeployed(client, t.messengerContractAddress); err != nil {
+		return false, "", fmt.Errorf("failure making a request to %s: %w", rpcURL, err)
+	} else if alreadyDeployed {
+		ux.Logger.PrintToUser("Teleporter Messenger has already been deployed to %s", chainName)
+		return true, t.messengerContractAddress, nil
+	}
+	balance, err := evm.GetAddressBalance(client, t.messengerDeployerAddress)
+	if err != nil {
+		return false, "", err
+	}
+	if balance.Cmp(messengerDeployerRequiredBalance) < 0 {
+		toFund := new(big.Int).Sub(messengerDeployerRequiredBalance, balance)
+		if err := evm.FundAddress(client, privateKey, t.messengerDeployerAddress, toFund); err != nil {
+			return false, "", fmt.Errorf("failure funding teleporter messenger deployer %s: %w", t.messengerDeployerAddress, err)
+		}
+	}
+	if err := evm.IssueTx(client, t.messengerDeployerTx); err != nil {
+		return false, "", fmt.Errorf("failure deploying teleporter messenger: %w", err)
+	}
+	ux.Logger.PrintToUser("Teleporter Messenger successfully deployed to %s (%s)", chainName, t.messengerContractAddress)
+	return false, t.messengerContractAddress, nil
+}
+
+// DeployRegistry deploys a registry that has the messenger as its initial version 1 entry
+func (t *Deployer) DeployRegistry(
+	app *application.Avalanche,
+	version string,
+	chainName string,
+	rpcURL string,
+	privateKey string,
+) (string, error) {
+	if err := t.DownloadAssets(app, version); err != nil {
+		return "", err
+	}
+	parsedABI, err := abi.JSON(strings.NewReader(registryABI))
+	if err != nil {
+		return "", err
+	}
+	bytecode, err := hex.DecodeString(strings.TrimPrefix(t.registryBytecode, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid teleporter registry bytecode: %w", err)
+	}
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	txOpts, err := evm.GetTxOptsWithSigner(client, privateKey)
+	if err != nil {
+		return "", err
+	}
+	initialEntries := []protocolRegistryEntry{
+		{
+			Version:         big.NewInt(1),
+			ProtocolAddress: common.HexToAddress(t.messengerContractAddress),
+		},
+	}
+	registryAddress, tx, _, err := bind.DeployContract(txOpts, parsedABI, bytecode, client, initialEntries)
+	if err != nil {
+		return "", fmt.Errorf("failure deploying teleporter registry: %w", err)
+	}
+	if _, success, err := evm.WaitForTransaction(client, tx); err != nil {
+		return "", err
+	} else if !success {
+		return "", fmt.Errorf("failure deploying teleporter registry: tx %s failed", tx.Hash())
+	}
+	ux.Logger.PrintToUser("Teleporter Registry successfully deployed to %s (%s)", chainName, registryAddress.Hex())
+	return registryAddress.Hex(), nil
+}
+
+// Info describes the key that is prefunded on teleporter ready subnets in order
+// to pay for the contract deploys
+type Info struct {
+	Version       string
+	Key           *key.SoftKey
+	FundedAddress string
+	FundedBalance *big.Int
+}
+
+// GetInfo loads the teleporter deployer key, creating it if needed, together with
+// the default teleporter version. No release lookup is made, so subnets can be
+// created offline
+func GetInfo(app *application.Avalanche) (*Info, error) {
+	k, err := GetKey(app)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{
+		Version:       constants.DefaultTeleporterVersion,
+		Key:           k,
+		FundedAddress: k.C(),
+		FundedBalance: PrefundedKeyBalance,
+	}, nil
+}
+
+// GetKey loads the teleporter deployer key, creating it if it does not exist
+func GetKey(app *application.Avalanche) (*key.SoftKey, error) {
+	keyPath := app.GetKeyPath(constants.TeleporterKeyName)
+	if utils.FileExists(keyPath) {
+		return key.LoadSoft(models.NewLocalNetwork().ID, keyPath)
+	}
+	k, err := key.NewSoft(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.Save(keyPath); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
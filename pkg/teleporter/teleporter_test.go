@@ -0,0 +1,88 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package teleporter
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ixAnkit/cryft/internal/mocks"
+	"github.com/ixAnkit/cryft/pkg/application"
+	"github.com/ixAnkit/cryft/pkg/config"
+	"github.com/ixAnkit/cryft/pkg/constants"
+	"github.com/ixAnkit/cryft/pkg/prompts"
+	"github.com/MetalBlockchain/metalgo/utils/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadAssets(t *testing.T) {
+	require := require.New(t)
+	version := "v1.0.0"
+	assets := map[string]string{
+		fmt.Sprintf(messengerContractAddressFileFmt, version): "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf\n",
+		fmt.Sprintf(messengerDeployerAddressFileFmt, version): "0x618FEdD9A45a8C456812ecAAE70C671c6249DfaC\n",
+		fmt.Sprintf(messengerDeployerTxFileFmt, version):      "0xf9\n",
+		fmt.Sprintf(registryBytecodeFileFmt, version):         "0x60\n",
+	}
+	downloader := &mocks.Downloader{}
+	for fileName, content := range assets {
+		url := fmt.Sprintf(releaseURL, constants.AvaLabsOrg, constants.TeleporterRepoName, version, fileName)
+		downloader.On("Download", url).Return([]byte(content), nil).Once()
+	}
+	app := &application.Avalanche{}
+	app.Setup(t.TempDir(), logging.NoLog{}, config.New(), prompts.NewPrompter(), downloader)
+
+	td := Deployer{}
+	messengerContractAddress, messengerDeployerAddress, messengerDeployerTx, registryBytecode, err := td.GetAssets(app, version)
+	require.NoError(err)
+	require.Equal("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf", messengerContractAddress)
+	require.Equal("0x618FEdD9A45a8C456812ecAAE70C671c6249DfaC", messengerDeployerAddress)
+	require.Equal("0xf9", messengerDeployerTx)
+	require.Equal("0x60", registryBytecode)
+
+	// a new deployer reads the assets from disk instead of downloading them again
+	td = Deployer{}
+	_, _, _, _, err = td.GetAssets(app, version)
+	require.NoError(err)
+	downloader.AssertExpectations(t)
+	downloader.AssertNumberOfCalls(t, "Download", len(assets))
+	downloader.AssertNotCalled(t, "GetLatestReleaseVersion", mock.Anything)
+}
+
+func TestGetInfo(t *testing.T) {
+	require := require.New(t)
+	downloader := &mocks.Downloader{}
+	app := &application.Avalanche{}
+	app.Setup(t.TempDir(), logging.NoLog{}, config.New(), prompts.NewPrompter(), downloader)
+	require.NoError(os.MkdirAll(app.GetKeyDir(), constants.DefaultPerms755))
+
+	info, err := GetInfo(app)
+	require.NoError(err)
+	require.Equal(constants.DefaultTeleporterVersion, info.Version)
+	require.Equal(info.Key.C(), info.FundedAddress)
+	// the deployer key is created once
+	again, err := GetInfo(app)
+	require.NoError(err)
+	require.Equal(info.FundedAddress, again.FundedAddress)
+	downloader.AssertNotCalled(t, "GetLatestReleaseVersion", mock.Anything)
+}
+
+func TestMessengerABI(t *testing.T) {
+	require := require.New(t)
+	messengerABI, err := getMessengerABI()
+	require.NoError(err)
+	require.Contains(messengerABI.Methods, sendCrossChainMessageMethod)
+	require.Contains(messengerABI.Events, sendCrossChainMessageEvent)
+	require.Contains(messengerABI.Events, receiveCrossChainMessageEvent)
+	_, err = messengerABI.Pack(sendCrossChainMessageMethod, messageInput{
+		FeeInfo:                 feeInfo{Amount: big.NewInt(0)},
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{},
+		Message:                 []byte("hello"),
+	})
+	require.NoError(err)
+}
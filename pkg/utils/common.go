@@ -123,6 +123,20 @@ func Filter[T any](input []T, f func(T) bool) []T {
 	return output
 }
 
+func FilterWithError[T any](input []T, f func(T) (bool, error)) ([]T, error) {
+	output := make([]T, 0, len(input))
+	for _, e := range input {
+		keep, err := f(e)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			output = append(output, e)
+		}
+	}
+	return output, nil
+}
+
 func Map[T, U any](input []T, f func(T) U) []U {
 	output := make([]U, 0, len(input))
 	for _, e := range input {
@@ -19,13 +19,13 @@ const composeTemplate = `version: '3'
 services:
 {{- $version := .UbuntuVersion }}
 {{- $pubkey := .SSHPubKey }}
-{{- range $i, $ip := .IPs }}
-  ubuntu{{$i}}:
+{{- range .Containers }}
+  ubuntu{{.Index}}:
     image: ubuntu:{{$version}}
-    container_name: ubuntu_container{{$i}}
+    container_name: ubuntu_container{{.Index}}
     networks:
       e2e:
-        ipv4_address: {{$ip}}
+        ipv4_address: {{.IP}}
     command: >
 	    /bin/bash -c "export DEBIAN_FRONTEND=noninteractive; set -e; sshd -V || apt-get update && apt-get install -y sudo openssh-server;
 		  id ubuntu || useradd -m -s /bin/bash ubuntu; mkdir -p /home/ubuntu/.ssh;
@@ -42,12 +42,18 @@ networks:
 
 // Config holds the information needed for the template
 type Config struct {
-	IPs           []string
+	Containers    []ComposeContainer
 	UbuntuVersion string
 	NetworkPrefix string
 	SSHPubKey     string
 }
 
+// ComposeContainer is a container service of the Docker Compose file
+type ComposeContainer struct {
+	Index int
+	IP    string
+}
+
 // IsE2E checks if the environment variable "RUN_E2E" is set and returns true if it is, false otherwise.
 func IsE2E() bool {
 	return os.Getenv("RUN_E2E") != ""
@@ -63,12 +69,22 @@ func E2EDocker() bool {
 
 // GenDockerComposeFile generates a Docker Compose file with the specified number of nodes and Ubuntu version.
 func GenDockerComposeFile(nodes int, ubuntuVersion string, networkPrefix string, sshPubKey string) (string, error) {
-	var ips []string
-	for i := 1; i <= nodes; i++ {
-		ips = append(ips, fmt.Sprintf("%s.%d", networkPrefix, i+1))
+	indexes := []int{}
+	for i := 0; i < nodes; i++ {
+		indexes = append(indexes, i)
+	}
+	return GenDockerComposeFileForContainers(indexes, ubuntuVersion, networkPrefix, sshPubKey)
+}
+
+// GenDockerComposeFileForContainers generates a Docker Compose file with the containers of the given
+// 0 based indexes. Container i is named ubuntu_container<i> and is reachable at <networkPrefix>.<i+2>
+func GenDockerComposeFileForContainers(indexes []int, ubuntuVersion string, networkPrefix string, sshPubKey string) (string, error) {
+	containers := []ComposeContainer{}
+	for _, i := range indexes {
+		containers = append(containers, ComposeContainer{Index: i, IP: fmt.Sprintf("%s.%d", networkPrefix, i+2)})
 	}
 	config := Config{
-		IPs:           ips,
+		Containers:    containers,
 		UbuntuVersion: ubuntuVersion,
 		NetworkPrefix: networkPrefix,
 		SSHPubKey:     base64.StdEncoding.EncodeToString([]byte(sshPubKey)),
@@ -3,6 +3,7 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"golang.org/x/exp/slices"
 
 	"github.com/ixAnkit/cryft/pkg/constants"
+	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
 )
 
@@ -101,3 +103,82 @@ func IsSSHPubKey(pubkey string) bool {
 	// Check if the key matches the pattern
 	return regex.MatchString(key)
 }
+
+// AuthorizedKey is a public key of an ssh authorized_keys file
+type AuthorizedKey struct {
+	Type        string
+	Comment     string
+	Fingerprint string
+}
+
+// ParseAuthorizedKeys returns the keys of the authorized_keys file [content], skipping
+// comments and lines that are not keys
+func ParseAuthorizedKeys(content []byte) []AuthorizedKey {
+	keys := []AuthorizedKey{}
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if key, ok := parseAuthorizedKey(line); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// RemoveAuthorizedKeys returns the authorized_keys file [content] without the keys
+// matching [key], and the keys removed
+func RemoveAuthorizedKeys(content []byte, key string) ([]byte, []AuthorizedKey) {
+	kept := [][]byte{}
+	removed := []AuthorizedKey{}
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if authorizedKey, ok := parseAuthorizedKey(line); ok && authorizedKey.Matches(key) {
+			removed = append(removed, authorizedKey)
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return bytes.Join(kept, []byte("\n")), removed
+}
+
+// Matches tells if the key is [key], given as public key, SHA256 fingerprint or comment
+func (k AuthorizedKey) Matches(key string) bool {
+	key = strings.TrimSpace(strings.Trim(key, "\"'"))
+	if key == "" {
+		return false
+	}
+	if key == k.Fingerprint || key == k.Comment {
+		return true
+	}
+	if publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key)); err == nil {
+		return ssh.FingerprintSHA256(publicKey) == k.Fingerprint
+	}
+	return false
+}
+
+// GetSSHPublicKeyFingerprint returns the SHA256 fingerprint of the public key of the
+// unencrypted private key at [privateKeyPath]
+func GetSSHPublicKeyFingerprint(privateKeyPath string) (string, error) {
+	privateKey, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return "", err
+	}
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ssh private key %s: %w", privateKeyPath, err)
+	}
+	return ssh.FingerprintSHA256(signer.PublicKey()), nil
+}
+
+func parseAuthorizedKey(line []byte) (AuthorizedKey, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return AuthorizedKey{}, false
+	}
+	publicKey, comment, _, _, err := ssh.ParseAuthorizedKey(line)
+	if err != nil {
+		return AuthorizedKey{}, false
+	}
+	return AuthorizedKey{
+		Type:        publicKey.Type(),
+		Comment:     comment,
+		Fingerprint: ssh.FingerprintSHA256(publicKey),
+	}, true
+}
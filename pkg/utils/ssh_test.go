@@ -2,7 +2,23 @@
 // See the file LICENSE for licensing terms.
 package utils
 
-import "testing"
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	aliceKey         = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIIH9LjJ9qbQBdq1Fzmw6CwPGlHq3mU0oub42E6UxNaPJ alice@laptop"
+	aliceFingerprint = "SHA256:6s7YE2nlgbN8FP162+w5OywdObgRly66v5RWB2/aQL4"
+	bobKey           = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIASV8IMF/PnBCExPyPGzhUOUUcIbdhvj+oLJQVUkL8cu bob@laptop"
+	bobFingerprint   = "SHA256:VKF54nHzWzo0nruSp8nH51zmeuLmeORAuRUfzRH//UU"
+)
 
 func TestIsSSHKey(t *testing.T) {
 	testCases := []struct {
@@ -41,3 +57,63 @@ func TestIsSSHKey(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAuthorizedKeys(t *testing.T) {
+	content := []byte("# managed keys\n" + aliceKey + "\n\nnot a key\n" + bobKey + "\n")
+	keys := ParseAuthorizedKeys(content)
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 keys, but got %d", len(keys))
+	}
+	if keys[0].Type != "ssh-ed25519" || keys[0].Comment != "alice@laptop" || keys[0].Fingerprint != aliceFingerprint {
+		t.Errorf("Unexpected first key %+v", keys[0])
+	}
+	if keys[1].Comment != "bob@laptop" || keys[1].Fingerprint != bobFingerprint {
+		t.Errorf("Unexpected second key %+v", keys[1])
+	}
+}
+
+func TestRemoveAuthorizedKeys(t *testing.T) {
+	content := []byte("# managed keys\n" + aliceKey + "\n" + bobKey + "\n")
+	for _, key := range []string{bobKey, bobFingerprint, "bob@laptop", "'" + bobKey + "'"} {
+		kept, removed := RemoveAuthorizedKeys(content, key)
+		if len(removed) != 1 || removed[0].Fingerprint != bobFingerprint {
+			t.Errorf("Expected bob's key to be removed by %q, but got %+v", key, removed)
+		}
+		if expected := "# managed keys\n" + aliceKey + "\n"; string(kept) != expected {
+			t.Errorf("Expected %q to be kept for %q, but got %q", expected, key, kept)
+		}
+	}
+	kept, removed := RemoveAuthorizedKeys(content, "carol@laptop")
+	if len(removed) != 0 || string(kept) != string(content) {
+		t.Errorf("Expected no key to be removed, but got %+v", removed)
+	}
+	if _, removed := RemoveAuthorizedKeys(content, ""); len(removed) != 0 {
+		t.Errorf("Expected no key to be removed by an empty key, but got %+v", removed)
+	}
+}
+
+func TestGetSSHPublicKeyFingerprint(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, err := GetSSHPublicKeyFingerprint(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := ssh.FingerprintSHA256(signer.PublicKey()); fingerprint != expected {
+		t.Errorf("Expected fingerprint %s, but got %s", expected, fingerprint)
+	}
+}
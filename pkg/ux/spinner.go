@@ -35,9 +35,14 @@ func NewUserSpinner() *UserSpinner {
 	return spinner
 }
 
+// Stop stops the spinners. It does nothing if none was started or they are already
+// stopped, as the spinner manager blocks on those
 func (us *UserSpinner) Stop() {
 	us.mutex.Lock()
-	us.spinner.Stop()
+	if us.started {
+		us.spinner.Stop()
+		us.started = false
+	}
 	us.mutex.Unlock()
 }
 